@@ -0,0 +1,404 @@
+// Package api defines the request and response bodies of the public
+// Chirpy API. The server encodes and decodes with these types, and the
+// client package re-exports them, so the two can't drift apart. Admin
+// endpoints are internal and keep their types next to their handlers.
+package api
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User is a Chirpy account.
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Email     string    `json:"email"`
+	// Username is null for accounts that haven't picked one.
+	Username *string `json:"username"`
+	// DisplayName and Bio are HTML: the display name is escaped text and
+	// the bio may also use <a>, <b>, <br>, <em>, <i> and <strong>.
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	// Website is an http or https URL. WebsiteVerified is set once the
+	// site links back to the profile with rel="me".
+	Website         *string `json:"website"`
+	WebsiteVerified bool    `json:"website_verified"`
+	// AvatarURL is a path under /media, set with POST /api/users/me/avatar.
+	AvatarURL *string `json:"avatar_url"`
+}
+
+// Profile is the public view of a user, from GET /api/users/{userID}.
+// Handle is the username, or the email for accounts without one.
+type Profile struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName *string   `json:"display_name"`
+	Bio         *string   `json:"bio"`
+	Website     *string   `json:"website"`
+	// WebsiteVerified is set once the website links back to the profile
+	// with rel="me".
+	WebsiteVerified bool      `json:"website_verified"`
+	AvatarURL       *string   `json:"avatar_url"`
+	CreatedAt       time.Time `json:"created_at"`
+	ChirpCount      int64     `json:"chirp_count"`
+	// ShareCount is how often the user's chirps have been shared.
+	ShareCount int64 `json:"share_count"`
+}
+
+// Credentials is the body of sign up, login, account updates and restores.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// Username is optional. Updates that leave it out keep the current one.
+	Username string `json:"username,omitempty"`
+	// DisplayName, Bio and Website are only read by account updates.
+	// Leaving them out keeps the current value; an empty string clears it.
+	// HTML the fields don't allow is removed, and websites without a
+	// scheme get https.
+	DisplayName *string `json:"display_name,omitempty"`
+	Bio         *string `json:"bio,omitempty"`
+	Website     *string `json:"website,omitempty"`
+	// RememberMe is only read by login. False starts a short session;
+	// leaving it out or true starts the long one.
+	RememberMe *bool `json:"remember_me,omitempty"`
+	// TOTPCode or RecoveryCode is required by login once two-factor
+	// authentication is enabled.
+	TOTPCode     string `json:"totp_code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+	// VerificationCode is the code emailed when a login comes from
+	// somewhere the user couldn't have travelled to since their last one.
+	VerificationCode string `json:"verification_code,omitempty"`
+}
+
+// TwoFactorSetup is returned by POST /api/users/me/2fa/enable. The secret
+// is added to an authenticator app, usually by showing OTPAuthURL as a
+// QR code, and takes effect once a code from it is confirmed.
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// TwoFactorConfirmRequest is the body of POST /api/users/me/2fa/confirm.
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodes is returned when two-factor authentication is enabled.
+// Each code logs in once in place of a TOTP code. They are only shown
+// this once.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// PasswordResetRequest is the body of POST /api/password_reset/request.
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// PasswordResetConfirmRequest is the body of POST
+// /api/password_reset/confirm, with the token from the reset email.
+type PasswordResetConfirmRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// DeviceRegistration is the body of POST /api/devices. Platform is apns
+// or fcm, and Token is the device token the platform gave the app.
+type DeviceRegistration struct {
+	Token    string `json:"token"`
+	Platform string `json:"platform"`
+}
+
+// LoginResponse is returned by POST /api/login.
+type LoginResponse struct {
+	User
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshResponse is returned by POST /api/refresh. RefreshToken
+// replaces the one the request was made with, which no longer works.
+type RefreshResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Chirp is a single post.
+type Chirp struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	// BodyHTML is the body escaped for use as HTML, with line breaks as
+	// <br>.
+	BodyHTML string    `json:"body_html"`
+	UserID   uuid.UUID `json:"user_id"`
+	// Username is the author's, null if they haven't picked one.
+	Username   *string `json:"username"`
+	LikesCount int64   `json:"likes_count"`
+	// ParentChirpID is set on replies. The parent may since have been
+	// deleted, by its author or along with their account; replies are
+	// kept either way.
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id"`
+	ReplyCount    int64      `json:"reply_count"`
+	RechirpCount  int64      `json:"rechirp_count"`
+	// ShareCount counts shares outside Chirpy, one per user and channel.
+	ShareCount int64 `json:"share_count"`
+	// Rechirp is set when a listing shows the chirp because someone
+	// rechirped it. The other fields describe the original chirp.
+	Rechirp *Rechirp `json:"rechirp"`
+	// ImageURL is a path under /media, set with PUT
+	// /api/chirps/{chirpID}/image. Images the author keeps to followers
+	// get a signed link that lasts a few minutes, shown only to the
+	// author and their followers; it is null for everyone else.
+	ImageURL *string `json:"image_url"`
+}
+
+// Rechirp is a user's repost of a chirp.
+type Rechirp struct {
+	ID        uuid.UUID `json:"id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShareRequest is the body of POST /api/chirps/{chirpID}/share. Channel
+// is where the chirp was shared: link, email, sms, x, facebook, whatsapp
+// or other.
+type ShareRequest struct {
+	Channel string `json:"channel"`
+}
+
+// ReportReason is a reason chirps can be reported for, from
+// GET /api/report_reasons.
+type ReportReason struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// ReportRequest is the body of POST /api/chirps/{chirpID}/report.
+// Reason is the Code of a ReportReason.
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+// ChirpRequest is the body of creating or editing a chirp.
+type ChirpRequest struct {
+	Body string `json:"body"`
+	// ParentChirpID makes a new chirp a reply. It is ignored when editing.
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id,omitempty"`
+	// DryRun validates and cleans a new chirp, and returns it as it would
+	// be posted, without posting it. It is ignored when editing.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Collection is a public, named list of chirps curated by a user.
+// Chirps is only filled in by GET /api/collections/{collectionID}, in
+// the owner's order.
+type Collection struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Chirps      []Chirp   `json:"chirps,omitempty"`
+}
+
+// CollectionRequest is the body of creating or renaming a collection.
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CollectionChirpRequest is the body of POST
+// /api/collections/{collectionID}/chirps, which appends a chirp.
+type CollectionChirpRequest struct {
+	ChirpID uuid.UUID `json:"chirp_id"`
+}
+
+// CollectionChirpsRequest is the body of PUT
+// /api/collections/{collectionID}/chirps. It replaces the chirps of the
+// collection with ChirpIDs, in that order.
+type CollectionChirpsRequest struct {
+	ChirpIDs []uuid.UUID `json:"chirp_ids"`
+}
+
+// FeedChirp is a chirp in a feed, with why it was included: "followed" or
+// "recommended".
+type FeedChirp struct {
+	Chirp
+	Reason string `json:"reason"`
+}
+
+// FeedMarker is the newest chirp a user has read on a timeline.
+type FeedMarker struct {
+	Timeline       string    `json:"timeline"`
+	ChirpID        uuid.UUID `json:"chirp_id"`
+	ChirpCreatedAt time.Time `json:"chirp_created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// FeedMarkerRequest is the body of PUT /api/feed/markers/{timeline}.
+type FeedMarkerRequest struct {
+	ChirpID uuid.UUID `json:"chirp_id"`
+}
+
+// FeedPage is one page of GET /api/feed or GET /api/feed/for_you.
+// NextCursor is empty on the last page.
+type FeedPage struct {
+	Ranking    string      `json:"ranking"`
+	Chirps     []FeedChirp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	LastRead   *FeedMarker `json:"last_read"`
+}
+
+// FeedSummary is returned by GET /api/feed/summary.
+type FeedSummary struct {
+	Since                  time.Time `json:"since"`
+	NewChirpsFromFollowing int64     `json:"new_chirps_from_following"`
+	NewFollowers           int64     `json:"new_followers"`
+	Highlights             []Chirp   `json:"highlights"`
+}
+
+// Digest is the daily summary returned by GET /api/digest. It is
+// computed once a day; Date is the UTC day it was computed.
+type Digest struct {
+	Date string `json:"date"`
+	// ChirpOfTheDay is the most engaging chirp of the last day, null on a
+	// day without any engagement.
+	ChirpOfTheDay *Chirp `json:"chirp_of_the_day"`
+	// Top are the most engaging chirps overall, Network those by users
+	// the user follows.
+	Top     []Chirp `json:"top"`
+	Network []Chirp `json:"network"`
+}
+
+// Settings are a user's preferences.
+type Settings struct {
+	FeedRanking string `json:"feed_ranking"`
+	// EmailDigest subscribes the user to the daily digest by email.
+	EmailDigest bool `json:"email_digest"`
+	// Notifications says, per event type (mention, like, follow, dm,
+	// rechirp), which channels (in_app, email, push) deliver it.
+	Notifications map[string]map[string]bool `json:"notifications"`
+	// QuietHours is null when the user has none.
+	QuietHours *QuietHours `json:"quiet_hours"`
+}
+
+// QuietHours are the daily hours during which email and push
+// notifications wait, to arrive afterwards as one summary per channel.
+// Start and End are "HH:MM" in Timezone, an IANA name such as
+// "Europe/Paris" that defaults to UTC. They may span midnight.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// SettingsRequest is the body of PUT /api/users/me/settings. Nil fields
+// are left unchanged.
+type SettingsRequest struct {
+	FeedRanking *string `json:"feed_ranking,omitempty"`
+	EmailDigest *bool   `json:"email_digest,omitempty"`
+	// Notifications only changes the event and channel pairs it lists.
+	Notifications map[string]map[string]bool `json:"notifications,omitempty"`
+	// QuietHours with an empty Start and End turns quiet hours off.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// ValidateChirpRequest is the body of POST /api/validate_chirp.
+//
+// Deprecated: create the chirp with ChirpRequest.DryRun set instead.
+type ValidateChirpRequest struct {
+	Body string `json:"body"`
+}
+
+// ValidateChirpResponse is returned by POST /api/validate_chirp.
+//
+// Deprecated: create the chirp with ChirpRequest.DryRun set instead.
+type ValidateChirpResponse struct {
+	CleanedBody string `json:"cleaned_body"`
+}
+
+// ModerationAction is an action moderators took against the user, from
+// GET /api/users/me/moderation. Kind is remove_chirp or suspend_account.
+type ModerationAction struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Kind       string     `json:"kind"`
+	ReasonCode string     `json:"reason_code"`
+	ChirpID    *uuid.UUID `json:"chirp_id,omitempty"`
+	ReversedAt *time.Time `json:"reversed_at"`
+}
+
+// TrustLevel is the user's trust level, new, basic or trusted, from
+// GET /api/users/me/trust. Capabilities are what it allows: post_links,
+// upload_media and higher_rate_limit.
+type TrustLevel struct {
+	Level        string   `json:"level"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// AppealRequest is the body of POST /api/appeals.
+type AppealRequest struct {
+	ActionID uuid.UUID `json:"action_id"`
+	Message  string    `json:"message"`
+}
+
+// Appeal asks moderators to reconsider an action. Status is pending,
+// approved (the action was reversed) or denied (the action stands).
+type Appeal struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ActionID  uuid.UUID  `json:"action_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	DecidedAt *time.Time `json:"decided_at"`
+}
+
+// UserExists reports whether an email or username is taken.
+type UserExists struct {
+	Exists bool `json:"exists"`
+}
+
+// Hashtag is a tag and how often it was used.
+type Hashtag struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// Stats is returned by GET /api/stats.
+type Stats struct {
+	TotalChirps     int64     `json:"total_chirps"`
+	ActiveUsersWeek int64     `json:"active_users_this_week"`
+	TopHashtags     []Hashtag `json:"top_hashtags"`
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// ErrorResponse is the body of every error response. RetryAfter is set,
+// in seconds, when the request was rate limited. Code tells apart errors
+// that clients handle specially, such as CodeReadOnly. RequestID
+// identifies the request in the server's logs, for reporting the
+// failure.
+type ErrorResponse struct {
+	Error      string `json:"error"`
+	Code       string `json:"code,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+// Error codes, in ErrorResponse.Code.
+const (
+	// CodeReadOnly is the Code of the 503 a write gets while the server
+	// is in read-only mode. Reads still work; retry the write later.
+	CodeReadOnly = "read_only"
+	// CodeBodyTooLarge is the Code of the 413 a request gets when its
+	// body is over the server's limit.
+	CodeBodyTooLarge = "body_too_large"
+)
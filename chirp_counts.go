@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"main.go/api"
+)
+
+// chirpCounts holds the like, reply, rechirp and share counts of a set of
+// chirps, along with their authors' usernames and the image links the
+// viewer may see. Chirps without any are missing from the maps.
+type chirpCounts struct {
+	likes     map[uuid.UUID]int64
+	replies   map[uuid.UUID]int64
+	rechirps  map[uuid.UUID]int64
+	shares    map[uuid.UUID]int64
+	usernames map[uuid.UUID]string
+	images    map[uuid.UUID]string
+}
+
+// countChirps fetches like, reply, rechirp and share counts, author
+// usernames and image links for the given chirps. The links are those
+// of the user in ctx, if any; see chirpImageLinks.
+func (cfg *apiConfig) countChirps(ctx context.Context, chirpIDs []uuid.UUID) (chirpCounts, error) {
+	counts := chirpCounts{
+		likes:     make(map[uuid.UUID]int64, len(chirpIDs)),
+		replies:   make(map[uuid.UUID]int64, len(chirpIDs)),
+		rechirps:  make(map[uuid.UUID]int64, len(chirpIDs)),
+		shares:    make(map[uuid.UUID]int64, len(chirpIDs)),
+		usernames: make(map[uuid.UUID]string, len(chirpIDs)),
+		images:    make(map[uuid.UUID]string),
+	}
+	if len(chirpIDs) == 0 {
+		return counts, nil
+	}
+
+	likes, err := cfg.DB.GetLikeCounts(ctx, chirpIDs)
+	if err != nil {
+		return counts, err
+	}
+	for _, row := range likes {
+		counts.likes[row.ChirpID] = row.LikesCount
+	}
+
+	replies, err := cfg.DB.GetReplyCounts(ctx, chirpIDs)
+	if err != nil {
+		return counts, err
+	}
+	for _, row := range replies {
+		counts.replies[row.ParentChirpID.UUID] = row.ReplyCount
+	}
+
+	rechirps, err := cfg.DB.GetRechirpCounts(ctx, chirpIDs)
+	if err != nil {
+		return counts, err
+	}
+	for _, row := range rechirps {
+		counts.rechirps[row.ChirpID] = row.RechirpCount
+	}
+
+	shares, err := cfg.DB.GetShareCounts(ctx, chirpIDs)
+	if err != nil {
+		return counts, err
+	}
+	for _, row := range shares {
+		counts.shares[row.ChirpID] = row.ShareCount
+	}
+
+	usernames, err := cfg.DB.GetChirpAuthorUsernames(ctx, chirpIDs)
+	if err != nil {
+		return counts, err
+	}
+	for _, row := range usernames {
+		counts.usernames[row.ID] = row.Username.String
+	}
+
+	counts.images, err = cfg.chirpImageLinks(ctx, contextUserID(ctx), chirpIDs)
+	return counts, err
+}
+
+// apply fills in the counts, author username and image of a chirp.
+func (c chirpCounts) apply(chirp *api.Chirp) {
+	chirp.LikesCount = c.likes[chirp.ID]
+	chirp.ReplyCount = c.replies[chirp.ID]
+	chirp.RechirpCount = c.rechirps[chirp.ID]
+	chirp.ShareCount = c.shares[chirp.ID]
+	if username, ok := c.usernames[chirp.ID]; ok {
+		chirp.Username = &username
+	}
+	if image, ok := c.images[chirp.ID]; ok {
+		chirp.ImageURL = &image
+	}
+}
+
+// addChirpCounts fills in the counts, author username and image of each
+// chirp.
+func (cfg *apiConfig) addChirpCounts(ctx context.Context, chirps []api.Chirp) error {
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		ids = append(ids, c.ID)
+	}
+	counts, err := cfg.countChirps(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		counts.apply(&chirps[i])
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPageSize is how many items the iterators fetch per request.
+const DefaultPageSize = 100
+
+// CreateChirp posts a chirp as the logged in user.
+func (c *Client) CreateChirp(ctx context.Context, body string) (Chirp, error) {
+	var chirp Chirp
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps",
+		body:          ChirpRequest{Body: body},
+		authenticated: true,
+	}, &chirp)
+	return chirp, err
+}
+
+// Reply posts a reply to a chirp as the logged in user.
+func (c *Client) Reply(ctx context.Context, parentID uuid.UUID, body string) (Chirp, error) {
+	var chirp Chirp
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps",
+		body:          ChirpRequest{Body: body, ParentChirpID: &parentID},
+		authenticated: true,
+	}, &chirp)
+	return chirp, err
+}
+
+// PreviewChirp checks and cleans a chirp or reply without posting it. The
+// result is the chirp as CreateChirp or Reply would post it, without an
+// ID. parentID may be nil.
+func (c *Client) PreviewChirp(ctx context.Context, body string, parentID *uuid.UUID) (Chirp, error) {
+	var chirp Chirp
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps",
+		body:          ChirpRequest{Body: body, ParentChirpID: parentID, DryRun: true},
+		authenticated: true,
+	}, &chirp)
+	return chirp, err
+}
+
+// GetChirp fetches a single chirp.
+func (c *Client) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	var chirp Chirp
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/chirps/" + id.String(),
+	}, &chirp)
+	return chirp, err
+}
+
+// ChirpExists reports whether a chirp exists, without fetching it.
+func (c *Client) ChirpExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return c.head(ctx, "/api/v1/chirps/"+id.String())
+}
+
+// UpdateChirp replaces the body of one of the logged in user's chirps.
+func (c *Client) UpdateChirp(ctx context.Context, id uuid.UUID, body string) (Chirp, error) {
+	var chirp Chirp
+	_, err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/chirps/" + id.String(),
+		body:          ChirpRequest{Body: body},
+		authenticated: true,
+	}, &chirp)
+	return chirp, err
+}
+
+// DeleteChirp deletes one of the logged in user's chirps. It fails with
+// ErrConflict if the chirp is under legal hold.
+func (c *Client) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/api/v1/chirps/" + id.String(),
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// LikeChirp likes a chirp as the logged in user. Liking a chirp twice is
+// not an error.
+func (c *Client) LikeChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps/" + id.String() + "/like",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// UnlikeChirp removes the logged in user's like from a chirp.
+func (c *Client) UnlikeChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/api/v1/chirps/" + id.String() + "/like",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// Rechirp reposts a chirp as the logged in user. Rechirping a chirp twice
+// returns the existing rechirp.
+func (c *Client) Rechirp(ctx context.Context, id uuid.UUID) (Rechirp, error) {
+	var rechirp Rechirp
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps/" + id.String() + "/rechirp",
+		authenticated: true,
+	}, &rechirp)
+	return rechirp, err
+}
+
+// ShareChirp records that the logged in user shared a chirp outside
+// Chirpy over channel. Each channel counts once per user.
+func (c *Client) ShareChirp(ctx context.Context, id uuid.UUID, channel string) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps/" + id.String() + "/share",
+		body:          ShareRequest{Channel: channel},
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// ReportReasons returns the reasons a chirp can be reported for.
+func (c *Client) ReportReasons(ctx context.Context) ([]ReportReason, error) {
+	var reasons []ReportReason
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/report_reasons",
+	}, &reasons)
+	return reasons, err
+}
+
+// ReportChirp reports a chirp to moderators. reason is the Code of one of
+// the ReportReasons. Each user can report a chirp once.
+func (c *Client) ReportChirp(ctx context.Context, id uuid.UUID, reason, details string) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/chirps/" + id.String() + "/report",
+		body:          ReportRequest{Reason: reason, Details: details},
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// SetChirpImage replaces the image of one of the logged in user's chirps
+// with the image read from r. With followersOnly, only the user's
+// followers get a link to it. The server accepts PNG, JPEG, GIF and WebP
+// images up to 2 MiB.
+func (c *Client) SetChirpImage(ctx context.Context, id uuid.UUID, filename string, r io.Reader, followersOnly bool) (Chirp, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile("image", filename)
+	if err != nil {
+		return Chirp{}, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return Chirp{}, err
+	}
+	if err := form.WriteField("followers_only", strconv.FormatBool(followersOnly)); err != nil {
+		return Chirp{}, err
+	}
+	if err := form.Close(); err != nil {
+		return Chirp{}, err
+	}
+
+	var chirp Chirp
+	_, err = c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/chirps/" + id.String() + "/image",
+		rawBody:       buf.Bytes(),
+		contentType:   form.FormDataContentType(),
+		authenticated: true,
+	}, &chirp)
+	return chirp, err
+}
+
+// UndoRechirp removes the logged in user's rechirp of a chirp.
+func (c *Client) UndoRechirp(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/api/v1/chirps/" + id.String() + "/rechirp",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// ListChirpsOptions filters and orders ListChirps.
+type ListChirpsOptions struct {
+	// AuthorID limits the results to what one user posted or rechirped,
+	// if set.
+	AuthorID uuid.UUID
+	// Sort is "asc" (oldest first, the default) or "desc".
+	Sort string
+	// PageSize defaults to DefaultPageSize.
+	PageSize int
+}
+
+// ListChirps iterates over all chirps and rechirps, fetching them a page
+// at a time.
+// Iteration stops at the first error, which is yielded.
+func (c *Client) ListChirps(ctx context.Context, opts ListChirpsOptions) iter.Seq2[Chirp, error] {
+	query := url.Values{}
+	if opts.AuthorID != uuid.Nil {
+		query.Set("author_id", opts.AuthorID.String())
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	return c.paginate(ctx, "/api/v1/chirps", query, opts.PageSize, false)
+}
+
+// SearchChirps iterates over chirps matching a web search style query,
+// best matches first.
+func (c *Client) SearchChirps(ctx context.Context, q string, pageSize int) iter.Seq2[Chirp, error] {
+	return c.paginate(ctx, "/api/v1/chirps/search", url.Values{"q": {q}}, pageSize, false)
+}
+
+// Replies iterates over the direct replies to a chirp, oldest first.
+func (c *Client) Replies(ctx context.Context, id uuid.UUID, pageSize int) iter.Seq2[Chirp, error] {
+	return c.paginate(ctx, "/api/v1/chirps/"+id.String()+"/replies", nil, pageSize, false)
+}
+
+// HashtagChirps iterates over chirps using a hashtag, newest first. The
+// tag is matched case-insensitively, with or without the leading #.
+func (c *Client) HashtagChirps(ctx context.Context, tag string, pageSize int) iter.Seq2[Chirp, error] {
+	return c.paginate(ctx, "/api/v1/hashtags/"+url.PathEscape(strings.TrimPrefix(tag, "#"))+"/chirps", nil, pageSize, false)
+}
+
+// Mentions iterates over chirps that @mention the logged in user, newest
+// first.
+func (c *Client) Mentions(ctx context.Context, pageSize int) iter.Seq2[Chirp, error] {
+	return c.paginate(ctx, "/api/v1/users/me/mentions", nil, pageSize, true)
+}
+
+// paginate walks a limit/offset paginated list of chirps.
+func (c *Client) paginate(ctx context.Context, path string, query url.Values, pageSize int, authenticated bool) iter.Seq2[Chirp, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(Chirp, error) bool) {
+		for offset := 0; ; {
+			q := url.Values{}
+			for k, v := range query {
+				q[k] = v
+			}
+			q.Set("limit", strconv.Itoa(pageSize))
+			q.Set("offset", strconv.Itoa(offset))
+
+			var page []Chirp
+			header, err := c.do(ctx, request{method: http.MethodGet, path: path, query: q, authenticated: authenticated}, &page)
+			if err != nil {
+				yield(Chirp{}, err)
+				return
+			}
+			for _, chirp := range page {
+				if !yield(chirp, nil) {
+					return
+				}
+			}
+
+			offset += len(page)
+			total, ok := totalCount(header)
+			if len(page) < pageSize || (ok && offset >= total) {
+				return
+			}
+		}
+	}
+}
+
+// Stats returns public, aggregate numbers about the server.
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/stats",
+	}, &stats)
+	return stats, err
+}
@@ -0,0 +1,188 @@
+// Package client is a Go client for the Chirpy API.
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.Login(ctx, "me@example.com", "hunter2"); err != nil {
+//		return err
+//	}
+//	for chirp, err := range c.ListChirps(ctx, client.ListChirpsOptions{Sort: "desc"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(chirp.Body)
+//	}
+//
+// After Login the client sends the access token on every request and,
+// when it expires, fetches a new one with the refresh token and retries.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client talks to a Chirpy server. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+
+	// refreshMu lets one expired request at a time refresh, since each
+	// refresh token can only be used once
+	refreshMu sync.Mutex
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTokens starts the client with previously issued tokens, e.g. ones
+// saved from an earlier LoginResponse.
+func WithTokens(accessToken, refreshToken string) Option {
+	return func(c *Client) {
+		c.accessToken = accessToken
+		c.refreshToken = refreshToken
+	}
+}
+
+// New creates a client for the server at baseURL, e.g.
+// "https://chirpy.example.com".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tokens returns the current access and refresh tokens.
+func (c *Client) Tokens() (accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken, c.refreshToken
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// rawBody is sent as is, with contentType, instead of JSON encoding
+	// body
+	rawBody     []byte
+	contentType string
+	// authenticated requests carry the access token
+	authenticated bool
+	// token overrides the bearer token, for endpoints that take the
+	// refresh token
+	token string
+}
+
+// do sends req and decodes a successful response into out, if not nil.
+// If the access token has expired it is refreshed once and the request
+// retried.
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	accessToken, _ := c.Tokens()
+	header, err := c.send(ctx, req, out)
+	if !req.authenticated || !errors.Is(err, ErrUnauthorized) {
+		return header, err
+	}
+	if _, refreshToken := c.Tokens(); refreshToken == "" {
+		return header, err
+	}
+
+	c.refreshMu.Lock()
+	// Another request may have refreshed while this one was in flight
+	var refreshErr error
+	if current, _ := c.Tokens(); current == accessToken {
+		_, refreshErr = c.Refresh(ctx)
+	}
+	c.refreshMu.Unlock()
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	return c.send(ctx, req, out)
+}
+
+func (c *Client) send(ctx context.Context, req request, out any) (http.Header, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := req.contentType
+	if req.rawBody != nil {
+		body = bytes.NewReader(req.rawBody)
+	} else if req.body != nil {
+		dat, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(dat)
+		contentType = "application/json"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	token := req.token
+	if req.authenticated {
+		token, _ = c.Tokens()
+		if token == "" {
+			return nil, ErrNotLoggedIn
+		}
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return resp.Header, newAPIError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.Header, fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err)
+	}
+	return resp.Header, nil
+}
+
+// totalCount reads the X-Total-Count header of a paginated response.
+func totalCount(header http.Header) (int, bool) {
+	n, err := strconv.Atoi(header.Get("X-Total-Count"))
+	return n, err == nil
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+)
+
+func TestRefreshesExpiredToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer refresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(RefreshResponse{Token: "fresh", RefreshToken: "rotated"})
+	})
+	mux.HandleFunc("GET /api/v1/users/me/settings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid token"})
+			return
+		}
+		json.NewEncoder(w).Encode(Settings{FeedRanking: "top"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL, WithTokens("stale", "refresh"))
+	settings, err := c.Settings(context.Background())
+	if err != nil {
+		t.Fatalf("Settings() error = %v", err)
+	}
+	if settings.FeedRanking != "top" {
+		t.Errorf("FeedRanking = %q, want top", settings.FeedRanking)
+	}
+	if access, refresh := c.Tokens(); access != "fresh" || refresh != "rotated" {
+		t.Errorf("tokens = %q, %q, want fresh, rotated", access, refresh)
+	}
+}
+
+func TestConcurrentRequestsRefreshOnce(t *testing.T) {
+	var mu sync.Mutex
+	refreshes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/refresh", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		refreshes++
+		// Refresh tokens only work once
+		if r.Header.Get("Authorization") != "Bearer refresh" || refreshes > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(RefreshResponse{Token: "fresh", RefreshToken: "rotated"})
+	})
+	mux.HandleFunc("GET /api/v1/users/me/settings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(Settings{FeedRanking: "top"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL, WithTokens("stale", "refresh"))
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Settings(context.Background()); err != nil {
+				t.Errorf("Settings() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if refreshes != 1 {
+		t.Errorf("refreshed %d times, want once", refreshes)
+	}
+}
+
+func TestListChirpsPaginates(t *testing.T) {
+	all := make([]Chirp, 5)
+	for i := range all {
+		all[i] = Chirp{ID: uuid.New(), Body: strconv.Itoa(i)}
+	}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := min(offset+limit, len(all))
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(all)))
+		json.NewEncoder(w).Encode(all[offset:end])
+	}))
+	defer srv.Close()
+
+	var got []string
+	for chirp, err := range New(srv.URL).ListChirps(context.Background(), ListChirpsOptions{PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("ListChirps() error = %v", err)
+		}
+		got = append(got, chirp.Body)
+	}
+	if len(got) != len(all) {
+		t.Fatalf("got %d chirps, want %d", len(got), len(all))
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Rate limit exceeded", RetryAfter: 30, RequestID: "req-1"})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).GetChirp(context.Background(), uuid.New())
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want ErrRateLimited", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %T, want *APIError", err)
+	}
+	if apiErr.Message != "Rate limit exceeded" || apiErr.RetryAfter != 30*time.Second || apiErr.RequestID != "req-1" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
+
+func TestReadOnlyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Chirpy is read-only for maintenance", Code: api.CodeReadOnly})
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL).RequestPasswordReset(context.Background(), "a@example.com")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("error = %v, want ErrReadOnly", err)
+	}
+	if errors.Is(err, ErrRateLimited) {
+		t.Error("read-only error matches ErrRateLimited")
+	}
+}
+
+func TestChirpExists(t *testing.T) {
+	known := uuid.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		if r.URL.Path != "/api/v1/chirps/"+known.String() {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	for id, want := range map[uuid.UUID]bool{known: true, uuid.New(): false} {
+		got, err := c.ChirpExists(context.Background(), id)
+		if err != nil {
+			t.Fatalf("ChirpExists() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("ChirpExists(%s) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestUploadAvatarRetriesWithBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/refresh", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(RefreshResponse{Token: "fresh"})
+	})
+	mux.HandleFunc("POST /api/v1/users/me/avatar", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		dat, _ := io.ReadAll(file)
+		if header.Filename != "me.png" || string(dat) != "image" {
+			t.Errorf("got %q with %q, want me.png with %q", header.Filename, dat, "image")
+		}
+		avatar := "/media/avatars/a.png"
+		json.NewEncoder(w).Encode(User{AvatarURL: &avatar})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL, WithTokens("stale", "refresh"))
+	user, err := c.UploadAvatar(context.Background(), "me.png", strings.NewReader("image"))
+	if err != nil {
+		t.Fatalf("UploadAvatar() error = %v", err)
+	}
+	if user.AvatarURL == nil || *user.AvatarURL != "/media/avatars/a.png" {
+		t.Errorf("AvatarURL = %v, want /media/avatars/a.png", user.AvatarURL)
+	}
+}
+
+func TestNotLoggedIn(t *testing.T) {
+	_, err := New("http://localhost").CreateChirp(context.Background(), "hello")
+	if !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("error = %v, want ErrNotLoggedIn", err)
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main.go/api"
+)
+
+var (
+	// ErrNotLoggedIn is returned by calls that need a token before Login.
+	ErrNotLoggedIn = errors.New("chirpy: not logged in")
+
+	// Matched by *APIError with errors.Is, by status code.
+	ErrBadRequest         = errors.New("chirpy: bad request")
+	ErrUnauthorized       = errors.New("chirpy: unauthorized")
+	ErrForbidden          = errors.New("chirpy: forbidden")
+	ErrNotFound           = errors.New("chirpy: not found")
+	ErrConflict           = errors.New("chirpy: conflict")
+	ErrPreconditionFailed = errors.New("chirpy: precondition failed")
+	ErrRateLimited        = errors.New("chirpy: rate limited")
+	ErrTooLarge           = errors.New("chirpy: request too large")
+
+	// ErrReadOnly is matched by the *APIError of a write refused because
+	// the server is in read-only mode.
+	ErrReadOnly = errors.New("chirpy: server is read-only")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:            ErrBadRequest,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusConflict:              ErrConflict,
+	http.StatusPreconditionFailed:    ErrPreconditionFailed,
+	http.StatusTooManyRequests:       ErrRateLimited,
+	http.StatusRequestEntityTooLarge: ErrTooLarge,
+}
+
+// APIError is an error response from the server.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Code is the machine-readable kind of some errors, e.g.
+	// api.CodeReadOnly.
+	Code string
+	// RetryAfter is how long to wait before retrying a rate limited
+	// request.
+	RetryAfter time.Duration
+	// RequestID identifies the request in the server's logs. Include it
+	// when reporting a failure.
+	RequestID string
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+	var body ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		apiErr.Message = body.Error
+		apiErr.Code = body.Code
+		if body.RequestID != "" {
+			apiErr.RequestID = body.RequestID
+		}
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("chirpy: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("chirpy: %d %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether target is the sentinel error for e's status code,
+// or for its Code.
+func (e *APIError) Is(target error) bool {
+	if target == ErrReadOnly {
+		return e.Code == api.CodeReadOnly
+	}
+	return statusErrors[e.StatusCode] == target
+}
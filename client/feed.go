@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// FeedOptions configures the for you feed.
+type FeedOptions struct {
+	// Ranking is "top" or "latest". Empty uses the user's setting.
+	Ranking string
+	// PageSize defaults to the server's page size.
+	PageSize int
+}
+
+// ForYouFeedPage fetches one page of the logged in user's feed. Pass the
+// previous page's NextCursor to continue, or "" to start at the top.
+func (c *Client) ForYouFeedPage(ctx context.Context, opts FeedOptions, cursor string) (FeedPage, error) {
+	query := url.Values{}
+	if opts.Ranking != "" {
+		query.Set("ranking", opts.Ranking)
+	}
+	if opts.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(opts.PageSize))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	return c.feedPage(ctx, "/api/v1/feed/for_you", query)
+}
+
+// ForYouFeed iterates over the whole feed, following cursors.
+func (c *Client) ForYouFeed(ctx context.Context, opts FeedOptions) iter.Seq2[FeedChirp, error] {
+	return followCursors(func(cursor string) (FeedPage, error) {
+		return c.ForYouFeedPage(ctx, opts, cursor)
+	})
+}
+
+// HomeFeedPage fetches one page of chirps from the users the logged in
+// user follows, newest first. Pass the previous page's NextCursor to
+// continue, or "" to start at the top.
+func (c *Client) HomeFeedPage(ctx context.Context, pageSize int, cursor string) (FeedPage, error) {
+	query := url.Values{}
+	if pageSize > 0 {
+		query.Set("limit", strconv.Itoa(pageSize))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	return c.feedPage(ctx, "/api/v1/feed", query)
+}
+
+// HomeFeed iterates over the whole home feed, following cursors.
+func (c *Client) HomeFeed(ctx context.Context, pageSize int) iter.Seq2[FeedChirp, error] {
+	return followCursors(func(cursor string) (FeedPage, error) {
+		return c.HomeFeedPage(ctx, pageSize, cursor)
+	})
+}
+
+func (c *Client) feedPage(ctx context.Context, path string, query url.Values) (FeedPage, error) {
+	var page FeedPage
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          path,
+		query:         query,
+		authenticated: true,
+	}, &page)
+	return page, err
+}
+
+// followCursors yields the chirps of each page returned by fetch until a
+// page has no next cursor.
+func followCursors(fetch func(cursor string) (FeedPage, error)) iter.Seq2[FeedChirp, error] {
+	return func(yield func(FeedChirp, error) bool) {
+		cursor := ""
+		for {
+			page, err := fetch(cursor)
+			if err != nil {
+				yield(FeedChirp{}, err)
+				return
+			}
+			for _, chirp := range page.Chirps {
+				if !yield(chirp, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
+
+// FeedSummary returns what happened since the user last loaded the
+// summary, and marks them as seen.
+func (c *Client) FeedSummary(ctx context.Context) (FeedSummary, error) {
+	var summary FeedSummary
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/feed/summary",
+		authenticated: true,
+	}, &summary)
+	return summary, err
+}
+
+// Digest returns the latest daily digest. It fails with a 404 before the
+// first one has been computed.
+func (c *Client) Digest(ctx context.Context) (Digest, error) {
+	var digest Digest
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/digest",
+		authenticated: true,
+	}, &digest)
+	return digest, err
+}
+
+// FeedMarkers returns the user's last read position on each timeline.
+func (c *Client) FeedMarkers(ctx context.Context) ([]FeedMarker, error) {
+	var markers []FeedMarker
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/feed/markers",
+		authenticated: true,
+	}, &markers)
+	return markers, err
+}
+
+// SetFeedMarker records chirpID as read on a timeline. Markers only move
+// forward; the returned marker is the current one.
+func (c *Client) SetFeedMarker(ctx context.Context, timeline string, chirpID uuid.UUID) (FeedMarker, error) {
+	var marker FeedMarker
+	_, err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/feed/markers/" + url.PathEscape(timeline),
+		body:          FeedMarkerRequest{ChirpID: chirpID},
+		authenticated: true,
+	}, &marker)
+	return marker, err
+}
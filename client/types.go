@@ -0,0 +1,46 @@
+package client
+
+import "main.go/api"
+
+// The API's request and response bodies, shared with the server.
+type (
+	User                        = api.User
+	Profile                     = api.Profile
+	Credentials                 = api.Credentials
+	PasswordResetRequest        = api.PasswordResetRequest
+	PasswordResetConfirmRequest = api.PasswordResetConfirmRequest
+	DeviceRegistration          = api.DeviceRegistration
+	LoginResponse               = api.LoginResponse
+	TwoFactorSetup              = api.TwoFactorSetup
+	TwoFactorConfirmRequest     = api.TwoFactorConfirmRequest
+	RecoveryCodes               = api.RecoveryCodes
+	RefreshResponse             = api.RefreshResponse
+	Chirp                       = api.Chirp
+	ChirpRequest                = api.ChirpRequest
+	Rechirp                     = api.Rechirp
+	ShareRequest                = api.ShareRequest
+	Collection                  = api.Collection
+	CollectionRequest           = api.CollectionRequest
+	CollectionChirpRequest      = api.CollectionChirpRequest
+	CollectionChirpsRequest     = api.CollectionChirpsRequest
+	FeedChirp                   = api.FeedChirp
+	FeedMarker                  = api.FeedMarker
+	FeedMarkerRequest           = api.FeedMarkerRequest
+	FeedPage                    = api.FeedPage
+	FeedSummary                 = api.FeedSummary
+	Digest                      = api.Digest
+	Settings                    = api.Settings
+	SettingsRequest             = api.SettingsRequest
+	ValidateChirpRequest        = api.ValidateChirpRequest
+	ValidateChirpResponse       = api.ValidateChirpResponse
+	UserExists                  = api.UserExists
+	ReportReason                = api.ReportReason
+	ReportRequest               = api.ReportRequest
+	ModerationAction            = api.ModerationAction
+	TrustLevel                  = api.TrustLevel
+	AppealRequest               = api.AppealRequest
+	Appeal                      = api.Appeal
+	Hashtag                     = api.Hashtag
+	Stats                       = api.Stats
+	ErrorResponse               = api.ErrorResponse
+)
@@ -0,0 +1,336 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// CreateUser signs up a new account.
+func (c *Client) CreateUser(ctx context.Context, email, password string) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/users",
+		body:   Credentials{Email: email, Password: password},
+	}, &user)
+	return user, err
+}
+
+// CreateUserWithUsername signs up with a username, which is lowercased.
+// It fails with ErrConflict if the username is taken.
+func (c *Client) CreateUserWithUsername(ctx context.Context, email, username, password string) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/users",
+		body:   Credentials{Email: email, Password: password, Username: username},
+	}, &user)
+	return user, err
+}
+
+// Login authenticates and stores the returned tokens on the client.
+func (c *Client) Login(ctx context.Context, email, password string) (LoginResponse, error) {
+	var resp LoginResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/login",
+		body:   Credentials{Email: email, Password: password},
+	}, &resp)
+	if err != nil {
+		return resp, err
+	}
+	c.mu.Lock()
+	c.accessToken = resp.Token
+	c.refreshToken = resp.RefreshToken
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// Refresh exchanges the refresh token for a new access token and a new
+// refresh token, which replaces the old one. Calls made with an expired
+// access token do this automatically.
+func (c *Client) Refresh(ctx context.Context) (string, error) {
+	_, refreshToken := c.Tokens()
+	if refreshToken == "" {
+		return "", ErrNotLoggedIn
+	}
+	var resp RefreshResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/refresh",
+		token:  refreshToken,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.accessToken = resp.Token
+	if resp.RefreshToken != "" {
+		c.refreshToken = resp.RefreshToken
+	}
+	c.mu.Unlock()
+	return resp.Token, nil
+}
+
+// Logout ends the session, revoking its refresh token, and forgets both
+// tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	_, refreshToken := c.Tokens()
+	if refreshToken == "" {
+		return ErrNotLoggedIn
+	}
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/logout",
+		token:  refreshToken,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.accessToken = ""
+	c.refreshToken = ""
+	c.mu.Unlock()
+	return nil
+}
+
+// UpdateUser changes the logged in user's email and password.
+func (c *Client) UpdateUser(ctx context.Context, email, password string) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/users",
+		body:          Credentials{Email: email, Password: password},
+		authenticated: true,
+	}, &user)
+	return user, err
+}
+
+// UpdateProfile is UpdateUser that also sets the display name and bio.
+// Empty strings clear them.
+func (c *Client) UpdateProfile(ctx context.Context, email, password, displayName, bio string) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/users",
+		body:          Credentials{Email: email, Password: password, DisplayName: &displayName, Bio: &bio},
+		authenticated: true,
+	}, &user)
+	return user, err
+}
+
+// UploadAvatar replaces the logged in user's avatar with the image read
+// from r. The server accepts PNG, JPEG, GIF and WebP images up to 2 MiB.
+func (c *Client) UploadAvatar(ctx context.Context, filename string, r io.Reader) (User, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile("avatar", filename)
+	if err != nil {
+		return User{}, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return User{}, err
+	}
+	if err := form.Close(); err != nil {
+		return User{}, err
+	}
+
+	var user User
+	_, err = c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/users/me/avatar",
+		rawBody:       buf.Bytes(),
+		contentType:   form.FormDataContentType(),
+		authenticated: true,
+	}, &user)
+	return user, err
+}
+
+// DeleteAccount schedules the logged in user's account for deletion. It
+// can be restored with RestoreAccount during the grace period. It fails
+// with ErrConflict if the account is under legal hold.
+func (c *Client) DeleteAccount(ctx context.Context) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/api/v1/users",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// RestoreAccount cancels a pending account deletion.
+func (c *Client) RestoreAccount(ctx context.Context, email, password string) (User, error) {
+	var user User
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/users/restore",
+		body:   Credentials{Email: email, Password: password},
+	}, &user)
+	return user, err
+}
+
+// RequestPasswordReset emails a password reset token to the address. It
+// succeeds whether or not the address belongs to an account.
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/password_reset/request",
+		body:   PasswordResetRequest{Email: email},
+	}, nil)
+	return err
+}
+
+// ResetPassword sets a new password with a token from a reset email. The
+// account is logged out everywhere, so Login has to be called afterwards.
+func (c *Client) ResetPassword(ctx context.Context, token, password string) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/password_reset/confirm",
+		body:   PasswordResetConfirmRequest{Token: token, Password: password},
+	}, nil)
+	return err
+}
+
+// Settings returns the logged in user's settings.
+func (c *Client) Settings(ctx context.Context) (Settings, error) {
+	var settings Settings
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/users/me/settings",
+		authenticated: true,
+	}, &settings)
+	return settings, err
+}
+
+// UpdateSettings changes the non-nil fields of req.
+func (c *Client) UpdateSettings(ctx context.Context, req SettingsRequest) (Settings, error) {
+	var settings Settings
+	_, err := c.do(ctx, request{
+		method:        http.MethodPut,
+		path:          "/api/v1/users/me/settings",
+		body:          req,
+		authenticated: true,
+	}, &settings)
+	return settings, err
+}
+
+// Follow follows the user with the given ID.
+func (c *Client) Follow(ctx context.Context, userID uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/users/" + userID.String() + "/follow",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// Unfollow stops following the user with the given ID.
+func (c *Client) Unfollow(ctx context.Context, userID uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method:        http.MethodDelete,
+		path:          "/api/v1/users/" + userID.String() + "/follow",
+		authenticated: true,
+	}, nil)
+	return err
+}
+
+// GetProfile fetches a user's public profile.
+func (c *Client) GetProfile(ctx context.Context, id uuid.UUID) (Profile, error) {
+	var profile Profile
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/users/" + id.String(),
+	}, &profile)
+	return profile, err
+}
+
+// GetProfileByUsername fetches the public profile of the user with
+// username, or of the user who had it until recently.
+func (c *Client) GetProfileByUsername(ctx context.Context, username string) (Profile, error) {
+	var profile Profile
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/usernames/" + url.PathEscape(username),
+	}, &profile)
+	return profile, err
+}
+
+// UserExists reports whether an active account has the given ID.
+func (c *Client) UserExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return c.head(ctx, "/api/v1/users/"+id.String())
+}
+
+// EmailTaken reports whether an account already uses email. The server
+// allows only a few of these checks per minute.
+func (c *Client) EmailTaken(ctx context.Context, email string) (bool, error) {
+	return c.userExists(ctx, url.Values{"email": {email}})
+}
+
+// UsernameTaken reports whether an account already uses username. The
+// server allows only a few of these checks per minute.
+func (c *Client) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	return c.userExists(ctx, url.Values{"username": {username}})
+}
+
+func (c *Client) userExists(ctx context.Context, query url.Values) (bool, error) {
+	var resp UserExists
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/users/exists",
+		query:  query,
+	}, &resp)
+	return resp.Exists, err
+}
+
+// ModerationActions returns the actions moderators took against the
+// logged in user, newest first.
+func (c *Client) ModerationActions(ctx context.Context) ([]ModerationAction, error) {
+	var actions []ModerationAction
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/users/me/moderation",
+		authenticated: true,
+	}, &actions)
+	return actions, err
+}
+
+// Trust returns the logged in user's trust level and the capabilities it
+// unlocks.
+func (c *Client) Trust(ctx context.Context) (TrustLevel, error) {
+	var level TrustLevel
+	_, err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/api/v1/users/me/trust",
+		authenticated: true,
+	}, &level)
+	return level, err
+}
+
+// Appeal asks moderators to reconsider one of the logged in user's
+// moderation actions. Each action can be appealed once.
+func (c *Client) Appeal(ctx context.Context, actionID uuid.UUID, message string) (Appeal, error) {
+	var appeal Appeal
+	_, err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/api/v1/appeals",
+		body:          AppealRequest{ActionID: actionID, Message: message},
+		authenticated: true,
+	}, &appeal)
+	return appeal, err
+}
+
+// head reports whether the resource at path exists.
+func (c *Client) head(ctx context.Context, path string) (bool, error) {
+	_, err := c.do(ctx, request{method: http.MethodHead, path: path}, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/fsck"
+)
+
+// runFsck implements `chirpy fsck [--fix]`. It reports rows whose
+// references no longer resolve, which only happens after manual DB
+// surgery, and with --fix deletes them in a single transaction. It
+// returns the process exit code: 1 if problems were left unrepaired.
+func runFsck(ctx context.Context, db *sql.DB, args []string) int {
+	flags := flag.NewFlagSet("fsck", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "delete the inconsistent rows that are found")
+	flags.Parse(args)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsck:", err)
+		return 2
+	}
+	defer tx.Rollback()
+
+	results, err := fsck.Run(ctx, fsckChecks(database.New(tx)), *fix)
+	fsck.Report(os.Stdout, results)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fsck:", err)
+		return 2
+	}
+	if fsck.Clean(results) {
+		return 0
+	}
+	if !*fix {
+		fmt.Println("Run with --fix to delete these rows.")
+		return 1
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Fprintln(os.Stderr, "fsck:", err)
+		return 2
+	}
+	return 0
+}
+
+// fsckChecks lists the consistency checks run by `chirpy fsck`. Chirps
+// come first so that repairing them surfaces the likes, rechirps,
+// hashtags and mentions left pointing at them in the same run.
+func fsckChecks(q *database.Queries) []fsck.Check {
+	return []fsck.Check{
+		{
+			Name: "chirps_without_author",
+			Find: func(ctx context.Context) ([]string, error) {
+				ids, err := q.FindOrphanedChirps(ctx)
+				return describe(ids, func(id uuid.UUID) string { return "chirp " + id.String() }), err
+			},
+			Repair: q.DeleteOrphanedChirps,
+		},
+		{
+			Name: "orphaned_likes",
+			Find: func(ctx context.Context) ([]string, error) {
+				rows, err := q.FindOrphanedLikes(ctx)
+				return describe(rows, func(r database.FindOrphanedLikesRow) string {
+					return fmt.Sprintf("like by user %s on chirp %s", r.UserID, r.ChirpID)
+				}), err
+			},
+			Repair: q.DeleteOrphanedLikes,
+		},
+		{
+			Name: "orphaned_rechirps",
+			Find: func(ctx context.Context) ([]string, error) {
+				ids, err := q.FindOrphanedRechirps(ctx)
+				return describe(ids, func(id uuid.UUID) string { return "rechirp " + id.String() }), err
+			},
+			Repair: q.DeleteOrphanedRechirps,
+		},
+		{
+			Name: "orphaned_chirp_hashtags",
+			Find: func(ctx context.Context) ([]string, error) {
+				rows, err := q.FindOrphanedChirpHashtags(ctx)
+				return describe(rows, func(r database.FindOrphanedChirpHashtagsRow) string {
+					return fmt.Sprintf("#%s on chirp %s", r.Tag, r.ChirpID)
+				}), err
+			},
+			Repair: q.DeleteOrphanedChirpHashtags,
+		},
+		{
+			Name: "orphaned_mentions",
+			Find: func(ctx context.Context) ([]string, error) {
+				rows, err := q.FindOrphanedMentions(ctx)
+				return describe(rows, func(r database.FindOrphanedMentionsRow) string {
+					return fmt.Sprintf("mention of user %s on chirp %s", r.UserID, r.ChirpID)
+				}), err
+			},
+			Repair: q.DeleteOrphanedMentions,
+		},
+		{
+			Name: "dangling_refresh_tokens",
+			Find: func(ctx context.Context) ([]string, error) {
+				rows, err := q.FindDanglingRefreshTokens(ctx)
+				return describe(rows, func(r database.FindDanglingRefreshTokensRow) string {
+					return fmt.Sprintf("token for missing user %s (expires %s)", r.UserID, r.ExpiresAt.Format("2006-01-02"))
+				}), err
+			},
+			Repair: q.DeleteDanglingRefreshTokens,
+		},
+	}
+}
+
+func describe[T any](items []T, f func(T) string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, f(item))
+	}
+	return out
+}
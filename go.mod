@@ -11,3 +11,5 @@ require (
 require golang.org/x/crypto v0.38.0
 
 require github.com/golang-jwt/jwt/v5 v5.2.2
+
+require golang.org/x/text v0.25.0
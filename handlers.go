@@ -1,17 +1,34 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"main.go/api"
+	"main.go/internal/anomaly"
 	"main.go/internal/auth"
+	"main.go/internal/chirptext"
+	"main.go/internal/contentfilter"
 	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/mail"
+	"main.go/internal/response"
+	"main.go/internal/sanitize"
+	"main.go/internal/tags"
+	"main.go/internal/trust"
 )
 
 // HealthzHandler handles the /healthz readiness check
@@ -21,456 +38,952 @@ func HealthzHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("OK"))
 }
 
+// GET /admin/metrics
+// Counters since this process started and all time. All-time values
+// include other instances up to their last flush.
 func (cfg *apiConfig) adminMetricsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 
-	count := cfg.fileserverHits.Load() // atomic load
+	count := cfg.fileserverHits.SinceBoot()
 
-	html := fmt.Sprintf(`
+	var rows strings.Builder
+	for _, c := range cfg.metrics.Snapshot() {
+		fmt.Fprintf(&rows, "<tr><td>%s</td><td>%d</td><td>%d</td></tr>", html.EscapeString(c.Name), c.SinceBoot, c.AllTime)
+	}
+
+	page := fmt.Sprintf(`
 		<html>
 		  <body>
 		    <h1>Welcome, Chirpy Admin</h1>
 		    <p>Chirpy has been visited %d times!</p>
+		    <table>
+		      <tr><th>Counter</th><th>Since boot</th><th>All time</th></tr>
+		      %s
+		    </table>
 		  </body>
 		</html>
-	`, count)
+	`, count, rows.String())
 
-	w.Write([]byte(html))
+	w.Write([]byte(page))
 }
 
 // POST /admin/reset
+// Only in the dev environment; see routePolicies.
 func (cfg *apiConfig) resetHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
-		return
-	}
-
-	if cfg.PLATFORM != "dev" {
-		respondWithError(w, http.StatusForbidden, "Forbidden: reset allowed only in dev environment", nil)
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
 		return
 	}
 
 	err := cfg.DB.Reset(r.Context())
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to delete users", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to delete users", err)
 		return
 	}
 
-	cfg.fileserverHits.Store(0)
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Counter reset"})
+	if err := cfg.metrics.Reset(r.Context()); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to reset counters", err)
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Counter reset"})
 }
 
-func handlerChirpsValidate(w http.ResponseWriter, r *http.Request) {
+// POST /api/validate_chirp
+// Deprecated in favor of POST /api/chirps with "dry_run": true, which
+// checks chirps the same way.
+func (cfg *apiConfig) handlerChirpsValidate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", `</api/chirps>; rel="successor-version"`)
+
 	decoder := json.NewDecoder(r.Body)
-	params := validateChirpRequest{}
+	params := api.ValidateChirpRequest{}
 	err := decoder.Decode(&params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
 		return
 	}
 
-	const maxChirpLength = 140
-	if len(params.Body) > maxChirpLength {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	chirp, ok := cfg.checkNewChirp(w, r, api.ChirpRequest{Body: params.Body}, uuid.Nil)
+	if !ok {
 		return
 	}
 
-	// List of banned words (lowercase)
-	bannedWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+	response.JSON(w, http.StatusOK, api.ValidateChirpResponse{
+		CleanedBody: chirp.Body,
+	})
+}
+
+const maxChirpLength = 140
+
+// prepareChirpBody normalizes a submitted chirp body, checks its length
+// and runs it through the content filters. Every handler that stores or validates a body
+// goes through it, so they all agree on what is accepted.
+func (cfg *apiConfig) prepareChirpBody(w http.ResponseWriter, r *http.Request, body string) (string, bool) {
+	body = chirptext.Normalize(body)
+	if body == "" {
+		response.Error(w, http.StatusBadRequest, "Chirp is empty", nil)
+		return "", false
+	}
+	if len(body) > maxChirpLength {
+		response.Error(w, http.StatusBadRequest, "Chirp is too long", nil)
+		return "", false
+	}
+	body, err := cfg.contentFilter.Clean(r.Context(), body)
+	if errors.Is(err, contentfilter.ErrRejected) {
+		response.Error(w, http.StatusBadRequest, "Chirp was rejected by content moderation", nil)
+		return "", false
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't check chirp content", err)
+		return "", false
+	}
+	return body, true
+}
+
+// indexChirp records the hashtags and mentions in a chirp's body,
+// replacing any recorded for an earlier version of it.
+func indexChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, body string) error {
+	if err := tagChirp(ctx, q, chirpID, body); err != nil {
+		return err
 	}
+	return mentionUsers(ctx, q, chirpID, body)
+}
 
-	words := strings.Split(params.Body, " ")
-	for i, word := range words {
-		if _, banned := bannedWords[strings.ToLower(word)]; banned {
-			words[i] = "****"
-		}
+// parseUsername validates an optional username from a request body. An
+// empty one is returned as NULL.
+func parseUsername(w http.ResponseWriter, s string) (sql.NullString, bool) {
+	if s == "" {
+		return sql.NullString{}, true
+	}
+	username, err := tags.NormalizeUsername(s)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid username: "+err.Error(), nil)
+		return sql.NullString{}, false
 	}
-	cleaned := strings.Join(words, " ")
+	return sql.NullString{String: username, Valid: true}, true
+}
+
+// Profile text limits, in characters.
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxWebsiteLength     = 200
+)
+
+// parseProfileText validates an optional display name or bio and strips
+// the HTML policy doesn't allow. nil keeps the current value and an empty
+// string clears it.
+func parseProfileText(w http.ResponseWriter, field string, s *string, maxLength int, policy sanitize.Policy) (sql.NullString, bool) {
+	if s == nil {
+		return sql.NullString{}, true
+	}
+	text := strings.TrimSpace(*s)
+	if utf8.RuneCountInString(text) > maxLength {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", field, maxLength), nil)
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: strings.TrimSpace(policy.HTML(text)), Valid: true}, true
+}
 
-	respondWithJSON(w, http.StatusOK, validateChirpResponse{
-		CleanedBody: cleaned,
+// parseWebsite validates an optional profile link, like parseProfileText.
+func parseWebsite(w http.ResponseWriter, s *string) (sql.NullString, bool) {
+	if s == nil {
+		return sql.NullString{}, true
+	}
+	if strings.TrimSpace(*s) == "" {
+		return sql.NullString{Valid: true}, true
+	}
+	if utf8.RuneCountInString(*s) > maxWebsiteLength {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("Website must be at most %d characters", maxWebsiteLength), nil)
+		return sql.NullString{}, false
+	}
+	website, err := sanitize.URL(*s)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid website: "+err.Error(), nil)
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: website, Valid: true}, true
+}
+
+// checkUsernameFree answers 409 if username is redirected to an account
+// other than userID, e.g. from a merged account. Usernames of existing
+// accounts are caught by the unique constraint instead; see
+// isUsernameTaken.
+func (cfg *apiConfig) checkUsernameFree(w http.ResponseWriter, r *http.Request, username sql.NullString, userID uuid.UUID) bool {
+	if !username.Valid {
+		return true
+	}
+	reserved, err := cfg.DB.UsernameReserved(r.Context(), database.UsernameReservedParams{
+		Username: username.String,
+		UserID:   userID,
 	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to check username", err)
+		return false
+	}
+	if reserved {
+		response.Error(w, http.StatusConflict, "Username is already taken", nil)
+		return false
+	}
+	return true
+}
+
+// isUsernameTaken reports whether err is a write of a username another
+// account already has.
+func isUsernameTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_username_key"
 }
 
 // POST /api/users
 func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request) {
-	var req createUserRequest
+	var req api.Credentials
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid JSON", err)
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+
+	username, ok := parseUsername(w, req.Username)
+	if !ok {
+		return
+	}
+	if !cfg.checkUsernameFree(w, r, username, uuid.Nil) {
 		return
 	}
 
 	// Hash the password before saving
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to hash password", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to hash password", err)
 		return
 	}
 
 	params := database.CreateUserParams{
 		Email:          req.Email,
 		HashedPassword: hashedPassword,
+		Username:       username,
 	}
 	userFromDB, err := cfg.DB.CreateUser(r.Context(), params)
 
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Could not create user", err)
+		if isUsernameTaken(err) {
+			response.Error(w, http.StatusConflict, "Username is already taken", nil)
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Could not create user", err)
 		return
 	}
 
-	user := User{
-		ID:        userFromDB.ID,
-		CreatedAt: userFromDB.CreatedAt,
-		UpdatedAt: userFromDB.UpdatedAt,
-		Email:     userFromDB.Email,
-	}
+	user := newUser(userFromDB)
+
+	cfg.events.Publish(r.Context(), events.New(events.UserCreated, userEventData(userFromDB)))
 
-	respondWithJSON(w, http.StatusCreated, user)
+	w.Header().Set("Last-Modified", userFromDB.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusCreated, user)
 }
 
 func (cfg *apiConfig) createChirpHandler(w http.ResponseWriter, r *http.Request) {
-	type request struct {
-		Body string `json:"body"`
-	}
+	userID := requestUserID(r)
 
-	type response struct {
-		ID        uuid.UUID `json:"id"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Body      string    `json:"body"`
-		UserID    uuid.UUID `json:"user_id"`
+	// ✅ Step 3: Decode JSON body
+	var req api.ChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
 	}
 
-	// ✅ Step 1: Extract token from header
-	tokenString, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid Authorization header", err)
+	// ✅ Step 4: Clean the body and check the chirp being replied to
+	params, ok := cfg.checkNewChirp(w, r, req, userID)
+	if !ok {
 		return
 	}
 
-	// ✅ Step 2: Validate the JWT
-	userID, err := auth.ValidateJWT(tokenString, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+	// ✅ Step 5: Stop here if the client only wanted to see the result
+	if req.DryRun {
+		now := time.Now().UTC()
+		response.JSON(w, http.StatusOK, newChirp(database.Chirp{
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Body:          params.Body,
+			UserID:        params.UserID,
+			ParentChirpID: params.ParentChirpID,
+		}))
 		return
 	}
 
-	// ✅ Step 3: Decode JSON body
-	var req request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload", err)
+	// ✅ Step 6: Create chirp in DB, along with its hashtags
+	var dbChirp database.Chirp
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		dbChirp, err = q.CreateChirp(r.Context(), params)
+		if err != nil {
+			return err
+		}
+		return indexChirp(r.Context(), q, dbChirp.ID, dbChirp.Body)
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to create chirp", err)
 		return
 	}
 
-	const maxChirpLength = 140
-	if len(req.Body) > maxChirpLength {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	event := events.New(events.ChirpCreated, events.ChirpData{
+		ID:        dbChirp.ID,
+		UserID:    dbChirp.UserID.UUID,
+		Body:      dbChirp.Body,
+		CreatedAt: dbChirp.CreatedAt,
+	})
+	cfg.events.Publish(r.Context(), event)
+	cfg.chirpStream.Publish(event)
+	cfg.recordActivity(r.Context(), userID, anomaly.KindChirp, "")
+	cfg.notifyMentions(r.Context(), dbChirp)
+
+	resp := newChirp(dbChirp)
+	counts, err := cfg.countChirps(r.Context(), []uuid.UUID{dbChirp.ID})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
 		return
 	}
+	counts.apply(&resp)
+
+	response.JSON(w, http.StatusCreated, resp)
+}
 
-	// ✅ Step 4: Filter banned words
-	bannedWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+// checkNewChirp validates a new chirp by userID and returns it ready to be
+// created. Dry runs and POST /api/validate_chirp use it as well, so they
+// accept exactly what posting does.
+func (cfg *apiConfig) checkNewChirp(w http.ResponseWriter, r *http.Request, req api.ChirpRequest, userID uuid.UUID) (database.CreateChirpParams, bool) {
+	body, ok := cfg.prepareChirpBody(w, r, req.Body)
+	if !ok {
+		return database.CreateChirpParams{}, false
 	}
-	words := strings.Split(req.Body, " ")
-	for i, word := range words {
-		if _, banned := bannedWords[strings.ToLower(word)]; banned {
-			words[i] = "****"
-		}
+	if userID != uuid.Nil && chirptext.HasLink(body) && !cfg.requireTrust(w, r, userID, trust.PostLinks) {
+		return database.CreateChirpParams{}, false
 	}
-	cleanedBody := strings.Join(words, " ")
 
-	// ✅ Step 5: Create chirp in DB
 	params := database.CreateChirpParams{
-		Body:   cleanedBody,
-		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Body:   body,
+		UserID: uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+	}
+	if req.ParentChirpID != nil {
+		parent, err := cfg.DB.GetChirp(r.Context(), *req.ParentChirpID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(w, http.StatusBadRequest, "Parent chirp not found", nil)
+			} else {
+				response.Error(w, http.StatusInternalServerError, "Failed to retrieve parent chirp", err)
+			}
+			return database.CreateChirpParams{}, false
+		}
+		params.ParentChirpID = uuid.NullUUID{UUID: parent.ID, Valid: true}
 	}
+	return params, true
+}
 
-	dbChirp, err := cfg.DB.CreateChirp(r.Context(), params)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create chirp", err)
+const (
+	defaultChirpsPageSize = 100
+	maxChirpsPageSize     = 500
+)
+
+// GET /api/chirps?author_id=UUID&sort=asc|desc&limit=N&offset=N
+// The page's ETag covers its chirps and paging metadata; If-None-Match
+// gets a 304 while they are unchanged.
+func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r, defaultChirpsPageSize, maxChirpsPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
 		return
 	}
 
-	resp := response{
-		ID:        dbChirp.ID,
-		CreatedAt: dbChirp.CreatedAt,
-		UpdatedAt: dbChirp.UpdatedAt,
-		Body:      dbChirp.Body,
-		UserID:    dbChirp.UserID.UUID,
+	sortOrder := r.URL.Query().Get("sort")
+	if sortOrder == "" {
+		sortOrder = "asc"
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		response.Error(w, http.StatusBadRequest, "sort must be asc or desc", nil)
+		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, resp)
-}
+	// Without author_id every chirp is listed. Listings include rechirps,
+	// ordered by when they were rechirped.
+	var authorID uuid.NullUUID
+	if v := r.URL.Query().Get("author_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid author ID", err)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
 
-func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
-	type chirpResponse struct {
-		ID        uuid.UUID `json:"id"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Body      string    `json:"body"`
-		UserID    uuid.UUID `json:"user_id"`
+	// The listing queries return identical rows under different names
+	var chirpsFromDB []database.GetChirpsRow
+	var total int64
+	var err error
+	switch {
+	case authorID.Valid && sortOrder == "asc":
+		var rows []database.GetChirpsByAuthorRow
+		rows, err = cfg.DB.GetChirpsByAuthor(r.Context(), database.GetChirpsByAuthorParams{
+			PostedBy: authorID,
+			Limit:    int32(limit),
+			Offset:   int32(offset),
+		})
+		for _, row := range rows {
+			chirpsFromDB = append(chirpsFromDB, database.GetChirpsRow(row))
+		}
+	case authorID.Valid:
+		var rows []database.GetChirpsByAuthorDescRow
+		rows, err = cfg.DB.GetChirpsByAuthorDesc(r.Context(), database.GetChirpsByAuthorDescParams{
+			PostedBy: authorID,
+			Limit:    int32(limit),
+			Offset:   int32(offset),
+		})
+		for _, row := range rows {
+			chirpsFromDB = append(chirpsFromDB, database.GetChirpsRow(row))
+		}
+	case sortOrder == "asc":
+		chirpsFromDB, err = cfg.DB.GetChirps(r.Context(), database.GetChirpsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+	default:
+		var rows []database.GetChirpsDescRow
+		rows, err = cfg.DB.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		for _, row := range rows {
+			chirpsFromDB = append(chirpsFromDB, database.GetChirpsRow(row))
+		}
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch chirps", err)
+		return
 	}
 
-	chirpsFromDB, err := cfg.DB.GetChirps(r.Context())
+	if authorID.Valid {
+		total, err = cfg.DB.CountChirpsByAuthor(r.Context(), authorID)
+	} else {
+		total, err = cfg.DB.CountVisibleChirps(r.Context())
+	}
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch chirps", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirps", err)
 		return
 	}
 
-	chirps := make([]chirpResponse, 0, len(chirpsFromDB))
+	chirps := make([]api.Chirp, 0, len(chirpsFromDB))
 	for _, c := range chirpsFromDB {
-		chirps = append(chirps, chirpResponse{
-			ID:        c.ID,
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
-			Body:      c.Body,
-			UserID:    c.UserID.UUID,
-		})
+		chirps = append(chirps, newListedChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, chirps)
+	// Paging metadata goes in headers so the body stays a plain array
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	etag := chirpsETag(chirps, strconv.FormatInt(total, 10), strconv.Itoa(limit), strconv.Itoa(offset))
+	if response.NotModified(w, r, etag) {
+		return
+	}
+	response.JSON(w, http.StatusOK, chirps)
 }
 
+// GET /api/chirps/{chirpID}
+// HEAD requests only check that the chirp exists, without counting its
+// engagement, so they have no ETag. GET honors If-None-Match.
 func (cfg *apiConfig) getChirpByIDHandler(w http.ResponseWriter, r *http.Request) {
 	chirpIDStr := r.PathValue("chirpID")
 	chirpID, err := uuid.Parse(chirpIDStr)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID format", err)
 		return
 	}
 
 	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
 		} else {
-			respondWithError(w, http.StatusInternalServerError, "Error fetching chirp", err)
+			response.Error(w, http.StatusInternalServerError, "Error fetching chirp", err)
 		}
 		return
 	}
 
-	type chirpResponse struct {
-		ID        uuid.UUID `json:"id"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Body      string    `json:"body"`
-		UserID    uuid.UUID `json:"user_id"`
+	w.Header().Set("Last-Modified", chirp.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Type", response.ContentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, chirpResponse{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.UUID,
-	})
+	resp := newChirp(chirp)
+	counts, err := cfg.countChirps(r.Context(), []uuid.UUID{chirp.ID})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+	counts.apply(&resp)
+
+	if response.NotModified(w, r, chirpsETag([]api.Chirp{resp})) {
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
 }
 
-func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
-	type parameters struct {
-		Password string `json:"password"`
-		Email    string `json:"email"`
+// checkCredentials returns the account matching an email and password.
+// Unknown emails and wrong passwords both fail with
+// auth.ErrIncorrectCredentials, after the same amount of work.
+func (cfg *apiConfig) checkCredentials(ctx context.Context, creds api.Credentials) (database.User, error) {
+	user, err := cfg.DB.GetUserByEmail(ctx, creds.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, err
 	}
-	type response struct {
-		User
-		Token        string `json:"token"`
-		RefreshToken string `json:"refresh_token"`
+	if err := auth.CheckCredentials(creds.Password, user.HashedPassword, err == nil); err != nil {
+		return database.User{}, err
 	}
+	return user, nil
+}
+
+// refreshTokenTTL is how long a refresh token lasts, from login or, with
+// sliding expiration, from its last use.
+const refreshTokenTTL = 60 * 24 * time.Hour
 
+// shortRefreshTokenTTL replaces refreshTokenTTL for sessions logged in
+// without remember_me.
+const shortRefreshTokenTTL = 12 * time.Hour
+
+func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
+	params := api.Credentials{}
 	err := decoder.Decode(&params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
 		return
 	}
 
-	user, err := cfg.DB.GetUserByEmail(r.Context(), params.Email)
+	user, err := cfg.checkCredentials(r.Context(), params)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		if errors.Is(err, auth.ErrIncorrectCredentials) {
+			response.Error(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Couldn't check credentials", err)
+		}
 		return
 	}
 
-	err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+	if user.DeletedAt.Valid {
+		response.Error(w, http.StatusForbidden, "Account is scheduled for deletion, restore it via /api/users/restore", nil)
+		return
+	}
+
+	factor, ok := cfg.checkSecondFactor(w, r, user.ID, params)
+	if !ok {
+		return
+	}
+	if !cfg.checkLoginLocation(w, r, user, params) {
+		return
+	}
+	if !cfg.useSecondFactor(w, r, factor) {
 		return
 	}
 
+	cfg.startSession(w, r, user, params.RememberMe == nil || *params.RememberMe)
+}
+
+// newAccessToken is what user's access tokens say about them.
+func newAccessToken(user database.User) auth.AccessToken {
+	return auth.AccessToken{UserID: user.ID, Role: user.Role, TrustLevel: user.TrustLevel}
+}
+
+// startSession logs user in, responding with an access token and a new
+// refresh token. rememberMe picks the long session over the short one.
+func (cfg *apiConfig) startSession(w http.ResponseWriter, r *http.Request, user database.User, rememberMe bool) {
 	accessToken, err := auth.MakeJWT(
-		user.ID,
-		cfg.jwtSecret,
+		newAccessToken(user),
+		cfg.jwtKeys,
 		time.Hour,
 	)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create access JWT", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't create access JWT", err)
 		return
 	}
 
+	cfg.recordActivity(r.Context(), user.ID, anomaly.KindLoginIP, clientIP(r))
+
 	refreshToken, err := auth.MakeRefreshToken()
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't create refresh token", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't create refresh token", err)
 		return
 	}
 
-	_, err = cfg.DB.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().UTC().Add(time.Hour * 24 * 60),
+	ttl := refreshTokenTTL
+	if !rememberMe {
+		ttl = shortRefreshTokenTTL
+	}
+
+	// Sessions beyond the cap are logged out, oldest first, so repeated
+	// or double-submitted logins can't pile up refresh tokens
+	var evicted int64
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		_, err := q.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+			UserID:     user.ID,
+			Token:      refreshToken,
+			ExpiresAt:  time.Now().UTC().Add(ttl),
+			RememberMe: rememberMe,
+		})
+		if err != nil {
+			return err
+		}
+		evicted, err = q.RevokeExcessRefreshTokens(r.Context(), database.RevokeExcessRefreshTokensParams{
+			UserID: user.ID,
+			Keep:   int32(cfg.maxSessions),
+		})
+		return err
 	})
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't save refresh token", err)
 		return
 	}
+	if evicted > 0 {
+		cfg.notifySessionsEvicted(r.Context(), user, evicted)
+	}
 
-	respondWithJSON(w, http.StatusOK, response{
-		User: User{
-			ID:        user.ID,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			Email:     user.Email,
-		},
+	w.Header().Set("Last-Modified", user.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusOK, api.LoginResponse{
+		User:         newUser(user),
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 	})
 }
 
-func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
-	type response struct {
-		Token string `json:"token"`
+// notifySessionsEvicted emails a user whose oldest sessions were logged
+// out to stay within the cap, in case the new login wasn't theirs.
+func (cfg *apiConfig) notifySessionsEvicted(ctx context.Context, user database.User, evicted int64) {
+	err := cfg.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "You were logged out of an older Chirpy session",
+		Body: fmt.Sprintf("Your Chirpy account was just logged in to. An account stays logged in on at most %d devices, "+
+			"so the oldest %d session(s) were logged out.\n\n"+
+			"If you didn't just log in, reset your password.\n", cfg.maxSessions, evicted),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to notify user of evicted sessions", "user_id", user.ID, "error", err)
 	}
+}
 
+func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := auth.GetBearerToken(r.Header)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Couldn't find token", err)
+		response.Error(w, http.StatusBadRequest, "Couldn't find token", err)
 		return
 	}
 
 	user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), refreshToken)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			cfg.revokeReusedRefreshToken(r.Context(), refreshToken)
+		}
+		response.Error(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
 		return
 	}
 
 	accessToken, err := auth.MakeJWT(
-		user.ID,
-		cfg.jwtSecret,
+		newAccessToken(user),
+		cfg.jwtKeys,
 		time.Hour,
 	)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		response.Error(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	newRefreshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't create refresh token", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, response{
-		Token: accessToken,
+	// Each refresh token works once, so a stolen one stops working as
+	// soon as either side uses it
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		_, err := q.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{
+			OldToken: refreshToken,
+			NewToken: newRefreshToken,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.MoveDeviceTokens(r.Context(), database.MoveDeviceTokensParams{
+			NewRefreshToken: newRefreshToken,
+			OldRefreshToken: refreshToken,
+		})
+		if err != nil {
+			return err
+		}
+		// With sliding expiration, a session in use keeps going until it
+		// reaches the maximum age
+		if cfg.refreshTokenMaxAge > 0 {
+			now := time.Now().UTC()
+			return q.ExtendRefreshToken(r.Context(), database.ExtendRefreshTokenParams{
+				ExpiresAt:      now.Add(refreshTokenTTL),
+				ShortExpiresAt: now.Add(shortRefreshTokenTTL),
+				MaxAgeSeconds:  int64(cfg.refreshTokenMaxAge.Seconds()),
+				Token:          newRefreshToken,
+			})
+		}
+		return nil
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Used by a concurrent refresh
+		cfg.revokeReusedRefreshToken(r.Context(), refreshToken)
+		response.Error(w, http.StatusUnauthorized, "Refresh token was already used", nil)
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't rotate refresh token", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, api.RefreshResponse{
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+	})
+}
+
+// revokeReusedRefreshToken ends the session of a refresh token that was
+// already rotated. Only one of its holders should have it, so whoever
+// presents it again may have stolen it; the session is ended so both
+// have to log in again. Tokens revoked some other way, e.g. by logging
+// out, are left alone.
+func (cfg *apiConfig) revokeReusedRefreshToken(ctx context.Context, token string) {
+	userID, err := cfg.DB.RevokeReusedRefreshTokenFamily(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to revoke reused refresh token family", "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "Rotated refresh token was reused, ended its session", "user_id", userID)
 }
 
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := auth.GetBearerToken(r.Header)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Couldn't find token", err)
+		response.Error(w, http.StatusBadRequest, "Couldn't find token", err)
 		return
 	}
 
 	_, err = cfg.DB.RevokeRefreshToken(r.Context(), refreshToken)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		response.Error(w, http.StatusInternalServerError, "Couldn't revoke session", err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// refreshTokenCookie holds the refresh token of browser sessions that
+// keep it in a cookie rather than in script-readable storage.
+const refreshTokenCookie = "chirpy_refresh_token"
+
+// POST /api/logout
+// Ends the current session: revokes the refresh token from the
+// refreshTokenCookie, or failing that the Authorization header, and
+// clears the cookie. Other sessions of the user are left alone. Logging
+// out of a session that has already ended still succeeds.
+func (cfg *apiConfig) handlerLogout(w http.ResponseWriter, r *http.Request) {
+	var refreshToken string
+	if cookie, err := r.Cookie(refreshTokenCookie); err == nil && cookie.Value != "" {
+		refreshToken = cookie.Value
+		clearRefreshTokenCookie(w)
+	} else {
+		refreshToken, err = auth.GetBearerToken(r.Header)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Couldn't find token", err)
+			return
+		}
+	}
+
+	_, err := cfg.DB.RevokeRefreshToken(r.Context(), refreshToken)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/logout_all
+// Ends every session of the user, for example after a refresh token
+// leaked. Access tokens already issued stay valid until they expire.
+func (cfg *apiConfig) handlerLogoutAll(w http.ResponseWriter, r *http.Request) {
+	if err := cfg.DB.RevokeAllRefreshTokensForUser(r.Context(), requestUserID(r)); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+	clearRefreshTokenCookie(w)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clearRefreshTokenCookie tells the browser to drop the
+// refreshTokenCookie.
+func clearRefreshTokenCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenCookie,
+		Path:     "/api/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// errUserModified aborts an update of a user changed since the request's
+// If-Unmodified-Since.
+var errUserModified = errors.New("user modified")
+
 func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
 		return
 	}
 
-	tokenStr, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid authorization header", err)
+	userID := requestUserID(r)
+
+	var req api.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(tokenStr, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+	username, ok := parseUsername(w, req.Username)
+	if !ok {
 		return
 	}
-
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	if !cfg.checkUsernameFree(w, r, username, userID) {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid JSON body", err)
+	displayName, ok := parseProfileText(w, "Display name", req.DisplayName, maxDisplayNameLength, sanitize.Text)
+	if !ok {
+		return
+	}
+	bio, ok := parseProfileText(w, "Bio", req.Bio, maxBioLength, sanitize.Inline)
+	if !ok {
+		return
+	}
+	website, ok := parseWebsite(w, req.Website)
+	if !ok {
 		return
 	}
 
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to hash password", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to hash password", err)
 		return
 	}
 
-	params := database.UpdateUserByIDParams{
-		ID:             userID,
-		Email:          req.Email,
-		HashedPassword: hashedPassword,
+	var updatedUser database.User
+	var nextChange time.Time
+
+	// Clients that send If-Unmodified-Since (taken from the Last-Modified
+	// header of a previous response) only update the user if nobody else
+	// has changed it in the meantime. Invalid dates are ignored per RFC 9110.
+	unmodifiedSince, parseErr := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		user, err := q.GetUserByIDForUpdate(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		nextChange, err = cfg.nextUsernameChange(r.Context(), q, userID, user.Username, username)
+		if err != nil {
+			return err
+		}
+		if !nextChange.IsZero() {
+			return errUsernameChangedRecently
+		}
+		if parseErr == nil {
+			updatedUser, err = q.UpdateUserByIDIfUnmodified(r.Context(), database.UpdateUserByIDIfUnmodifiedParams{
+				ID:             userID,
+				Email:          req.Email,
+				HashedPassword: hashedPassword,
+				// HTTP dates have second precision, updated_at does not
+				UpdatedAt:   unmodifiedSince.Add(time.Second),
+				Username:    username,
+				DisplayName: displayName,
+				Bio:         bio,
+				Website:     website,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return errUserModified
+			}
+		} else {
+			updatedUser, err = q.UpdateUserByID(r.Context(), database.UpdateUserByIDParams{
+				ID:             userID,
+				Email:          req.Email,
+				HashedPassword: hashedPassword,
+				Username:       username,
+				DisplayName:    displayName,
+				Bio:            bio,
+				Website:        website,
+			})
+		}
+		if err != nil || !username.Valid {
+			return err
+		}
+		return cfg.recordUsernameChange(r.Context(), q, userID, user.Username, username.String)
+	})
+	if errors.Is(err, errUserModified) {
+		response.Error(w, http.StatusPreconditionFailed, "User has been modified since "+unmodifiedSince.Format(http.TimeFormat), nil)
+		return
+	}
+	if errors.Is(err, errUsernameChangedRecently) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(nextChange).Seconds()))))
+		response.Error(w, http.StatusTooManyRequests, "Username was changed recently; it can be changed again after "+nextChange.UTC().Format(time.RFC3339), nil)
+		return
+	}
+	if isUsernameTaken(err) {
+		response.Error(w, http.StatusConflict, "Username is already taken", nil)
+		return
 	}
-
-	updatedUser, err := cfg.DB.UpdateUserByID(r.Context(), params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to update user", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to update user", err)
 		return
 	}
 
-	resp := User{
-		ID:        updatedUser.ID,
-		Email:     updatedUser.Email,
-		CreatedAt: updatedUser.CreatedAt,
-		UpdatedAt: updatedUser.UpdatedAt,
+	resp := newUser(updatedUser)
+
+	cfg.events.Publish(r.Context(), events.New(events.UserUpdated, userEventData(updatedUser)))
+	if req.Website != nil {
+		cfg.queueWebsiteVerification(r.Context(), updatedUser)
 	}
 
-	respondWithJSON(w, http.StatusOK, resp)
+	w.Header().Set("Last-Modified", updatedUser.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusOK, resp)
 }
 
 func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request) {
 	// Step 1: Check HTTP method
 	if r.Method != http.MethodDelete {
-		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
 		return
 	}
 
-	// Step 2: Extract and validate JWT
-	tokenStr, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Missing or invalid token", err)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(tokenStr, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Invalid token", err)
-		return
-	}
+	userID := requestUserID(r)
 
 	// Step 3: Extract chirp ID from path
 	chirpIDStr := r.PathValue("chirpID")
 	chirpID, err := uuid.Parse(chirpIDStr)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
 		return
 	}
 
@@ -478,26 +991,36 @@ func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
 	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
 		} else {
-			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
 		}
 		return
 	}
 
 	// Step 5: Check ownership
 	if chirp.UserID.UUID != userID {
-		respondWithError(w, http.StatusForbidden, "You are not the owner of this chirp", nil)
+		response.Error(w, http.StatusForbidden, "You are not the owner of this chirp", nil)
+		return
+	}
+	if chirp.LegalHoldAt.Valid {
+		response.Error(w, http.StatusConflict, chirpLegalHoldMessage, nil)
 		return
 	}
 
 	// Step 6: Delete chirp
 	err = cfg.DB.DeleteChirp(r.Context(), chirpID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to delete chirp", err)
 		return
 	}
 
+	cfg.events.Publish(r.Context(), events.New(events.ChirpDeleted, events.ChirpData{
+		ID:        chirp.ID,
+		UserID:    chirp.UserID.UUID,
+		CreatedAt: chirp.CreatedAt,
+	}))
+
 	// Step 7: Return 204 No Content
 	w.WriteHeader(http.StatusNoContent)
 }
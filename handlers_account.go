@@ -0,0 +1,109 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+)
+
+// DELETE /api/users
+// Soft-deletes the authenticated user. The account can be restored with
+// POST /api/users/restore until the grace period runs out, after which the
+// purge_deleted_users task removes it for good.
+func (cfg *apiConfig) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve user", err)
+		}
+		return
+	}
+	if user.LegalHoldAt.Valid {
+		response.Error(w, http.StatusConflict, accountLegalHoldMessage, nil)
+		return
+	}
+
+	deletedUser, err := cfg.DB.SoftDeleteUser(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to delete user", err)
+		}
+		return
+	}
+
+	// Log the user out everywhere; restoring requires logging in again
+	err = cfg.DB.RevokeAllRefreshTokensForUser(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.UserDeleted, userEventData(deletedUser)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/users/restore
+func (cfg *apiConfig) restoreUserHandler(w http.ResponseWriter, r *http.Request) {
+	var params api.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+
+	user, err := cfg.checkCredentials(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, auth.ErrIncorrectCredentials) {
+			response.Error(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Couldn't check credentials", err)
+		}
+		return
+	}
+
+	if !user.DeletedAt.Valid {
+		response.Error(w, http.StatusConflict, "Account is not scheduled for deletion", nil)
+		return
+	}
+
+	restored, err := cfg.DB.RestoreUser(r.Context(), database.RestoreUserParams{
+		ID:        user.ID,
+		DeletedAt: sql.NullTime{Time: time.Now().UTC().Add(-cfg.deletionGracePeriod), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusGone, "Grace period has expired", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to restore user", err)
+		}
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.UserRestored, userEventData(restored)))
+
+	w.Header().Set("Last-Modified", restored.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusOK, newUser(restored))
+}
+
+// userEventData is the event bus representation of a user.
+func userEventData(u database.User) events.UserData {
+	return events.UserData{
+		ID:        u.ID,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+)
+
+// Actions recorded in the admin audit log.
+const (
+	auditDeleteChirp = "delete_chirp"
+)
+
+// deletedChirp is what a delete_chirp entry keeps of the chirp. The
+// audit log outlives the erasure of its author, so it only has IDs.
+type deletedChirp struct {
+	UserID        uuid.UUID  `json:"user_id"`
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id,omitempty"`
+}
+
+func newDeletedChirp(c database.Chirp) deletedChirp {
+	d := deletedChirp{UserID: c.UserID.UUID}
+	if c.ParentChirpID.Valid {
+		d.ParentChirpID = &c.ParentChirpID.UUID
+	}
+	return d
+}
+
+var errChirpLegalHold = errors.New("chirp is under legal hold")
+
+type auditLogResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	AdminID   *uuid.UUID      `json:"admin_id"`
+	Action    string          `json:"action"`
+	TargetID  uuid.UUID       `json:"target_id"`
+	Details   json.RawMessage `json:"details"`
+}
+
+func newAuditLogResponse(e database.AdminAuditLog) auditLogResponse {
+	resp := auditLogResponse{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		Action:    e.Action,
+		TargetID:  e.TargetID,
+		Details:   e.Details,
+	}
+	if e.AdminID.Valid {
+		resp.AdminID = &e.AdminID.UUID
+	}
+	return resp
+}
+
+// GET /admin/chirps?user_id=...&limit=N&offset=N
+// Lists a user's chirps newest first, including those of deleted
+// accounts that public listings hide.
+func (cfg *apiConfig) adminListChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid or missing user_id", err)
+		return
+	}
+	limit, ok := parseLimit(w, r, defaultChirpsPageSize, maxChirpsPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	chirps, err := cfg.DB.ListChirpsByUser(r.Context(), database.ListChirpsByUserParams{
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirps", err)
+		return
+	}
+
+	resp := make([]api.Chirp, 0, len(chirps))
+	for _, c := range chirps {
+		resp = append(resp, newChirp(c))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// DELETE /admin/chirps/{chirpID}
+// Deletes any chirp, recording it in the audit log. Unlike
+// POST /admin/chirps/{chirpID}/remove this isn't a moderation action:
+// the author isn't told and can't appeal.
+func (cfg *apiConfig) adminDeleteChirpHandler(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	var chirp database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		if _, err := q.LockChirp(r.Context(), chirpID); err != nil {
+			return err
+		}
+		var err error
+		chirp, err = q.GetChirp(r.Context(), chirpID)
+		if err != nil {
+			return err
+		}
+		if chirp.LegalHoldAt.Valid {
+			return errChirpLegalHold
+		}
+
+		details, err := json.Marshal(newDeletedChirp(chirp))
+		if err != nil {
+			return err
+		}
+		if _, err := q.CreateAuditLogEntry(r.Context(), database.CreateAuditLogEntryParams{
+			AdminID:  uuid.NullUUID{UUID: requestUserID(r), Valid: true},
+			Action:   auditDeleteChirp,
+			TargetID: chirp.ID,
+			Details:  details,
+		}); err != nil {
+			return err
+		}
+		return q.DeleteChirp(r.Context(), chirp.ID)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		case errors.Is(err, errChirpLegalHold):
+			response.Error(w, http.StatusConflict, chirpLegalHoldMessage, nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		}
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.ChirpDeleted, events.ChirpData{
+		ID:        chirp.ID,
+		UserID:    chirp.UserID.UUID,
+		CreatedAt: chirp.CreatedAt,
+	}))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/audit_log?limit=N&offset=N
+// Newest entries first.
+func (cfg *apiConfig) adminListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := cfg.DB.ListAuditLog(r.Context(), database.ListAuditLogParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch audit log", err)
+		return
+	}
+
+	resp := make([]auditLogResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, newAuditLogResponse(e))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/reports"
+	"main.go/internal/response"
+)
+
+// Moderation action kinds and appeal statuses.
+const (
+	actionRemoveChirp    = "remove_chirp"
+	actionSuspendAccount = "suspend_account"
+
+	appealPending  = "pending"
+	appealApproved = "approved"
+	appealDenied   = "denied"
+
+	maxAppealLength = 2000
+)
+
+type moderationActionRequest struct {
+	ReasonCode string `json:"reason_code"`
+}
+
+// removedChirp is what a remove_chirp action keeps of the chirp, enough
+// to put it back if an appeal is approved. Likes and rechirps are lost.
+type removedChirp struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Body          string     `json:"body"`
+	UserID        uuid.UUID  `json:"user_id"`
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id,omitempty"`
+}
+
+func newRemovedChirp(c database.Chirp) removedChirp {
+	kept := removedChirp{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		Body:      c.Body,
+		UserID:    c.UserID.UUID,
+	}
+	if c.ParentChirpID.Valid {
+		kept.ParentChirpID = &c.ParentChirpID.UUID
+	}
+	return kept
+}
+
+func newModerationAction(a database.ModerationAction) api.ModerationAction {
+	resp := api.ModerationAction{
+		ID:         a.ID,
+		CreatedAt:  a.CreatedAt,
+		Kind:       a.Kind,
+		ReasonCode: a.ReasonCode,
+	}
+	if a.ChirpID.Valid {
+		resp.ChirpID = &a.ChirpID.UUID
+	}
+	if a.ReversedAt.Valid {
+		resp.ReversedAt = &a.ReversedAt.Time
+	}
+	return resp
+}
+
+func newAppeal(a database.Appeal) api.Appeal {
+	resp := api.Appeal{
+		ID:        a.ID,
+		CreatedAt: a.CreatedAt,
+		ActionID:  a.ActionID,
+		UserID:    a.UserID,
+		Message:   a.Message,
+		Status:    a.Status,
+	}
+	if a.DecidedAt.Valid {
+		resp.DecidedAt = &a.DecidedAt.Time
+	}
+	return resp
+}
+
+// decodeReasonCode reads a moderationActionRequest and checks its reason.
+func decodeReasonCode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req moderationActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return "", false
+	}
+	if !reports.Known(req.ReasonCode) {
+		response.Error(w, http.StatusBadRequest, "Unknown reason_code", nil)
+		return "", false
+	}
+	return req.ReasonCode, true
+}
+
+func (cfg *apiConfig) publishModerationAction(ctx context.Context, a database.ModerationAction) {
+	data := events.ModerationActionData{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Kind:       a.Kind,
+		ReasonCode: a.ReasonCode,
+	}
+	if a.ChirpID.Valid {
+		data.ChirpID = &a.ChirpID.UUID
+	}
+	cfg.events.Publish(ctx, events.New(events.ModerationActionTaken, data))
+}
+
+// removeChirp deletes a chirp with a remove_chirp action that keeps a
+// copy of it. It is run in a transaction, and publishChirpRemoved once
+// that commits.
+func removeChirp(ctx context.Context, q *database.Queries, chirp database.Chirp, reasonCode string) (database.ModerationAction, error) {
+	details, err := json.Marshal(newRemovedChirp(chirp))
+	if err != nil {
+		return database.ModerationAction{}, err
+	}
+
+	action, err := q.CreateModerationAction(ctx, database.CreateModerationActionParams{
+		UserID:     chirp.UserID.UUID,
+		Kind:       actionRemoveChirp,
+		ReasonCode: reasonCode,
+		ChirpID:    uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		Details:    details,
+	})
+	if err != nil {
+		return database.ModerationAction{}, err
+	}
+	return action, q.DeleteChirp(ctx, chirp.ID)
+}
+
+func (cfg *apiConfig) publishChirpRemoved(ctx context.Context, chirp database.Chirp, action database.ModerationAction) {
+	cfg.events.Publish(ctx, events.New(events.ChirpDeleted, events.ChirpData{
+		ID:        chirp.ID,
+		UserID:    chirp.UserID.UUID,
+		CreatedAt: chirp.CreatedAt,
+	}))
+	cfg.publishModerationAction(ctx, action)
+}
+
+// POST /admin/chirps/{chirpID}/remove
+// Deletes a chirp on moderators' behalf, keeping a copy so an appeal can
+// restore it.
+func (cfg *apiConfig) adminRemoveChirpHandler(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+	reasonCode, ok := decodeReasonCode(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch chirp", err)
+		}
+		return
+	}
+	if chirp.LegalHoldAt.Valid {
+		response.Error(w, http.StatusConflict, chirpLegalHoldMessage, nil)
+		return
+	}
+
+	var action database.ModerationAction
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		action, err = removeChirp(r.Context(), q, chirp, reasonCode)
+		return err
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to remove chirp", err)
+		return
+	}
+
+	cfg.publishChirpRemoved(r.Context(), chirp, action)
+	response.JSON(w, http.StatusCreated, newModerationAction(action))
+}
+
+// POST /admin/users/{userID}/suspend
+// Makes an account read-only until an appeal is approved.
+func (cfg *apiConfig) adminSuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	reasonCode, ok := decodeReasonCode(w, r)
+	if !ok {
+		return
+	}
+
+	var action database.ModerationAction
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.SuspendUser(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		action, err = q.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+			UserID:     userID,
+			Kind:       actionSuspendAccount,
+			ReasonCode: reasonCode,
+			Details:    json.RawMessage(`{}`),
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "User not found or already suspended", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to suspend user", err)
+		}
+		return
+	}
+
+	cfg.publishModerationAction(r.Context(), action)
+	response.JSON(w, http.StatusCreated, newModerationAction(action))
+}
+
+// GET /api/users/me/moderation
+// Actions moderators took against the current user, newest first, with
+// the reason code for each.
+func (cfg *apiConfig) getMyModerationActionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	actions, err := cfg.DB.GetModerationActionsByUser(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch moderation actions", err)
+		return
+	}
+
+	resp := make([]api.ModerationAction, 0, len(actions))
+	for _, a := range actions {
+		resp = append(resp, newModerationAction(a))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /api/appeals
+// Appeals one of the current user's moderation actions. Each action can
+// be appealed once.
+func (cfg *apiConfig) createAppealHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	var req api.AppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Message == "" || len(req.Message) > maxAppealLength {
+		response.Error(w, http.StatusBadRequest, "Message is required and must be at most 2000 characters", nil)
+		return
+	}
+
+	action, err := cfg.DB.GetModerationAction(r.Context(), req.ActionID)
+	if err == nil && action.UserID != userID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Moderation action not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch moderation action", err)
+		}
+		return
+	}
+	if action.ReversedAt.Valid {
+		response.Error(w, http.StatusConflict, "Action has already been reversed", nil)
+		return
+	}
+
+	appeal, err := cfg.DB.CreateAppeal(r.Context(), database.CreateAppealParams{
+		ActionID: action.ID,
+		UserID:   userID,
+		Message:  req.Message,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Action has already been appealed", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to create appeal", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, newAppeal(appeal))
+}
+
+// GET /admin/appeals?status=pending|approved|denied&limit=N
+// Oldest first, so appeals are reviewed in order.
+func (cfg *apiConfig) adminListAppealsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = appealPending
+	case appealPending, appealApproved, appealDenied:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+
+	appeals, err := cfg.DB.ListAppeals(r.Context(), database.ListAppealsParams{
+		Status: status,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch appeals", err)
+		return
+	}
+
+	resp := make([]api.Appeal, 0, len(appeals))
+	for _, a := range appeals {
+		resp = append(resp, newAppeal(a))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /admin/appeals/{appealID}/approve
+// Reverses the appealed action: the account is unsuspended or the chirp
+// is restored.
+func (cfg *apiConfig) adminApproveAppealHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.decideAppeal(w, r, appealApproved)
+}
+
+// POST /admin/appeals/{appealID}/deny
+// Upholds the appealed action.
+func (cfg *apiConfig) adminDenyAppealHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.decideAppeal(w, r, appealDenied)
+}
+
+func (cfg *apiConfig) decideAppeal(w http.ResponseWriter, r *http.Request, status string) {
+	appealID, err := uuid.Parse(r.PathValue("appealID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid appeal ID", err)
+		return
+	}
+
+	var appeal database.Appeal
+	var restored *database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		appeal, err = q.DecideAppeal(r.Context(), database.DecideAppealParams{
+			ID:     appealID,
+			Status: status,
+		})
+		if err != nil || status != appealApproved {
+			return err
+		}
+		restored, err = reverseModerationAction(r.Context(), q, appeal.ActionID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Only pending appeals can be decided", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to decide appeal", err)
+		}
+		return
+	}
+
+	if restored != nil {
+		cfg.events.Publish(r.Context(), events.New(events.ChirpCreated, events.ChirpData{
+			ID:        restored.ID,
+			UserID:    restored.UserID.UUID,
+			Body:      restored.Body,
+			CreatedAt: restored.CreatedAt,
+		}))
+	}
+	cfg.events.Publish(r.Context(), events.New(events.AppealDecided, events.AppealData{
+		ID:       appeal.ID,
+		ActionID: appeal.ActionID,
+		UserID:   appeal.UserID,
+		Status:   appeal.Status,
+	}))
+	response.JSON(w, http.StatusOK, newAppeal(appeal))
+}
+
+// reverseModerationAction undoes an action, returning the chirp it put
+// back if it was a removal.
+func reverseModerationAction(ctx context.Context, q *database.Queries, actionID uuid.UUID) (*database.Chirp, error) {
+	action, err := q.ReverseModerationAction(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action.Kind {
+	case actionSuspendAccount:
+		return nil, q.UnsuspendUser(ctx, action.UserID)
+	case actionRemoveChirp:
+		var kept removedChirp
+		if err := json.Unmarshal(action.Details, &kept); err != nil {
+			return nil, err
+		}
+		params := database.RestoreRemovedChirpParams{
+			ID:        kept.ID,
+			CreatedAt: kept.CreatedAt,
+			UpdatedAt: kept.UpdatedAt,
+			Body:      kept.Body,
+			UserID:    uuid.NullUUID{UUID: kept.UserID, Valid: true},
+		}
+		if kept.ParentChirpID != nil {
+			params.ParentChirpID = uuid.NullUUID{UUID: *kept.ParentChirpID, Valid: true}
+		}
+		chirp, err := q.RestoreRemovedChirp(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return &chirp, indexChirp(ctx, q, chirp.ID, chirp.Body)
+	}
+	return nil, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/media"
+	"main.go/internal/response"
+	"main.go/internal/trust"
+)
+
+const (
+	maxImageSize = 2 << 20 // bytes
+	avatarDir    = "avatars"
+)
+
+// POST /api/users/me/avatar
+// Takes a multipart form with the image in its "avatar" field and
+// replaces the current user's avatar. PNG, JPEG, GIF and WebP images up
+// to 2 MiB are accepted; the type is sniffed from the content, not taken
+// from the upload's headers.
+func (cfg *apiConfig) uploadAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	if !cfg.requireTrust(w, r, userID, trust.UploadMedia) {
+		return
+	}
+
+	data, ext, ok := readImage(w, r, "avatar", "Avatar")
+	if !ok {
+		return
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve user", err)
+		}
+		return
+	}
+
+	name, err := cfg.mediaStore.Save(avatarDir, ext, bytes.NewReader(data))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to store avatar", err)
+		return
+	}
+
+	updated, err := cfg.DB.SetUserAvatar(r.Context(), database.SetUserAvatarParams{
+		ID:        userID,
+		AvatarURL: sql.NullString{String: "/media/" + name, Valid: true},
+	})
+	if err != nil {
+		cfg.mediaStore.Remove(name)
+		response.Error(w, http.StatusInternalServerError, "Failed to save avatar", err)
+		return
+	}
+	if err := cfg.removeAvatar(user.AvatarURL.String); err != nil {
+		slog.WarnContext(r.Context(), "Couldn't remove old avatar", "user_id", userID, "error", err)
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.UserUpdated, userEventData(updated)))
+
+	w.Header().Set("Last-Modified", updated.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusOK, newUser(updated))
+}
+
+// readImage reads the image in a field of a multipart form, with the
+// extension to store it with. what names the image in error messages.
+// It responds and returns false if the image is missing, too large or
+// not of a supported type.
+func readImage(w http.ResponseWriter, r *http.Request, field, what string) ([]byte, string, bool) {
+	// Leave some room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize+64<<10)
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, http.StatusRequestEntityTooLarge, what+" must be at most 2 MiB", nil)
+		} else {
+			response.Error(w, http.StatusBadRequest, "Expected a multipart form with an "+field+" file", err)
+		}
+		return nil, "", false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Couldn't read "+field, err)
+		return nil, "", false
+	}
+	if len(data) > maxImageSize {
+		response.Error(w, http.StatusRequestEntityTooLarge, what+" must be at most 2 MiB", nil)
+		return nil, "", false
+	}
+	ext, err := media.ImageExtension(data)
+	if err != nil {
+		response.Error(w, http.StatusUnsupportedMediaType, what+" must be a PNG, JPEG, GIF or WebP image", nil)
+		return nil, "", false
+	}
+	return data, ext, true
+}
+
+// removeAvatar deletes the stored file behind an avatar_url, if any.
+func (cfg *apiConfig) removeAvatar(avatarURL string) error {
+	name, ok := strings.CutPrefix(avatarURL, "/media/")
+	if !ok || !strings.HasPrefix(name, avatarDir+"/") {
+		return nil
+	}
+	return cfg.mediaStore.Remove(name)
+}
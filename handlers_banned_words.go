@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"main.go/internal/chirptext"
+	"main.go/internal/response"
+)
+
+// bannedWordsTTL is how long other instances keep masking with a list an
+// admin changed; the instance that made the change reloads it at once.
+const bannedWordsTTL = time.Minute
+
+const maxBannedWordLength = 50
+
+// profanityFilter caches a chirptext.Filter for the banned_words table.
+type profanityFilter struct {
+	mode chirptext.MatchMode
+	load func(ctx context.Context) ([]string, error)
+
+	mu       sync.Mutex
+	filter   *chirptext.Filter
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+func newProfanityFilter(mode chirptext.MatchMode, load func(ctx context.Context) ([]string, error)) *profanityFilter {
+	return &profanityFilter{mode: mode, load: load, now: time.Now}
+}
+
+// Filter returns the cached filter, reloading the words when the cache
+// is older than bannedWordsTTL or was invalidated. If they can't be
+// loaded the stale filter is kept, so chirps are still masked.
+func (p *profanityFilter) Filter(ctx context.Context) (*chirptext.Filter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.filter != nil && p.now().Sub(p.loadedAt) < bannedWordsTTL {
+		return p.filter, nil
+	}
+	words, err := p.load(ctx)
+	if err != nil {
+		if p.filter == nil {
+			return nil, err
+		}
+		slog.ErrorContext(ctx, "Failed to reload banned words, keeping the old list", "error", err)
+		return p.filter, nil
+	}
+	p.filter = chirptext.NewFilter(words, p.mode)
+	p.loadedAt = p.now()
+	return p.filter, nil
+}
+
+// Clean masks the banned words in body, making the banned words a
+// contentfilter.ContentFilter.
+func (p *profanityFilter) Clean(ctx context.Context, body string) (string, error) {
+	f, err := p.Filter(ctx)
+	if err != nil {
+		return "", err
+	}
+	return f.Clean(body), nil
+}
+
+// Invalidate makes the next Filter call reload the words.
+func (p *profanityFilter) Invalidate() {
+	p.mu.Lock()
+	p.loadedAt = time.Time{}
+	p.mu.Unlock()
+}
+
+type bannedWordRequest struct {
+	Word string `json:"word"`
+}
+
+// normalizeBannedWord lowercases a submitted word, and reports whether
+// it is a single word a Filter can match.
+func normalizeBannedWord(word string) (string, bool) {
+	word = strings.ToLower(chirptext.Normalize(word))
+	if word == "" || len(word) > maxBannedWordLength || strings.ContainsFunc(word, unicode.IsSpace) {
+		return "", false
+	}
+	return word, true
+}
+
+// GET /admin/banned_words
+func (cfg *apiConfig) adminListBannedWordsHandler(w http.ResponseWriter, r *http.Request) {
+	words, err := cfg.DB.ListBannedWords(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch banned words", err)
+		return
+	}
+	if words == nil {
+		words = []string{}
+	}
+	response.JSON(w, http.StatusOK, words)
+}
+
+// POST /admin/banned_words
+// Bans a word. Banning it again does nothing.
+func (cfg *apiConfig) adminAddBannedWordHandler(w http.ResponseWriter, r *http.Request) {
+	var req bannedWordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	word, ok := normalizeBannedWord(req.Word)
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "Banned words must be a single word", nil)
+		return
+	}
+
+	added, err := cfg.DB.AddBannedWord(r.Context(), word)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to ban word", err)
+		return
+	}
+	if added == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	cfg.profanity.Invalidate()
+	slog.InfoContext(r.Context(), "Banned word", "word", word, "admin_id", requestUserID(r))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DELETE /admin/banned_words/{word}
+func (cfg *apiConfig) adminDeleteBannedWordHandler(w http.ResponseWriter, r *http.Request) {
+	word := strings.ToLower(r.PathValue("word"))
+	deleted, err := cfg.DB.DeleteBannedWord(r.Context(), word)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to unban word", err)
+		return
+	}
+	if deleted == 0 {
+		response.Error(w, http.StatusNotFound, "Word isn't banned", nil)
+		return
+	}
+	cfg.profanity.Invalidate()
+	slog.InfoContext(r.Context(), "Unbanned word", "word", word, "admin_id", requestUserID(r))
+	w.WriteHeader(http.StatusNoContent)
+}
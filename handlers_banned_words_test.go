@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"main.go/internal/chirptext"
+)
+
+func TestProfanityFilterCache(t *testing.T) {
+	words := []string{"kerfuffle"}
+	var loadErr error
+	loads := 0
+	p := newProfanityFilter(chirptext.MatchExact, func(ctx context.Context) ([]string, error) {
+		loads++
+		return words, loadErr
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	clean := func(s string) string {
+		t.Helper()
+		f, err := p.Filter(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.Clean(s)
+	}
+
+	if got := clean("what a kerfuffle"); got != "what a ****" {
+		t.Errorf("Clean = %q, want %q", got, "what a ****")
+	}
+	words = []string{"kerfuffle", "fornax"}
+	if got := clean("fornax"); got != "fornax" {
+		t.Errorf("Clean before the TTL = %q, want the cached filter's %q", got, "fornax")
+	}
+	p.Invalidate()
+	if got := clean("fornax"); got != "****" {
+		t.Errorf("Clean after Invalidate = %q, want %q", got, "****")
+	}
+
+	loadErr = errors.New("database is down")
+	now = now.Add(bannedWordsTTL)
+	if got := clean("fornax"); got != "****" {
+		t.Errorf("Clean after a failed reload = %q, want the stale filter's %q", got, "****")
+	}
+	if loads != 3 {
+		t.Errorf("loaded %d times, want 3", loads)
+	}
+}
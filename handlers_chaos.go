@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"main.go/internal/chaos"
+	"main.go/internal/response"
+)
+
+// chaosFaults is the body of GET and PUT /admin/chaos: the faults
+// injected into each route, by its routePolicies key.
+type chaosFaults struct {
+	Faults map[string]chaosFault `json:"faults"`
+}
+
+type chaosFault struct {
+	LatencyMS int     `json:"latency_ms"`
+	ErrorRate float64 `json:"error_rate"`
+	Status    int     `json:"status,omitempty"` // 503 when omitted
+}
+
+// requireChaos writes a 404 and returns false when fault injection is
+// disabled, as it is outside dev and staging.
+func (cfg *apiConfig) requireChaos(w http.ResponseWriter) bool {
+	if cfg.chaos == nil {
+		response.Error(w, http.StatusNotFound, "Fault injection is only available in dev and staging", nil)
+		return false
+	}
+	return true
+}
+
+func newChaosFaults(faults map[string]chaos.Fault) chaosFaults {
+	resp := chaosFaults{Faults: make(map[string]chaosFault, len(faults))}
+	for route, f := range faults {
+		resp.Faults[route] = chaosFault{
+			LatencyMS: int(f.Latency / time.Millisecond),
+			ErrorRate: f.ErrorRate,
+			Status:    f.Status,
+		}
+	}
+	return resp
+}
+
+// GET /admin/chaos
+func (cfg *apiConfig) adminGetChaosHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireChaos(w) {
+		return
+	}
+	response.JSON(w, http.StatusOK, newChaosFaults(cfg.chaos.Faults()))
+}
+
+// PUT /admin/chaos
+// Replaces every fault, on this instance only. Routes are keyed as in
+// routePolicies, e.g. "GET /api/chirps", and match every API version.
+func (cfg *apiConfig) adminSetChaosHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireChaos(w) {
+		return
+	}
+	var req chaosFaults
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	faults := make(map[string]chaos.Fault, len(req.Faults))
+	for route, f := range req.Faults {
+		if _, ok := routePolicies[route]; !ok {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("Unknown route %q", route), nil)
+			return
+		}
+		if strings.HasSuffix(route, " /admin/chaos") {
+			response.Error(w, http.StatusBadRequest, "Faults can't be injected into /admin/chaos", nil)
+			return
+		}
+		faults[route] = chaos.Fault{
+			Latency:   time.Duration(f.LatencyMS) * time.Millisecond,
+			ErrorRate: f.ErrorRate,
+			Status:    f.Status,
+		}
+	}
+	if err := cfg.chaos.Set(faults); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid fault: "+err.Error(), nil)
+		return
+	}
+	slog.InfoContext(r.Context(), "Set injected faults", "routes", len(faults), "admin_id", requestUserID(r))
+	response.JSON(w, http.StatusOK, newChaosFaults(faults))
+}
+
+// DELETE /admin/chaos
+func (cfg *apiConfig) adminClearChaosHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireChaos(w) {
+		return
+	}
+	cfg.chaos.Set(nil)
+	slog.InfoContext(r.Context(), "Cleared injected faults", "admin_id", requestUserID(r))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/chirptext"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+	"main.go/internal/trust"
+)
+
+// PUT /api/chirps/{chirpID}
+func (cfg *apiConfig) updateChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	var req api.ChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	body, ok := cfg.prepareChirpBody(w, r, req.Body)
+	if !ok {
+		return
+	}
+	if chirptext.HasLink(body) && !cfg.requireTrust(w, r, userID, trust.PostLinks) {
+		return
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+		}
+		return
+	}
+
+	if chirp.UserID.UUID != userID {
+		response.Error(w, http.StatusForbidden, "You are not the owner of this chirp", nil)
+		return
+	}
+
+	var updated database.Chirp
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		updated, err = q.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+			ID:   chirpID,
+			Body: body,
+		})
+		if err != nil {
+			return err
+		}
+		return indexChirp(r.Context(), q, updated.ID, updated.Body)
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update chirp", err)
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.ChirpUpdated, events.ChirpData{
+		ID:        updated.ID,
+		UserID:    updated.UserID.UUID,
+		Body:      updated.Body,
+		CreatedAt: updated.CreatedAt,
+	}))
+
+	resp := newChirp(updated)
+	counts, err := cfg.countChirps(r.Context(), []uuid.UUID{updated.ID})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+	counts.apply(&resp)
+
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/media"
+	"main.go/internal/response"
+	"main.go/internal/trust"
+)
+
+// Chirp images are stored under chirpImageDir, or under the same
+// directory in privateMediaPrefix when only followers may see them.
+const chirpImageDir = "chirps"
+
+// Signed links to followers-only images last between mediaLinkTTL and
+// twice that. Rounding the expiry keeps a chirp's link, and so its ETag,
+// the same for a while.
+const mediaLinkTTL = 5 * time.Minute
+
+// Files without a chirp_images row are removed once they are this old,
+// leaving uploads in progress alone.
+const orphanedImageAge = time.Hour
+
+// PUT /api/chirps/{chirpID}/image
+// Takes a multipart form with the image in its "image" field and
+// replaces the image of one of the user's chirps. With followers_only
+// set to true in the form, the image is kept to the author's followers:
+// chirp responses only give them a signed link to it. Same limits as
+// avatars.
+func (cfg *apiConfig) setChirpImageHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+	if !cfg.requireTrust(w, r, userID, trust.UploadMedia) {
+		return
+	}
+
+	data, ext, ok := readImage(w, r, "image", "Image")
+	if !ok {
+		return
+	}
+	followersOnly := false
+	if v := r.FormValue("followers_only"); v != "" {
+		followersOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "followers_only must be true or false", nil)
+			return
+		}
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+		}
+		return
+	}
+	if chirp.UserID.UUID != userID {
+		response.Error(w, http.StatusForbidden, "You are not the owner of this chirp", nil)
+		return
+	}
+	old, err := cfg.DB.GetChirpImage(r.Context(), chirpID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp image", err)
+		return
+	}
+
+	dir := chirpImageDir
+	if followersOnly {
+		dir = privateMediaPrefix + chirpImageDir
+	}
+	name, err := cfg.mediaStore.Save(dir, ext, bytes.NewReader(data))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to store image", err)
+		return
+	}
+	_, err = cfg.DB.SetChirpImage(r.Context(), database.SetChirpImageParams{
+		ChirpID:       chirpID,
+		Path:          name,
+		FollowersOnly: followersOnly,
+	})
+	if err != nil {
+		cfg.mediaStore.Remove(name)
+		response.Error(w, http.StatusInternalServerError, "Failed to save image", err)
+		return
+	}
+	if old.Path != "" {
+		if err := cfg.mediaStore.Remove(old.Path); err != nil {
+			slog.WarnContext(r.Context(), "Couldn't remove old chirp image", "chirp_id", chirpID, "error", err)
+		}
+	}
+
+	resp := newChirp(chirp)
+	counts, err := cfg.countChirps(r.Context(), []uuid.UUID{chirp.ID})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+	counts.apply(&resp)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// chirpImageLinks returns the links to the images of the given chirps
+// that viewerID may see, by chirp. Followers-only images are only
+// linked for their author and followers, with a signed link. viewerID
+// is uuid.Nil for anonymous viewers.
+func (cfg *apiConfig) chirpImageLinks(ctx context.Context, viewerID uuid.UUID, chirpIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	images, err := cfg.DB.ListChirpImages(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors of followers-only images the viewer might follow
+	var authors []uuid.UUID
+	for _, img := range images {
+		if img.FollowersOnly && viewerID != uuid.Nil && img.UserID.UUID != viewerID {
+			authors = append(authors, img.UserID.UUID)
+		}
+	}
+	allowed := map[uuid.UUID]bool{viewerID: viewerID != uuid.Nil}
+	if len(authors) > 0 {
+		followed, err := cfg.DB.ListFollowedAmong(ctx, database.ListFollowedAmongParams{
+			FollowerID:  viewerID,
+			FolloweeIds: authors,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range followed {
+			allowed[id] = true
+		}
+	}
+
+	expires := time.Now().Truncate(mediaLinkTTL).Add(2 * mediaLinkTTL)
+	links := make(map[uuid.UUID]string, len(images))
+	for _, img := range images {
+		link := "/media/" + img.Path
+		if img.FollowersOnly {
+			if !allowed[img.UserID.UUID] {
+				continue
+			}
+			link = media.SignURL(link, cfg.mediaSigningSecret, expires)
+		}
+		links[img.ChirpID] = link
+	}
+	return links, nil
+}
+
+// cleanupChirpImagesTask removes stored chirp images whose chirp is
+// gone. Deleting a chirp, by its author, a moderator or with its
+// account, only deletes the chirp_images row.
+func (cfg *apiConfig) cleanupChirpImagesTask(ctx context.Context) error {
+	before := time.Now().Add(-orphanedImageAge)
+	removed := 0
+	for _, dir := range []string{chirpImageDir, privateMediaPrefix + chirpImageDir} {
+		names, err := cfg.mediaStore.Files(dir, before)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			exists, err := cfg.DB.ChirpImageExists(ctx, name)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+			if err := cfg.mediaStore.Remove(name); err != nil {
+				return err
+			}
+			removed++
+		}
+	}
+	if removed > 0 {
+		slog.Info("Removed images of deleted chirps", "count", removed)
+	}
+	return nil
+}
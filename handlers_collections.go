@@ -0,0 +1,371 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+const (
+	maxCollectionNameLength        = 100
+	maxCollectionDescriptionLength = 500
+	// maxCollectionChirps keeps a collection small enough to render in
+	// one response.
+	maxCollectionChirps = 100
+
+	defaultCollectionPageSize = 20
+	maxCollectionPageSize     = 100
+)
+
+// parseCollectionRequest decodes and checks the body of creating or
+// renaming a collection. It responds with an error and returns ok=false
+// if the body is invalid.
+func parseCollectionRequest(w http.ResponseWriter, r *http.Request) (req api.CollectionRequest, ok bool) {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxCollectionNameLength {
+		response.Error(w, http.StatusBadRequest, "Name must be 1 to "+strconv.Itoa(maxCollectionNameLength)+" characters", nil)
+		return req, false
+	}
+	if utf8.RuneCountInString(req.Description) > maxCollectionDescriptionLength {
+		response.Error(w, http.StatusBadRequest, "Description must be at most "+strconv.Itoa(maxCollectionDescriptionLength)+" characters", nil)
+		return req, false
+	}
+	return req, true
+}
+
+// getCollection loads the collection named in the path. It responds with
+// an error and returns ok=false if there is no such collection.
+func (cfg *apiConfig) getCollection(w http.ResponseWriter, r *http.Request) (database.Collection, bool) {
+	collectionID, err := uuid.Parse(r.PathValue("collectionID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid collection ID", err)
+		return database.Collection{}, false
+	}
+
+	collection, err := cfg.DB.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Collection not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch collection", err)
+		}
+		return database.Collection{}, false
+	}
+	return collection, true
+}
+
+// ownCollection is getCollection for changes, which only the owner can
+// make.
+func (cfg *apiConfig) ownCollection(w http.ResponseWriter, r *http.Request) (database.Collection, bool) {
+	collection, ok := cfg.getCollection(w, r)
+	if !ok {
+		return collection, false
+	}
+	if collection.UserID != requestUserID(r) {
+		response.Error(w, http.StatusForbidden, "You are not the owner of this collection", nil)
+		return collection, false
+	}
+	return collection, true
+}
+
+// POST /api/collections
+func (cfg *apiConfig) createCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseCollectionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := cfg.DB.CreateCollection(r.Context(), database.CreateCollectionParams{
+		UserID:      requestUserID(r),
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to create collection", err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, newCollection(collection))
+}
+
+// GET /api/collections/{collectionID}
+// The collection with its chirps, in the owner's order. Chirps of
+// deleted accounts are left out.
+func (cfg *apiConfig) getCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.getCollection(w, r)
+	if !ok {
+		return
+	}
+
+	chirpsFromDB, err := cfg.DB.GetCollectionChirps(r.Context(), collection.ID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch chirps", err)
+		return
+	}
+
+	resp := newCollection(collection)
+	resp.Chirps = make([]api.Chirp, 0, len(chirpsFromDB))
+	for _, c := range chirpsFromDB {
+		resp.Chirps = append(resp.Chirps, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), resp.Chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	w.Header().Set("Last-Modified", collection.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// GET /api/users/{userID}/collections?limit=N&offset=N
+// A user's collections, newest first, without their chirps.
+func (cfg *apiConfig) listUserCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	limit, ok := parseLimit(w, r, defaultCollectionPageSize, maxCollectionPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	collections, err := cfg.DB.ListCollectionsByUser(r.Context(), database.ListCollectionsByUserParams{
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch collections", err)
+		return
+	}
+	total, err := cfg.DB.CountCollectionsByUser(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count collections", err)
+		return
+	}
+
+	resp := make([]api.Collection, 0, len(collections))
+	for _, c := range collections {
+		resp = append(resp, newCollection(c))
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// PUT /api/collections/{collectionID}
+// Renames the collection and replaces its description.
+func (cfg *apiConfig) updateCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.ownCollection(w, r)
+	if !ok {
+		return
+	}
+	req, ok := parseCollectionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := cfg.DB.UpdateCollection(r.Context(), database.UpdateCollectionParams{
+		ID:          collection.ID,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update collection", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newCollection(updated))
+}
+
+// DELETE /api/collections/{collectionID}
+// The chirps in it are not affected.
+func (cfg *apiConfig) deleteCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	if err := cfg.DB.DeleteCollection(r.Context(), collection.ID); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to delete collection", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/collections/{collectionID}/chirps
+// Appends any user's chirp to the collection. Adding a chirp that is
+// already in it changes nothing.
+func (cfg *apiConfig) addCollectionChirpHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var req api.CollectionChirpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+
+	if _, err := cfg.DB.GetChirp(r.Context(), req.ChirpID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch chirp", err)
+		}
+		return
+	}
+
+	errCollectionFull := errors.New("collection is full")
+	err := cfg.withTx(r.Context(), func(q *database.Queries) error {
+		count, err := q.CountCollectionChirps(r.Context(), collection.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxCollectionChirps {
+			return errCollectionFull
+		}
+		added, err := q.AddChirpToCollection(r.Context(), database.AddChirpToCollectionParams{
+			CollectionID: collection.ID,
+			ChirpID:      req.ChirpID,
+		})
+		if err != nil || added == 0 {
+			return err
+		}
+		return q.TouchCollection(r.Context(), collection.ID)
+	})
+	if errors.Is(err, errCollectionFull) {
+		response.Error(w, http.StatusConflict, "A collection holds at most "+strconv.Itoa(maxCollectionChirps)+" chirps", nil)
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to add chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /api/collections/{collectionID}/chirps
+// Replaces the chirps of the collection with the given list, in order.
+// This is how chirps are reordered; chirps left out are removed.
+func (cfg *apiConfig) setCollectionChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var req api.CollectionChirpsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if len(req.ChirpIDs) > maxCollectionChirps {
+		response.Error(w, http.StatusBadRequest, "A collection holds at most "+strconv.Itoa(maxCollectionChirps)+" chirps", nil)
+		return
+	}
+	seen := make(map[uuid.UUID]bool, len(req.ChirpIDs))
+	for _, id := range req.ChirpIDs {
+		if seen[id] {
+			response.Error(w, http.StatusBadRequest, "Chirp "+id.String()+" is listed twice", nil)
+			return
+		}
+		seen[id] = true
+	}
+	if req.ChirpIDs == nil {
+		req.ChirpIDs = []uuid.UUID{}
+	}
+
+	found, err := cfg.DB.CountChirpsByIDs(r.Context(), req.ChirpIDs)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch chirps", err)
+		return
+	}
+	if found != int64(len(req.ChirpIDs)) {
+		response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		return
+	}
+
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		err := q.RemoveChirpsNotInCollection(r.Context(), database.RemoveChirpsNotInCollectionParams{
+			CollectionID: collection.ID,
+			ChirpIds:     req.ChirpIDs,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.SetCollectionChirps(r.Context(), database.SetCollectionChirpsParams{
+			CollectionID: collection.ID,
+			ChirpIds:     req.ChirpIDs,
+		})
+		if err != nil {
+			return err
+		}
+		return q.TouchCollection(r.Context(), collection.ID)
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update collection", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /api/collections/{collectionID}/chirps/{chirpID}
+func (cfg *apiConfig) removeCollectionChirpHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := cfg.ownCollection(w, r)
+	if !ok {
+		return
+	}
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	var removed int64
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		removed, err = q.RemoveChirpFromCollection(r.Context(), database.RemoveChirpFromCollectionParams{
+			CollectionID: collection.ID,
+			ChirpID:      chirpID,
+		})
+		if err != nil || removed == 0 {
+			return err
+		}
+		return q.TouchCollection(r.Context(), collection.ID)
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to remove chirp", err)
+		return
+	}
+	if removed == 0 {
+		response.Error(w, http.StatusNotFound, "Chirp is not in this collection", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
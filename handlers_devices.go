@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+)
+
+// POST /api/devices
+// Registers an APNs or FCM device token for pushes. It is authenticated
+// with the refresh token of the app's session, and pushes stop when that
+// session ends. A token registered again moves to the new session.
+func (cfg *apiConfig) registerDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	refreshToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Couldn't find token", err)
+		return
+	}
+
+	var req api.DeviceRegistration
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if !notify.Platform(req.Platform).Valid() {
+		response.Error(w, http.StatusBadRequest, "platform must be apns or fcm", nil)
+		return
+	}
+	if req.Token == "" || len(req.Token) > 4096 {
+		response.Error(w, http.StatusBadRequest, "token must be 1 to 4096 bytes", nil)
+		return
+	}
+
+	user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+		return
+	}
+
+	err = cfg.DB.RegisterDeviceToken(r.Context(), database.RegisterDeviceTokenParams{
+		Token:        req.Token,
+		UserID:       user.ID,
+		Platform:     req.Platform,
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't register device", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /api/devices/{token}
+// Unregisters one of the user's device tokens, e.g. when the user turns
+// pushes off in the app.
+func (cfg *apiConfig) unregisterDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := cfg.DB.UnregisterDeviceToken(r.Context(), database.UnregisterDeviceTokenParams{
+		Token:  r.PathValue("token"),
+		UserID: requestUserID(r),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't unregister device", err)
+		return
+	}
+	if n == 0 {
+		response.Error(w, http.StatusNotFound, "Device not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
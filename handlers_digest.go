@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/mail"
+	"main.go/internal/response"
+)
+
+const (
+	// digestCandidates is how many of the day's chirps computeDigestTask
+	// ranks. Network picks come from the same list, so users whose
+	// network was quiet get fewer of them.
+	digestCandidates = 200
+	digestTopChirps  = 10
+	// digestRetention is how long computed digests are kept.
+	digestRetention = 30 * 24 * time.Hour
+
+	emailDigestJob = "email_digest"
+)
+
+// computeDigestTask ranks the chirps of the last 24 hours by engagement
+// (likes, rechirps, shares and replies) and stores them as today's
+// digest, replacing one computed earlier in the day. It then queues the
+// digest emails.
+func (cfg *apiConfig) computeDigestTask(ctx context.Context) error {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+
+	var ranked int64
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		if err := q.CreateDigest(ctx, day); err != nil {
+			return err
+		}
+		if err := q.DeleteDigestChirps(ctx, day); err != nil {
+			return err
+		}
+		var err error
+		ranked, err = q.InsertDigestChirps(ctx, database.InsertDigestChirpsParams{
+			Day:       day,
+			Since:     now.Add(-24 * time.Hour),
+			MaxChirps: digestCandidates,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := cfg.DB.DeleteDigestsBefore(ctx, day.Add(-digestRetention)); err != nil {
+		return err
+	}
+	slog.Info("Computed digest", "day", day.Format(time.DateOnly), "chirps", ranked)
+
+	if ranked == 0 {
+		return nil
+	}
+	subscribers, err := cfg.DB.ListDigestSubscribers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, userID := range subscribers {
+		if _, err := cfg.jobs.Enqueue(ctx, emailDigestJob, emailDigestPayload{UserID: userID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// digest assembles the latest digest for a user. It returns
+// sql.ErrNoRows before the first digest has been computed.
+func (cfg *apiConfig) digest(ctx context.Context, userID uuid.UUID) (api.Digest, error) {
+	day, err := cfg.DB.GetLatestDigestDay(ctx)
+	if err != nil {
+		return api.Digest{}, err
+	}
+
+	top, err := cfg.DB.GetDigestChirps(ctx, database.GetDigestChirpsParams{
+		Day:       day,
+		MaxChirps: digestTopChirps,
+	})
+	if err != nil {
+		return api.Digest{}, err
+	}
+	network, err := cfg.DB.GetNetworkDigestChirps(ctx, database.GetNetworkDigestChirpsParams{
+		Day:       day,
+		UserID:    userID,
+		MaxChirps: digestTopChirps,
+	})
+	if err != nil {
+		return api.Digest{}, err
+	}
+
+	digest := api.Digest{
+		Date:    day.Format(time.DateOnly),
+		Top:     make([]api.Chirp, 0, len(top)),
+		Network: make([]api.Chirp, 0, len(network)),
+	}
+	for _, c := range top {
+		digest.Top = append(digest.Top, newChirp(c))
+	}
+	for _, c := range network {
+		digest.Network = append(digest.Network, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(ctx, digest.Top); err != nil {
+		return api.Digest{}, err
+	}
+	if err := cfg.addChirpCounts(ctx, digest.Network); err != nil {
+		return api.Digest{}, err
+	}
+	if len(digest.Top) > 0 {
+		digest.ChirpOfTheDay = &digest.Top[0]
+	}
+	return digest, nil
+}
+
+// GET /api/digest
+// The daily summary: the chirp of the day, the top chirps and the top
+// chirps from followed users.
+func (cfg *apiConfig) digestHandler(w http.ResponseWriter, r *http.Request) {
+	digest, err := cfg.digest(r.Context(), requestUserID(r))
+	if errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, "No digest yet", nil)
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to build digest", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, digest)
+}
+
+type emailDigestPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// emailDigest is the handler of email_digest jobs. It mails the latest
+// digest, unless the user has since unsubscribed or there is nothing to
+// tell them.
+func (cfg *apiConfig) emailDigest(ctx context.Context, payload json.RawMessage) error {
+	var p emailDigestPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	user, err := cfg.DB.GetUserByID(ctx, p.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.EmailDigest || user.DeletedAt.Valid {
+		return nil
+	}
+
+	digest, err := cfg.digest(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if digest.ChirpOfTheDay == nil {
+		return nil
+	}
+
+	return cfg.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your Chirpy digest for " + digest.Date,
+		Body:    formatDigest(digest),
+	})
+}
+
+// formatDigest renders a digest as the body of an email.
+func formatDigest(d api.Digest) string {
+	var b strings.Builder
+	writeChirp := func(c api.Chirp) {
+		author := "someone"
+		if c.Username != nil {
+			author = "@" + *c.Username
+		}
+		fmt.Fprintf(&b, "- %s, by %s (%d likes, %d rechirps)\n", c.Body, author, c.LikesCount, c.RechirpCount)
+	}
+
+	b.WriteString("Chirp of the day:\n")
+	writeChirp(*d.ChirpOfTheDay)
+	if len(d.Network) > 0 {
+		b.WriteString("\nFrom people you follow:\n")
+		for _, c := range d.Network {
+			writeChirp(c)
+		}
+	}
+	if len(d.Top) > 1 {
+		b.WriteString("\nAlso popular:\n")
+		for _, c := range d.Top[1:] {
+			writeChirp(c)
+		}
+	}
+	b.WriteString("\nYou can turn these emails off in your settings.\n")
+	return b.String()
+}
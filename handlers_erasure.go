@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+)
+
+// errErasureLegalHold aborts an erasure of preserved data.
+var errErasureLegalHold = errors.New("account or its chirps are under legal hold")
+
+type erasureRequest struct {
+	// Reference ties the erasure to the request that asked for it, e.g. a
+	// support ticket. It must not contain personal data.
+	Reference string `json:"reference"`
+}
+
+// erasureReport records what an erasure removed. It is stored with the
+// erasure, so it only ever holds counts and names, never the data.
+type erasureReport struct {
+	// Deleted is the number of rows removed, by table. Rows of other
+	// users that only pointed at the erased user's chirps, like their
+	// likes, are not counted.
+	Deleted map[string]int64 `json:"deleted"`
+	// FilesRemoved counts files deleted from media storage.
+	FilesRemoved int `json:"files_removed"`
+	// CachesCleared names the in-process caches the user was dropped
+	// from. event_consumers means a user.erased event was published for
+	// services holding copies.
+	CachesCleared []string `json:"caches_cleared"`
+	// Errors lists the steps that failed after the database was erased.
+	Errors []string `json:"errors,omitempty"`
+}
+
+type erasureResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UserID      uuid.UUID     `json:"user_id"`
+	Reference   string        `json:"reference"`
+	Report      erasureReport `json:"report"`
+	CompletedAt *time.Time    `json:"completed_at"`
+}
+
+func newErasureResponse(e database.Erasure) erasureResponse {
+	resp := erasureResponse{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		UserID:    e.UserID,
+		Reference: e.Reference,
+	}
+	json.Unmarshal(e.Report, &resp.Report)
+	if e.CompletedAt.Valid {
+		resp.CompletedAt = &e.CompletedAt.Time
+	}
+	return resp
+}
+
+// POST /admin/users/{userID}/erase
+// Right to be forgotten: irreversibly removes an account and everything
+// tied to it, from the database, media storage and in-process caches,
+// and tells event consumers to do the same. Unlike DELETE /api/users
+// there is no grace period. The erasure is recorded, with its report,
+// and listed by GET /admin/erasures.
+//
+// This server keeps no backups of its own. Erasures have to be run again
+// after restoring a database backup; the erasures table lists them.
+func (cfg *apiConfig) adminEraseUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	var req erasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	req.Reference = strings.TrimSpace(req.Reference)
+	if req.Reference == "" {
+		response.Error(w, http.StatusBadRequest, "A reference for the erasure request is required", nil)
+		return
+	}
+
+	var (
+		erasure database.Erasure
+		user    database.User
+		chirps  []database.Chirp
+		avatars []sql.NullString
+		images  []string
+		report  = erasureReport{CachesCleared: []string{}}
+	)
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		var err error
+		user, err = q.GetUserByID(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		held, err := q.UserHasHeldChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+		if err != nil {
+			return err
+		}
+		if held || user.LegalHoldAt.Valid {
+			return errErasureLegalHold
+		}
+
+		counts, err := q.CountUserData(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		report.Deleted = map[string]int64{
+			"users":              1,
+			"chirps":             counts.Chirps,
+			"likes":              counts.Likes,
+			"rechirps":           counts.Rechirps,
+			"follows":            counts.Follows,
+			"chirp_mentions":     counts.Mentions,
+			"refresh_tokens":     counts.RefreshTokens,
+			"feed_markers":       counts.FeedMarkers,
+			"moderation_queue":   counts.ModerationQueue,
+			"moderation_actions": counts.ModerationActions,
+			"appeals":            counts.Appeals,
+			"collections":        counts.Collections,
+			"chirp_shares":       counts.Shares,
+		}
+
+		// Image rows go with the chirps, but the files have to be found first
+		images, err = q.DeleteChirpImagesByUser(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+		if err != nil {
+			return err
+		}
+		report.Deleted["chirp_images"] = int64(len(images))
+
+		// Everything else goes with the user through ON DELETE CASCADE
+		chirps, err = q.DeleteChirpsByUsers(r.Context(), []uuid.UUID{userID})
+		if err != nil {
+			return err
+		}
+		avatars, err = q.PurgeUsers(r.Context(), []uuid.UUID{userID})
+		if err != nil {
+			return err
+		}
+
+		// Recorded deliveries hold copies of events, bodies included,
+		// with nothing to cascade from
+		chirpIDs := make([]string, 0, len(chirps))
+		for _, c := range chirps {
+			chirpIDs = append(chirpIDs, c.ID.String())
+		}
+		report.Deleted["webhook_deliveries"], err = q.DeleteWebhookDeliveriesAbout(r.Context(), database.DeleteWebhookDeliveriesAboutParams{
+			UserID:   userID.String(),
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return err
+		}
+
+		dat, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		erasure, err = q.CreateErasure(r.Context(), database.CreateErasureParams{
+			UserID:    userID,
+			Reference: req.Reference,
+			Report:    dat,
+		})
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		case errors.Is(err, errErasureLegalHold):
+			response.Error(w, http.StatusConflict, "Account or its chirps are under legal hold and can't be erased", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "Failed to erase user", err)
+		}
+		return
+	}
+
+	cfg.eraseOutsideDatabase(r.Context(), user, chirps, avatars, images, &report)
+
+	dat, err := json.Marshal(report)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to encode erasure report", err)
+		return
+	}
+	erasure, err = cfg.DB.CompleteErasure(r.Context(), database.CompleteErasureParams{
+		ID:     erasure.ID,
+		Report: dat,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "User erased, but failed to record the report", err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Erased user", "user_id", userID, "erasure_id", erasure.ID)
+	response.JSON(w, http.StatusOK, newErasureResponse(erasure))
+}
+
+// eraseOutsideDatabase removes what the database transaction couldn't:
+// stored files, cached activity and copies held by event consumers.
+func (cfg *apiConfig) eraseOutsideDatabase(ctx context.Context, user database.User, chirps []database.Chirp, avatars []sql.NullString, images []string, report *erasureReport) {
+	for _, avatar := range avatars {
+		if !avatar.Valid {
+			continue
+		}
+		if err := cfg.removeAvatar(avatar.String); err != nil {
+			report.Errors = append(report.Errors, "removing avatar: "+err.Error())
+			continue
+		}
+		report.FilesRemoved++
+	}
+	for _, image := range images {
+		if err := cfg.mediaStore.Remove(image); err != nil {
+			report.Errors = append(report.Errors, "removing chirp image: "+err.Error())
+			continue
+		}
+		report.FilesRemoved++
+	}
+
+	cfg.anomalies.Forget(user.ID)
+	report.CachesCleared = append(report.CachesCleared, "anomaly_detector")
+
+	for _, chirp := range chirps {
+		cfg.events.Publish(ctx, events.New(events.ChirpDeleted, events.ChirpData{
+			ID:        chirp.ID,
+			UserID:    chirp.UserID.UUID,
+			CreatedAt: chirp.CreatedAt,
+		}))
+	}
+	cfg.events.Publish(ctx, events.New(events.UserErased, userEventData(user)))
+	report.CachesCleared = append(report.CachesCleared, "event_consumers")
+}
+
+// GET /admin/erasures?limit=N
+// Newest first.
+func (cfg *apiConfig) adminListErasuresHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+
+	erasures, err := cfg.DB.ListErasures(r.Context(), int32(limit))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch erasures", err)
+		return
+	}
+
+	resp := make([]erasureResponse, 0, len(erasures))
+	for _, e := range erasures {
+		resp = append(resp, newErasureResponse(e))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"main.go/api"
+	"main.go/internal/response"
+)
+
+// GET /api/users/exists?email=... or ?username=...
+// Lets signup forms check availability. Accounts pending deletion still
+// hold their email and username. Each IP gets a small quota so this
+// can't be used to enumerate accounts.
+func (cfg *apiConfig) userExistsHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkRateLimit(w, cfg.existsRateLimiter, clientIP(r)) {
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+
+	query := r.URL.Query()
+	email := query.Get("email")
+	username := strings.ToLower(strings.TrimSpace(query.Get("username")))
+	if (email == "") == (username == "") {
+		response.Error(w, http.StatusBadRequest, "Provide either email or username", nil)
+		return
+	}
+
+	var exists bool
+	var err error
+	if email != "" {
+		exists, err = cfg.DB.EmailExists(r.Context(), email)
+	} else {
+		exists, err = cfg.DB.UsernameExists(r.Context(), sql.NullString{String: username, Valid: true})
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to check account", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, api.UserExists{Exists: exists})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+// Exports are streamed from sql.Rows rather than through the generated
+// queries, which load every row into a slice first. Memory stays flat
+// however big the table is.
+const (
+	exportChirpsQuery = `SELECT id, created_at, updated_at, body, user_id, parent_chirp_id FROM chirps ORDER BY created_at, id`
+	exportUsersQuery  = `SELECT id, created_at, updated_at, email, username, display_name, bio, website, avatar_url FROM users ORDER BY created_at, id`
+
+	// exportFlushEvery is how many rows are buffered before they are
+	// flushed to the client.
+	exportFlushEvery = 500
+)
+
+// streamNDJSON runs query and writes one JSON line per row, as scan
+// converts it. Once the first line is out the status can't change, so
+// later failures end the response early and are only logged; clients
+// can tell by the missing trailing rows.
+func (cfg *apiConfig) streamNDJSON(w http.ResponseWriter, r *http.Request, query string, scan func(*sql.Rows) (any, error)) {
+	rows, err := cfg.sqlDB.QueryContext(r.Context(), query)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to start export", err)
+		return
+	}
+	defer rows.Close()
+
+	cfg.prom.streams.Inc(r.Pattern)
+	defer cfg.prom.streams.Dec(r.Pattern)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	rc := http.NewResponseController(w)
+	// Big exports take longer than WRITE_TIMEOUT allows a response
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "Couldn't lift the write deadline for an export", "error", err)
+	}
+	enc := json.NewEncoder(w)
+	n := 0
+	for rows.Next() {
+		v, err := scan(rows)
+		if err == nil {
+			err = enc.Encode(v)
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "rows", n, "error", err)
+			return
+		}
+		n++
+		if n%exportFlushEvery == 0 {
+			if err := rc.Flush(); err != nil {
+				slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "rows", n, "error", err)
+				return
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "Export failed", "path", r.URL.Path, "rows", n, "error", err)
+		return
+	}
+	if n == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	slog.InfoContext(r.Context(), "Exported rows", "path", r.URL.Path, "rows", n, "admin_id", requestUserID(r))
+}
+
+// GET /admin/export/chirps
+// Every chirp as NDJSON, oldest first, including those of deleted
+// accounts.
+func (cfg *apiConfig) adminExportChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.streamNDJSON(w, r, exportChirpsQuery, func(rows *sql.Rows) (any, error) {
+		var c database.Chirp
+		err := rows.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt, &c.Body, &c.UserID, &c.ParentChirpID)
+		return newChirp(c), err
+	})
+}
+
+// GET /admin/export/users
+// Every account as NDJSON, oldest first, including deleted ones.
+func (cfg *apiConfig) adminExportUsersHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.streamNDJSON(w, r, exportUsersQuery, func(rows *sql.Rows) (any, error) {
+		var u database.User
+		err := rows.Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt, &u.Email, &u.Username, &u.DisplayName, &u.Bio, &u.Website, &u.AvatarURL)
+		return newUser(u), err
+	})
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/feed"
+	"main.go/internal/response"
+)
+
+const (
+	// How far back, and how many chirps, the ranked feed considers
+	forYouWindow        = 3 * 24 * time.Hour
+	forYouMaxCandidates = 500
+
+	defaultFeedPageSize = 20
+	maxFeedPageSize     = 100
+)
+
+// Feed orderings, selectable per request or as a user setting.
+const (
+	feedRankingTop    = "top"
+	feedRankingLatest = "latest"
+)
+
+func newFeedChirp(row database.GetFeedCandidatesRow, counts chirpCounts) api.FeedChirp {
+	reason := "recommended"
+	if row.Followed {
+		reason = "followed"
+	}
+	fc := api.FeedChirp{
+		Chirp: newChirp(database.Chirp{
+			ID:            row.ID,
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+			Body:          row.Body,
+			UserID:        row.UserID,
+			ParentChirpID: row.ParentChirpID,
+		}),
+		Reason: reason,
+	}
+	counts.apply(&fc.Chirp)
+	return fc
+}
+
+// feedCursor is the opaque position handed to clients as next_cursor.
+//
+// For latest it is the (created_at, id) of the last chirp served. For top
+// it pins the candidate set to chirps created up to AsOf. Served chirps
+// are marked seen, and top leaves seen chirps out, so each top page is
+// the best of the pinned set not served yet.
+type feedCursor struct {
+	Ranking   string    `json:"r"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	ID        uuid.UUID `json:"id,omitempty"`
+	AsOf      time.Time `json:"as_of,omitempty"`
+}
+
+func (c feedCursor) encode() string {
+	dat, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(dat)
+}
+
+func decodeFeedCursor(s string) (feedCursor, error) {
+	var c feedCursor
+	dat, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(dat, &c); err != nil {
+		return c, err
+	}
+	if c.Ranking != feedRankingTop && c.Ranking != feedRankingLatest {
+		return c, errors.New("unknown ranking")
+	}
+	return c, nil
+}
+
+// GET /api/feed/for_you?ranking=latest|top&limit=N&cursor=...
+// Chirps from followed users blended with recommendations. Ordering
+// defaults to the user's feed_ranking setting. Every chirp served is
+// marked seen, and top doesn't show seen chirps again.
+func (cfg *apiConfig) forYouFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	limit, ok := parseLimit(w, r, defaultFeedPageSize, maxFeedPageSize)
+	if !ok {
+		return
+	}
+
+	ranking := r.URL.Query().Get("ranking")
+	switch ranking {
+	case feedRankingTop, feedRankingLatest:
+	case "":
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+			return
+		}
+		ranking = user.FeedRanking
+	default:
+		response.Error(w, http.StatusBadRequest, "ranking must be latest or top", nil)
+		return
+	}
+
+	var cursor *feedCursor
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		c, err := decodeFeedCursor(v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+		// A cursor only makes sense for the ordering it was issued for
+		if c.Ranking != ranking {
+			if r.URL.Query().Get("ranking") != "" {
+				response.Error(w, http.StatusBadRequest, "Cursor was issued for a different ranking", nil)
+				return
+			}
+			ranking = c.Ranking
+		}
+		cursor = &c
+	}
+
+	seen, err := cfg.loadSeen(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch seen chirps", err)
+		return
+	}
+
+	var page []api.FeedChirp
+	var next *feedCursor
+	if ranking == feedRankingLatest {
+		page, next, err = cfg.latestFeedPage(r, userID, cursor, limit)
+	} else {
+		page, next, err = cfg.topFeedPage(r, userID, cursor, limit, seen)
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch feed", err)
+		return
+	}
+	cfg.markSeen(r.Context(), userID, seen, page)
+
+	lastRead, err := cfg.getFeedMarker(r, userID, timelineForYou)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch last read marker", err)
+		return
+	}
+
+	resp := api.FeedPage{
+		Ranking:  ranking,
+		Chirps:   page,
+		LastRead: lastRead,
+	}
+	if next != nil {
+		resp.NextCursor = next.encode()
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// latestFeedPage serves the feed in reverse chronological order using
+// keyset pagination.
+func (cfg *apiConfig) latestFeedPage(r *http.Request, userID uuid.UUID, cursor *feedCursor, limit int) ([]api.FeedChirp, *feedCursor, error) {
+	params := database.GetFeedLatestParams{
+		UserID:          userID,
+		BeforeCreatedAt: time.Now().UTC().Add(time.Hour),
+		BeforeID:        uuid.Max,
+		// Fetch one extra row to know whether there is a next page
+		PageSize: int32(limit + 1),
+	}
+	if cursor != nil {
+		params.BeforeCreatedAt = cursor.CreatedAt
+		params.BeforeID = cursor.ID
+	}
+
+	rows, err := cfg.DB.GetFeedLatest(r.Context(), params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	counts, err := cfg.countChirps(r.Context(), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := make([]api.FeedChirp, 0, limit)
+	for i := 0; i < len(rows) && i < limit; i++ {
+		page = append(page, newFeedChirp(database.GetFeedCandidatesRow(rows[i]), counts))
+	}
+
+	var next *feedCursor
+	if len(rows) > limit {
+		last := rows[limit-1]
+		next = &feedCursor{Ranking: feedRankingLatest, CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page, next, nil
+}
+
+// topFeedPage serves the feed ordered by the configured ranker, leaving
+// out chirps the user has seen.
+func (cfg *apiConfig) topFeedPage(r *http.Request, userID uuid.UUID, cursor *feedCursor, limit int, seen feed.Seen) ([]api.FeedChirp, *feedCursor, error) {
+	asOf := time.Now().UTC()
+	if cursor != nil {
+		asOf = cursor.AsOf
+	}
+
+	rows, err := cfg.DB.GetFeedCandidates(r.Context(), database.GetFeedCandidatesParams{
+		UserID:        userID,
+		Since:         asOf.Add(-forYouWindow),
+		AsOf:          asOf,
+		MaxCandidates: forYouMaxCandidates,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	counts, err := cfg.countChirps(r.Context(), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[uuid.UUID]database.GetFeedCandidatesRow, len(rows))
+	candidates := make([]feed.Candidate, 0, len(rows))
+	for _, row := range rows {
+		if seen.Has(row.ID) {
+			continue
+		}
+		byID[row.ID] = row
+		candidates = append(candidates, feed.Candidate{
+			ChirpID:    row.ID,
+			AuthorID:   row.UserID.UUID,
+			CreatedAt:  row.CreatedAt,
+			Followed:   row.Followed,
+			Engagement: counts.likes[row.ID] + counts.shares[row.ID],
+		})
+	}
+
+	ranked, err := cfg.ranker.Rank(r.Context(), userID, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := make([]api.FeedChirp, 0, limit)
+	for i := 0; i < len(ranked) && len(page) < limit; i++ {
+		page = append(page, newFeedChirp(byID[ranked[i].ChirpID], counts))
+	}
+
+	var next *feedCursor
+	if limit < len(ranked) {
+		next = &feedCursor{Ranking: feedRankingTop, AsOf: asOf}
+	}
+	return page, next, nil
+}
+
+// loadSeen returns the chirps a user was recently served.
+func (cfg *apiConfig) loadSeen(ctx context.Context, userID uuid.UUID) (feed.Seen, error) {
+	row, err := cfg.DB.GetSeenChirps(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return feed.NewSeen(time.Now().UTC()), nil
+	}
+	if err != nil {
+		return feed.Seen{}, err
+	}
+	seen := feed.Seen{Current: row.CurrentFilter, Previous: row.PreviousFilter, RotatedAt: row.RotatedAt}
+	if !seen.Valid() {
+		return feed.NewSeen(time.Now().UTC()), nil
+	}
+	return seen, nil
+}
+
+// markSeen adds a served page to seen and saves it. Concurrent requests
+// by the same user may overwrite each other's marks, so a chirp can
+// occasionally come back. Failures are logged rather than failing the
+// page, which has been fetched already.
+func (cfg *apiConfig) markSeen(ctx context.Context, userID uuid.UUID, seen feed.Seen, page []api.FeedChirp) {
+	if len(page) == 0 {
+		return
+	}
+	seen.Rotate(time.Now().UTC(), forYouWindow)
+	for _, c := range page {
+		seen.Add(c.ID)
+	}
+	if err := cfg.DB.SaveSeenChirps(ctx, database.SaveSeenChirpsParams{
+		UserID:         userID,
+		CurrentFilter:  seen.Current,
+		PreviousFilter: seen.Previous,
+		RotatedAt:      seen.RotatedAt,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to save seen chirps", "user_id", userID, "error", err)
+	}
+}
+
+const (
+	// Used as "last seen" for users who have never loaded a summary
+	defaultSummaryWindow = 24 * time.Hour
+	maxSummaryHighlights = 3
+)
+
+// GET /api/feed?limit=N&cursor=...
+// Chirps from followed users only, newest first.
+func (cfg *apiConfig) homeFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	limit, ok := parseLimit(w, r, defaultFeedPageSize, maxFeedPageSize)
+	if !ok {
+		return
+	}
+
+	params := database.GetHomeFeedParams{
+		UserID:          userID,
+		BeforeCreatedAt: time.Now().UTC().Add(time.Hour),
+		BeforeID:        uuid.Max,
+		// Fetch one extra row to know whether there is a next page
+		PageSize: int32(limit + 1),
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		c, err := decodeFeedCursor(v)
+		if err != nil || c.Ranking != feedRankingLatest {
+			response.Error(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+		params.BeforeCreatedAt = c.CreatedAt
+		params.BeforeID = c.ID
+	}
+
+	rows, err := cfg.DB.GetHomeFeed(r.Context(), params)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch feed", err)
+		return
+	}
+
+	chirps := make([]api.Chirp, 0, limit)
+	for i := 0; i < len(rows) && i < limit; i++ {
+		chirps = append(chirps, newChirp(rows[i]))
+	}
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	lastRead, err := cfg.getFeedMarker(r, userID, timelineHome)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch last read marker", err)
+		return
+	}
+
+	resp := api.FeedPage{
+		Ranking:  feedRankingLatest,
+		Chirps:   make([]api.FeedChirp, 0, len(chirps)),
+		LastRead: lastRead,
+	}
+	for _, c := range chirps {
+		resp.Chirps = append(resp.Chirps, api.FeedChirp{Chirp: c, Reason: "followed"})
+	}
+	if len(rows) > limit {
+		last := rows[limit-1]
+		resp.NextCursor = feedCursor{Ranking: feedRankingLatest, CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// GET /api/feed/summary
+// A "while you were away" digest of what happened since the user last
+// loaded it. Loading the summary marks the user as seen.
+func (cfg *apiConfig) feedSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+
+	since := time.Now().UTC().Add(-defaultSummaryWindow)
+	if user.LastSeenAt.Valid {
+		since = user.LastSeenAt.Time
+	}
+
+	newChirps, err := cfg.DB.CountFollowedChirpsSince(r.Context(), database.CountFollowedChirpsSinceParams{
+		UserID: userID,
+		Since:  since,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirps", err)
+		return
+	}
+
+	newFollowers, err := cfg.DB.CountNewFollowersSince(r.Context(), database.CountNewFollowersSinceParams{
+		UserID: userID,
+		Since:  since,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count followers", err)
+		return
+	}
+
+	highlights, err := cfg.DB.GetFollowedHighlightsSince(r.Context(), database.GetFollowedHighlightsSinceParams{
+		UserID:        userID,
+		Since:         since,
+		MaxHighlights: maxSummaryHighlights,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch highlights", err)
+		return
+	}
+
+	if err := cfg.DB.UpdateUserLastSeen(r.Context(), userID); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update last seen", err)
+		return
+	}
+
+	resp := api.FeedSummary{
+		Since:                  since,
+		NewChirpsFromFollowing: newChirps,
+		NewFollowers:           newFollowers,
+		Highlights:             make([]api.Chirp, 0, len(highlights)),
+	}
+	for _, c := range highlights {
+		resp.Highlights = append(resp.Highlights, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), resp.Highlights); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
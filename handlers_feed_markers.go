@@ -0,0 +1,117 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+// Timelines that can carry a last-read marker.
+const (
+	timelineForYou = "for_you"
+	timelineHome   = "home"
+)
+
+var feedTimelines = map[string]struct{}{
+	timelineForYou: {},
+	timelineHome:   {},
+}
+
+func newFeedMarker(m database.FeedMarker) api.FeedMarker {
+	return api.FeedMarker{
+		Timeline:       m.Timeline,
+		ChirpID:        m.ChirpID,
+		ChirpCreatedAt: m.ChirpCreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// getFeedMarker returns the user's marker for a timeline, or nil if they
+// haven't set one.
+func (cfg *apiConfig) getFeedMarker(r *http.Request, userID uuid.UUID, timeline string) (*api.FeedMarker, error) {
+	marker, err := cfg.DB.GetFeedMarker(r.Context(), database.GetFeedMarkerParams{
+		UserID:   userID,
+		Timeline: timeline,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := newFeedMarker(marker)
+	return &resp, nil
+}
+
+// GET /api/feed/markers
+func (cfg *apiConfig) getFeedMarkersHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	markers, err := cfg.DB.GetFeedMarkers(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch markers", err)
+		return
+	}
+
+	resp := make([]api.FeedMarker, 0, len(markers))
+	for _, m := range markers {
+		resp = append(resp, newFeedMarker(m))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// PUT /api/feed/markers/{timeline}
+// Records the newest chirp the user has read on a timeline. Markers only
+// move forward, so a device with a stale position can't rewind another
+// device's progress; the response is always the current marker.
+func (cfg *apiConfig) updateFeedMarkerHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	timeline := r.PathValue("timeline")
+	if _, ok := feedTimelines[timeline]; !ok {
+		response.Error(w, http.StatusNotFound, "Unknown timeline", nil)
+		return
+	}
+
+	var req api.FeedMarkerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), req.ChirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch chirp", err)
+		}
+		return
+	}
+
+	marker, err := cfg.DB.UpsertFeedMarker(r.Context(), database.UpsertFeedMarkerParams{
+		UserID:         userID,
+		Timeline:       timeline,
+		ChirpID:        chirp.ID,
+		ChirpCreatedAt: chirp.CreatedAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The stored marker is already further along
+		marker, err = cfg.DB.GetFeedMarker(r.Context(), database.GetFeedMarkerParams{
+			UserID:   userID,
+			Timeline: timeline,
+		})
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update marker", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newFeedMarker(marker))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/internal/anomaly"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+)
+
+// POST /api/users/{userID}/follow
+func (cfg *apiConfig) followUserHandler(w http.ResponseWriter, r *http.Request) {
+	followerID := requestUserID(r)
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	if followeeID == followerID {
+		response.Error(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+
+	followee, err := cfg.DB.GetUserByID(r.Context(), followeeID)
+	if err != nil || followee.DeletedAt.Valid {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		}
+		return
+	}
+
+	n, err := cfg.DB.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+
+	// Following someone twice is not an error, but only the first
+	// follow is an event
+	if n == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.FollowCreated, events.FollowData{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	}))
+	cfg.recordActivity(r.Context(), followerID, anomaly.KindFollow, "")
+	cfg.notify(r.Context(), notify.Notification{
+		UserID:  followeeID,
+		Event:   notify.Follow,
+		ActorID: followerID,
+		Text:    "You have a new follower",
+	})
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DELETE /api/users/{userID}/follow
+func (cfg *apiConfig) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	followerID := requestUserID(r)
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	n, err := cfg.DB.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to unfollow user", err)
+		return
+	}
+
+	if n > 0 {
+		cfg.events.Publish(r.Context(), events.New(events.FollowDeleted, events.FollowData{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		}))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+	"main.go/internal/tags"
+)
+
+const (
+	defaultHashtagPageSize = 100
+	maxHashtagPageSize     = 500
+)
+
+// tagChirp replaces the hashtags recorded for a chirp with those in body.
+func tagChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, body string) error {
+	if err := q.UntagChirp(ctx, chirpID); err != nil {
+		return err
+	}
+	found := tags.Hashtags(body)
+	if len(found) == 0 {
+		return nil
+	}
+	if err := q.CreateHashtags(ctx, found); err != nil {
+		return err
+	}
+	return q.TagChirp(ctx, database.TagChirpParams{
+		ChirpID: chirpID,
+		Tags:    found,
+	})
+}
+
+// GET /api/hashtags/{tag}/chirps?limit=N&offset=N
+// Chirps using a hashtag, newest first. The tag is matched
+// case-insensitively, with or without the leading #.
+func (cfg *apiConfig) getHashtagChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	tag := tags.Normalize(r.PathValue("tag"))
+	if tag == "" {
+		response.Error(w, http.StatusBadRequest, "Invalid hashtag", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, defaultHashtagPageSize, maxHashtagPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	chirpsFromDB, err := cfg.DB.GetChirpsByHashtag(r.Context(), database.GetChirpsByHashtagParams{
+		Tag:    tag,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch chirps", err)
+		return
+	}
+
+	total, err := cfg.DB.CountChirpsByHashtag(r.Context(), tag)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirps", err)
+		return
+	}
+
+	chirps := make([]api.Chirp, 0, len(chirpsFromDB))
+	for _, c := range chirpsFromDB {
+		chirps = append(chirps, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	response.JSON(w, http.StatusOK, chirps)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/jobs"
+	"main.go/internal/response"
+)
+
+type jobResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	LastError   *string         `json:"last_error"`
+	RunAt       time.Time       `json:"run_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+}
+
+func newJobResponse(job database.Job) jobResponse {
+	resp := jobResponse{
+		ID:          job.ID,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		Type:        job.Type,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+	}
+	if job.LastError.Valid {
+		resp.LastError = &job.LastError.String
+	}
+	if job.FinishedAt.Valid {
+		resp.FinishedAt = &job.FinishedAt.Time
+	}
+	return resp
+}
+
+// GET /admin/jobs?status=pending|running|failed|succeeded|cancelled&limit=N
+func (cfg *apiConfig) adminListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = jobs.StatusPending
+	case jobs.StatusPending, jobs.StatusRunning, jobs.StatusFailed, jobs.StatusSucceeded, jobs.StatusCancelled:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+
+	jobsFromDB, err := cfg.DB.ListJobsByStatus(r.Context(), database.ListJobsByStatusParams{
+		Status: status,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch jobs", err)
+		return
+	}
+
+	resp := make([]jobResponse, 0, len(jobsFromDB))
+	for _, job := range jobsFromDB {
+		resp = append(resp, newJobResponse(job))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// GET /admin/jobs/{jobID}
+func (cfg *apiConfig) adminGetJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(r.PathValue("jobID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, err := cfg.DB.GetJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Job not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch job", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newJobResponse(job))
+}
+
+// POST /admin/jobs/{jobID}/retry
+func (cfg *apiConfig) adminRetryJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(r.PathValue("jobID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, err := cfg.DB.RetryJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Only failed or cancelled jobs can be retried", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retry job", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newJobResponse(job))
+}
+
+// POST /admin/jobs/{jobID}/cancel
+func (cfg *apiConfig) adminCancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(r.PathValue("jobID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, err := cfg.DB.CancelJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Only pending jobs can be cancelled", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to cancel job", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newJobResponse(job))
+}
+
+type jobTypeStats struct {
+	Type        string  `json:"type"`
+	Pending     int64   `json:"pending"`
+	Running     int64   `json:"running"`
+	Succeeded   int64   `json:"succeeded_24h"`
+	Failed      int64   `json:"failed_24h"`
+	FailureRate float64 `json:"failure_rate_24h"`
+}
+
+// GET /admin/jobs/stats
+// Queue depth per job type, plus throughput and failure rate over the
+// last 24 hours.
+func (cfg *apiConfig) adminJobStatsHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := cfg.DB.GetJobStats(r.Context(), time.Now().UTC().Add(-24*time.Hour))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch job stats", err)
+		return
+	}
+
+	resp := make([]jobTypeStats, 0, len(rows))
+	for _, row := range rows {
+		stats := jobTypeStats{
+			Type:      row.Type,
+			Pending:   row.Pending,
+			Running:   row.Running,
+			Succeeded: row.Succeeded,
+			Failed:    row.Failed,
+		}
+		if finished := row.Succeeded + row.Failed; finished > 0 {
+			stats.FailureRate = float64(row.Failed) / float64(finished)
+		}
+		resp = append(resp, stats)
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"main.go/internal/response"
+)
+
+// Returned when an owner or moderator tries to delete held content.
+const (
+	accountLegalHoldMessage = "Account is under legal hold and can't be deleted"
+	chirpLegalHoldMessage   = "Chirp is under legal hold and can't be deleted"
+)
+
+// PUT /admin/users/{userID}/legal_hold
+// Preserves an account: it can't be deleted, and if it already was, the
+// purge leaves it alone until the hold is released.
+func (cfg *apiConfig) adminPlaceUserLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	setLegalHold(w, r, "userID", "User not found", cfg.DB.PlaceUserLegalHold)
+}
+
+// DELETE /admin/users/{userID}/legal_hold
+func (cfg *apiConfig) adminReleaseUserLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	setLegalHold(w, r, "userID", "User not found", cfg.DB.ReleaseUserLegalHold)
+}
+
+// PUT /admin/chirps/{chirpID}/legal_hold
+// Preserves a chirp, and with it its author's account, which the purge
+// keeps even if it is deleted.
+func (cfg *apiConfig) adminPlaceChirpLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	setLegalHold(w, r, "chirpID", "Chirp not found", cfg.DB.PlaceChirpLegalHold)
+}
+
+// DELETE /admin/chirps/{chirpID}/legal_hold
+func (cfg *apiConfig) adminReleaseChirpLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	setLegalHold(w, r, "chirpID", "Chirp not found", cfg.DB.ReleaseChirpLegalHold)
+}
+
+// setLegalHold places or releases a hold on the row whose ID is in the
+// named path value. Both are idempotent.
+func setLegalHold(w http.ResponseWriter, r *http.Request, name, notFound string, update func(context.Context, uuid.UUID) (int64, error)) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	n, err := update(r.Context(), id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to update legal hold", err)
+		return
+	}
+	if n == 0 {
+		response.Error(w, http.StatusNotFound, notFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+)
+
+// POST /api/chirps/{chirpID}/like
+func (cfg *apiConfig) likeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+		}
+		return
+	}
+
+	n, err := cfg.DB.LikeChirp(r.Context(), database.LikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to like chirp", err)
+		return
+	}
+
+	// Liking a chirp twice is not an error
+	if n == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	cfg.notify(r.Context(), notify.Notification{
+		UserID:  chirp.UserID.UUID,
+		Event:   notify.Like,
+		ActorID: userID,
+		ChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+		Text:    "Someone liked your chirp",
+	})
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DELETE /api/chirps/{chirpID}/like
+func (cfg *apiConfig) unlikeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	_, err = cfg.DB.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to unlike chirp", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
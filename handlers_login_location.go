@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/geoip"
+	"main.go/internal/mail"
+	"main.go/internal/response"
+)
+
+// How long the code in a login verification email can be used
+const loginVerificationTTL = 15 * time.Minute
+
+// checkLoginLocation compares where a login comes from with the user's
+// previous one. If nobody could have travelled between them in the time
+// since, a code is emailed to the user and the login only succeeds once
+// it is retried with that code. Addresses the GeoIP database doesn't
+// know, and failed lookups, are let through. It responds and returns
+// false if the login can't go ahead.
+func (cfg *apiConfig) checkLoginLocation(w http.ResponseWriter, r *http.Request, user database.User, creds api.Credentials) bool {
+	if cfg.geoip == nil {
+		return true
+	}
+	ip, err := netip.ParseAddr(clientIP(r))
+	if err != nil {
+		return true
+	}
+	loc, ok, err := cfg.geoip.Lookup(ip)
+	if err != nil {
+		slog.WarnContext(r.Context(), "GeoIP lookup failed", "error", err)
+		return true
+	}
+	if !ok {
+		return true
+	}
+
+	prev, err := cfg.DB.GetLoginLocation(r.Context(), user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch last login location", err)
+		return false
+	}
+	if err == nil {
+		from := geoip.Location{Latitude: prev.Latitude, Longitude: prev.Longitude, Country: prev.Country}
+		elapsed := time.Now().UTC().Sub(prev.LoggedInAt)
+		if geoip.ImpossibleTravel(from, loc, elapsed, cfg.loginMaxTravelSpeed) && !cfg.verifyLogin(w, r, user, creds) {
+			return false
+		}
+	}
+
+	err = cfg.DB.SetLoginLocation(r.Context(), database.SetLoginLocationParams{
+		UserID:    user.ID,
+		Latitude:  loc.Latitude,
+		Longitude: loc.Longitude,
+		Country:   loc.Country,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't save login location", err)
+		return false
+	}
+	return true
+}
+
+// verifyLogin checks the emailed code of a login from an implausible
+// location. Without one, it emails a new code. Each code gets a single
+// attempt, so it can't be guessed. It responds and returns false unless
+// the code is right.
+func (cfg *apiConfig) verifyLogin(w http.ResponseWriter, r *http.Request, user database.User, creds api.Credentials) bool {
+	if creds.VerificationCode != "" {
+		v, err := cfg.DB.TakeLoginVerification(r.Context(), user.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusInternalServerError, "Couldn't check verification code", err)
+			return false
+		}
+		if err != nil || time.Now().UTC().After(v.ExpiresAt) ||
+			subtle.ConstantTimeCompare([]byte(v.CodeHash), []byte(auth.HashToken(creds.VerificationCode))) != 1 {
+			response.Error(w, http.StatusUnauthorized, "Invalid verification code, log in again without it for a new one", nil)
+			return false
+		}
+		return true
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't create verification code", err)
+		return false
+	}
+	code := fmt.Sprintf("%06d", n)
+	err = cfg.DB.CreateLoginVerification(r.Context(), database.CreateLoginVerificationParams{
+		UserID:    user.ID,
+		CodeHash:  auth.HashToken(code),
+		ExpiresAt: time.Now().UTC().Add(loginVerificationTTL),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't save verification code", err)
+		return false
+	}
+	err = cfg.mailer.Send(r.Context(), mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your Chirpy login",
+		Body: fmt.Sprintf("Someone logged in to your Chirpy account from an unusual location (%s). "+
+			"If it was you, enter this code within %.0f minutes to continue:\n\n%s\n\n"+
+			"If it wasn't, change your password now.\n", clientIP(r), loginVerificationTTL.Minutes(), code),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't send verification code", err)
+		return false
+	}
+
+	response.Error(w, http.StatusUnauthorized, "Login from an unusual location, enter the code sent to your email as verification_code", nil)
+	return false
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/geoip"
+	"main.go/internal/totp"
+)
+
+func TestLoginFromAfarWithSecondFactor(t *testing.T) {
+	userID := uuid.New()
+	hash, err := auth.HashPassword("pa$$word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usedSteps []int64
+	db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+		"GetUserByEmail": func(args []driver.Value) fakeResult {
+			row := userRow(userID, "walt@example.com")
+			row[4] = hash
+			return fakeResult{rows: [][]driver.Value{row}}
+		},
+		"GetTwoFactor": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), secret, time.Now(), time.Now(), int64(0)}}}
+		},
+		"UseTwoFactorStep": func(args []driver.Value) fakeResult {
+			usedSteps = append(usedSteps, args[0].(int64))
+			return fakeResult{affected: 1}
+		},
+		// A minute ago from London
+		"GetLoginLocation": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), 51.5142, -0.0931, "GB", time.Now().UTC().Add(-time.Minute)}}}
+		},
+		"SetLoginLocation": func(args []driver.Value) fakeResult {
+			return fakeResult{affected: 1}
+		},
+		"CreateLoginVerification": func(args []driver.Value) fakeResult {
+			return fakeResult{affected: 1}
+		},
+		"CreateRefreshToken": func(args []driver.Value) fakeResult {
+			now := time.Now()
+			return fakeResult{rows: [][]driver.Value{{args[0], now, now, userID.String(), now.Add(time.Hour), nil, true, uuid.NewString(), nil}}}
+		},
+		"RevokeExcessRefreshTokens": func(args []driver.Value) fakeResult {
+			return fakeResult{}
+		},
+		"TakeLoginVerification": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), auth.HashToken("123456"), time.Now(), time.Now().Add(time.Minute)}}}
+		},
+	}}
+	buf, err := os.ReadFile("testdata/geoip.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := geoip.New(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB := sql.OpenDB(db)
+	cfg := &apiConfig{
+		DB:                  database.New(sqlDB),
+		sqlDB:               sqlDB,
+		prom:                newServerMetrics(),
+		jwtKeys:             auth.Keyring{{Secret: []byte("secret")}},
+		geoip:               reader,
+		loginMaxTravelSpeed: 1000,
+		mailer:              &fakeMailer{},
+	}
+	routes := cfg.routes(".")
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Logging in from Linköping
+	login := func(verificationCode string) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(api.Credentials{
+			Email:            "walt@example.com",
+			Password:         "pa$$word",
+			TOTPCode:         code,
+			VerificationCode: verificationCode,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewReader(body))
+		req.RemoteAddr = "89.160.20.1:1234"
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := login("")
+	if msg := errorMessage(t, rec); rec.Code != http.StatusUnauthorized || !strings.HasPrefix(msg, "Login from an unusual location") {
+		t.Fatalf("login from afar = %d %q, want 401 asking for a verification code", rec.Code, msg)
+	}
+	if len(usedSteps) != 0 {
+		t.Errorf("two-factor code used by a login that didn't go ahead")
+	}
+
+	rec = login("123456")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("retry with the same two-factor code = %d %q, want 200", rec.Code, errorMessage(t, rec))
+	}
+	if len(usedSteps) != 1 || usedSteps[0] != step {
+		t.Errorf("UseTwoFactorStep steps = %v, want [%d] from the successful login", usedSteps, step)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"main.go/internal/media"
+	"main.go/internal/response"
+)
+
+// Media under this prefix is only served through signed URLs, which are
+// handed out by endpoints that have already checked who may see it.
+const privateMediaPrefix = "private/"
+
+// GET /media/{path...}
+// Serves uploaded media. Public files may only be embedded by this site
+// (or the hosts in MEDIA_ALLOWED_HOSTS); private files require a signed URL.
+func (cfg *apiConfig) mediaHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	if !fs.ValidPath(name) {
+		response.Error(w, http.StatusNotFound, "Not found", nil)
+		return
+	}
+
+	signed := false
+	if r.URL.Query().Has("sig") {
+		err := media.VerifyURL(r.URL.Path, r.URL.Query(), cfg.mediaSigningSecret, time.Now())
+		if err != nil {
+			response.Error(w, http.StatusForbidden, "Invalid or expired media link", err)
+			return
+		}
+		signed = true
+	}
+
+	if strings.HasPrefix(name, privateMediaPrefix) && !signed {
+		response.Error(w, http.StatusForbidden, "This media requires a signed link", nil)
+		return
+	}
+
+	if !signed && !cfg.mediaRefererAllowed(r) {
+		response.Error(w, http.StatusForbidden, "Hotlinking is not allowed", nil)
+		return
+	}
+
+	mediaFS := os.DirFS(cfg.mediaRoot)
+	info, err := fs.Stat(mediaFS, name)
+	if err != nil || info.IsDir() {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			response.Error(w, http.StatusNotFound, "Not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Couldn't read media", err)
+		}
+		return
+	}
+
+	if signed {
+		w.Header().Set("Cache-Control", "private, max-age=300")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("Vary", "Referer")
+		// Lets browsers enforce the embedding policy too
+		w.Header().Set("Cross-Origin-Resource-Policy", "same-site")
+	}
+	http.ServeFileFS(w, r, mediaFS, name)
+}
+
+// mediaRefererAllowed reports whether the request comes from a page that
+// may embed our media. Requests without a Referer (direct visits, apps,
+// privacy-conscious browsers) are allowed.
+func (cfg *apiConfig) mediaRefererAllowed(r *http.Request) bool {
+	referer := r.Header.Get("Referer")
+	if referer == "" {
+		return true
+	}
+	u, err := url.Parse(referer)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, host := range cfg.mediaAllowedHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
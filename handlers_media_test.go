@@ -0,0 +1,104 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+)
+
+func TestFollowersOnlyChirpImage(t *testing.T) {
+	authorID, followerID, strangerID := uuid.New(), uuid.New(), uuid.New()
+	chirpID := uuid.New()
+	const image = "private/chirps/a.png"
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "private", "chirps"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, image), []byte("\x89PNG\r\n\x1a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	none := func(args []driver.Value) fakeResult { return fakeResult{} }
+	db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+		"GetChirp": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{chirpID.String(), time.Now(), time.Now(), "Say my name", authorID.String(), nil, nil, nil}}}
+		},
+		"GetLikeCounts":           none,
+		"GetReplyCounts":          none,
+		"GetRechirpCounts":        none,
+		"GetShareCounts":          none,
+		"GetChirpAuthorUsernames": none,
+		"ListChirpImages": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{chirpID.String(), time.Now(), image, true, authorID.String()}}}
+		},
+		"ListFollowedAmong": func(args []driver.Value) fakeResult {
+			if args[0] != followerID.String() {
+				return fakeResult{}
+			}
+			return fakeResult{rows: [][]driver.Value{{authorID.String()}}}
+		},
+	}}
+	cfg := &apiConfig{
+		DB:                 database.New(sql.OpenDB(db)),
+		jwtKeys:            auth.Keyring{{Secret: []byte("secret")}},
+		mediaRoot:          root,
+		mediaSigningSecret: "media",
+	}
+	mux := cfg.middlewareAuthorize(cfg.routes(".").ServeMux)
+
+	get := func(path string, userID uuid.UUID) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if userID != uuid.Nil {
+			token, err := auth.MakeJWT(auth.AccessToken{UserID: userID, Role: auth.RoleUser}, cfg.jwtKeys, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	imageURL := func(userID uuid.UUID) *string {
+		t.Helper()
+		rec := get("/api/v1/chirps/"+chirpID.String(), userID)
+		var chirp api.Chirp
+		if err := json.NewDecoder(rec.Body).Decode(&chirp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("get chirp = %d, %v", rec.Code, err)
+		}
+		return chirp.ImageURL
+	}
+
+	for name, userID := range map[string]uuid.UUID{"author": authorID, "follower": followerID} {
+		link := imageURL(userID)
+		if link == nil {
+			t.Errorf("%s got no image link", name)
+			continue
+		}
+		if rec := get(*link, uuid.Nil); rec.Code != http.StatusOK {
+			t.Errorf("%s's link %s = %d, want 200", name, *link, rec.Code)
+		}
+	}
+
+	for name, userID := range map[string]uuid.UUID{"stranger": strangerID, "anonymous": uuid.Nil} {
+		if link := imageURL(userID); link != nil {
+			t.Errorf("%s got image link %s, want none", name, *link)
+		}
+		if rec := get("/media/"+image, userID); rec.Code != http.StatusForbidden {
+			t.Errorf("%s fetching the image = %d, want 403", name, rec.Code)
+		}
+	}
+}
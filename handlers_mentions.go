@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+	"main.go/internal/tags"
+)
+
+const (
+	defaultMentionsPageSize = 100
+	maxMentionsPageSize     = 500
+)
+
+// mentionUsers replaces the mentions recorded for a chirp with the users
+// @mentioned in body. Unknown usernames are ignored.
+func mentionUsers(ctx context.Context, q *database.Queries, chirpID uuid.UUID, body string) error {
+	if err := q.ClearMentions(ctx, chirpID); err != nil {
+		return err
+	}
+	usernames := tags.Mentions(body)
+	if len(usernames) == 0 {
+		return nil
+	}
+	return q.MentionUsers(ctx, database.MentionUsersParams{
+		ChirpID:   chirpID,
+		Usernames: usernames,
+	})
+}
+
+// GET /api/users/me/mentions?limit=N&offset=N
+// Chirps that @mention the current user, newest first.
+func (cfg *apiConfig) getMentionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	limit, ok := parseLimit(w, r, defaultMentionsPageSize, maxMentionsPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	chirpsFromDB, err := cfg.DB.GetMentions(r.Context(), database.GetMentionsParams{
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch mentions", err)
+		return
+	}
+
+	total, err := cfg.DB.CountMentions(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count mentions", err)
+		return
+	}
+
+	chirps := make([]api.Chirp, 0, len(chirpsFromDB))
+	for _, c := range chirpsFromDB {
+		chirps = append(chirps, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	response.JSON(w, http.StatusOK, chirps)
+}
+
+// notifyMentions notifies the users @mentioned in a new chirp.
+func (cfg *apiConfig) notifyMentions(ctx context.Context, chirp database.Chirp) {
+	userIDs, err := cfg.DB.GetMentionedUserIDs(ctx, chirp.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to fetch mentioned users", "chirp_id", chirp.ID, "error", err)
+		return
+	}
+	for _, userID := range userIDs {
+		cfg.notify(ctx, notify.Notification{
+			UserID:  userID,
+			Event:   notify.Mention,
+			ActorID: chirp.UserID.UUID,
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+			Text:    "You were mentioned in a chirp",
+		})
+	}
+}
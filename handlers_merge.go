@@ -0,0 +1,197 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+)
+
+const auditMergeUser = "merge_user"
+
+var (
+	errMergeLegalHold = errors.New("account or its chirps are under legal hold")
+	errMergeTarget    = errors.New("account to merge into is deleted")
+)
+
+type mergeRequest struct {
+	// Into is the account that keeps the data.
+	Into uuid.UUID `json:"into"`
+}
+
+// mergeReport records what a merge did. It is stored in the audit log.
+type mergeReport struct {
+	UserID uuid.UUID `json:"user_id"`
+	IntoID uuid.UUID `json:"into_id"`
+	// Username is the merged account's username, which now redirects to
+	// the other account.
+	Username *string `json:"username"`
+	// Moved is the number of rows moved to the other account, by table.
+	Moved map[string]int64 `json:"moved"`
+	// Dropped is the number of rows the other account already had an
+	// equivalent of, such as a like of the same chirp, by table. They
+	// were deleted with the merged account.
+	Dropped map[string]int64 `json:"dropped"`
+}
+
+// POST /admin/users/{userID}/merge
+// Merges a duplicate account into another: its chirps, follows, likes,
+// rechirps, mentions, collections and shares move to the other account,
+// where that account doesn't already have the same, and the duplicate is
+// deleted. Its username keeps leading to the other account, for mentions,
+// and can't be taken by anyone else. Recorded in the audit log.
+func (cfg *apiConfig) adminMergeUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var req mergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Into == uuid.Nil {
+		response.Error(w, http.StatusBadRequest, "The account to merge into is required", nil)
+		return
+	}
+	if req.Into == userID {
+		response.Error(w, http.StatusBadRequest, "Can't merge an account into itself", nil)
+		return
+	}
+
+	report := mergeReport{UserID: userID, IntoID: req.Into}
+	var avatars []sql.NullString
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		user, err := q.GetUserByID(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		into, err := q.GetUserByID(r.Context(), req.Into)
+		if err != nil {
+			return err
+		}
+		if into.DeletedAt.Valid {
+			return errMergeTarget
+		}
+		held, err := q.UserHasHeldChirps(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+		if err != nil {
+			return err
+		}
+		if held || user.LegalHoldAt.Valid {
+			return errMergeLegalHold
+		}
+
+		counts, err := q.CountUserData(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+		moved := map[string]int64{}
+		for _, m := range []struct {
+			table string
+			merge func() (int64, error)
+		}{
+			{"chirps", func() (int64, error) {
+				return q.MergeChirps(r.Context(), database.MergeChirpsParams{ToID: into.ID, FromID: userID})
+			}},
+			{"following", func() (int64, error) {
+				return q.MergeFollowing(r.Context(), database.MergeFollowingParams{ToID: into.ID, FromID: userID})
+			}},
+			{"followers", func() (int64, error) {
+				return q.MergeFollowers(r.Context(), database.MergeFollowersParams{ToID: into.ID, FromID: userID})
+			}},
+			{"likes", func() (int64, error) {
+				return q.MergeLikes(r.Context(), database.MergeLikesParams{ToID: into.ID, FromID: userID})
+			}},
+			{"rechirps", func() (int64, error) {
+				return q.MergeRechirps(r.Context(), database.MergeRechirpsParams{ToID: into.ID, FromID: userID})
+			}},
+			{"chirp_mentions", func() (int64, error) {
+				return q.MergeMentions(r.Context(), database.MergeMentionsParams{ToID: into.ID, FromID: userID})
+			}},
+			{"collections", func() (int64, error) {
+				return q.MergeCollections(r.Context(), database.MergeCollectionsParams{ToID: into.ID, FromID: userID})
+			}},
+			{"chirp_shares", func() (int64, error) {
+				return q.MergeShares(r.Context(), database.MergeSharesParams{ToID: into.ID, FromID: userID})
+			}},
+			{"username_redirects", func() (int64, error) {
+				return q.MergeUsernameRedirects(r.Context(), database.MergeUsernameRedirectsParams{ToID: into.ID, FromID: userID})
+			}},
+		} {
+			n, err := m.merge()
+			if err != nil {
+				return err
+			}
+			moved[m.table] = n
+		}
+		report.Moved = moved
+		report.Dropped = map[string]int64{
+			"follows":        counts.Follows - moved["following"] - moved["followers"],
+			"likes":          counts.Likes - moved["likes"],
+			"rechirps":       counts.Rechirps - moved["rechirps"],
+			"chirp_mentions": counts.Mentions - moved["chirp_mentions"],
+			"chirp_shares":   counts.Shares - moved["chirp_shares"],
+		}
+
+		if user.Username.Valid {
+			report.Username = &user.Username.String
+			if err := q.CreateUsernameRedirect(r.Context(), database.CreateUsernameRedirectParams{
+				Username: user.Username.String,
+				UserID:   into.ID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Whatever wasn't moved goes with the user through ON DELETE CASCADE
+		avatars, err = q.PurgeUsers(r.Context(), []uuid.UUID{userID})
+		if err != nil {
+			return err
+		}
+
+		details, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLogEntry(r.Context(), database.CreateAuditLogEntryParams{
+			AdminID:  uuid.NullUUID{UUID: requestUserID(r), Valid: true},
+			Action:   auditMergeUser,
+			TargetID: userID,
+			Details:  details,
+		})
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		case errors.Is(err, errMergeTarget):
+			response.Error(w, http.StatusConflict, "The account to merge into is deleted", nil)
+		case errors.Is(err, errMergeLegalHold):
+			response.Error(w, http.StatusConflict, "Account or its chirps are under legal hold and can't be merged", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "Failed to merge accounts", err)
+		}
+		return
+	}
+
+	for _, avatar := range avatars {
+		if !avatar.Valid {
+			continue
+		}
+		if err := cfg.removeAvatar(avatar.String); err != nil {
+			slog.WarnContext(r.Context(), "Couldn't remove avatar of merged user", "user_id", userID, "error", err)
+		}
+	}
+	cfg.events.Publish(r.Context(), events.New(events.UserMerged, events.MergeData{UserID: userID, IntoID: req.Into}))
+
+	slog.InfoContext(r.Context(), "Merged user", "user_id", userID, "into_id", req.Into)
+	response.JSON(w, http.StatusOK, report)
+}
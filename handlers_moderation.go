@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/anomaly"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+// Moderation queue entry statuses and sources.
+const (
+	moderationOpen     = "open"
+	moderationResolved = "resolved"
+
+	moderationSourceAnomaly = "anomaly"
+)
+
+type moderationResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Source     string          `json:"source"`
+	Signals    json.RawMessage `json:"signals"`
+	Status     string          `json:"status"`
+	ResolvedAt *time.Time      `json:"resolved_at"`
+}
+
+func newModerationResponse(e database.ModerationQueue) moderationResponse {
+	resp := moderationResponse{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+		UserID:    e.UserID,
+		Source:    e.Source,
+		Signals:   e.Signals,
+		Status:    e.Status,
+	}
+	if e.ResolvedAt.Valid {
+		resp.ResolvedAt = &e.ResolvedAt.Time
+	}
+	return resp
+}
+
+// recordActivity feeds the anomaly detector and queues the user for
+// moderation if they trip one of its rules. Failures are logged rather
+// than failing the request that did the activity.
+func (cfg *apiConfig) recordActivity(ctx context.Context, userID uuid.UUID, kind, value string) {
+	if cfg.anomalies == nil {
+		return
+	}
+	signal, ok := cfg.anomalies.Record(userID, kind, value)
+	if !ok {
+		return
+	}
+
+	signals, err := json.Marshal([]anomaly.Signal{signal})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode anomaly signal", "error", err)
+		return
+	}
+	if _, err := cfg.DB.CreateModerationEntry(ctx, database.CreateModerationEntryParams{
+		UserID:  userID,
+		Source:  moderationSourceAnomaly,
+		Signals: signals,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to queue user for moderation", "user_id", userID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Queued user for moderation", "user_id", userID, "kind", signal.Kind, "threshold", signal.Threshold, "window", signal.Window)
+}
+
+// GET /admin/moderation?status=open|resolved&limit=N
+// Oldest entries first, so the queue is worked in order.
+func (cfg *apiConfig) adminListModerationHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = moderationOpen
+	case moderationOpen, moderationResolved:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+
+	entries, err := cfg.DB.ListModerationEntries(r.Context(), database.ListModerationEntriesParams{
+		Status: status,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch moderation queue", err)
+		return
+	}
+
+	resp := make([]moderationResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, newModerationResponse(e))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /admin/moderation/{entryID}/resolve
+func (cfg *apiConfig) adminResolveModerationHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(r.PathValue("entryID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid entry ID", err)
+		return
+	}
+
+	entry, err := cfg.DB.ResolveModerationEntry(r.Context(), entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Only open entries can be resolved", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to resolve entry", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newModerationResponse(entry))
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/oauth"
+	"main.go/internal/response"
+)
+
+// oauthStateCookie carries the state of a sign-in in progress from the
+// login redirect to the callback, so a callback can't be forged for
+// someone else's browser.
+const oauthStateCookie = "chirpy_oauth_state"
+
+// oauthStateTTL is how long a user has to sign in with the provider.
+const oauthStateTTL = 10 * time.Minute
+
+// oauthCodesCookie carries the codes a sign-in was started with to the
+// callback, which the provider redirects to without them.
+const oauthCodesCookie = "chirpy_oauth_codes"
+
+// oauthCodeParams are the codes GET /api/auth/{provider}/login takes,
+// named as in the body of POST /api/login.
+var oauthCodeParams = []string{"totp_code", "recovery_code", "verification_code"}
+
+// oauthProvider returns the configured provider named in the path,
+// responding with 404 if there is none.
+func (cfg *apiConfig) oauthProvider(w http.ResponseWriter, r *http.Request) (*oauth.Provider, bool) {
+	p, ok := cfg.oauthProviders[r.PathValue("provider")]
+	if !ok {
+		response.Error(w, http.StatusNotFound, "Unknown sign-in provider", nil)
+	}
+	return p, ok
+}
+
+// GET /api/auth/{provider}/login?totp_code=...
+// Redirects to the provider to sign in. A user with two-factor
+// authentication enabled starts again with totp_code or recovery_code
+// once the callback has asked for one, and a user signing in from an
+// unusual location with the verification_code emailed to them.
+func (cfg *apiConfig) oauthLoginHandler(w http.ResponseWriter, r *http.Request) {
+	p, ok := cfg.oauthProvider(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := auth.MakeRefreshToken()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't create sign-in state", err)
+		return
+	}
+	// Lax, as the provider redirects back from another site
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/api/",
+		MaxAge:   int(oauthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	codes := url.Values{}
+	for _, name := range oauthCodeParams {
+		if v := r.URL.Query().Get(name); v != "" {
+			codes.Set(name, v)
+		}
+	}
+	codesCookie := &http.Cookie{
+		Name:     oauthCodesCookie,
+		Value:    codes.Encode(),
+		Path:     "/api/",
+		MaxAge:   int(oauthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if len(codes) > 0 {
+		http.SetCookie(w, codesCookie)
+	} else if _, err := r.Cookie(oauthCodesCookie); err == nil {
+		// The codes of an earlier sign-in aren't reused
+		codesCookie.MaxAge = -1
+		http.SetCookie(w, codesCookie)
+	}
+	http.Redirect(w, r, p.AuthCodeURL(state), http.StatusFound)
+}
+
+// GET /api/auth/{provider}/callback?code=...&state=...
+// Completes a sign-in started by oauthLoginHandler. The account with the
+// provider's verified email address is logged in, and created if there
+// is none, with the same response as POST /api/login. Like a login, it
+// needs a second factor once the account has one, and an emailed code
+// when it comes from somewhere the user couldn't have travelled to.
+func (cfg *apiConfig) oauthCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	p, ok := cfg.oauthProvider(w, r)
+	if !ok {
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	state := r.URL.Query().Get("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		response.Error(w, http.StatusBadRequest, "Invalid sign-in state", err)
+		return
+	}
+	creds := oauthCredentials(r)
+	for _, name := range []string{oauthStateCookie, oauthCodesCookie} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Path:     "/api/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	// The user declined, or the provider failed
+	if e := r.URL.Query().Get("error"); e != "" {
+		response.Error(w, http.StatusUnauthorized, "Sign-in was cancelled", errors.New(e))
+		return
+	}
+
+	accessToken, err := p.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, "Couldn't complete sign-in", err)
+		return
+	}
+	identity, err := p.Identity(r.Context(), accessToken)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnverifiedEmail) {
+			response.Error(w, http.StatusForbidden, "Your account with the provider has no verified email address", err)
+		} else {
+			response.Error(w, http.StatusBadGateway, "Couldn't get your account from the provider", err)
+		}
+		return
+	}
+
+	user, err := cfg.DB.GetUserByEmail(r.Context(), identity.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = cfg.createOAuthUser(r, identity)
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't find or create user", err)
+		return
+	}
+
+	if user.DeletedAt.Valid {
+		response.Error(w, http.StatusForbidden, "Account is scheduled for deletion, restore it via /api/users/restore", nil)
+		return
+	}
+
+	factor, ok := cfg.checkSecondFactor(w, r, user.ID, creds)
+	if !ok {
+		return
+	}
+	if !cfg.checkLoginLocation(w, r, user, creds) {
+		return
+	}
+	if !cfg.useSecondFactor(w, r, factor) {
+		return
+	}
+
+	cfg.startSession(w, r, user, true)
+}
+
+// oauthCredentials returns the codes the sign-in in progress was started
+// with.
+func oauthCredentials(r *http.Request) api.Credentials {
+	var codes url.Values
+	if cookie, err := r.Cookie(oauthCodesCookie); err == nil {
+		codes, _ = url.ParseQuery(cookie.Value)
+	}
+	return api.Credentials{
+		TOTPCode:         codes.Get("totp_code"),
+		RecoveryCode:     codes.Get("recovery_code"),
+		VerificationCode: codes.Get("verification_code"),
+	}
+}
+
+// createOAuthUser signs up the user behind identity. The account gets a
+// random password nobody knows; a password reset sets a real one.
+func (cfg *apiConfig) createOAuthUser(r *http.Request, identity oauth.Identity) (database.User, error) {
+	password, err := auth.MakeRefreshToken()
+	if err != nil {
+		return database.User{}, err
+	}
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	user, err := cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
+		Email:          identity.Email,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	cfg.events.Publish(r.Context(), events.New(events.UserCreated, userEventData(user)))
+	return user, nil
+}
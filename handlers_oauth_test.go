@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/geoip"
+	"main.go/internal/mail"
+	"main.go/internal/oauth"
+	"main.go/internal/totp"
+)
+
+func TestOAuthState(t *testing.T) {
+	cfg := &apiConfig{oauthProviders: map[string]*oauth.Provider{
+		"github": oauth.GitHub("client", "secret", "https://chirpy.example/api/v1/auth/github/callback"),
+	}}
+	mux := cfg.routes(".")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/auth/github/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302", rec.Code)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := location.Query().Get("state")
+	cookies := rec.Result().Cookies()
+	if state == "" || len(cookies) != 1 || cookies[0].Value != state {
+		t.Fatalf("state = %q, cookies = %v, want the state in a cookie", state, cookies)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		cookie string
+		want   int
+	}{
+		{name: "Unknown provider", path: "/api/v1/auth/myspace/login", want: http.StatusNotFound},
+		{name: "No cookie", path: "/api/v1/auth/github/callback?code=c&state=" + state, want: http.StatusBadRequest},
+		{name: "Other state", path: "/api/v1/auth/github/callback?code=c&state=forged", cookie: state, want: http.StatusBadRequest},
+		{name: "Declined", path: "/api/v1/auth/github/callback?error=access_denied&state=" + state, cookie: state, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuthSignInRequiresSecondFactor(t *testing.T) {
+	userID := uuid.New()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usedStep int64
+	db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+		"GetUserByEmail": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{userRow(userID, "walt@example.com")}}
+		},
+		"GetTwoFactor": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), secret, time.Now(), time.Now(), int64(0)}}}
+		},
+		// As if the code had been used already
+		"UseTwoFactorStep": func(args []driver.Value) fakeResult {
+			usedStep = args[0].(int64)
+			return fakeResult{}
+		},
+	}}
+	cfg := &apiConfig{
+		DB:             database.New(sql.OpenDB(db)),
+		oauthProviders: fakeOAuth(t, "walt@example.com"),
+	}
+	mux := cfg.routes(".")
+
+	rec := oauthSignIn(t, mux, "google", "")
+	if msg := errorMessage(t, rec); rec.Code != http.StatusUnauthorized || msg != "Two-factor code required" {
+		t.Fatalf("sign-in without a code = %d %q, want 401 asking for a code", rec.Code, msg)
+	}
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = oauthSignIn(t, mux, "google", "?totp_code="+code)
+	if msg := errorMessage(t, rec); rec.Code != http.StatusUnauthorized || msg != "Invalid two-factor code" {
+		t.Errorf("sign-in with a used code = %d %q, want 401 refusing the code", rec.Code, msg)
+	}
+	if usedStep != step {
+		t.Errorf("UseTwoFactorStep step = %d, want %d from the code given at login", usedStep, step)
+	}
+}
+
+func TestOAuthSignInChecksLocation(t *testing.T) {
+	userID := uuid.New()
+	db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+		"GetUserByEmail": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{userRow(userID, "walt@example.com")}}
+		},
+		"GetTwoFactor": func(args []driver.Value) fakeResult {
+			return fakeResult{}
+		},
+		// A minute ago from London
+		"GetLoginLocation": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), 51.5142, -0.0931, "GB", time.Now().UTC().Add(-time.Minute)}}}
+		},
+		"CreateLoginVerification": func(args []driver.Value) fakeResult {
+			return fakeResult{affected: 1}
+		},
+		"TakeLoginVerification": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{userID.String(), auth.HashToken("123456"), time.Now(), time.Now().Add(time.Minute)}}}
+		},
+	}}
+	buf, err := os.ReadFile("testdata/geoip.mmdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := geoip.New(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer := &fakeMailer{}
+	cfg := &apiConfig{
+		DB:                  database.New(sql.OpenDB(db)),
+		oauthProviders:      fakeOAuth(t, "walt@example.com"),
+		geoip:               reader,
+		loginMaxTravelSpeed: 1000,
+		mailer:              mailer,
+	}
+	routes := cfg.routes(".")
+	// Signing in from Linköping
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = "89.160.20.1:1234"
+		routes.ServeHTTP(w, r)
+	})
+
+	rec := oauthSignIn(t, mux, "github", "")
+	if msg := errorMessage(t, rec); rec.Code != http.StatusUnauthorized || !strings.HasPrefix(msg, "Login from an unusual location") {
+		t.Fatalf("sign-in from afar = %d %q, want 401 asking for a verification code", rec.Code, msg)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "walt@example.com" {
+		t.Errorf("sent %v, want a verification code emailed to the user", mailer.sent)
+	}
+
+	rec = oauthSignIn(t, mux, "github", "?verification_code=654321")
+	if msg := errorMessage(t, rec); rec.Code != http.StatusUnauthorized || !strings.HasPrefix(msg, "Invalid verification code") {
+		t.Errorf("sign-in with a wrong code = %d %q, want 401 refusing the code", rec.Code, msg)
+	}
+}
+
+// oauthSignIn starts a sign-in with provider, with query added to the
+// login URL, and returns the response of its callback.
+func oauthSignIn(t *testing.T, mux http.Handler, provider, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/auth/"+provider+"/login"+query, nil))
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/"+provider+"/callback?code=good&state="+location.Query().Get("state"), nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorMessage returns the message of an error response.
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response %q isn't an error: %v", rec.Body, err)
+	}
+	return resp.Error
+}
+
+// fakeOAuth returns Google and GitHub providers whose token and user
+// endpoints are served by a test server, signing in email.
+func fakeOAuth(t *testing.T, email string) map[string]*oauth.Provider {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"sub": "g-1", "email": email, "email_verified": true})
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42}`))
+	})
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{{"email": email, "primary": true, "verified": true}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	google := oauth.Google("client", "secret", "https://chirpy.example/api/v1/auth/google/callback")
+	google.UserURL = srv.URL + "/userinfo"
+	github := oauth.GitHub("client", "secret", "https://chirpy.example/api/v1/auth/github/callback")
+	github.UserURL = srv.URL
+	for _, p := range []*oauth.Provider{google, github} {
+		p.TokenURL = srv.URL + "/token"
+		p.Client = srv.Client()
+	}
+	return map[string]*oauth.Provider{"google": google, "github": github}
+}
+
+// userRow is a users row, in the column order of the generated queries.
+func userRow(id uuid.UUID, email string) []driver.Value {
+	now := time.Now()
+	return []driver.Value{id.String(), now, now, email, "", nil, "latest", nil, nil, nil, nil, nil, nil, nil, false, "user", "new", nil, nil}
+}
+
+// fakeDB is a database/sql connector answering queries by their sqlc
+// name, such as "GetUserByEmail", from the test's functions. Any other
+// query fails the test.
+type fakeDB struct {
+	t       *testing.T
+	queries map[string]func(args []driver.Value) fakeResult
+}
+
+// fakeResult is what a fakeDB query returns: rows, or the number of rows
+// a statement affected.
+type fakeResult struct {
+	rows     [][]driver.Value
+	affected int64
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return db }
+func (db *fakeDB) Open(string) (driver.Conn, error)             { return fakeConn{db}, nil }
+
+func (db *fakeDB) run(query string, args []driver.Value) (fakeResult, error) {
+	name, _, _ := strings.Cut(strings.TrimPrefix(query, "-- name: "), " ")
+	fn, ok := db.queries[name]
+	if !ok {
+		db.t.Errorf("unexpected query %s", name)
+		return fakeResult{}, fmt.Errorf("fakeDB: unexpected query %s", name)
+	}
+	return fn(args), nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+// fakeTx runs queries as they come, like the connection outside it.
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.db.run(s.query, args)
+	return driver.RowsAffected(res.affected), err
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, err := s.db.run(s.query, args)
+	return &fakeRows{rows: res.rows}, err
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// fakeMailer records the messages sent through it.
+type fakeMailer struct {
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/mail"
+	"main.go/internal/response"
+)
+
+// How long the token in a password reset email can be used
+const passwordResetTokenTTL = time.Hour
+
+// POST /api/password_reset/request
+// Emails a single-use reset token to the address, if it belongs to an
+// account. The response is the same either way so this can't be used to
+// find out who has an account, and each IP gets a small quota.
+func (cfg *apiConfig) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkRateLimit(w, cfg.resetRateLimiter, clientIP(r)) {
+		return
+	}
+
+	var req api.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Email == "" {
+		response.Error(w, http.StatusBadRequest, "Email is required", nil)
+		return
+	}
+
+	user, err := cfg.DB.GetUserByEmail(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusInternalServerError, "Failed to retrieve user", err)
+		return
+	}
+	// Accounts pending deletion have to be restored first
+	if err != nil || user.DeletedAt.Valid {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't create reset token", err)
+		return
+	}
+	_, err = cfg.DB.CreatePasswordResetToken(r.Context(), database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(passwordResetTokenTTL),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't save reset token", err)
+		return
+	}
+
+	err = cfg.mailer.Send(r.Context(), mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Chirpy account. "+
+			"If it was you, use this token within %.0f minutes to choose a new one:\n\n%s\n\n"+
+			"If it wasn't, you can ignore this email.\n", passwordResetTokenTTL.Minutes(), token),
+	})
+	if err != nil {
+		// Failing here would tell the caller the account exists
+		slog.ErrorContext(r.Context(), "Failed to send password reset email", "user_id", user.ID, "error", err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// POST /api/password_reset/confirm
+// Sets a new password with a token from a reset email. Tokens work once;
+// a successful reset also invalidates the account's other reset tokens
+// and logs it out everywhere.
+func (cfg *apiConfig) confirmPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+	if req.Token == "" || req.Password == "" {
+		response.Error(w, http.StatusBadRequest, "Token and password are required", nil)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to hash password", err)
+		return
+	}
+
+	var user database.User
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		resetToken, err := q.UsePasswordResetToken(r.Context(), auth.HashToken(req.Token))
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+			ID:             resetToken.UserID,
+			HashedPassword: hashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.DeletePasswordResetTokensForUser(r.Context(), user.ID); err != nil {
+			return err
+		}
+		return q.RevokeAllRefreshTokensForUser(r.Context(), user.ID)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusBadRequest, "Invalid or expired reset token", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to reset password", err)
+		}
+		return
+	}
+
+	cfg.events.Publish(r.Context(), events.New(events.UserUpdated, userEventData(user)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/response"
+)
+
+// GET /api/users/{userID}
+// Public profile of an active account. No authentication required. HEAD
+// requests only check that the account exists.
+func (cfg *apiConfig) getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	cfg.serveProfile(w, r, userID)
+}
+
+// serveProfile responds with the public profile of an active account.
+func (cfg *apiConfig) serveProfile(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err == nil && user.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		}
+		return
+	}
+
+	w.Header().Set("Last-Modified", user.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Type", response.ContentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	chirpCount, err := cfg.DB.CountChirpsByUser(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirps", err)
+		return
+	}
+
+	shareCount, err := cfg.DB.CountSharesOfUserChirps(r.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count shares", err)
+		return
+	}
+
+	profile := api.Profile{
+		ID:         user.ID,
+		Handle:     user.Email,
+		CreatedAt:  user.CreatedAt,
+		ChirpCount: chirpCount,
+		ShareCount: shareCount,
+	}
+	if user.Username.Valid {
+		profile.Handle = user.Username.String
+	}
+	profile.DisplayName, profile.Bio, profile.Website = profileText(user)
+	profile.WebsiteVerified = profile.Website != nil && user.WebsiteVerifiedAt.Valid
+	if user.AvatarURL.Valid {
+		profile.AvatarURL = &user.AvatarURL.String
+	}
+
+	response.JSON(w, http.StatusOK, profile)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"main.go/internal/response"
+)
+
+type readOnlyRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type readOnlyResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GET /admin/read_only
+func (cfg *apiConfig) adminGetReadOnlyHandler(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, readOnlyResponse{Enabled: cfg.readOnly.Load()})
+}
+
+// PUT /admin/read_only
+// Switches read-only mode on this instance until it restarts, when
+// READ_ONLY applies again.
+func (cfg *apiConfig) adminSetReadOnlyHandler(w http.ResponseWriter, r *http.Request) {
+	var req readOnlyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if cfg.readOnly.Swap(req.Enabled) != req.Enabled {
+		slog.InfoContext(r.Context(), "Switched read-only mode", "enabled", req.Enabled, "admin_id", requestUserID(r))
+	}
+	response.JSON(w, http.StatusOK, readOnlyResponse{Enabled: req.Enabled})
+}
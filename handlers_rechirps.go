@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+)
+
+// POST /api/chirps/{chirpID}/rechirp
+// Reposts a chirp so it shows up in listings of what the user posted.
+// Rechirping the same chirp again returns the existing rechirp.
+func (cfg *apiConfig) rechirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+		}
+		return
+	}
+
+	params := database.CreateRechirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}
+	status := http.StatusCreated
+	rechirp, err := cfg.DB.CreateRechirp(r.Context(), params)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already rechirped
+		status = http.StatusOK
+		rechirp, err = cfg.DB.GetRechirp(r.Context(), database.GetRechirpParams(params))
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to rechirp", err)
+		return
+	}
+
+	if status == http.StatusCreated {
+		cfg.notify(r.Context(), notify.Notification{
+			UserID:  chirp.UserID.UUID,
+			Event:   notify.Rechirp,
+			ActorID: userID,
+			ChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+			Text:    "Someone rechirped your chirp",
+		})
+	}
+
+	response.JSON(w, status, api.Rechirp{
+		ID:        rechirp.ID,
+		ChirpID:   rechirp.ChirpID,
+		UserID:    rechirp.UserID,
+		CreatedAt: rechirp.CreatedAt,
+	})
+}
+
+// DELETE /api/chirps/{chirpID}/rechirp
+func (cfg *apiConfig) undoRechirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	_, err = cfg.DB.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to undo rechirp", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+const (
+	defaultRepliesPageSize = 100
+	maxRepliesPageSize     = 500
+)
+
+// GET /api/chirps/{chirpID}/replies?limit=N&offset=N
+// Direct replies to a chirp, oldest first. Replies outlive their parent,
+// so this works for deleted chirps too and returns an empty list for IDs
+// nobody replied to.
+func (cfg *apiConfig) getRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, defaultRepliesPageSize, maxRepliesPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	parentID := uuid.NullUUID{UUID: chirpID, Valid: true}
+	replies, err := cfg.DB.GetReplies(r.Context(), database.GetRepliesParams{
+		ParentChirpID: parentID,
+		Limit:         int32(limit),
+		Offset:        int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch replies", err)
+		return
+	}
+
+	total, err := cfg.DB.CountReplies(r.Context(), parentID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count replies", err)
+		return
+	}
+
+	chirps := make([]api.Chirp, 0, len(replies))
+	for _, c := range replies {
+		chirps = append(chirps, newChirp(c))
+	}
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	response.JSON(w, http.StatusOK, chirps)
+}
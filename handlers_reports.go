@@ -0,0 +1,322 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/reports"
+	"main.go/internal/response"
+)
+
+const (
+	moderationSourceReports = "reports"
+
+	maxReportDetailsLength = 1000
+	reportStatsWindow      = 7 * 24 * time.Hour
+)
+
+var errReportOwnChirp = errors.New("reporting own chirp")
+
+// reportSignal is what a moderation queue entry from reports records.
+type reportSignal struct {
+	ChirpID uuid.UUID `json:"chirp_id"`
+	Reason  string    `json:"reason"`
+	Reports int64     `json:"reports"`
+}
+
+// reportStatsResponse is one reason's row of GET /admin/moderation/stats.
+// RecentReports covers the last week.
+type reportStatsResponse struct {
+	Reason        string `json:"reason"`
+	Reports       int64  `json:"reports"`
+	Chirps        int64  `json:"chirps"`
+	RecentReports int64  `json:"recent_reports"`
+	Hidden        int64  `json:"hidden"`
+	Escalated     int64  `json:"escalated"`
+	HideAfter     int    `json:"hide_after"`
+	EscalateAfter int    `json:"escalate_after"`
+}
+
+// reportResponse is a report in GET /admin/reports.
+type reportResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ChirpID    uuid.UUID  `json:"chirp_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	HidChirp   bool       `json:"hid_chirp"`
+	Escalated  bool       `json:"escalated"`
+	Status     string     `json:"status"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+}
+
+func newReportResponse(rep database.ChirpReport) reportResponse {
+	resp := reportResponse{
+		ID:         rep.ID,
+		CreatedAt:  rep.CreatedAt,
+		ChirpID:    rep.ChirpID,
+		ReporterID: rep.ReporterID,
+		Reason:     rep.Reason,
+		Details:    rep.Details,
+		HidChirp:   rep.HidChirp,
+		Escalated:  rep.Escalated,
+		Status:     rep.Status,
+	}
+	if rep.ResolvedAt.Valid {
+		resp.ResolvedAt = &rep.ResolvedAt.Time
+	}
+	if rep.ResolvedBy.Valid {
+		resp.ResolvedBy = &rep.ResolvedBy.UUID
+	}
+	return resp
+}
+
+// GET /api/report_reasons
+func (cfg *apiConfig) getReportReasonsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := make([]api.ReportReason, 0, len(reports.Reasons))
+	for _, reason := range reports.Reasons {
+		resp = append(resp, api.ReportReason{Code: reason.Code, Description: reason.Description})
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /api/chirps/{chirpID}/report
+// Reports a chirp, and applies the reason's policy once the report count
+// reaches one of its thresholds. Reporting a chirp again does nothing.
+func (cfg *apiConfig) reportChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	var req api.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if !reports.Known(req.Reason) {
+		response.Error(w, http.StatusBadRequest, "Unknown report reason", nil)
+		return
+	}
+	if len(req.Details) > maxReportDetailsLength {
+		response.Error(w, http.StatusBadRequest, "Details are too long", nil)
+		return
+	}
+
+	var (
+		chirp     database.Chirp
+		created   bool
+		hidden    *database.ModerationAction
+		escalated bool
+	)
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		if _, err := q.LockChirp(r.Context(), chirpID); err != nil {
+			return err
+		}
+		var err error
+		chirp, err = q.GetChirp(r.Context(), chirpID)
+		if err != nil {
+			return err
+		}
+		if chirp.UserID.UUID == userID {
+			return errReportOwnChirp
+		}
+
+		report, err := q.CreateChirpReport(r.Context(), database.CreateChirpReportParams{
+			ChirpID:    chirpID,
+			ReporterID: userID,
+			Reason:     req.Reason,
+			Details:    req.Details,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		created = true
+
+		count, err := q.CountChirpReports(r.Context(), database.CountChirpReportsParams{
+			ChirpID: chirpID,
+			Reason:  req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		hide, escalate := cfg.reportPolicies[req.Reason].Apply(int(count))
+		if hide && chirp.LegalHoldAt.Valid {
+			// A moderator has to look at a chirp that can't be hidden
+			hide, escalate = false, true
+		}
+		if !hide && !escalate {
+			return nil
+		}
+
+		if hide {
+			action, err := removeChirp(r.Context(), q, chirp, req.Reason)
+			if err != nil {
+				return err
+			}
+			hidden = &action
+		}
+		if escalate && chirp.UserID.Valid {
+			signals, err := json.Marshal([]reportSignal{{ChirpID: chirpID, Reason: req.Reason, Reports: count}})
+			if err != nil {
+				return err
+			}
+			if _, err := q.CreateModerationEntry(r.Context(), database.CreateModerationEntryParams{
+				UserID:  chirp.UserID.UUID,
+				Source:  moderationSourceReports,
+				Signals: signals,
+			}); err != nil {
+				return err
+			}
+			escalated = true
+		}
+		return q.SetChirpReportActions(r.Context(), database.SetChirpReportActionsParams{
+			ID:        report.ID,
+			HidChirp:  hidden != nil,
+			Escalated: escalated,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		case errors.Is(err, errReportOwnChirp):
+			response.Error(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "Failed to report chirp", err)
+		}
+		return
+	}
+
+	if !created {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if hidden != nil {
+		cfg.publishChirpRemoved(r.Context(), chirp, *hidden)
+	}
+	if hidden != nil || escalated {
+		slog.InfoContext(r.Context(), "Reports triggered moderation", "chirp_id", chirpID, "reason", req.Reason, "hidden", hidden != nil, "escalated", escalated)
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GET /admin/reports?status=open|resolved&limit=N&offset=N
+// Oldest reports first, so the queue is worked in order. Reports share
+// the moderation queue's statuses.
+func (cfg *apiConfig) adminListReportsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = moderationOpen
+	case moderationOpen, moderationResolved:
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.ListChirpReports(r.Context(), database.ListChirpReportsParams{
+		Status: status,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch reports", err)
+		return
+	}
+
+	resp := make([]reportResponse, 0, len(rows))
+	for _, rep := range rows {
+		resp = append(resp, newReportResponse(rep))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /admin/reports/{reportID}/resolve
+func (cfg *apiConfig) adminResolveReportHandler(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid report ID", err)
+		return
+	}
+
+	report, err := cfg.DB.ResolveChirpReport(r.Context(), database.ResolveChirpReportParams{
+		ID:         reportID,
+		ResolvedBy: uuid.NullUUID{UUID: requestUserID(r), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either there is no such report or it isn't open
+		_, err = cfg.DB.GetChirpReport(r.Context(), reportID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, "Report not found", nil)
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch report", err)
+		default:
+			response.Error(w, http.StatusConflict, "Only open reports can be resolved", nil)
+		}
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to resolve report", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newReportResponse(report))
+}
+
+// GET /admin/moderation/stats
+// Reports per reason, with the automated actions they triggered and the
+// policy in force. Every reason is listed, reported or not.
+func (cfg *apiConfig) adminReportStatsHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := cfg.DB.GetReportStats(r.Context(), time.Now().UTC().Add(-reportStatsWindow))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch report stats", err)
+		return
+	}
+	byReason := make(map[string]database.GetReportStatsRow, len(rows))
+	for _, row := range rows {
+		byReason[row.Reason] = row
+	}
+
+	resp := make([]reportStatsResponse, 0, len(reports.Reasons))
+	for _, reason := range reports.Reasons {
+		row := byReason[reason.Code]
+		policy := cfg.reportPolicies[reason.Code]
+		resp = append(resp, reportStatsResponse{
+			Reason:        reason.Code,
+			Reports:       row.Reports,
+			Chirps:        row.Chirps,
+			RecentReports: row.RecentReports,
+			Hidden:        row.Hidden,
+			Escalated:     row.Escalated,
+			HideAfter:     policy.HideAfter,
+			EscalateAfter: policy.EscalateAfter,
+		})
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+)
+
+func TestAdminResolveReportNotOpen(t *testing.T) {
+	reportID := uuid.New()
+	resolved := []driver.Value{
+		reportID.String(), time.Now(), uuid.NewString(), uuid.NewString(), "spam", "",
+		false, false, "resolved", time.Now(), uuid.NewString(),
+	}
+	tests := []struct {
+		name    string
+		report  [][]driver.Value
+		want    int
+		wantMsg string
+	}{
+		{name: "Unknown", want: http.StatusNotFound, wantMsg: "Report not found"},
+		{name: "Resolved", report: [][]driver.Value{resolved}, want: http.StatusConflict, wantMsg: "Only open reports can be resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+				"ResolveChirpReport": func(args []driver.Value) fakeResult {
+					return fakeResult{}
+				},
+				"GetChirpReport": func(args []driver.Value) fakeResult {
+					return fakeResult{rows: tt.report}
+				},
+			}}
+			cfg := &apiConfig{DB: database.New(sql.OpenDB(db))}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/reports/"+reportID.String()+"/resolve", nil)
+			req.SetPathValue("reportID", reportID.String())
+			rec := httptest.NewRecorder()
+			cfg.adminResolveReportHandler(rec, req)
+			if msg := errorMessage(t, rec); rec.Code != tt.want || msg != tt.wantMsg {
+				t.Errorf("resolve = %d %q, want %d %q", rec.Code, msg, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+type roleResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+func validRole(role string) bool {
+	return role == auth.RoleUser || role == auth.RoleAdmin
+}
+
+// PUT /admin/users/{userID}/role
+// The new role takes effect at the user's next login or refresh. Admins
+// can't demote themselves, so there is always one left.
+func (cfg *apiConfig) adminSetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var req roleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if !validRole(req.Role) {
+		response.Error(w, http.StatusBadRequest, "Unknown role, expected user or admin", nil)
+		return
+	}
+	if userID == requestUserID(r) && req.Role != auth.RoleAdmin {
+		response.Error(w, http.StatusConflict, "Admins can't remove their own role", nil)
+		return
+	}
+
+	user, err := cfg.DB.SetUserRole(r.Context(), database.SetUserRoleParams{
+		ID:   userID,
+		Role: req.Role,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to set role", err)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, roleResponse{UserID: user.ID, Role: user.Role})
+}
+
+// runSetRole implements `chirpy set-role <email> <role>`, which is how
+// the first admin is made. It returns the process exit code.
+func runSetRole(ctx context.Context, db *database.Queries, args []string) int {
+	if len(args) != 2 || !validRole(args[1]) {
+		fmt.Fprintln(os.Stderr, "usage: chirpy set-role <email> user|admin")
+		return 2
+	}
+	user, err := db.GetUserByEmail(ctx, args[0])
+	if err == nil {
+		user, err = db.SetUserRole(ctx, database.SetUserRoleParams{ID: user.ID, Role: args[1]})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("no user with email %s", args[0])
+		}
+		fmt.Fprintln(os.Stderr, "set-role:", err)
+		return 1
+	}
+	fmt.Printf("%s is now %s\n", user.Email, user.Role)
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+	maxSearchQueryLength  = 200
+)
+
+// GET /api/chirps/search?q=...&limit=N&offset=N
+// Full-text search over chirp bodies, best matches first. The query
+// supports web search syntax: "quoted phrases", OR and -exclusions.
+func (cfg *apiConfig) searchChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("q"))
+	if search == "" {
+		response.Error(w, http.StatusBadRequest, "Missing search query", nil)
+		return
+	}
+	if len(search) > maxSearchQueryLength {
+		response.Error(w, http.StatusBadRequest, "Search query is too long", nil)
+		return
+	}
+
+	limit, ok := parseLimit(w, r, defaultSearchPageSize, maxSearchPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := cfg.DB.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Search:     search,
+		MaxResults: int32(limit),
+		Skip:       int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to search chirps", err)
+		return
+	}
+
+	total, err := cfg.DB.CountSearchChirps(r.Context(), search)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count results", err)
+		return
+	}
+
+	chirps := make([]api.Chirp, 0, len(results))
+	for _, c := range results {
+		chirps = append(chirps, newChirp(database.Chirp{
+			ID:            c.ID,
+			CreatedAt:     c.CreatedAt,
+			UpdatedAt:     c.UpdatedAt,
+			Body:          c.Body,
+			UserID:        c.UserID,
+			ParentChirpID: c.ParentChirpID,
+		}))
+	}
+
+	if err := cfg.addChirpCounts(r.Context(), chirps); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to count chirp engagement", err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+	response.JSON(w, http.StatusOK, chirps)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/notify"
+	"main.go/internal/response"
+)
+
+func newSettings(user database.User, prefs notify.Preferences) api.Settings {
+	notifications := map[string]map[string]bool{}
+	for _, e := range notify.Events {
+		channels := map[string]bool{}
+		for _, c := range notify.Channels {
+			channels[string(c)] = prefs.Enabled(e, c)
+		}
+		notifications[string(e)] = channels
+	}
+	settings := api.Settings{
+		FeedRanking:   user.FeedRanking,
+		EmailDigest:   user.EmailDigest,
+		Notifications: notifications,
+	}
+	if q := prefs.QuietHours; q.Enabled() {
+		settings.QuietHours = &api.QuietHours{
+			Start:    formatClock(q.Start),
+			End:      formatClock(q.End),
+			Timezone: q.Location.String(),
+		}
+	}
+	return settings
+}
+
+// parseClock parses an "HH:MM" time of day into minutes since midnight.
+func parseClock(s string) (int32, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return int32(t.Hour()*60 + t.Minute()), true
+}
+
+// formatClock formats a time of day as "HH:MM".
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// GET /api/users/me/settings
+func (cfg *apiConfig) getSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+
+	prefs, err := cfg.notificationPreferences(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch notification preferences", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newSettings(user, prefs))
+}
+
+// PUT /api/users/me/settings
+// Only the fields present in the body are changed.
+func (cfg *apiConfig) updateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	var req api.SettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+
+	for event, channels := range req.Notifications {
+		if !notify.Event(event).Valid() {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("Unknown notification event %q", event), nil)
+			return
+		}
+		for channel := range channels {
+			if !notify.Channel(channel).Valid() {
+				response.Error(w, http.StatusBadRequest, fmt.Sprintf("Unknown notification channel %q", channel), nil)
+				return
+			}
+		}
+	}
+
+	var quiet database.SetQuietHoursParams
+	if q := req.QuietHours; q != nil && (q.Start != "" || q.End != "") {
+		start, okStart := parseClock(q.Start)
+		end, okEnd := parseClock(q.End)
+		if !okStart || !okEnd {
+			response.Error(w, http.StatusBadRequest, "quiet_hours start and end must be HH:MM", nil)
+			return
+		}
+		if start == end {
+			response.Error(w, http.StatusBadRequest, "quiet_hours start and end must differ", nil)
+			return
+		}
+		timezone := q.Timezone
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("Unknown time zone %q", timezone), nil)
+			return
+		}
+		quiet = database.SetQuietHoursParams{UserID: userID, StartMinute: start, EndMinute: end, Timezone: timezone}
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+
+	if req.FeedRanking != nil {
+		if *req.FeedRanking != feedRankingTop && *req.FeedRanking != feedRankingLatest {
+			response.Error(w, http.StatusBadRequest, "feed_ranking must be latest or top", nil)
+			return
+		}
+		user, err = cfg.DB.UpdateUserFeedRanking(r.Context(), database.UpdateUserFeedRankingParams{
+			ID:          userID,
+			FeedRanking: *req.FeedRanking,
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	if req.EmailDigest != nil {
+		user, err = cfg.DB.UpdateUserEmailDigest(r.Context(), database.UpdateUserEmailDigestParams{
+			ID:          userID,
+			EmailDigest: *req.EmailDigest,
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	if len(req.Notifications) > 0 {
+		err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+			for event, channels := range req.Notifications {
+				for channel, enabled := range channels {
+					err := q.SetNotificationPreference(r.Context(), database.SetNotificationPreferenceParams{
+						UserID:  userID,
+						Event:   event,
+						Channel: channel,
+						Enabled: enabled,
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	if req.QuietHours != nil {
+		if quiet.UserID != uuid.Nil {
+			err = cfg.DB.SetQuietHours(r.Context(), quiet)
+		} else {
+			err = cfg.DB.DeleteQuietHours(r.Context(), userID)
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Failed to update settings", err)
+			return
+		}
+	}
+
+	prefs, err := cfg.notificationPreferences(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch notification preferences", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newSettings(user, prefs))
+}
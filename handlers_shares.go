@@ -0,0 +1,73 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+// shareChannels are the places a chirp can be shared to, matching the
+// check constraint on chirp_shares.channel.
+var shareChannels = map[string]bool{
+	"link":     true,
+	"email":    true,
+	"sms":      true,
+	"x":        true,
+	"facebook": true,
+	"whatsapp": true,
+	"other":    true,
+}
+
+// POST /api/chirps/{chirpID}/share
+// Records that the user shared a chirp outside Chirpy. Sharing again
+// over the same channel is not an error, but isn't counted twice.
+func (cfg *apiConfig) shareChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	var req api.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+	if !shareChannels[req.Channel] {
+		response.Error(w, http.StatusBadRequest, "Unknown share channel", nil)
+		return
+	}
+
+	if _, err := cfg.DB.GetChirp(r.Context(), chirpID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to retrieve chirp", err)
+		}
+		return
+	}
+
+	n, err := cfg.DB.RecordShare(r.Context(), database.RecordShareParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+		Channel: req.Channel,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to record share", err)
+		return
+	}
+
+	if n == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+)
+
+const (
+	// A hashtag must be used by at least this many different users before
+	// it shows up in public stats, so nobody can be singled out by a tag.
+	statsMinHashtagAuthors = 3
+	statsMaxHashtags       = 10
+	statsActiveWindow      = 7 * 24 * time.Hour
+)
+
+// GET /api/stats
+// Public, aggregate-only numbers suitable for a status page.
+func (cfg *apiConfig) publicStatsHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	since := now.Add(-statsActiveWindow)
+
+	totalChirps, err := cfg.DB.CountChirps(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't count chirps", err)
+		return
+	}
+
+	activeUsers, err := cfg.DB.CountActiveUsersSince(r.Context(), since)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't count active users", err)
+		return
+	}
+
+	tags, err := cfg.DB.GetTopHashtagsSince(r.Context(), database.GetTopHashtagsSinceParams{
+		Since:      since,
+		MinAuthors: statsMinHashtagAuthors,
+		MaxTags:    statsMaxHashtags,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't fetch hashtags", err)
+		return
+	}
+
+	topHashtags := make([]api.Hashtag, 0, len(tags))
+	for _, t := range tags {
+		topHashtags = append(topHashtags, api.Hashtag{Tag: t.Tag, Count: t.Uses})
+	}
+
+	response.JSON(w, http.StatusOK, api.Stats{
+		TotalChirps:     totalChirps,
+		ActiveUsersWeek: activeUsers,
+		TopHashtags:     topHashtags,
+		GeneratedAt:     now,
+	}, response.CacheControl("public, max-age=60"))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"main.go/internal/health"
+	"main.go/internal/response"
+)
+
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) },
+}).Parse(`
+<html>
+  <head><title>Chirpy Status</title></head>
+  <body>
+    <h1>Chirpy is {{.Overall}}</h1>
+    <table>
+      <tr><th>Component</th><th>Status</th><th>Uptime (24h)</th><th>Last hours</th></tr>
+      {{range .Components}}
+      <tr>
+        <td>{{.Name}}</td>
+        <td>{{if .Current}}{{.Current.Status}}{{else}}unknown{{end}}</td>
+        <td>{{percent .Uptime}}</td>
+        <td>{{range .History}}{{if .Failures}}&#x1F7E5;{{else}}&#x1F7E9;{{end}}{{end}}</td>
+      </tr>
+      {{end}}
+    </table>
+  </body>
+</html>
+`))
+
+type statusResponse struct {
+	Overall    string                   `json:"status"`
+	Components []health.ComponentStatus `json:"components"`
+}
+
+// GET /status
+// Renders component health as HTML, or as JSON for ?format=json and
+// clients that accept application/json.
+func (cfg *apiConfig) statusHandler(w http.ResponseWriter, r *http.Request) {
+	components := cfg.health.Snapshot()
+	overall := "operational"
+	for _, c := range components {
+		if c.Current == nil || c.Current.Status != health.StatusUp {
+			overall = "degraded"
+			break
+		}
+	}
+	resp := statusResponse{Overall: overall, Components: components}
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		response.JSON(w, http.StatusOK, resp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := statusPageTemplate.Execute(w, resp); err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering status page", "error", err)
+	}
+}
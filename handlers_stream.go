@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"main.go/internal/broadcast"
+	"main.go/internal/response"
+)
+
+const (
+	// streamHeartbeat is how often an idle stream gets a comment, so
+	// proxies and load balancers don't close it.
+	streamHeartbeat = 30 * time.Second
+	// streamRetry is how long EventSource clients wait before
+	// reconnecting, in milliseconds.
+	streamRetry = 5000
+)
+
+// GET /api/chirps/stream
+// Server-sent events: each chirp posted after the stream opens is sent as
+// a "chirp.created" event whose data is the versioned event envelope, as
+// on the event bus (see package events), and whose ID is the event's.
+// Clients that fall more than STREAM_BUFFER chirps behind are
+// disconnected; EventSource reconnects on its own, and GET /api/chirps
+// fills in what was missed.
+func (cfg *apiConfig) streamChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	sub, err := cfg.chirpStream.Subscribe(cfg.streamBuffer)
+	if err != nil {
+		w.Header().Set("Retry-After", "30")
+		if errors.Is(err, broadcast.ErrFull) {
+			response.Error(w, http.StatusServiceUnavailable, "Too many clients are streaming chirps", nil)
+		} else {
+			response.Error(w, http.StatusServiceUnavailable, "Server is shutting down", nil)
+		}
+		return
+	}
+	defer sub.Close()
+
+	cfg.prom.streams.Inc(r.Pattern)
+	defer cfg.prom.streams.Dec(r.Pattern)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	// Keeps nginx from buffering events
+	w.Header().Set("X-Accel-Buffering", "no")
+	rc := http.NewResponseController(w)
+	// Streams stay open far longer than WRITE_TIMEOUT allows a response
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "Couldn't lift the write deadline for a stream", "error", err)
+	}
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-sub.C():
+			if !ok {
+				if errors.Is(sub.Err(), broadcast.ErrLagged) {
+					slog.InfoContext(r.Context(), "Dropped a chirp stream client that fell behind", "buffer", cfg.streamBuffer)
+				}
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Couldn't encode a streamed event", "event_id", event.ID, "event_type", event.Type, "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
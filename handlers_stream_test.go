@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"main.go/internal/broadcast"
+	"main.go/internal/events"
+)
+
+func TestStreamChirps(t *testing.T) {
+	cfg := &apiConfig{
+		prom:         newServerMetrics(),
+		chirpStream:  broadcast.New[events.Event](1),
+		streamBuffer: 4,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(cfg.streamChirpsHandler))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	// The stream is subscribed once the header is out
+	second, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("second GET error = %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status over STREAM_MAX_CLIENTS = %d, want 503", second.StatusCode)
+	}
+
+	event := events.New(events.ChirpCreated, events.ChirpData{ID: uuid.New(), Body: "hello"})
+	cfg.chirpStream.Publish(event)
+
+	lines := bufio.NewReader(resp.Body)
+	var fields []string
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "retry:") || line == "" && len(fields) == 0 {
+			continue
+		}
+		if line == "" {
+			break
+		}
+		fields = append(fields, line)
+	}
+	if len(fields) != 3 || fields[0] != "id: "+event.ID.String() || fields[1] != "event: chirp.created" {
+		t.Fatalf("event = %q, want a chirp.created event", fields)
+	}
+	var got struct {
+		Type    events.Type `json:"type"`
+		Version int         `json:"version"`
+		Data    struct {
+			Body string `json:"body"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(fields[2], "data: ")), &got); err != nil {
+		t.Fatalf("decoding the event data: %v", err)
+	}
+	if got.Type != events.ChirpCreated || got.Version != 1 || got.Data.Body != "hello" {
+		t.Errorf("event data = %+v, want the chirp.created envelope", got)
+	}
+
+	cfg.chirpStream.Close()
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("stream didn't end cleanly when the hub closed: %v", err)
+	}
+}
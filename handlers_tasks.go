@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"main.go/internal/response"
+	"main.go/internal/scheduler"
+)
+
+// GET /admin/tasks
+func (cfg *apiConfig) adminListTasksHandler(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, cfg.scheduler.Status())
+}
+
+// POST /admin/tasks/{taskName}/run
+func (cfg *apiConfig) adminRunTaskHandler(w http.ResponseWriter, r *http.Request) {
+	err := cfg.scheduler.Trigger(r.PathValue("taskName"))
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrTaskNotFound):
+			response.Error(w, http.StatusNotFound, "Task not found", nil)
+		case errors.Is(err, scheduler.ErrTaskRunning):
+			response.Error(w, http.StatusConflict, "Task is already running", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "Couldn't start task", err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{"message": "Task started"})
+}
+
+// GET /admin/heartbeats
+func (cfg *apiConfig) adminHeartbeatsHandler(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, cfg.deadman.Status())
+}
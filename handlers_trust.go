@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/database"
+	"main.go/internal/response"
+	"main.go/internal/trust"
+)
+
+// Refusals of capabilities a user's trust level doesn't have yet.
+var trustRefusals = map[trust.Capability]string{
+	trust.PostLinks:   "Your account is too new to post links",
+	trust.UploadMedia: "Your account is too new to upload media",
+}
+
+func newTrustActivity(createdAt time.Time, chirps, likesReceived, followers, strikes int64) trust.Activity {
+	return trust.Activity{
+		AccountAge:    time.Since(createdAt),
+		Chirps:        int(chirps),
+		LikesReceived: int(likesReceived),
+		Followers:     int(followers),
+		Strikes:       int(strikes),
+	}
+}
+
+// evaluateTrust works out a user's trust level from their activity now,
+// storing it if it changed from current.
+func (cfg *apiConfig) evaluateTrust(ctx context.Context, userID uuid.UUID, current trust.Level) (trust.Level, error) {
+	a, err := cfg.DB.GetTrustActivity(ctx, userID)
+	if err != nil {
+		return current, err
+	}
+	level := cfg.trustRules.Evaluate(newTrustActivity(a.CreatedAt, a.Chirps, a.LikesReceived, a.Followers, a.Strikes))
+	if level == current {
+		return level, nil
+	}
+	err = cfg.DB.SetUserTrustLevel(ctx, database.SetUserTrustLevelParams{
+		ID:         userID,
+		TrustLevel: string(level),
+	})
+	return level, err
+}
+
+// requireTrust reports whether userID has capability c, responding with
+// a 403 if not. A user refused by their stored level is evaluated again
+// first, so they needn't wait for the nightly task once they qualify.
+func (cfg *apiConfig) requireTrust(w http.ResponseWriter, r *http.Request, userID uuid.UUID, c trust.Capability) bool {
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return false
+	}
+	level := trust.Level(user.TrustLevel)
+	if level.Can(c) {
+		return true
+	}
+	level, err = cfg.evaluateTrust(r.Context(), userID, level)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to evaluate trust level", err)
+		return false
+	}
+	if !level.Can(c) {
+		response.Error(w, http.StatusForbidden, trustRefusals[c], nil)
+		return false
+	}
+	return true
+}
+
+// GET /api/users/me/trust
+// Evaluates the user's trust level now, rather than returning the one
+// the nightly task stored.
+func (cfg *apiConfig) getMyTrustHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+	level, err := cfg.evaluateTrust(r.Context(), userID, trust.Level(user.TrustLevel))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to evaluate trust level", err)
+		return
+	}
+
+	resp := api.TrustLevel{Level: string(level), Capabilities: []string{}}
+	for _, c := range level.Capabilities() {
+		resp.Capabilities = append(resp.Capabilities, string(c))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// evaluateTrustLevelsTask evaluates the trust level of every user, so
+// levels rise with age and fall with strikes without the users doing
+// anything.
+func (cfg *apiConfig) evaluateTrustLevelsTask(ctx context.Context) error {
+	rows, err := cfg.DB.ListTrustActivity(ctx)
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for _, row := range rows {
+		level := cfg.trustRules.Evaluate(newTrustActivity(row.CreatedAt, row.Chirps, row.LikesReceived, row.Followers, row.Strikes))
+		if string(level) == row.TrustLevel {
+			continue
+		}
+		if err := cfg.DB.SetUserTrustLevel(ctx, database.SetUserTrustLevelParams{
+			ID:         row.ID,
+			TrustLevel: string(level),
+		}); err != nil {
+			return err
+		}
+		changed++
+	}
+	if changed > 0 {
+		slog.Info("Updated trust levels", "count", changed)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/database"
+	"main.go/internal/response"
+	"main.go/internal/totp"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets.
+const recoveryCodeCount = 10
+
+// POST /api/users/me/2fa/enable
+// Starts enrolling a new TOTP secret, replacing one that was never
+// confirmed. Two-factor authentication isn't required until the secret
+// is confirmed.
+func (cfg *apiConfig) enableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't create secret", err)
+		return
+	}
+	n, err := cfg.DB.StartTwoFactorEnrollment(r.Context(), database.StartTwoFactorEnrollmentParams{
+		UserID: userID,
+		Secret: secret,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't save secret", err)
+		return
+	}
+	if n == 0 {
+		response.Error(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, api.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: totp.URL("Chirpy", user.Email, secret),
+	})
+}
+
+// POST /api/users/me/2fa/confirm
+// Enables two-factor authentication with a first code from the app, and
+// returns the recovery codes.
+func (cfg *apiConfig) confirmTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	var req api.TwoFactorConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid JSON body", err)
+		return
+	}
+
+	tf, err := cfg.DB.GetTwoFactor(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusConflict, "Start with POST /api/users/me/2fa/enable", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch two-factor settings", err)
+		}
+		return
+	}
+	if tf.EnabledAt.Valid {
+		response.Error(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	step, ok := totp.Validate(tf.Secret, req.Code, time.Now())
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "Invalid two-factor code", nil)
+		return
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i] = newRecoveryCode()
+	}
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		n, err := q.EnableTwoFactor(r.Context(), database.EnableTwoFactorParams{Step: step, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errTwoFactorEnabled
+		}
+		if err := q.DeleteRecoveryCodes(r.Context(), userID); err != nil {
+			return err
+		}
+		for _, code := range codes {
+			err := q.CreateRecoveryCode(r.Context(), database.CreateRecoveryCodeParams{
+				UserID:   userID,
+				CodeHash: auth.HashToken(normalizeRecoveryCode(code)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errTwoFactorEnabled) {
+		response.Error(w, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't enable two-factor authentication", err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, api.RecoveryCodes{RecoveryCodes: codes})
+}
+
+// errTwoFactorEnabled rolls back a confirmation that lost a race with
+// another one.
+var errTwoFactorEnabled = errors.New("two-factor authentication already enabled")
+
+// secondFactor is the code a login proved its second factor with, to
+// be marked used by useSecondFactor. It is the zero value for users
+// without two-factor authentication.
+type secondFactor struct {
+	userID       uuid.UUID
+	step         int64  // of a code from the user's app
+	recoveryHash string // hash of a recovery code
+}
+
+// checkSecondFactor makes a user with two-factor authentication enabled
+// prove it at login, with a code from their app that hasn't been used
+// yet or an unused recovery code. It responds and returns false if they
+// don't. The code isn't marked used, so that a login held up by a later
+// check can be retried with it: call useSecondFactor once the login is
+// allowed.
+func (cfg *apiConfig) checkSecondFactor(w http.ResponseWriter, r *http.Request, userID uuid.UUID, creds api.Credentials) (secondFactor, bool) {
+	tf, err := cfg.DB.GetTwoFactor(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !tf.EnabledAt.Valid) {
+		return secondFactor{}, true
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch two-factor settings", err)
+		return secondFactor{}, false
+	}
+
+	f := secondFactor{userID: userID}
+	ok := false
+	switch {
+	case creds.TOTPCode != "":
+		f.step, ok = totp.Validate(tf.Secret, creds.TOTPCode, time.Now())
+		ok = ok && f.step > tf.LastUsedStep
+	case creds.RecoveryCode != "":
+		f.recoveryHash = auth.HashToken(normalizeRecoveryCode(creds.RecoveryCode))
+		ok, err = cfg.DB.HasRecoveryCode(r.Context(), database.HasRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: f.recoveryHash,
+		})
+	default:
+		response.Error(w, http.StatusUnauthorized, "Two-factor code required", nil)
+		return secondFactor{}, false
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't check two-factor code", err)
+		return secondFactor{}, false
+	}
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Invalid two-factor code", nil)
+		return secondFactor{}, false
+	}
+	return f, true
+}
+
+// useSecondFactor marks the code checkSecondFactor accepted as used.
+// Each code is accepted once, so one seen over a shoulder or intercepted
+// can't be replayed; a login that raced another with the same code is
+// refused. It responds and returns false if the login can't go ahead.
+func (cfg *apiConfig) useSecondFactor(w http.ResponseWriter, r *http.Request, f secondFactor) bool {
+	var n int64
+	var err error
+	switch {
+	case f.step != 0:
+		n, err = cfg.DB.UseTwoFactorStep(r.Context(), database.UseTwoFactorStepParams{Step: f.step, UserID: f.userID})
+	case f.recoveryHash != "":
+		n, err = cfg.DB.UseRecoveryCode(r.Context(), database.UseRecoveryCodeParams{UserID: f.userID, CodeHash: f.recoveryHash})
+	default:
+		return true
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Couldn't check two-factor code", err)
+		return false
+	}
+	if n == 0 {
+		response.Error(w, http.StatusUnauthorized, "Invalid two-factor code", nil)
+		return false
+	}
+	return true
+}
+
+// newRecoveryCode returns a random code such as "k7qmz-2xw9d", with 50
+// bits of entropy.
+func newRecoveryCode() string {
+	text := strings.ToLower(rand.Text())
+	return text[:5] + "-" + text[5:10]
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the
+// dash, in upper case or with spaces.
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
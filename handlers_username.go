@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/response"
+	"main.go/internal/tags"
+)
+
+// errUsernameChangedRecently aborts a username change made sooner than
+// usernameCooldown after the last one.
+var errUsernameChangedRecently = errors.New("username changed recently")
+
+// nextUsernameChange returns when the user can change their username
+// from old to username, or the zero time if they can now. Keeping the
+// same username, or picking a first one, is always allowed. It runs in
+// the transaction that updates the user, with their row locked, so
+// concurrent changes can't both see the same last one.
+func (cfg *apiConfig) nextUsernameChange(ctx context.Context, q *database.Queries, userID uuid.UUID, old, username sql.NullString) (time.Time, error) {
+	if !username.Valid || !old.Valid || old == username || cfg.usernameCooldown == 0 {
+		return time.Time{}, nil
+	}
+	last, err := q.LastUsernameChange(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if next := last.Add(cfg.usernameCooldown); time.Now().Before(next) {
+		return next, nil
+	}
+	return time.Time{}, nil
+}
+
+// recordUsernameChange records, in the transaction that updated the
+// user, that their username went from old to username. The old username
+// keeps leading to the user, and can't be taken by anyone else, for
+// usernameGracePeriod. A redirect of the new username to the user,
+// left from an earlier change, is dropped now that they have it back.
+func (cfg *apiConfig) recordUsernameChange(ctx context.Context, q *database.Queries, userID uuid.UUID, old sql.NullString, username string) error {
+	if old.Valid && old.String == username {
+		return nil
+	}
+	if err := q.CreateUsernameChange(ctx, database.CreateUsernameChangeParams{
+		UserID:      userID,
+		OldUsername: old,
+		NewUsername: username,
+	}); err != nil {
+		return err
+	}
+	if err := q.DeleteUsernameRedirect(ctx, database.DeleteUsernameRedirectParams{
+		Username: username,
+		UserID:   userID,
+	}); err != nil {
+		return err
+	}
+	if !old.Valid || cfg.usernameGracePeriod == 0 {
+		return nil
+	}
+	return q.CreateUsernameRedirect(ctx, database.CreateUsernameRedirectParams{
+		Username:  old.String,
+		UserID:    userID,
+		ExpiresAt: sql.NullTime{Time: time.Now().UTC().Add(cfg.usernameGracePeriod), Valid: true},
+	})
+}
+
+// GET /api/usernames/{username}
+// Public profile of the account with a username. A username the account
+// has since changed, or one of an account merged into it, redirects with
+// 302 Found to the account's current username, or to its profile by ID
+// if it has none. The redirect is temporary: old usernames are released
+// once their redirect expires.
+func (cfg *apiConfig) getProfileByUsernameHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := tags.NormalizeUsername(r.PathValue("username"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid username: "+err.Error(), nil)
+		return
+	}
+
+	resolved, err := cfg.DB.ResolveUsername(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to look up username", err)
+		}
+		return
+	}
+	if !resolved.Redirected {
+		cfg.serveProfile(w, r, resolved.UserID)
+		return
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), resolved.UserID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch user", err)
+		return
+	}
+	// The API prefix, with the request's version
+	prefix := strings.TrimSuffix(r.URL.Path, "/usernames/"+r.PathValue("username"))
+	location := prefix + "/users/" + user.ID.String()
+	if user.Username.Valid {
+		location = prefix + "/usernames/" + url.PathEscape(user.Username.String)
+	}
+	w.Header().Set("Location", location)
+	w.WriteHeader(http.StatusFound)
+}
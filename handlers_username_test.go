@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+)
+
+func TestUsernameChangeCooldown(t *testing.T) {
+	userID := uuid.New()
+	row := userRow(userID, "walt@example.com")
+	row[8] = "walt"
+	db := &fakeDB{t: t, queries: map[string]func(args []driver.Value) fakeResult{
+		"UsernameReserved": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{false}}}
+		},
+		// The cooldown is checked with the user's row locked
+		"GetUserByIDForUpdate": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{row}}
+		},
+		"LastUsernameChange": func(args []driver.Value) fakeResult {
+			return fakeResult{rows: [][]driver.Value{{time.Now().Add(-time.Hour)}}}
+		},
+	}}
+	sqlDB := sql.OpenDB(db)
+	cfg := &apiConfig{
+		DB:               database.New(sqlDB),
+		sqlDB:            sqlDB,
+		prom:             newServerMetrics(),
+		usernameCooldown: 24 * time.Hour,
+	}
+
+	body := `{"email": "walt@example.com", "password": "hunter2", "username": "heisenberg"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/users", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	cfg.updateUserHandler(rec, req.WithContext(context.WithValue(req.Context(), userIDKey{}, userID)))
+	if msg := errorMessage(t, rec); rec.Code != http.StatusTooManyRequests || !strings.HasPrefix(msg, "Username was changed recently") {
+		t.Fatalf("update = %d %q, want 429", rec.Code, msg)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "82800" {
+		t.Errorf("Retry-After = %q, want 82800", got)
+	}
+}
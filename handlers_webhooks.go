@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/events"
+	"main.go/internal/response"
+)
+
+// webhookDeliveryRetention is how long delivery attempts are kept, and
+// so how long an integrator has to ask for a redelivery.
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// webhookDeliveryLog is the webhook publisher's events.DeliveryLog,
+// backed by webhook_deliveries.
+type webhookDeliveryLog struct {
+	db *database.Queries
+}
+
+// RecordDelivery -
+func (l webhookDeliveryLog) RecordDelivery(ctx context.Context, d events.WebhookDelivery) error {
+	return l.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		EventID:         d.EventID,
+		EventType:       string(d.EventType),
+		Payload:         d.Payload,
+		StatusCode:      sql.NullInt32{Int32: int32(d.StatusCode), Valid: d.StatusCode != 0},
+		ResponseSnippet: d.ResponseSnippet,
+		Error:           d.Error,
+		Succeeded:       d.Succeeded,
+	})
+}
+
+type webhookDeliveryResponse struct {
+	EventID         uuid.UUID `json:"event_id"`
+	EventType       string    `json:"event_type"`
+	AttemptedAt     time.Time `json:"attempted_at"`
+	Succeeded       bool      `json:"succeeded"`
+	StatusCode      *int      `json:"status_code"`
+	ResponseSnippet string    `json:"response_snippet"`
+	Error           string    `json:"error,omitempty"`
+}
+
+func newWebhookDeliveryResponse(d database.WebhookDelivery) webhookDeliveryResponse {
+	resp := webhookDeliveryResponse{
+		EventID:         d.EventID,
+		EventType:       d.EventType,
+		AttemptedAt:     d.CreatedAt,
+		Succeeded:       d.Succeeded,
+		ResponseSnippet: d.ResponseSnippet,
+		Error:           d.Error,
+	}
+	if d.StatusCode.Valid {
+		code := int(d.StatusCode.Int32)
+		resp.StatusCode = &code
+	}
+	return resp
+}
+
+// requireWebhooks writes a 404 and returns false unless events are
+// delivered by webhook.
+func (cfg *apiConfig) requireWebhooks(w http.ResponseWriter) bool {
+	if cfg.webhooks == nil {
+		response.Error(w, http.StatusNotFound, "Events aren't delivered by webhook", nil)
+		return false
+	}
+	return true
+}
+
+// GET /admin/webhook_deliveries/failed?limit=N&offset=N
+// Events whose latest delivery attempt failed, newest first, with that
+// attempt.
+func (cfg *apiConfig) adminListFailedWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireWebhooks(w) {
+		return
+	}
+	limit, ok := parseLimit(w, r, 100, 1000)
+	if !ok {
+		return
+	}
+	offset, ok := parseOffset(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.ListFailedWebhookDeliveries(r.Context(), database.ListFailedWebhookDeliveriesParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch webhook deliveries", err)
+		return
+	}
+
+	resp := make([]webhookDeliveryResponse, 0, len(rows))
+	for _, d := range rows {
+		resp = append(resp, newWebhookDeliveryResponse(d))
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// POST /admin/webhook_deliveries/{eventID}/redeliver
+// Sends an event to the webhook again, with the payload and event ID of
+// its first attempt, and responds with the new attempt. Succeeded events
+// can be redelivered too.
+func (cfg *apiConfig) adminRedeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireWebhooks(w) {
+		return
+	}
+	eventID, err := uuid.Parse(r.PathValue("eventID"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	last, err := cfg.DB.GetLatestWebhookDelivery(r.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, "Event not found", nil)
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to fetch webhook delivery", err)
+		}
+		return
+	}
+
+	d, err := cfg.webhooks.Deliver(r.Context(), last.EventID, events.Type(last.EventType), last.Payload)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to record webhook delivery", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Redelivered webhook", "event_id", eventID, "succeeded", d.Succeeded, "admin_id", requestUserID(r))
+
+	resp := webhookDeliveryResponse{
+		EventID:         d.EventID,
+		EventType:       string(d.EventType),
+		AttemptedAt:     time.Now().UTC(),
+		Succeeded:       d.Succeeded,
+		ResponseSnippet: d.ResponseSnippet,
+		Error:           d.Error,
+	}
+	if d.StatusCode != 0 {
+		resp.StatusCode = &d.StatusCode
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// cleanupWebhookDeliveriesTask removes delivery attempts older than
+// webhookDeliveryRetention.
+func (cfg *apiConfig) cleanupWebhookDeliveriesTask(ctx context.Context) error {
+	n, err := cfg.DB.DeleteOldWebhookDeliveries(ctx, time.Now().UTC().Add(-webhookDeliveryRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("Deleted old webhook deliveries", "count", n)
+	}
+	return nil
+}
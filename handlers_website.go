@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/relme"
+)
+
+const verifyWebsiteJob = "verify_website"
+
+type verifyWebsitePayload struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Website string    `json:"website"`
+}
+
+// profileURLs are the links to a user's profile that their website may
+// link back to, versioned or not.
+func (cfg *apiConfig) profileURLs(userID uuid.UUID) []string {
+	return []string{
+		cfg.publicURL + "/api/v1/users/" + userID.String(),
+		cfg.publicURL + "/api/users/" + userID.String(),
+	}
+}
+
+// queueWebsiteVerification queues a check of a user's unverified website,
+// if verification is enabled. Failing to queue it isn't worth failing
+// the request over.
+func (cfg *apiConfig) queueWebsiteVerification(ctx context.Context, user database.User) {
+	if cfg.publicURL == "" || !user.Website.Valid || user.WebsiteVerifiedAt.Valid {
+		return
+	}
+	payload := verifyWebsitePayload{UserID: user.ID, Website: user.Website.String}
+	if _, err := cfg.jobs.Enqueue(ctx, verifyWebsiteJob, payload); err != nil {
+		slog.Warn("Failed to queue website verification", "user_id", user.ID, "error", err)
+	}
+}
+
+// verifyWebsite is the handler of verify_website jobs. It marks the
+// website verified if the page links to the user's profile with
+// rel="me", and it is still the user's website. Sites that can't be
+// reached fail the job, so it is tried again.
+func (cfg *apiConfig) verifyWebsite(ctx context.Context, payload json.RawMessage) error {
+	var p verifyWebsitePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	verified, err := relme.Verify(ctx, cfg.websiteClient, p.Website, cfg.profileURLs(p.UserID))
+	if err != nil {
+		return err
+	}
+	if !verified {
+		slog.Info("Website doesn't link back to the profile", "user_id", p.UserID, "website", p.Website)
+		return nil
+	}
+	_, err = cfg.DB.VerifyUserWebsite(ctx, database.VerifyUserWebsiteParams{
+		ID:      p.UserID,
+		Website: sql.NullString{String: p.Website, Valid: true},
+	})
+	return err
+}
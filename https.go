@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net"
+	"net/http"
+)
+
+// redirectHTTPS sends plain HTTP requests to the same URL over HTTPS on
+// httpsPort. GET and HEAD get a 301; other methods a 308, which clients
+// repeat with the same method and body.
+func redirectHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
@@ -0,0 +1,181 @@
+// Package anomaly flags accounts whose activity is implausible for a
+// person, such as posting or following faster than anyone could by hand.
+// It keeps a short in-memory history per user, so each instance only sees
+// the traffic it serves.
+package anomaly
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of activity the default rules watch.
+const (
+	KindChirp   = "chirp"
+	KindFollow  = "follow"
+	KindLoginIP = "login_ip"
+)
+
+// Rule flags a user who records more than Threshold events of a kind
+// within Window. With Distinct set only distinct values count, e.g. the
+// IPs a user logs in from.
+type Rule struct {
+	Kind      string
+	Window    time.Duration
+	Threshold int
+	Distinct  bool
+}
+
+// DefaultRules catch superhuman posting, mass following and logins from
+// many places at once.
+var DefaultRules = []Rule{
+	{Kind: KindChirp, Window: time.Minute, Threshold: 10},
+	{Kind: KindFollow, Window: time.Hour, Threshold: 100},
+	{Kind: KindLoginIP, Window: time.Hour, Threshold: 5, Distinct: true},
+}
+
+// Signal describes a rule a user tripped.
+type Signal struct {
+	Kind      string    `json:"kind"`
+	Count     int       `json:"count"`
+	Threshold int       `json:"threshold"`
+	Window    string    `json:"window"`
+	Values    []string  `json:"values,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+type key struct {
+	user uuid.UUID
+	kind string
+}
+
+type event struct {
+	at    time.Time
+	value string
+}
+
+// Detector applies rules to the activity recorded with it.
+type Detector struct {
+	rules     map[string]Rule
+	maxWindow time.Duration
+
+	mu        sync.Mutex
+	events    map[key][]event
+	flagged   map[key]time.Time // when each user last tripped each rule
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewDetector creates a Detector applying rules, one per kind.
+func NewDetector(rules []Rule) *Detector {
+	d := &Detector{
+		rules:   map[string]Rule{},
+		events:  map[key][]event{},
+		flagged: map[key]time.Time{},
+		now:     time.Now,
+	}
+	for _, r := range rules {
+		d.rules[r.Kind] = r
+		d.maxWindow = max(d.maxWindow, r.Window)
+	}
+	return d
+}
+
+// Record notes an event of a kind for a user and returns a signal if it
+// took them over the rule's threshold. A user trips each rule at most
+// once per window, however long the burst lasts.
+func (d *Detector) Record(userID uuid.UUID, kind, value string) (Signal, bool) {
+	rule, ok := d.rules[kind]
+	if !ok {
+		return Signal{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.sweep(now)
+
+	k := key{user: userID, kind: kind}
+	events := append(recent(d.events[k], now.Add(-rule.Window)), event{at: now, value: value})
+	d.events[k] = events
+
+	count := len(events)
+	var values []string
+	if rule.Distinct {
+		values = distinctValues(events)
+		count = len(values)
+	}
+	if count <= rule.Threshold {
+		return Signal{}, false
+	}
+	if last, ok := d.flagged[k]; ok && now.Sub(last) < rule.Window {
+		return Signal{}, false
+	}
+	d.flagged[k] = now
+
+	return Signal{
+		Kind:      kind,
+		Count:     count,
+		Threshold: rule.Threshold,
+		Window:    rule.Window.String(),
+		Values:    values,
+		At:        now,
+	}, true
+}
+
+// Forget drops everything recorded about a user.
+func (d *Detector) Forget(userID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k := range d.events {
+		if k.user == userID {
+			delete(d.events, k)
+		}
+	}
+	for k := range d.flagged {
+		if k.user == userID {
+			delete(d.flagged, k)
+		}
+	}
+}
+
+// recent drops the events before cutoff. events is in time order.
+func recent(events []event, cutoff time.Time) []event {
+	i := sort.Search(len(events), func(i int) bool { return events[i].at.After(cutoff) })
+	return events[i:]
+}
+
+func distinctValues(events []event) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, e := range events {
+		if !seen[e.value] {
+			seen[e.value] = true
+			values = append(values, e.value)
+		}
+	}
+	return values
+}
+
+// sweep forgets users with no recent activity, at most once per longest
+// window. d.mu must be held.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.maxWindow {
+		return
+	}
+	for k, events := range d.events {
+		if len(recent(events, now.Add(-d.rules[k.kind].Window))) == 0 {
+			delete(d.events, k)
+		}
+	}
+	for k, at := range d.flagged {
+		if now.Sub(at) >= d.rules[k.kind].Window {
+			delete(d.flagged, k)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,118 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordFlagsOncePerWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDetector([]Rule{{Kind: KindChirp, Window: time.Minute, Threshold: 3}})
+	d.now = func() time.Time { return now }
+	user := uuid.New()
+
+	var flagged int
+	for i := 0; i < 10; i++ {
+		if sig, ok := d.Record(user, KindChirp, ""); ok {
+			flagged++
+			if sig.Count != 4 || sig.Threshold != 3 {
+				t.Errorf("signal = %+v, want count 4 over threshold 3", sig)
+			}
+		}
+		now = now.Add(time.Second)
+	}
+	if flagged != 1 {
+		t.Errorf("flagged %d times in one burst, want 1", flagged)
+	}
+
+	// Another burst after the window flags again
+	now = now.Add(time.Minute)
+	flagged = 0
+	for i := 0; i < 5; i++ {
+		if _, ok := d.Record(user, KindChirp, ""); ok {
+			flagged++
+		}
+	}
+	if flagged != 1 {
+		t.Errorf("flagged %d times in second burst, want 1", flagged)
+	}
+}
+
+func TestRecordSlowActivityIsNotFlagged(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDetector([]Rule{{Kind: KindChirp, Window: time.Minute, Threshold: 3}})
+	d.now = func() time.Time { return now }
+	user := uuid.New()
+
+	for i := 0; i < 20; i++ {
+		if sig, ok := d.Record(user, KindChirp, ""); ok {
+			t.Fatalf("flagged at event %d: %+v", i, sig)
+		}
+		now = now.Add(30 * time.Second)
+	}
+}
+
+func TestRecordDistinct(t *testing.T) {
+	d := NewDetector([]Rule{{Kind: KindLoginIP, Window: time.Hour, Threshold: 2, Distinct: true}})
+	user := uuid.New()
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.1", "10.0.0.2", "10.0.0.2"} {
+		if _, ok := d.Record(user, KindLoginIP, ip); ok {
+			t.Fatalf("flagged after repeat logins from %s", ip)
+		}
+	}
+	sig, ok := d.Record(user, KindLoginIP, "10.0.0.3")
+	if !ok {
+		t.Fatal("not flagged after a third distinct IP")
+	}
+	if len(sig.Values) != 3 || sig.Count != 3 {
+		t.Errorf("signal = %+v, want the 3 IPs", sig)
+	}
+}
+
+func TestRecordUnknownKindAndOtherUsers(t *testing.T) {
+	d := NewDetector([]Rule{{Kind: KindFollow, Window: time.Hour, Threshold: 1}})
+	a, b := uuid.New(), uuid.New()
+
+	if _, ok := d.Record(a, "unknown", ""); ok {
+		t.Error("flagged an unknown kind")
+	}
+	d.Record(a, KindFollow, "")
+	if _, ok := d.Record(b, KindFollow, ""); ok {
+		t.Error("one user's activity counted against another")
+	}
+}
+
+func TestSweepForgetsIdleUsers(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDetector([]Rule{{Kind: KindChirp, Window: time.Minute, Threshold: 1}})
+	d.now = func() time.Time { return now }
+
+	d.Record(uuid.New(), KindChirp, "")
+	d.Record(uuid.New(), KindChirp, "")
+	now = now.Add(2 * time.Minute)
+	d.Record(uuid.New(), KindChirp, "")
+
+	if len(d.events) != 1 {
+		t.Errorf("tracking %d users after sweep, want 1", len(d.events))
+	}
+}
+
+func TestForget(t *testing.T) {
+	d := NewDetector([]Rule{{Kind: KindLoginIP, Window: time.Hour, Threshold: 1, Distinct: true}})
+	a, b := uuid.New(), uuid.New()
+
+	d.Record(a, KindLoginIP, "192.0.2.1")
+	d.Record(a, KindLoginIP, "192.0.2.2")
+	d.Record(b, KindLoginIP, "192.0.2.3")
+	d.Forget(a)
+
+	if len(d.events) != 1 || len(d.flagged) != 0 {
+		t.Errorf("after Forget: %d event keys, %d flags, want 1 and 0", len(d.events), len(d.flagged))
+	}
+	if _, ok := d.Record(a, KindLoginIP, "192.0.2.4"); ok {
+		t.Error("forgotten activity still counted")
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -21,9 +22,40 @@ const (
 	TokenTypeAccess TokenType = "chirpy-access"
 )
 
+// Roles of users, carried in their access tokens.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// AccessToken is what a valid access token says about its bearer, as of
+// when it was issued.
+type AccessToken struct {
+	UserID     uuid.UUID
+	Role       string
+	TrustLevel string
+}
+
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Role       string `json:"role,omitempty"`
+	TrustLevel string `json:"trust_level,omitempty"`
+}
+
 // ErrNoAuthHeaderIncluded -
 var ErrNoAuthHeaderIncluded = errors.New("no auth header included in request")
 
+// ErrIncorrectCredentials is returned by CheckCredentials both for an
+// unknown account and for a wrong password.
+var ErrIncorrectCredentials = errors.New("incorrect email or password")
+
+// dummyHash stands in for the password hash of accounts that don't
+// exist, so CheckCredentials costs one bcrypt comparison either way.
+var dummyHash, _ = HashPassword("chirpy-dummy-password")
+
+// compareHash is replaced in tests to count comparisons.
+var compareHash = bcrypt.CompareHashAndPassword
+
 // HashPassword -
 func HashPassword(password string) (string, error) {
 	dat, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -35,55 +67,140 @@ func HashPassword(password string) (string, error) {
 
 // CheckPasswordHash -
 func CheckPasswordHash(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return compareHash([]byte(hash), []byte(password))
 }
 
-// MakeJWT -
+// CheckCredentials verifies a login attempt. found reports whether an
+// account with the given email exists, and hash is its password hash.
+// Missing accounts are checked against a dummy hash so the response time
+// and error don't reveal which emails are registered.
+func CheckCredentials(password, hash string, found bool) error {
+	if !found {
+		hash = dummyHash
+	}
+	if err := CheckPasswordHash(password, hash); err != nil || !found {
+		return ErrIncorrectCredentials
+	}
+	return nil
+}
+
+// Key is a secret access tokens are signed with. ID goes in the kid
+// header of the tokens it signs, and is empty for tokens made before
+// keys had IDs.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring holds the keys access tokens are valid with. The first key
+// signs new tokens; the others only validate tokens they signed, so the
+// secret can be rotated without logging everyone out.
+type Keyring []Key
+
+// ErrUnknownKey is returned by ValidateJWT for a token whose kid isn't in
+// the keyring, e.g. because its key was retired.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// ParseKeyring reads a keyring such as "2024-06:secret,2024-01:old", the
+// signing key first.
+func ParseKeyring(s string) (Keyring, error) {
+	var keys Keyring
+	for _, part := range strings.Split(s, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || id == "" || secret == "" {
+			return nil, errors.New("expected id:secret pairs")
+		}
+		if _, dup := keys.secret(id); dup {
+			return nil, fmt.Errorf("duplicate key ID %q", id)
+		}
+		keys = append(keys, Key{ID: id, Secret: []byte(secret)})
+	}
+	return keys, nil
+}
+
+func (k Keyring) secret(id string) ([]byte, bool) {
+	for _, key := range k {
+		if key.ID == id {
+			return key.Secret, true
+		}
+	}
+	return nil, false
+}
+
+// MakeJWT signs an access token with the first key of keys.
 func MakeJWT(
-	userID uuid.UUID,
-	tokenSecret string,
+	claims AccessToken,
+	keys Keyring,
 	expiresIn time.Duration,
 ) (string, error) {
-	signingKey := []byte(tokenSecret)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    string(TokenTypeAccess),
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
-		Subject:   userID.String(),
+	if len(keys) == 0 {
+		return "", errors.New("no signing key")
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    string(TokenTypeAccess),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
+			Subject:   claims.UserID.String(),
+		},
+		Role:       claims.Role,
+		TrustLevel: claims.TrustLevel,
 	})
-	return token.SignedString(signingKey)
+	if keys[0].ID != "" {
+		token.Header["kid"] = keys[0].ID
+	}
+	return token.SignedString(keys[0].Secret)
+}
+
+// ValidateJWT checks an access token against the key of keys its kid
+// names, and returns its user.
+func ValidateJWT(tokenString string, keys Keyring) (uuid.UUID, error) {
+	token, err := ParseAccessToken(tokenString, keys)
+	return token.UserID, err
 }
 
-// ValidateJWT -
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	claimsStruct := jwt.RegisteredClaims{}
+// ParseAccessToken is ValidateJWT, also returning the role of the user.
+// Tokens from before roles were added are for RoleUser.
+func ParseAccessToken(tokenString string, keys Keyring) (AccessToken, error) {
+	claimsStruct := accessClaims{}
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&claimsStruct,
-		func(token *jwt.Token) (interface{}, error) { return []byte(tokenSecret), nil },
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			secret, ok := keys.secret(kid)
+			if !ok {
+				return nil, ErrUnknownKey
+			}
+			return secret, nil
+		},
 	)
 	if err != nil {
-		return uuid.Nil, err
+		return AccessToken{}, err
 	}
 
 	userIDString, err := token.Claims.GetSubject()
 	if err != nil {
-		return uuid.Nil, err
+		return AccessToken{}, err
 	}
 
 	issuer, err := token.Claims.GetIssuer()
 	if err != nil {
-		return uuid.Nil, err
+		return AccessToken{}, err
 	}
 	if issuer != string(TokenTypeAccess) {
-		return uuid.Nil, errors.New("invalid issuer")
+		return AccessToken{}, errors.New("invalid issuer")
 	}
 
 	id, err := uuid.Parse(userIDString)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID: %w", err)
+		return AccessToken{}, fmt.Errorf("invalid user ID: %w", err)
 	}
-	return id, nil
+	role := claimsStruct.Role
+	if role == "" {
+		role = RoleUser
+	}
+	return AccessToken{UserID: id, Role: role, TrustLevel: claimsStruct.TrustLevel}, nil
 }
 
 // GetBearerToken -
@@ -100,6 +217,20 @@ func GetBearerToken(headers http.Header) (string, error) {
 	return splitAuth[1], nil
 }
 
+// GetAPIKey returns the key from an "Authorization: ApiKey <key>" header,
+// which webhook callers use instead of a bearer token.
+func GetAPIKey(headers http.Header) (string, error) {
+	authHeader := headers.Get("Authorization")
+	if authHeader == "" {
+		return "", ErrNoAuthHeaderIncluded
+	}
+	key, ok := strings.CutPrefix(authHeader, "ApiKey ")
+	if !ok || key == "" {
+		return "", errors.New("malformed authorization header")
+	}
+	return key, nil
+}
+
 // MakeRefreshToken makes a random 256 bit token
 // encoded in hex
 func MakeRefreshToken() (string, error) {
@@ -110,3 +241,10 @@ func MakeRefreshToken() (string, error) {
 	}
 	return hex.EncodeToString(token), nil
 }
+
+// HashToken returns the hex SHA-256 hash of a random token, for storing
+// tokens that only need to be looked up, never read back.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCheckPasswordHash(t *testing.T) {
@@ -63,35 +66,80 @@ func TestCheckPasswordHash(t *testing.T) {
 	}
 }
 
+// Logging in with an unknown email must be indistinguishable from using
+// a wrong password: same error, and the same bcrypt work.
+func TestCheckCredentialsDoesNotRevealAccounts(t *testing.T) {
+	hash, _ := HashPassword("correctPassword123!")
+
+	comparisons := 0
+	compareHash = func(hashed, password []byte) error {
+		comparisons++
+		return bcrypt.CompareHashAndPassword(hashed, password)
+	}
+	defer func() { compareHash = bcrypt.CompareHashAndPassword }()
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		found    bool
+		wantErr  error
+	}{
+		{name: "Correct password", password: "correctPassword123!", hash: hash, found: true, wantErr: nil},
+		{name: "Wrong password", password: "wrongPassword", hash: hash, found: true, wantErr: ErrIncorrectCredentials},
+		{name: "Unknown account", password: "wrongPassword", found: false, wantErr: ErrIncorrectCredentials},
+		{name: "Unknown account with the dummy password", password: "chirpy-dummy-password", found: false, wantErr: ErrIncorrectCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparisons = 0
+			err := CheckCredentials(tt.password, tt.hash, tt.found)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("CheckCredentials() error = %v, want %v", err, tt.wantErr)
+			}
+			if comparisons != 1 {
+				t.Errorf("CheckCredentials() did %d hash comparisons, want 1", comparisons)
+			}
+		})
+	}
+
+	cost, err := bcrypt.Cost([]byte(dummyHash))
+	if err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d (%v), want %d to match real hashes", cost, err, bcrypt.DefaultCost)
+	}
+}
+
 func TestValidateJWT(t *testing.T) {
 	userID := uuid.New()
-	validToken, _ := MakeJWT(userID, "secret", time.Hour)
+	secret := Keyring{{Secret: []byte("secret")}}
+	validToken, _ := MakeJWT(AccessToken{UserID: userID}, secret, time.Hour)
 
 	tests := []struct {
 		name        string
 		tokenString string
-		tokenSecret string
+		keys        Keyring
 		wantUserID  uuid.UUID
 		wantErr     bool
 	}{
 		{
 			name:        "Valid token",
 			tokenString: validToken,
-			tokenSecret: "secret",
+			keys:        secret,
 			wantUserID:  userID,
 			wantErr:     false,
 		},
 		{
 			name:        "Invalid token",
 			tokenString: "invalid.token.string",
-			tokenSecret: "secret",
+			keys:        secret,
 			wantUserID:  uuid.Nil,
 			wantErr:     true,
 		},
 		{
 			name:        "Wrong secret",
 			tokenString: validToken,
-			tokenSecret: "wrong_secret",
+			keys:        Keyring{{Secret: []byte("wrong_secret")}},
 			wantUserID:  uuid.Nil,
 			wantErr:     true,
 		},
@@ -99,7 +147,7 @@ func TestValidateJWT(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotUserID, err := ValidateJWT(tt.tokenString, tt.tokenSecret)
+			gotUserID, err := ValidateJWT(tt.tokenString, tt.keys)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateJWT() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -111,6 +159,38 @@ func TestValidateJWT(t *testing.T) {
 	}
 }
 
+func TestKeyRotation(t *testing.T) {
+	userID := uuid.New()
+	old := Keyring{{ID: "2024-01", Secret: []byte("old")}}
+	rotated, err := ParseKeyring("2024-06:new, 2024-01:old")
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+
+	oldToken, _ := MakeJWT(AccessToken{UserID: userID}, old, time.Hour)
+	if got, err := ValidateJWT(oldToken, rotated); err != nil || got != userID {
+		t.Errorf("token of the previous key: ValidateJWT() = %v, %v, want %v", got, err, userID)
+	}
+
+	newToken, _ := MakeJWT(AccessToken{UserID: userID, Role: RoleAdmin, TrustLevel: "basic"}, rotated, time.Hour)
+	token, _, _ := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	if kid := token.Header["kid"]; kid != "2024-06" {
+		t.Errorf("kid = %v, want the first key's ID", kid)
+	}
+	if got, err := ParseAccessToken(newToken, rotated); err != nil || got.Role != RoleAdmin || got.TrustLevel != "basic" {
+		t.Errorf("ParseAccessToken() = %+v, %v, want role %s and trust level basic", got, err, RoleAdmin)
+	}
+	if _, err := ValidateJWT(newToken, old); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("token of a key missing from the keyring: ValidateJWT() error = %v, want ErrUnknownKey", err)
+	}
+
+	for _, s := range []string{"secret", "a:x,:y", "a:x,a:y", "a:"} {
+		if _, err := ParseKeyring(s); err == nil {
+			t.Errorf("ParseKeyring(%q) error = nil", s)
+		}
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -155,3 +235,68 @@ func TestGetBearerToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers http.Header
+		wantKey string
+		wantErr bool
+	}{
+		{
+			name:    "Valid API key",
+			headers: http.Header{"Authorization": []string{"ApiKey f271c81ff7084ee5b99a5091b42d486e"}},
+			wantKey: "f271c81ff7084ee5b99a5091b42d486e",
+		},
+		{
+			name:    "Missing Authorization header",
+			headers: http.Header{},
+			wantErr: true,
+		},
+		{
+			name:    "Bearer token",
+			headers: http.Header{"Authorization": []string{"Bearer f271c81ff7084ee5b99a5091b42d486e"}},
+			wantErr: true,
+		},
+		{
+			name:    "Empty key",
+			headers: http.Header{"Authorization": []string{"ApiKey "}},
+			wantErr: true,
+		},
+		{
+			name:    "Scheme without key",
+			headers: http.Header{"Authorization": []string{"ApiKey"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, err := GetAPIKey(tt.headers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotKey != tt.wantKey {
+				t.Errorf("GetAPIKey() gotKey = %v, want %v", gotKey, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatalf("MakeRefreshToken() error = %v", err)
+	}
+	hash := HashToken(token)
+	if hash == token || len(hash) != 64 {
+		t.Errorf("HashToken(%q) = %q, want a 64 character digest", token, hash)
+	}
+	if again := HashToken(token); again != hash {
+		t.Errorf("HashToken is not deterministic: %q then %q", hash, again)
+	}
+	if other := HashToken(token + "x"); other == hash {
+		t.Errorf("HashToken gave the same hash for different tokens")
+	}
+}
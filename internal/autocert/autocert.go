@@ -0,0 +1,377 @@
+// Package autocert obtains and renews TLS certificates from an ACME
+// certificate authority such as Let's Encrypt.
+//
+// It answers HTTP-01 challenges, so the plain HTTP listener on port 80
+// must route requests through Manager.HTTPHandler. Certificates and the
+// account key are kept in a cache directory so that restarts don't
+// request new ones; Let's Encrypt rate limits issuance per domain.
+//
+// golang.org/x/crypto/acme/autocert does the same, but needs
+// golang.org/x/net for its host policy.
+package autocert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// DefaultRenewBefore is how long before expiry certificates are renewed.
+// Let's Encrypt certificates last 90 days.
+const DefaultRenewBefore = 30 * 24 * time.Hour
+
+// Manager serves certificates for a fixed list of host names, obtaining
+// each one the first time a client asks for it.
+type Manager struct {
+	hosts        map[string]bool
+	cacheDir     string
+	email        string
+	directoryURL string
+	renewBefore  time.Duration
+
+	mu     sync.Mutex
+	certs  map[string]*tls.Certificate
+	tokens map[string]string // HTTP-01 challenge path -> key authorization
+
+	// issueMu serializes issuance, which is rare and slow anyway
+	issueMu sync.Mutex
+	client  *acme.Client
+}
+
+// NewManager returns a Manager for hosts that caches in cacheDir. email
+// is the optional contact address of the ACME account. directoryURL
+// defaults to the Let's Encrypt production directory.
+func NewManager(hosts []string, cacheDir, email, directoryURL string) *Manager {
+	m := &Manager{
+		hosts:        make(map[string]bool, len(hosts)),
+		cacheDir:     cacheDir,
+		email:        email,
+		directoryURL: directoryURL,
+		renewBefore:  DefaultRenewBefore,
+		certs:        make(map[string]*tls.Certificate),
+		tokens:       make(map[string]string),
+	}
+	for _, h := range hosts {
+		m.hosts[normalizeHost(h)] = true
+	}
+	if m.directoryURL == "" {
+		m.directoryURL = acme.LetsEncryptURL
+	}
+	return m
+}
+
+// GetCertificate implements tls.Config.GetCertificate. The first
+// handshake for a host waits while its certificate is issued.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := normalizeHost(hello.ServerName)
+	if host == "" {
+		return nil, errors.New("autocert: missing server name")
+	}
+	if !m.hosts[host] {
+		return nil, fmt.Errorf("autocert: host %q not configured", host)
+	}
+
+	if cert := m.cached(host); cert != nil {
+		return cert, nil
+	}
+
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+	// Another handshake may have got it while we waited
+	if cert := m.cached(host); cert != nil {
+		return cert, nil
+	}
+	return m.obtain(hello.Context(), host)
+}
+
+// HTTPHandler answers ACME challenges and passes every other request to
+// fallback.
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/.well-known/acme-challenge/") {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		m.mu.Lock()
+		resp, ok := m.tokens[r.URL.Path]
+		m.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(resp))
+	})
+}
+
+// Run renews certificates that are due, checking every interval, until
+// ctx is cancelled. Certificates that have never been requested are left
+// to GetCertificate.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		var due []string
+		for host, cert := range m.certs {
+			if m.dueForRenewal(cert) {
+				due = append(due, host)
+			}
+		}
+		m.mu.Unlock()
+
+		for _, host := range due {
+			m.issueMu.Lock()
+			if _, err := m.obtain(ctx, host); err != nil {
+				slog.Error("Failed to renew certificate", "host", host, "error", err)
+			}
+			m.issueMu.Unlock()
+		}
+	}
+}
+
+// cached returns host's certificate from memory or the cache directory,
+// or nil if there is none that is still good.
+func (m *Manager) cached(host string) *tls.Certificate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cert, ok := m.certs[host]; ok && time.Now().Before(cert.Leaf.NotAfter) {
+		return cert
+	}
+
+	data, err := os.ReadFile(m.certPath(host))
+	if err != nil {
+		return nil
+	}
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		slog.Warn("Ignoring unreadable cached certificate", "host", host, "error", err)
+		return nil
+	}
+	// Go 1.23 and later set Leaf
+	if cert.Leaf.VerifyHostname(host) != nil || !time.Now().Before(cert.Leaf.NotAfter) {
+		return nil
+	}
+	m.certs[host] = &cert
+	return &cert
+}
+
+func (m *Manager) dueForRenewal(cert *tls.Certificate) bool {
+	return time.Until(cert.Leaf.NotAfter) < m.renewBefore
+}
+
+// obtain issues a certificate for host and caches it. The caller holds
+// issueMu.
+func (m *Manager) obtain(ctx context.Context, host string) (*tls.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(host))
+	if err != nil {
+		return nil, fmt.Errorf("autocert: ordering certificate for %s: %w", host, err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, authzURL); err != nil {
+			return nil, fmt.Errorf("autocert: authorizing %s: %w", host, err)
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("autocert: waiting for order of %s: %w", host, err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: host},
+		DNSNames: []string{host},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("autocert: finalizing certificate for %s: %w", host, err)
+	}
+
+	data, err := encodeCertificate(der, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return nil, fmt.Errorf("autocert: CA returned an unusable certificate for %s: %w", host, err)
+	}
+	if err := writeFile(m.certPath(host), data); err != nil {
+		// The certificate still works until the next restart
+		slog.Error("Failed to cache certificate", "host", host, "error", err)
+	}
+
+	m.mu.Lock()
+	m.certs[host] = &cert
+	m.mu.Unlock()
+	slog.Info("Obtained certificate", "host", host, "expires", cert.Leaf.NotAfter)
+	return &cert, nil
+}
+
+// authorize proves control of the domain of one authorization with an
+// HTTP-01 challenge.
+func (m *Manager) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return errors.New("CA offered no http-01 challenge")
+	}
+
+	resp, err := client.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return err
+	}
+	path := client.HTTP01ChallengePath(chal.Token)
+	m.mu.Lock()
+	m.tokens[path] = resp
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.tokens, path)
+		m.mu.Unlock()
+	}()
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return err
+	}
+	_, err = client.WaitAuthorization(ctx, authz.URI)
+	return err
+}
+
+// acmeClient returns the client of the ACME account, registering it
+// the first time. The account key is kept in the cache directory.
+func (m *Manager) acmeClient(ctx context.Context) (*acme.Client, error) {
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	key, err := m.accountKey()
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{Key: key, DirectoryURL: m.directoryURL}
+	account := &acme.Account{}
+	if m.email != "" {
+		account.Contact = []string{"mailto:" + m.email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("autocert: registering account: %w", err)
+	}
+	m.client = client
+	return client, nil
+}
+
+// accountKey loads the account key from the cache directory, generating
+// it if there is none.
+func (m *Manager) accountKey() (crypto.Signer, error) {
+	path := filepath.Join(m.cacheDir, "account.key")
+	if data, err := os.ReadFile(path); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("autocert: %s is not a PEM key", path)
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})); err != nil {
+		return nil, fmt.Errorf("autocert: saving account key: %w", err)
+	}
+	return key, nil
+}
+
+func (m *Manager) certPath(host string) string {
+	return filepath.Join(m.cacheDir, host+".pem")
+}
+
+// encodeCertificate puts the private key and the certificate chain in
+// one PEM file.
+func encodeCertificate(chain [][]byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	pem.Encode(&buf, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	for _, c := range chain {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: c})
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFile writes data readable only by the server, replacing the file
+// atomically.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// normalizeHost lowercases a host name and drops a trailing dot.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,108 @@
+package autocert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// selfSigned returns a cache file for host valid until notAfter.
+func selfSigned(t *testing.T, host string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := encodeCertificate([][]byte{der}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGetCertificateFromCache(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager([]string{"Chirpy.Example"}, dir, "", "")
+	if err := writeFile(filepath.Join(dir, "chirpy.example.pem"), selfSigned(t, "chirpy.example", time.Now().Add(60*24*time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "chirpy.example."})
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "chirpy.example" {
+		t.Errorf("got certificate for %q", cert.Leaf.Subject.CommonName)
+	}
+	if m.dueForRenewal(cert) {
+		t.Error("certificate with 60 days left is due for renewal")
+	}
+}
+
+func TestCachedIgnoresExpiredCertificates(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager([]string{"chirpy.example"}, dir, "", "")
+	if err := writeFile(m.certPath("chirpy.example"), selfSigned(t, "chirpy.example", time.Now().Add(-time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	if cert := m.cached("chirpy.example"); cert != nil {
+		t.Error("cached() returned an expired certificate")
+	}
+}
+
+func TestGetCertificateRejectsUnknownHosts(t *testing.T) {
+	m := NewManager([]string{"chirpy.example"}, t.TempDir(), "", "")
+	for _, name := range []string{"", "evil.example"} {
+		if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: name}); err == nil {
+			t.Errorf("GetCertificate(%q) error = nil", name)
+		}
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	m := NewManager([]string{"chirpy.example"}, t.TempDir(), "", "")
+	m.tokens["/.well-known/acme-challenge/abc"] = "abc.thumbprint"
+	h := m.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/.well-known/acme-challenge/abc", http.StatusOK, "abc.thumbprint"},
+		{"/.well-known/acme-challenge/other", http.StatusNotFound, ""},
+		{"/api/healthz", http.StatusTeapot, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, rec.Body.String(), tt.body)
+		}
+	}
+}
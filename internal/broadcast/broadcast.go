@@ -0,0 +1,129 @@
+// Package broadcast fans values out to subscribers in the same process,
+// such as the clients of a server-sent event stream. Each subscriber has
+// its own buffer, so a slow one never holds up publishing or the others.
+package broadcast
+
+import (
+	"errors"
+	"sync"
+)
+
+// Errors returned by Subscribe and by Subscription.Err.
+var (
+	// ErrFull is returned by Subscribe when the hub has as many
+	// subscribers as it allows.
+	ErrFull = errors.New("too many subscribers")
+	// ErrClosed means the hub was closed, e.g. because the server is
+	// shutting down.
+	ErrClosed = errors.New("hub closed")
+	// ErrLagged means the subscriber fell a whole buffer behind and was
+	// dropped rather than silently miss values.
+	ErrLagged = errors.New("subscriber fell behind")
+)
+
+// Hub publishes values of type T to its subscribers. The zero value is
+// not usable; call New.
+type Hub[T any] struct {
+	maxSubscribers int
+
+	mu     sync.Mutex
+	subs   map[*Subscription[T]]struct{}
+	closed bool
+}
+
+// New returns a hub that allows up to maxSubscribers at a time, or any
+// number if it is 0.
+func New[T any](maxSubscribers int) *Hub[T] {
+	return &Hub[T]{
+		maxSubscribers: maxSubscribers,
+		subs:           make(map[*Subscription[T]]struct{}),
+	}
+}
+
+// Subscription receives the values published after it was made, until it
+// is closed or dropped by the hub.
+type Subscription[T any] struct {
+	hub *Hub[T]
+	c   chan T
+	// err is why the hub ended the subscription. It is set, under the
+	// hub's lock, before c is closed.
+	err error
+}
+
+// Subscribe adds a subscriber that can be up to buffer values behind.
+func (h *Hub[T]) Subscribe(buffer int) (*Subscription[T], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	if h.maxSubscribers > 0 && len(h.subs) >= h.maxSubscribers {
+		return nil, ErrFull
+	}
+	s := &Subscription[T]{hub: h, c: make(chan T, buffer)}
+	h.subs[s] = struct{}{}
+	return s, nil
+}
+
+// Publish hands v to every subscriber without waiting. Subscribers whose
+// buffer is full are dropped with ErrLagged.
+func (h *Hub[T]) Publish(v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		select {
+		case s.c <- v:
+		default:
+			h.drop(s, ErrLagged)
+		}
+	}
+}
+
+// Len returns the number of subscribers.
+func (h *Hub[T]) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close drops every subscriber with ErrClosed and refuses new ones.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subs {
+		h.drop(s, ErrClosed)
+	}
+}
+
+// drop ends s with err. h.mu must be held.
+func (h *Hub[T]) drop(s *Subscription[T], err error) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.err = err
+	close(s.c)
+}
+
+// C returns the channel values arrive on. It is closed when the
+// subscription ends; Err says why.
+func (s *Subscription[T]) C() <-chan T {
+	return s.c
+}
+
+// Err returns ErrLagged or ErrClosed once the hub has ended the
+// subscription, and nil before then or if it was ended with Close.
+func (s *Subscription[T]) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Close ends the subscription. It is safe to call more than once, and
+// after the hub has dropped it.
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s, nil)
+}
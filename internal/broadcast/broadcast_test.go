@@ -0,0 +1,86 @@
+package broadcast
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	h := New[int](0)
+	a, err := h.Subscribe(2)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	b, err := h.Subscribe(2)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	h.Publish(1)
+	h.Publish(2)
+	for name, s := range map[string]*Subscription[int]{"a": a, "b": b} {
+		for _, want := range []int{1, 2} {
+			if got := <-s.C(); got != want {
+				t.Errorf("%s received %d, want %d", name, got, want)
+			}
+		}
+	}
+
+	b.Close()
+	b.Close()
+	if _, ok := <-b.C(); ok {
+		t.Error("closed subscription still receives")
+	}
+	if b.Err() != nil {
+		t.Errorf("Err() after Close = %v, want nil", b.Err())
+	}
+	if h.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", h.Len())
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h := New[int](0)
+	slow, _ := h.Subscribe(1)
+	fast, _ := h.Subscribe(1)
+
+	h.Publish(1)
+	<-fast.C()
+	h.Publish(2)
+
+	if got := <-slow.C(); got != 1 {
+		t.Errorf("slow received %d, want 1", got)
+	}
+	if _, ok := <-slow.C(); ok {
+		t.Error("slow subscriber wasn't dropped")
+	}
+	if !errors.Is(slow.Err(), ErrLagged) {
+		t.Errorf("Err() = %v, want ErrLagged", slow.Err())
+	}
+	if got := <-fast.C(); got != 2 {
+		t.Errorf("fast received %d, want 2", got)
+	}
+}
+
+func TestLimitsAndClose(t *testing.T) {
+	h := New[int](1)
+	s, err := h.Subscribe(1)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, err := h.Subscribe(1); !errors.Is(err, ErrFull) {
+		t.Errorf("Subscribe() over the limit error = %v, want ErrFull", err)
+	}
+
+	h.Close()
+	if _, ok := <-s.C(); ok {
+		t.Error("subscription wasn't ended by Close")
+	}
+	if !errors.Is(s.Err(), ErrClosed) {
+		t.Errorf("Err() = %v, want ErrClosed", s.Err())
+	}
+	if _, err := h.Subscribe(1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe() after Close error = %v, want ErrClosed", err)
+	}
+	s.Close()
+}
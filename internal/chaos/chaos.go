@@ -0,0 +1,91 @@
+// Package chaos injects latency and errors into requests, so clients'
+// retries and timeouts can be tested against a misbehaving server.
+package chaos
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Fault is what happens to requests to one route.
+type Fault struct {
+	// Latency delays every request.
+	Latency time.Duration
+	// ErrorRate is the fraction of requests, from 0 to 1, that fail
+	// with Status instead of reaching the handler.
+	ErrorRate float64
+	// Status is the status of failed requests; 503 when zero.
+	Status int
+}
+
+// Validate reports whether f can be injected.
+func (f Fault) Validate() error {
+	switch {
+	case f.Latency < 0:
+		return errors.New("latency can't be negative")
+	case f.ErrorRate < 0 || f.ErrorRate > 1:
+		return errors.New("error rate must be between 0 and 1")
+	case f.Status != 0 && (f.Status < 400 || f.Status > 599):
+		return errors.New("status must be a 4xx or 5xx code")
+	}
+	return nil
+}
+
+// Injector holds the faults of each route. It is safe for concurrent use.
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[string]Fault
+	rand   func() float64
+}
+
+// New creates an Injector with no faults.
+func New() *Injector {
+	return &Injector{faults: map[string]Fault{}, rand: rand.Float64}
+}
+
+// Set replaces every fault with faults, keyed by route. It changes
+// nothing if one of them is invalid.
+func (in *Injector) Set(faults map[string]Fault) error {
+	for _, f := range faults {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	copied := make(map[string]Fault, len(faults))
+	for route, f := range faults {
+		copied[route] = f
+	}
+	in.mu.Lock()
+	in.faults = copied
+	in.mu.Unlock()
+	return nil
+}
+
+// Faults returns the faults being injected, keyed by route.
+func (in *Injector) Faults() map[string]Fault {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	faults := make(map[string]Fault, len(in.faults))
+	for route, f := range in.faults {
+		faults[route] = f
+	}
+	return faults
+}
+
+// Inject decides the fate of a request to route: how long to delay it,
+// and the status to fail it with, or 0 to let it through.
+func (in *Injector) Inject(route string) (time.Duration, int) {
+	in.mu.RLock()
+	f, ok := in.faults[route]
+	in.mu.RUnlock()
+	if !ok || f.ErrorRate == 0 || in.rand() >= f.ErrorRate {
+		return f.Latency, 0
+	}
+	if f.Status == 0 {
+		return f.Latency, http.StatusServiceUnavailable
+	}
+	return f.Latency, f.Status
+}
@@ -0,0 +1,60 @@
+package chaos
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInject(t *testing.T) {
+	in := New()
+	roll := 0.0
+	in.rand = func() float64 { return roll }
+	err := in.Set(map[string]Fault{
+		"GET /api/chirps":  {Latency: 200 * time.Millisecond},
+		"POST /api/chirps": {ErrorRate: 0.25},
+		"GET /api/feed":    {Latency: time.Second, ErrorRate: 1, Status: http.StatusBadGateway},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		route       string
+		roll        float64
+		wantLatency time.Duration
+		wantStatus  int
+	}{
+		{route: "GET /api/healthz"},
+		{route: "GET /api/chirps", wantLatency: 200 * time.Millisecond},
+		{route: "POST /api/chirps", roll: 0.1, wantStatus: http.StatusServiceUnavailable},
+		{route: "POST /api/chirps", roll: 0.25},
+		{route: "GET /api/feed", roll: 0.99, wantLatency: time.Second, wantStatus: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		roll = tt.roll
+		latency, status := in.Inject(tt.route)
+		if latency != tt.wantLatency || status != tt.wantStatus {
+			t.Errorf("Inject(%q) with roll %v = %v, %d, want %v, %d", tt.route, tt.roll, latency, status, tt.wantLatency, tt.wantStatus)
+		}
+	}
+}
+
+func TestSetRejectsInvalidFaults(t *testing.T) {
+	in := New()
+	if err := in.Set(map[string]Fault{"GET /api/chirps": {ErrorRate: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []Fault{
+		{Latency: -time.Second},
+		{ErrorRate: 1.5},
+		{ErrorRate: 0.5, Status: http.StatusOK},
+	} {
+		if err := in.Set(map[string]Fault{"GET /api/feed": f}); err == nil {
+			t.Errorf("Set(%+v) = nil, want an error", f)
+		}
+	}
+	if faults := in.Faults(); len(faults) != 1 || faults["GET /api/chirps"].ErrorRate != 0.5 {
+		t.Errorf("Faults() = %v after invalid Sets, want the original fault", faults)
+	}
+}
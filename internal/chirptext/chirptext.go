@@ -0,0 +1,128 @@
+// Package chirptext cleans up chirp bodies, so that every handler that
+// accepts or shows chirp text treats it the same way.
+package chirptext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	zeroWidthNonJoiner = '\u200c'
+	zeroWidthJoiner    = '\u200d'
+)
+
+// Normalize prepares a chirp body for storage:
+//
+//   - invalid UTF-8 is dropped and the text is put in NFC;
+//   - control characters and bidirectional overrides are removed;
+//   - zero-width spaces are removed, and zero-width (non-)joiners are
+//     only kept between two visible characters, where emoji sequences and
+//     some scripts need them;
+//   - tabs and other Unicode spaces become plain spaces, runs of spaces
+//     collapse into one and lines are trimmed;
+//   - line breaks become \n, with at most one blank line in a row and
+//     none at the start or end.
+func Normalize(s string) string {
+	s = norm.NFC.String(strings.ToValidUTF8(s, ""))
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	var lines [][]rune
+	var line []rune
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029':
+			lines = append(lines, line)
+			line = nil
+		case unicode.IsSpace(r):
+			line = append(line, ' ')
+		case unicode.IsControl(r), isBidiOverride(r), isZeroWidthSpace(r):
+		default:
+			line = append(line, r)
+		}
+	}
+	lines = append(lines, line)
+
+	var b strings.Builder
+	blank := 0
+	for _, l := range lines {
+		l = cleanLine(l)
+		if len(l) == 0 {
+			blank++
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+			if blank > 0 {
+				b.WriteString("\n")
+			}
+		}
+		blank = 0
+		b.WriteString(string(l))
+	}
+	return b.String()
+}
+
+// cleanLine collapses the spaces of a line, trims it and drops joiners
+// that don't join two visible characters.
+func cleanLine(l []rune) []rune {
+	out := l[:0]
+	for i, r := range l {
+		switch r {
+		case ' ':
+			if len(out) == 0 || out[len(out)-1] == ' ' {
+				continue
+			}
+		case zeroWidthJoiner, zeroWidthNonJoiner:
+			if len(out) == 0 || !joinable(out[len(out)-1]) || i+1 == len(l) || !joinable(l[i+1]) {
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	if len(out) > 0 && out[len(out)-1] == ' ' {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func joinable(r rune) bool {
+	return r != ' ' && r != zeroWidthJoiner && r != zeroWidthNonJoiner
+}
+
+// isBidiOverride reports whether r is an embedding, override or isolate
+// control, which can make text display in a different order than it is
+// stored in.
+func isBidiOverride(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// isZeroWidthSpace reports whether r is an invisible character with no
+// use in a chirp.
+func isZeroWidthSpace(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff', '\u180e':
+		return true
+	}
+	return false
+}
+
+// HTML escapes a normalized body for use as HTML text, with line breaks
+// as <br>.
+func HTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
+
+// linkPattern matches URLs with a scheme, www. hosts and bare domains
+// under the TLDs links are commonly shortened to. Other bare domains are
+// let through, rather than every "end.start" with a missing space.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:[a-z][a-z0-9+.-]*://|www\.|[a-z0-9-]+\.(?:com|net|org|io|co|ly|me|gl|info|biz|xyz|app|dev|link|click|top|site|online|ru|cn)\b)`)
+
+// HasLink reports whether a chirp body contains a link.
+func HasLink(s string) bool {
+	return linkPattern.MatchString(s)
+}
@@ -0,0 +1,93 @@
+package chirptext
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Unchanged", in: "Hello, world!", want: "Hello, world!"},
+		{name: "Empty", in: "", want: ""},
+		{name: "Only whitespace", in: " \t\n \u3000 ", want: ""},
+		{name: "NFC", in: "Cafe\u0301 time", want: "Caf\u00e9 time"},
+		{name: "NFC singleton", in: "\u212b", want: "\u00c5"},
+		{name: "NFC Hangul", in: "\u1100\u1161\u11a8", want: "\uac01"},
+		{name: "Invalid UTF-8", in: "ok\xffay", want: "okay"},
+		{name: "Control characters", in: "be\x00ep\x07 \x1b[31mred", want: "beep [31mred"},
+		{name: "DEL and C1 controls", in: "a\x7fb\u0080c", want: "abc"},
+		{name: "Tabs", in: "a\tb\t\tc", want: "a b c"},
+		{name: "Unicode spaces", in: "a\u00a0b\u2003c\u3000d", want: "a b c d"},
+		{name: "Collapsed spaces", in: "  lots   of    space  ", want: "lots of space"},
+		{name: "CRLF", in: "one\r\ntwo\rthree", want: "one\ntwo\nthree"},
+		{name: "Line separators", in: "one\u2028two\u2029three", want: "one\ntwo\nthree"},
+		{name: "Lines trimmed", in: "  one  \n  two  ", want: "one\ntwo"},
+		{name: "One blank line kept", in: "one\n\ntwo", want: "one\n\ntwo"},
+		{name: "Blank lines collapsed", in: "one\n\n\n \n\t\ntwo", want: "one\n\ntwo"},
+		{name: "Outer lines trimmed", in: "\n\n one \n\n", want: "one"},
+		{name: "Zero-width spaces", in: "sp\u200bam \ufeffand\u2060 eggs\u180e", want: "spam and eggs"},
+		{name: "Bidi overrides", in: "abc\u202edcba\u202c \u2066x\u2069", want: "abcdcba x"},
+		{name: "Emoji ZWJ sequence", in: "\U0001F469\u200d\U0001F4BB", want: "\U0001F469\u200d\U0001F4BB"},
+		{name: "ZWNJ in a word", in: "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", want: "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645"},
+		{name: "Dangling joiners", in: "\u200dstart end\u200d a \u200d b", want: "start end a b"},
+		{name: "Repeated joiners", in: "a\u200d\u200db", want: "a\u200db"},
+		{name: "Joiner hiding a space", in: "bad \u200d\u200b word", want: "bad word"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.in)
+			if got != tt.want {
+				t.Errorf("Normalize(%+q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+			if again := Normalize(got); again != got {
+				t.Errorf("Normalize is not idempotent: %+q became %+q", got, again)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Plain", in: "hello", want: "hello"},
+		{name: "Tags", in: "<script>alert(1)</script>", want: "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{name: "Entities", in: `Tom & "Jerry" 'n' co`, want: "Tom &amp; &#34;Jerry&#34; &#39;n&#39; co"},
+		{name: "Line breaks", in: "one\n\ntwo", want: "one<br><br>two"},
+		{name: "Unicode", in: "caf\u00e9 \U0001F44D", want: "caf\u00e9 \U0001F44D"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.in); got != tt.want {
+				t.Errorf("HTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasLink(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"no links here", false},
+		{"see https://example.org/page", true},
+		{"HTTP://EXAMPLE.ORG", true},
+		{"visit www.example.net", true},
+		{"cheap pills at pills.xyz!", true},
+		{"bit.ly/abc", true},
+		{"e.g. this", false},
+		{"the end.Next sentence", false},
+		{"version 1.2.3", false},
+	}
+	for _, tt := range tests {
+		if got := HasLink(tt.in); got != tt.want {
+			t.Errorf("HasLink(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package chirptext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchMode selects how a Filter recognizes banned words.
+type MatchMode string
+
+const (
+	// MatchExact masks words equal to a banned word, ignoring case and
+	// surrounding punctuation.
+	MatchExact MatchMode = "exact"
+	// MatchSubstring masks words that contain a banned word anywhere.
+	// It catches compounds, and also innocent words that happen to
+	// contain one.
+	MatchSubstring MatchMode = "substring"
+	// MatchLeetspeak is MatchExact after undoing common character
+	// substitutions, so "k3rfuffl3" and "sh@rbert" match too.
+	MatchLeetspeak MatchMode = "leetspeak"
+)
+
+// ParseMatchMode parses the name of a MatchMode.
+func ParseMatchMode(s string) (MatchMode, error) {
+	switch m := MatchMode(s); m {
+	case MatchExact, MatchSubstring, MatchLeetspeak:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown match mode %q, expected exact, substring or leetspeak", s)
+}
+
+// mask replaces every banned word.
+const mask = "****"
+
+// leetspeak maps symbols and digits to the letters they commonly stand
+// in for.
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
+
+// Filter masks banned words in text.
+type Filter struct {
+	words map[string]bool
+	mode  MatchMode
+}
+
+// NewFilter creates a Filter for the given words.
+func NewFilter(words []string, mode MatchMode) *Filter {
+	f := &Filter{words: make(map[string]bool, len(words)), mode: mode}
+	for _, w := range words {
+		f.words[foldCase(w)] = true
+	}
+	return f
+}
+
+// Clean replaces each word of s that matches a banned word with ****,
+// leaving the text around it as it was.
+func (f *Filter) Clean(s string) string {
+	var b strings.Builder
+	rest := s
+	for rest != "" {
+		start, end := f.nextWord(rest)
+		if start < 0 {
+			break
+		}
+		word := rest[start:end]
+		if lo, hi, ok := f.match(word); ok {
+			b.WriteString(rest[:start+lo])
+			b.WriteString(mask)
+			b.WriteString(rest[start+hi : end])
+		} else {
+			b.WriteString(rest[:end])
+		}
+		rest = rest[end:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// nextWord returns the byte offsets of the first word in s, or -1 if it
+// has none. Words are runs of letters, digits and combining marks; in
+// leetspeak mode the substitution symbols count as well.
+func (f *Filter) nextWord(s string) (start, end int) {
+	start = -1
+	for i, r := range s {
+		if f.isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			return start, i
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, len(s)
+}
+
+func (f *Filter) isWordRune(r rune) bool {
+	if isAlphanumeric(r) {
+		return true
+	}
+	_, ok := leetspeak[r]
+	return ok && f.mode == MatchLeetspeak
+}
+
+// match reports whether word is banned, and which part of it to mask.
+func (f *Filter) match(word string) (lo, hi int, ok bool) {
+	folded := foldCase(word)
+	switch f.mode {
+	case MatchSubstring:
+		for w := range f.words {
+			if strings.Contains(folded, w) {
+				return 0, len(word), true
+			}
+		}
+	case MatchLeetspeak:
+		if f.words[unleet(folded)] {
+			return 0, len(word), true
+		}
+		// Symbols around a word are more likely punctuation, as in
+		// "kerfuffle!", than part of it
+		lo = strings.IndexFunc(word, isAlphanumeric)
+		if lo < 0 {
+			return 0, 0, false
+		}
+		hi = strings.LastIndexFunc(word, isAlphanumeric)
+		_, size := utf8.DecodeRuneInString(word[hi:])
+		hi += size
+		if f.words[unleet(foldCase(word[lo:hi]))] {
+			return lo, hi, true
+		}
+	default:
+		if f.words[folded] {
+			return 0, len(word), true
+		}
+	}
+	return 0, 0, false
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+// foldCase maps s to a caseless form, so words that only differ in case
+// compare equal. Going through upper case first folds letters with
+// several lowercase forms, like final sigma, onto one.
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		return unicode.ToLower(unicode.ToUpper(r))
+	}, s)
+}
+
+func unleet(s string) string {
+	return strings.Map(func(r rune) rune {
+		if l, ok := leetspeak[r]; ok {
+			return l
+		}
+		return r
+	}, s)
+}
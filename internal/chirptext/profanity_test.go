@@ -0,0 +1,72 @@
+package chirptext
+
+import "testing"
+
+func TestFilterClean(t *testing.T) {
+	words := []string{"kerfuffle", "sharbert", "fornax"}
+
+	tests := []struct {
+		name string
+		mode MatchMode
+		in   string
+		want string
+	}{
+		{name: "Clean text", mode: MatchExact, in: "This is a chirp", want: "This is a chirp"},
+		{name: "Whole word", mode: MatchExact, in: "what a kerfuffle today", want: "what a **** today"},
+		{name: "Upper case", mode: MatchExact, in: "KERFUFFLE, Sharbert", want: "****, ****"},
+		{name: "Punctuation", mode: MatchExact, in: "kerfuffle! (fornax) \"sharbert\"...", want: "****! (****) \"****\"..."},
+		{name: "Apostrophe", mode: MatchExact, in: "the kerfuffle's end", want: "the ****'s end"},
+		{name: "Line breaks", mode: MatchExact, in: "fornax\nfornax", want: "****\n****"},
+		{name: "Unicode case folding", mode: MatchExact, in: "\u212AERFUFFLE", want: "****"},
+		{name: "Exact ignores compounds", mode: MatchExact, in: "kerfufflemania", want: "kerfufflemania"},
+		{name: "Exact ignores leetspeak", mode: MatchExact, in: "k3rfuffl3", want: "k3rfuffl3"},
+		{name: "Substring", mode: MatchSubstring, in: "kerfufflemania is real", want: "**** is real"},
+		{name: "Substring case folded", mode: MatchSubstring, in: "SuperSharbert!", want: "****!"},
+		{name: "Substring clean text", mode: MatchSubstring, in: "a fine chirp", want: "a fine chirp"},
+		{name: "Leetspeak digits", mode: MatchLeetspeak, in: "k3rfuffl3 again", want: "**** again"},
+		{name: "Leetspeak symbols", mode: MatchLeetspeak, in: "sh@rb3r+ and f0rn@x", want: "**** and ****"},
+		{name: "Leetspeak trailing punctuation", mode: MatchLeetspeak, in: "KERFUFFLE!", want: "****!"},
+		{name: "Leetspeak surrounding symbols", mode: MatchLeetspeak, in: "$$fornax$$", want: "$$****$$"},
+		{name: "Leetspeak clean text", mode: MatchLeetspeak, in: "I paid $5 @ the shop!", want: "I paid $5 @ the shop!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFilter(words, tt.mode)
+			if got := f.Clean(tt.in); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterFoldsBannedWords(t *testing.T) {
+	f := NewFilter([]string{"Straße", "ΣΟΦΟΣ"}, MatchExact)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "STRASSE", want: "STRASSE"},
+		{in: "straße", want: "****"},
+		{in: "STRAẞE", want: "****"},
+		{in: "σοφος", want: "****"},
+		{in: "σοφoς", want: "σοφoς"},
+		{in: "σοφος.", want: "****."},
+	}
+	for _, tt := range tests {
+		if got := f.Clean(tt.in); got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for _, s := range []string{"exact", "substring", "leetspeak"} {
+		if m, err := ParseMatchMode(s); err != nil || string(m) != s {
+			t.Errorf("ParseMatchMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := ParseMatchMode("fuzzy"); err == nil {
+		t.Error("ParseMatchMode(\"fuzzy\") succeeded, want an error")
+	}
+}
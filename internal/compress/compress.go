@@ -0,0 +1,263 @@
+// Package compress gzips responses for clients that accept it.
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Policy says which responses are compressed.
+type Policy struct {
+	// MinSize is the smallest body worth compressing, in bytes. Smaller
+	// bodies would barely shrink, or even grow.
+	MinSize int
+	// Types are the media types that are compressed, e.g.
+	// "application/json". Images and other already compressed formats
+	// shouldn't be listed.
+	Types []string
+}
+
+// DefaultPolicy compresses JSON, NDJSON and the text formats of the file
+// server once they reach 1 KiB. Server-sent events are left out, so
+// proxies don't hold events back to fill a compression block.
+var DefaultPolicy = Policy{
+	MinSize: 1 << 10,
+	Types: []string{
+		"application/json",
+		"application/problem+json",
+		"application/x-ndjson",
+		"application/javascript",
+		"application/xml",
+		"image/svg+xml",
+		"text/css",
+		"text/csv",
+		"text/html",
+		"text/javascript",
+		"text/plain",
+		"text/xml",
+	},
+}
+
+var gzipWriters = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// Handler gzips the responses of next when the request's Accept-Encoding
+// allows it and the response has one of p.Types and reaches p.MinSize.
+// Responses that are flushed before reaching p.MinSize, such as streams,
+// are compressed from the first flush. HEAD and range requests, and
+// responses that already have a Content-Encoding, are left alone.
+func (p Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || r.Header.Get("Range") != "" || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &responseWriter{ResponseWriter: w, policy: p}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip,
+// named or as *, with a non-zero quality.
+func acceptsGzip(header string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch coding {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// responseWriter holds back the start of a response until it knows
+// whether to compress it: when the body reaches MinSize, the handler
+// flushes, or the handler returns.
+type responseWriter struct {
+	http.ResponseWriter
+	policy Policy
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status != 0 {
+		return
+	}
+	if code < 200 {
+		// informational responses such as 103 Early Hints go straight out
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	h := w.Header()
+	if code == http.StatusNoContent || code == http.StatusNotModified || h.Get("Content-Encoding") != "" {
+		w.start(false)
+		return
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < w.policy.MinSize {
+		w.start(false)
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.policy.MinSize {
+		if err := w.start(w.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// compressible reports whether the response has one of the policy's
+// types. Responses without a Content-Type get the sniffed one, as
+// net/http would give them.
+func (w *responseWriter) compressible() bool {
+	h := w.Header()
+	if _, ok := h["Content-Type"]; !ok {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range w.policy.Types {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// start sends the header, with or without compression, and what has
+// been buffered.
+func (w *responseWriter) start(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	if w.status != http.StatusNoContent && w.status != http.StatusNotModified && h.Get("Content-Encoding") == "" && w.compressible() {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// The compressed body is a different representation
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// FlushError implements the flushing of http.ResponseController. A
+// response flushed before reaching MinSize is taken to be a stream and
+// compressed if its type allows.
+func (w *responseWriter) FlushError() error {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.start(w.compressible()); err != nil {
+			return err
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	w.FlushError()
+}
+
+// Hijack implements http.Hijacker, for handlers that take over the
+// connection before writing anything.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g.
+// to set deadlines.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the response once the handler has returned.
+func (w *responseWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			// Nothing was written; net/http sends its own 200.
+			return
+		}
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		// Write starts bodies that reach MinSize
+		w.start(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.gz.Reset(io.Discard)
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
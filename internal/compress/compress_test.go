@@ -0,0 +1,147 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"br, GZIP":            true,
+		"deflate":             false,
+		"gzip;q=0":            false,
+		"*":                   true,
+		"gzip;q=0, *":         false,
+		"*;q=0":               false,
+		"deflate, gzip;q=0.5": true,
+	}
+	for header, want := range tests {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %t, want %t", header, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	large := `{"body":"` + strings.Repeat("chirp ", 400) + `"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		contentType    string
+		etag           string
+		body           string
+		wantGzip       bool
+		wantVary       bool
+	}{
+		{name: "Large JSON", acceptEncoding: "gzip", contentType: "application/json", body: large, wantGzip: true, wantVary: true},
+		{name: "Small JSON", acceptEncoding: "gzip", contentType: "application/json", body: `{"ok":true}`, wantVary: true},
+		{name: "Not accepted", acceptEncoding: "br", contentType: "application/json", body: large},
+		{name: "Image", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "Sniffed text", acceptEncoding: "gzip", body: strings.Repeat("plain text ", 200), wantGzip: true, wantVary: true},
+		{name: "HEAD", method: http.MethodHead, acceptEncoding: "gzip", contentType: "application/json", body: large},
+		{name: "Strong ETag", acceptEncoding: "gzip", contentType: "application/json", etag: `"abc"`, body: large, wantGzip: true, wantVary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := DefaultPolicy.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				w.WriteHeader(http.StatusOK)
+				// in pieces, to check buffering
+				io.WriteString(w, tt.body[:len(tt.body)/2])
+				io.WriteString(w, tt.body[len(tt.body)/2:])
+			}))
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/api/chirps", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+			gzipped := rec.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("Content-Encoding = %q, want gzip %t", rec.Header().Get("Content-Encoding"), tt.wantGzip)
+			}
+			if vary := rec.Header().Get("Vary") == "Accept-Encoding"; vary != tt.wantVary {
+				t.Errorf("Vary = %q, want Accept-Encoding %t", rec.Header().Get("Vary"), tt.wantVary)
+			}
+			body := rec.Body.String()
+			if gzipped {
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader: %v", err)
+				}
+				b, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("reading gzip body: %v", err)
+				}
+				body = string(b)
+				if tt.etag != "" && rec.Header().Get("ETag") != "W/"+tt.etag {
+					t.Errorf("ETag = %q, want it weakened", rec.Header().Get("ETag"))
+				}
+			}
+			if body != tt.body {
+				t.Errorf("body = %.40q..., want %.40q...", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestHandlerStream(t *testing.T) {
+	handler := DefaultPolicy.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"n\":1}\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+		io.WriteString(w, "{\"n\":2}\n")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/admin/export/chirps", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !rec.Flushed || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Flushed, Content-Encoding = %t, %q, want a flushed gzip stream", rec.Flushed, rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	b, _ := io.ReadAll(zr)
+	if string(b) != "{\"n\":1}\n{\"n\":2}\n" {
+		t.Errorf("body = %q", b)
+	}
+}
+
+func TestHandlerNoContent(t *testing.T) {
+	handler := DefaultPolicy.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodDelete, "/api/chirps/1", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+		t.Errorf("got %d, Content-Encoding %q, %d bytes, want a bare 204", rec.Code, rec.Header().Get("Content-Encoding"), rec.Body.Len())
+	}
+}
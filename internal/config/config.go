@@ -0,0 +1,570 @@
+// Package config loads the server's settings from environment variables
+// and command-line flags, and checks them before anything starts.
+//
+// Flags take precedence over environment variables, which take
+// precedence over the defaults. Every setting has an environment
+// variable; the ones operators commonly override per run also have a
+// flag, named in the usage message.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"main.go/internal/auth"
+	"main.go/internal/chirptext"
+	"main.go/internal/metrics"
+	"main.go/internal/reports"
+	"main.go/internal/trust"
+)
+
+// Config is everything the server reads from its environment.
+type Config struct {
+	Port      string // PORT, -port
+	FileRoot  string // FILE_ROOT, -root: served under /app/
+	DBURL     string // DB_URL, -db-url
+	JWTSecret string // JWT_SECRET: the key of access tokens without a key ID
+	Platform  string // PLATFORM, -platform: "dev" enables POST /admin/reset; "dev" and "staging" enable fault injection
+	PolkaKey  string // POLKA_KEY: API key of incoming webhooks
+	LogFormat string // LOG_FORMAT, -log-format: text or json
+	ReadOnly  bool   // READ_ONLY, -read-only: start refusing writes, see PUT /admin/read_only
+	PublicURL string // PUBLIC_URL: where users reach the server; enables rel="me" website verification
+
+	// Prometheus metrics are served at GET /metrics on a listener of
+	// their own, which can be kept off the public network
+	MetricsAddr string // METRICS_ADDR, -metrics-addr, e.g. localhost:9090; empty disables it
+
+	ShutdownTimeout            time.Duration // SHUTDOWN_TIMEOUT, -shutdown-timeout
+	ReadHeaderTimeout          time.Duration // READ_HEADER_TIMEOUT
+	ReadTimeout                time.Duration // READ_TIMEOUT: 0 disables, as do the next two
+	WriteTimeout               time.Duration // WRITE_TIMEOUT: streamed exports lift it
+	IdleTimeout                time.Duration // IDLE_TIMEOUT: keep-alive connections
+	AccountDeletionGracePeriod time.Duration // ACCOUNT_DELETION_GRACE_PERIOD
+	RefreshTokenMaxAge         time.Duration // REFRESH_TOKEN_MAX_AGE: 0 disables sliding expiration
+	UsernameRedirectPeriod     time.Duration // USERNAME_REDIRECT_PERIOD: how long an old username is kept; 0 releases it at once
+	UsernameChangeInterval     time.Duration // USERNAME_CHANGE_INTERVAL: least time between username changes; 0 disables the limit
+	MaxSessionsPerUser         int           // MAX_SESSIONS_PER_USER
+	MaxBodySize                int64         // MAX_BODY_SIZE: largest API request body, in bytes
+	StreamMaxClients           int           // STREAM_MAX_CLIENTS: open chirp streams; 0 for no limit
+	StreamBuffer               int           // STREAM_BUFFER: chirps a stream client may fall behind before it is dropped
+
+	RateLimitRequests          int           // RATE_LIMIT_REQUESTS, per user
+	RateLimitTrustedRequests   int           // RATE_LIMIT_TRUSTED_REQUESTS, per trusted user
+	RateLimitAnonymousRequests int           // RATE_LIMIT_ANONYMOUS_REQUESTS, per IP
+	RateLimitWindow            time.Duration // RATE_LIMIT_WINDOW
+
+	// HTTPS, from certificate files or from Let's Encrypt. Either one
+	// enables the HTTPRedirectPort listener.
+	TLSCertFile          string   // TLS_CERT_FILE, -tls-cert
+	TLSKeyFile           string   // TLS_KEY_FILE, -tls-key
+	AutocertHosts        []string // AUTOCERT_HOSTS: host names to get certificates for
+	AutocertCacheDir     string   // AUTOCERT_CACHE_DIR
+	AutocertEmail        string   // AUTOCERT_EMAIL: contact of the ACME account
+	AutocertDirectoryURL string   // AUTOCERT_DIRECTORY_URL, e.g. the staging CA
+	HTTPRedirectPort     string   // HTTP_REDIRECT_PORT: plain HTTP, redirected to HTTPS; empty disables it
+
+	MediaRoot          string   // MEDIA_ROOT
+	MediaSigningSecret string   // MEDIA_SIGNING_SECRET: signs private media links, not shared with JWT keys
+	MediaAllowedHosts  []string // MEDIA_ALLOWED_HOSTS
+
+	EventBus           string // EVENT_BUS: empty, nats, kafka or webhook
+	EventSubjectPrefix string // EVENT_SUBJECT_PREFIX
+	NATSURL            string // NATS_URL
+	KafkaRESTURL       string // KAFKA_REST_URL
+	EventWebhookURL    string // EVENT_WEBHOOK_URL
+	EventWebhookSecret string // EVENT_WEBHOOK_SECRET: signs deliveries when set
+
+	// OAuth sign-in. A provider is enabled by setting its client ID and
+	// secret; its callback is <OAUTH_REDIRECT_BASE_URL>/api/v1/auth/<provider>/callback
+	OAuthRedirectBaseURL string // OAUTH_REDIRECT_BASE_URL: public URL of the server
+	GoogleClientID       string // GOOGLE_CLIENT_ID
+	GoogleClientSecret   string // GOOGLE_CLIENT_SECRET
+	GitHubClientID       string // GITHUB_CLIENT_ID
+	GitHubClientSecret   string // GITHUB_CLIENT_SECRET
+
+	// Geo-velocity checks at login, enabled by GEOIP_DATABASE. A login
+	// further from the previous one than LoginMaxTravelSpeed allows
+	// needs a code sent by email.
+	GeoIPDatabase       string // GEOIP_DATABASE: MaxMind DB file with city locations
+	LoginMaxTravelSpeed int    // LOGIN_MAX_TRAVEL_SPEED, in km/h
+
+	FeedRankerURL   string              // FEED_RANKER_URL
+	AlertWebhookURL string              // ALERT_WEBHOOK_URL
+	ProfanityMode   chirptext.MatchMode // PROFANITY_MATCH_MODE
+
+	// Content filters chirp bodies go through, in order: words (the
+	// banned words), regex (the rules in CONTENT_FILTER_RULES_FILE) and
+	// api (the moderation service at CONTENT_FILTER_API_URL)
+	ContentFilters         []string // CONTENT_FILTERS, default words
+	ContentFilterRulesFile string   // CONTENT_FILTER_RULES_FILE
+	ContentFilterAPIURL    string   // CONTENT_FILTER_API_URL
+
+	// Gzip compression of responses
+	Compression        bool     // COMPRESSION
+	CompressionMinSize int      // COMPRESSION_MIN_SIZE, in bytes
+	CompressionTypes   []string // COMPRESSION_TYPES: nil keeps the compress.DefaultPolicy types
+
+	// CORS_ALLOWED_*; nil keeps the cors.DefaultPolicy setting
+	CORSAllowedOrigins []string
+	CORSAllowedMethods []string
+	CORSAllowedHeaders []string
+
+	// METRICS_EXCLUDED_PATHS and METRICS_EXCLUDED_USER_AGENTS each
+	// replace their default list, even when set to nothing
+	HitExclusions metrics.Exclusions
+
+	// TaskSchedules maps lower case task names to the cron expression
+	// given in SCHEDULE_<TASK_NAME>
+	TaskSchedules map[string]string
+
+	// ReportPolicies maps report reasons to their automated actions:
+	// reports.DefaultPolicies, with REPORT_POLICY_<REASON> replacing a
+	// reason's policy, e.g. REPORT_POLICY_SPAM=hide=5,escalate=2
+	ReportPolicies map[string]reports.Policy
+
+	// TrustRules are trust.DefaultRules, with TRUST_BASIC and
+	// TRUST_TRUSTED replacing a level's requirement, e.g.
+	// TRUST_BASIC=age=72h,chirps=3
+	TrustRules trust.Rules
+
+	// JWTKeys are the keys access tokens are valid with: JWT_KEYS, such
+	// as "2024-06:secret,2024-01:old", then JWTSecret with an empty ID if
+	// it is set. The first one signs new tokens.
+	JWTKeys auth.Keyring
+
+	// Args are the arguments left after the flags, e.g. a maintenance
+	// command
+	Args []string
+}
+
+// Load reads the configuration from environ, in the form returned by
+// os.Environ, and args, the command-line arguments without the program
+// name. All invalid settings are reported together. Flag errors are
+// printed with the usage message, and -h returns flag.ErrHelp.
+func Load(args, environ []string) (*Config, error) {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	l := &loader{env: env}
+
+	c := &Config{
+		Port:      l.string("PORT", "8080"),
+		FileRoot:  l.string("FILE_ROOT", "."),
+		DBURL:     l.string("DB_URL", ""),
+		JWTSecret: l.string("JWT_SECRET", ""),
+		Platform:  l.string("PLATFORM", ""),
+		PolkaKey:  l.string("POLKA_KEY", ""),
+		LogFormat: l.string("LOG_FORMAT", "text"),
+		ReadOnly:  l.bool("READ_ONLY", false),
+		PublicURL: strings.TrimRight(l.string("PUBLIC_URL", ""), "/"),
+
+		MetricsAddr: l.string("METRICS_ADDR", ""),
+
+		ShutdownTimeout:            l.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		ReadHeaderTimeout:          l.duration("READ_HEADER_TIMEOUT", 10*time.Second),
+		ReadTimeout:                l.duration("READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:               l.duration("WRITE_TIMEOUT", time.Minute),
+		IdleTimeout:                l.duration("IDLE_TIMEOUT", 2*time.Minute),
+		AccountDeletionGracePeriod: l.duration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		RefreshTokenMaxAge:         l.duration("REFRESH_TOKEN_MAX_AGE", 0),
+		UsernameRedirectPeriod:     l.duration("USERNAME_REDIRECT_PERIOD", 30*24*time.Hour),
+		UsernameChangeInterval:     l.duration("USERNAME_CHANGE_INTERVAL", 7*24*time.Hour),
+		MaxSessionsPerUser:         l.int("MAX_SESSIONS_PER_USER", 10),
+		MaxBodySize:                int64(l.int("MAX_BODY_SIZE", 1<<20)),
+		StreamMaxClients:           l.int("STREAM_MAX_CLIENTS", 1000),
+		StreamBuffer:               l.int("STREAM_BUFFER", 64),
+
+		RateLimitRequests:          l.int("RATE_LIMIT_REQUESTS", 120),
+		RateLimitTrustedRequests:   l.int("RATE_LIMIT_TRUSTED_REQUESTS", 300),
+		RateLimitAnonymousRequests: l.int("RATE_LIMIT_ANONYMOUS_REQUESTS", 60),
+		RateLimitWindow:            l.duration("RATE_LIMIT_WINDOW", time.Minute),
+
+		TLSCertFile:          l.string("TLS_CERT_FILE", ""),
+		TLSKeyFile:           l.string("TLS_KEY_FILE", ""),
+		AutocertHosts:        l.list("AUTOCERT_HOSTS"),
+		AutocertCacheDir:     l.string("AUTOCERT_CACHE_DIR", "certs"),
+		AutocertEmail:        l.string("AUTOCERT_EMAIL", ""),
+		AutocertDirectoryURL: l.string("AUTOCERT_DIRECTORY_URL", ""),
+
+		MediaRoot:          l.string("MEDIA_ROOT", "media"),
+		MediaSigningSecret: l.string("MEDIA_SIGNING_SECRET", ""),
+		MediaAllowedHosts:  l.list("MEDIA_ALLOWED_HOSTS"),
+
+		EventBus:           l.string("EVENT_BUS", ""),
+		EventSubjectPrefix: l.string("EVENT_SUBJECT_PREFIX", "chirpy"),
+		NATSURL:            l.string("NATS_URL", ""),
+		KafkaRESTURL:       l.string("KAFKA_REST_URL", ""),
+		EventWebhookURL:    l.string("EVENT_WEBHOOK_URL", ""),
+		EventWebhookSecret: l.string("EVENT_WEBHOOK_SECRET", ""),
+
+		OAuthRedirectBaseURL: l.string("OAUTH_REDIRECT_BASE_URL", ""),
+		GoogleClientID:       l.string("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret:   l.string("GOOGLE_CLIENT_SECRET", ""),
+		GitHubClientID:       l.string("GITHUB_CLIENT_ID", ""),
+		GitHubClientSecret:   l.string("GITHUB_CLIENT_SECRET", ""),
+
+		GeoIPDatabase:       l.string("GEOIP_DATABASE", ""),
+		LoginMaxTravelSpeed: l.int("LOGIN_MAX_TRAVEL_SPEED", 1000),
+
+		FeedRankerURL:   l.string("FEED_RANKER_URL", ""),
+		AlertWebhookURL: l.string("ALERT_WEBHOOK_URL", ""),
+
+		ContentFilters:         l.list("CONTENT_FILTERS"),
+		ContentFilterRulesFile: l.string("CONTENT_FILTER_RULES_FILE", ""),
+		ContentFilterAPIURL:    l.string("CONTENT_FILTER_API_URL", ""),
+
+		Compression:        l.bool("COMPRESSION", true),
+		CompressionMinSize: l.int("COMPRESSION_MIN_SIZE", 1<<10),
+		CompressionTypes:   l.list("COMPRESSION_TYPES"),
+
+		CORSAllowedOrigins: l.list("CORS_ALLOWED_ORIGINS"),
+		CORSAllowedMethods: l.list("CORS_ALLOWED_METHODS"),
+		CORSAllowedHeaders: l.list("CORS_ALLOWED_HEADERS"),
+
+		ProfanityMode:  chirptext.MatchExact,
+		HitExclusions:  metrics.DefaultExclusions,
+		TaskSchedules:  make(map[string]string),
+		ReportPolicies: maps.Clone(reports.DefaultPolicies),
+		TrustRules:     trust.DefaultRules,
+	}
+	// Let's Encrypt checks HTTP-01 challenges on port 80
+	redirectDefault := ""
+	if c.AutocertHosts != nil {
+		redirectDefault = "80"
+	}
+	c.HTTPRedirectPort = l.string("HTTP_REDIRECT_PORT", redirectDefault)
+	if v := l.string("PROFANITY_MATCH_MODE", ""); v != "" {
+		mode, err := chirptext.ParseMatchMode(v)
+		l.check("PROFANITY_MATCH_MODE", err)
+		c.ProfanityMode = mode
+	}
+	if c.ContentFilters == nil {
+		c.ContentFilters = []string{"words"}
+	}
+	if v := env["TRUST_BASIC"]; v != "" {
+		q, err := trust.ParseRequirement(v)
+		l.check("TRUST_BASIC", err)
+		c.TrustRules.Basic = q
+	}
+	if v := env["TRUST_TRUSTED"]; v != "" {
+		q, err := trust.ParseRequirement(v)
+		l.check("TRUST_TRUSTED", err)
+		c.TrustRules.Trusted = q
+	}
+	if v, ok := env["METRICS_EXCLUDED_PATHS"]; ok {
+		c.HitExclusions.Paths = metrics.ParseList(v)
+	}
+	if v, ok := env["METRICS_EXCLUDED_USER_AGENTS"]; ok {
+		c.HitExclusions.UserAgents = metrics.ParseList(v)
+	}
+	for k, v := range env {
+		if name, ok := strings.CutPrefix(k, "SCHEDULE_"); ok && v != "" {
+			c.TaskSchedules[strings.ToLower(name)] = v
+		}
+		if name, ok := strings.CutPrefix(k, "REPORT_POLICY_"); ok {
+			reason := strings.ToLower(name)
+			if !reports.Known(reason) {
+				l.check(k, fmt.Errorf("unknown report reason %q", reason))
+				continue
+			}
+			policy, err := reports.ParsePolicy(v)
+			l.check(k, err)
+			c.ReportPolicies[reason] = policy
+		}
+	}
+
+	flags := flag.NewFlagSet("chirpy", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: chirpy [flags] [fsck [--fix] | set-role <email> user|admin]")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&c.Port, "port", c.Port, "port to listen on (PORT)")
+	flags.StringVar(&c.FileRoot, "root", c.FileRoot, "directory served under /app/ (FILE_ROOT)")
+	// No default shown for the URL, which may hold a password
+	dbURL := flags.String("db-url", "", "PostgreSQL connection URL (DB_URL)")
+	flags.StringVar(&c.Platform, "platform", c.Platform, `"dev" enables destructive admin endpoints (PLATFORM)`)
+	flags.StringVar(&c.LogFormat, "log-format", c.LogFormat, "text or json (LOG_FORMAT)")
+	flags.StringVar(&c.TLSCertFile, "tls-cert", c.TLSCertFile, "certificate file, enables HTTPS (TLS_CERT_FILE)")
+	flags.StringVar(&c.TLSKeyFile, "tls-key", c.TLSKeyFile, "private key file of -tls-cert (TLS_KEY_FILE)")
+	flags.StringVar(&c.MetricsAddr, "metrics-addr", c.MetricsAddr, "address serving Prometheus metrics, e.g. localhost:9090 (METRICS_ADDR)")
+	flags.BoolVar(&c.ReadOnly, "read-only", c.ReadOnly, "refuse writes to the API, e.g. during a migration (READ_ONLY)")
+	flags.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "time to drain requests on shutdown (SHUTDOWN_TIMEOUT)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	c.Args = flags.Args()
+	if *dbURL != "" {
+		c.DBURL = *dbURL
+	}
+
+	if v := l.env["JWT_KEYS"]; v != "" {
+		keys, err := auth.ParseKeyring(v)
+		l.check("JWT_KEYS", err)
+		c.JWTKeys = keys
+	}
+	if c.JWTSecret != "" {
+		c.JWTKeys = append(c.JWTKeys, auth.Key{Secret: []byte(c.JWTSecret)})
+	}
+	l.errs = append(l.errs, c.validate()...)
+	if err := errors.Join(l.errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validate checks settings that parsed but don't make sense.
+func (c *Config) validate() []error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("invalid %s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		invalid("PORT", "%q is not a port number", c.Port)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		invalid("TLS_CERT_FILE", "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSCertFile != "" && c.AutocertHosts != nil {
+		invalid("AUTOCERT_HOSTS", "can't be used with TLS_CERT_FILE")
+	}
+	if c.AutocertHosts != nil && c.AutocertCacheDir == "" {
+		invalid("AUTOCERT_CACHE_DIR", "required with AUTOCERT_HOSTS")
+	}
+	if c.HTTPRedirectPort != "" {
+		if port, err := strconv.Atoi(c.HTTPRedirectPort); err != nil || port < 1 || port > 65535 {
+			invalid("HTTP_REDIRECT_PORT", "%q is not a port number", c.HTTPRedirectPort)
+		} else if !c.TLSEnabled() {
+			invalid("HTTP_REDIRECT_PORT", "requires TLS_CERT_FILE or AUTOCERT_HOSTS")
+		} else if c.HTTPRedirectPort == c.Port {
+			invalid("HTTP_REDIRECT_PORT", "must differ from PORT")
+		}
+	}
+	if c.MetricsAddr != "" {
+		if _, port, err := net.SplitHostPort(c.MetricsAddr); err != nil || port == "" {
+			invalid("METRICS_ADDR", "%q is not a host:port address", c.MetricsAddr)
+		} else if port == c.Port || port == c.HTTPRedirectPort {
+			invalid("METRICS_ADDR", "must use a port of its own")
+		}
+	}
+	if c.DBURL == "" {
+		invalid("DB_URL", "not set")
+	}
+	if len(c.JWTKeys) == 0 {
+		invalid("JWT_SECRET", "not set, and neither is JWT_KEYS")
+	}
+	if c.MediaSigningSecret == "" {
+		invalid("MEDIA_SIGNING_SECRET", "not set")
+	}
+	for _, key := range c.JWTKeys {
+		if string(key.Secret) == c.MediaSigningSecret {
+			invalid("MEDIA_SIGNING_SECRET", "must differ from the JWT keys")
+			break
+		}
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		invalid("LOG_FORMAT", "%q, expected text or json", c.LogFormat)
+	}
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("PUBLIC_URL", "%q is not an absolute URL", c.PublicURL)
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		invalid("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	// Without a header timeout slow clients can hold connections open
+	if c.ReadHeaderTimeout <= 0 {
+		invalid("READ_HEADER_TIMEOUT", "must be positive")
+	}
+	if c.ReadTimeout < 0 {
+		invalid("READ_TIMEOUT", "must not be negative")
+	}
+	if c.WriteTimeout < 0 {
+		invalid("WRITE_TIMEOUT", "must not be negative")
+	}
+	if c.IdleTimeout < 0 {
+		invalid("IDLE_TIMEOUT", "must not be negative")
+	}
+	if c.AccountDeletionGracePeriod < 0 {
+		invalid("ACCOUNT_DELETION_GRACE_PERIOD", "must not be negative")
+	}
+	if c.RefreshTokenMaxAge < 0 {
+		invalid("REFRESH_TOKEN_MAX_AGE", "must not be negative")
+	}
+	if c.UsernameRedirectPeriod < 0 {
+		invalid("USERNAME_REDIRECT_PERIOD", "must not be negative")
+	}
+	if c.UsernameChangeInterval < 0 {
+		invalid("USERNAME_CHANGE_INTERVAL", "must not be negative")
+	}
+	if c.MaxSessionsPerUser < 1 {
+		invalid("MAX_SESSIONS_PER_USER", "must be at least 1")
+	}
+	if c.MaxBodySize < 1<<10 {
+		invalid("MAX_BODY_SIZE", "must be at least 1024")
+	}
+	if c.StreamMaxClients < 0 {
+		invalid("STREAM_MAX_CLIENTS", "must not be negative")
+	}
+	if c.StreamBuffer < 1 {
+		invalid("STREAM_BUFFER", "must be at least 1")
+	}
+	if c.CompressionMinSize < 0 {
+		invalid("COMPRESSION_MIN_SIZE", "must not be negative")
+	}
+	if c.RateLimitRequests < 1 {
+		invalid("RATE_LIMIT_REQUESTS", "must be at least 1")
+	}
+	if c.RateLimitTrustedRequests < c.RateLimitRequests {
+		invalid("RATE_LIMIT_TRUSTED_REQUESTS", "must be at least RATE_LIMIT_REQUESTS")
+	}
+	if c.RateLimitAnonymousRequests < 1 {
+		invalid("RATE_LIMIT_ANONYMOUS_REQUESTS", "must be at least 1")
+	}
+	if c.RateLimitWindow <= 0 {
+		invalid("RATE_LIMIT_WINDOW", "must be positive")
+	}
+	if (c.GoogleClientID == "") != (c.GoogleClientSecret == "") {
+		invalid("GOOGLE_CLIENT_ID", "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET must be set together")
+	}
+	if (c.GitHubClientID == "") != (c.GitHubClientSecret == "") {
+		invalid("GITHUB_CLIENT_ID", "GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET must be set together")
+	}
+	if c.GoogleClientID != "" || c.GitHubClientID != "" {
+		if u, err := url.Parse(c.OAuthRedirectBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("OAUTH_REDIRECT_BASE_URL", "%q is not an absolute URL, required with an OAuth provider", c.OAuthRedirectBaseURL)
+		}
+	}
+	if c.LoginMaxTravelSpeed < 1 {
+		invalid("LOGIN_MAX_TRAVEL_SPEED", "must be at least 1")
+	}
+	for _, f := range c.ContentFilters {
+		switch f {
+		case "words":
+		case "regex":
+			if c.ContentFilterRulesFile == "" {
+				invalid("CONTENT_FILTER_RULES_FILE", "required with the regex content filter")
+			}
+		case "api":
+			if u, err := url.Parse(c.ContentFilterAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+				invalid("CONTENT_FILTER_API_URL", "%q is not an absolute URL, required with the api content filter", c.ContentFilterAPIURL)
+			}
+		default:
+			invalid("CONTENT_FILTERS", "unknown filter %q, expected words, regex or api", f)
+		}
+	}
+	switch c.EventBus {
+	case "":
+	case "nats":
+		if c.NATSURL == "" {
+			invalid("NATS_URL", "required with EVENT_BUS=nats")
+		}
+	case "kafka":
+		if c.KafkaRESTURL == "" {
+			invalid("KAFKA_REST_URL", "required with EVENT_BUS=kafka")
+		}
+	case "webhook":
+		if u, err := url.Parse(c.EventWebhookURL); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("EVENT_WEBHOOK_URL", "%q is not an absolute URL, required with EVENT_BUS=webhook", c.EventWebhookURL)
+		}
+	default:
+		invalid("EVENT_BUS", "%q, expected nats, kafka or webhook", c.EventBus)
+	}
+	return errs
+}
+
+// Addr is the address to listen on.
+func (c *Config) Addr() string {
+	return ":" + c.Port
+}
+
+// TLSEnabled reports whether the server serves HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.AutocertHosts != nil
+}
+
+// RedirectAddr is the address of the HTTP to HTTPS redirect listener, or
+// "" if there is none.
+func (c *Config) RedirectAddr() string {
+	if c.HTTPRedirectPort == "" {
+		return ""
+	}
+	return ":" + c.HTTPRedirectPort
+}
+
+// TaskSchedule returns the cron expression for a task, defaultSpec
+// unless SCHEDULE_<TASK_NAME> overrides it.
+func (c *Config) TaskSchedule(name, defaultSpec string) string {
+	if spec, ok := c.TaskSchedules[name]; ok {
+		return spec
+	}
+	return defaultSpec
+}
+
+// loader reads environment variables, collecting the errors.
+type loader struct {
+	env  map[string]string
+	errs []error
+}
+
+func (l *loader) check(key string, err error) {
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("invalid %s: %w", key, err))
+	}
+}
+
+func (l *loader) string(key, def string) string {
+	if v := l.env[key]; v != "" {
+		return v
+	}
+	return def
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v := l.env[key]
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	l.check(key, err)
+	return d
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	v := l.env[key]
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	l.check(key, err)
+	return b
+}
+
+func (l *loader) int(key string, def int) int {
+	v := l.env[key]
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	l.check(key, err)
+	return n
+}
+
+// list splits a comma separated variable, returning nil if it is unset.
+func (l *loader) list(key string) []string {
+	if v := l.env[key]; v != "" {
+		return strings.Split(v, ",")
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"main.go/internal/chirptext"
+	"main.go/internal/reports"
+	"main.go/internal/trust"
+)
+
+var required = []string{"DB_URL=postgres://localhost/chirpy", "JWT_SECRET=secret", "MEDIA_SIGNING_SECRET=media"}
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := Load(nil, required)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Addr() != ":8080" || c.FileRoot != "." || c.LogFormat != "text" {
+		t.Errorf("Addr, FileRoot, LogFormat = %q, %q, %q, want :8080, ., text", c.Addr(), c.FileRoot, c.LogFormat)
+	}
+	if c.ReadHeaderTimeout != 10*time.Second || c.WriteTimeout != time.Minute || c.IdleTimeout != 2*time.Minute {
+		t.Errorf("ReadHeaderTimeout, WriteTimeout, IdleTimeout = %s, %s, %s, want 10s, 1m, 2m", c.ReadHeaderTimeout, c.WriteTimeout, c.IdleTimeout)
+	}
+	if c.ShutdownTimeout != 30*time.Second || c.MaxSessionsPerUser != 10 || c.MaxBodySize != 1<<20 || c.ProfanityMode != chirptext.MatchExact {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+	if c.CORSAllowedOrigins != nil {
+		t.Errorf("CORSAllowedOrigins = %q, want nil to keep the default policy", c.CORSAllowedOrigins)
+	}
+	if !c.Compression || c.CompressionMinSize != 1024 || c.CompressionTypes != nil {
+		t.Errorf("Compression, CompressionMinSize, CompressionTypes = %t, %d, %q, want true, 1024, nil", c.Compression, c.CompressionMinSize, c.CompressionTypes)
+	}
+	if !slices.Equal(c.ContentFilters, []string{"words"}) {
+		t.Errorf("ContentFilters = %q, want [words]", c.ContentFilters)
+	}
+}
+
+func TestFlagsOverrideEnvironment(t *testing.T) {
+	env := append([]string{"PORT=9000", "PLATFORM=prod", "SHUTDOWN_TIMEOUT=5s", "READ_ONLY=false"}, required...)
+	c, err := Load([]string{"-port", "9100", "-shutdown-timeout", "1m", "-read-only", "fsck", "--fix"}, env)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Port != "9100" || c.ShutdownTimeout != time.Minute {
+		t.Errorf("Port, ShutdownTimeout = %q, %s, want the flag values 9100, 1m0s", c.Port, c.ShutdownTimeout)
+	}
+	if !c.ReadOnly {
+		t.Error("ReadOnly = false, want the flag's true")
+	}
+	if c.Platform != "prod" {
+		t.Errorf("Platform = %q, want prod from the environment", c.Platform)
+	}
+	if strings.Join(c.Args, " ") != "fsck --fix" {
+		t.Errorf("Args = %q, want the command after the flags", c.Args)
+	}
+}
+
+func TestJWTKeys(t *testing.T) {
+	c, err := Load(nil, []string{"DB_URL=postgres://localhost/chirpy", "JWT_KEYS=2024-06:new,2024-01:old", "JWT_SECRET=legacy", "MEDIA_SIGNING_SECRET=media"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var ids []string
+	for _, k := range c.JWTKeys {
+		ids = append(ids, k.ID+"="+string(k.Secret))
+	}
+	if got := strings.Join(ids, ","); got != "2024-06=new,2024-01=old,=legacy" {
+		t.Errorf("JWTKeys = %s, want JWT_KEYS then JWT_SECRET without an ID", got)
+	}
+
+	_, err = Load(nil, []string{"DB_URL=postgres://localhost/chirpy", "JWT_KEYS=secret", "MEDIA_SIGNING_SECRET=media"})
+	if err == nil || !strings.Contains(err.Error(), "invalid JWT_KEYS") {
+		t.Errorf("Load() error = %v, want invalid JWT_KEYS", err)
+	}
+}
+
+func TestEventWebhook(t *testing.T) {
+	_, err := Load(nil, append([]string{"EVENT_BUS=webhook", "EVENT_WEBHOOK_URL=/hooks"}, required...))
+	if err == nil || !strings.Contains(err.Error(), "invalid EVENT_WEBHOOK_URL") {
+		t.Errorf("Load() error = %v, want invalid EVENT_WEBHOOK_URL", err)
+	}
+
+	c, err := Load(nil, append([]string{"EVENT_BUS=webhook", "EVENT_WEBHOOK_URL=https://hooks.example/chirpy"}, required...))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.EventWebhookURL != "https://hooks.example/chirpy" {
+		t.Errorf("EventWebhookURL = %q", c.EventWebhookURL)
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	_, err := Load(nil, []string{
+		"MAX_SESSIONS_PER_USER=0",
+		"RATE_LIMIT_WINDOW=soon",
+		"EVENT_BUS=kafka",
+		"PROFANITY_MATCH_MODE=fuzzy",
+		"LOGIN_MAX_TRAVEL_SPEED=0",
+		"REPORT_POLICY_RUDENESS=escalate=1",
+		"TRUST_BASIC=karma=5",
+		"RATE_LIMIT_TRUSTED_REQUESTS=10",
+		"READ_ONLY=maybe",
+		"CONTENT_FILTERS=words,regex,ai",
+		"MAX_BODY_SIZE=100",
+		"READ_HEADER_TIMEOUT=0s",
+		"WRITE_TIMEOUT=-1s",
+		"COMPRESSION_MIN_SIZE=-1",
+		"PUBLIC_URL=chirpy.example",
+		"USERNAME_CHANGE_INTERVAL=-24h",
+		"STREAM_BUFFER=0",
+	})
+	if err == nil {
+		t.Fatal("Load() error = nil, want errors")
+	}
+	for _, key := range []string{"DB_URL", "JWT_SECRET", "MAX_SESSIONS_PER_USER", "RATE_LIMIT_WINDOW", "KAFKA_REST_URL", "PROFANITY_MATCH_MODE", "LOGIN_MAX_TRAVEL_SPEED", "REPORT_POLICY_RUDENESS", "TRUST_BASIC", "RATE_LIMIT_TRUSTED_REQUESTS", "READ_ONLY", "CONTENT_FILTER_RULES_FILE", "CONTENT_FILTERS", "MAX_BODY_SIZE", "READ_HEADER_TIMEOUT", "WRITE_TIMEOUT", "COMPRESSION_MIN_SIZE", "PUBLIC_URL", "USERNAME_CHANGE_INTERVAL", "STREAM_BUFFER"} {
+		if !strings.Contains(err.Error(), "invalid "+key) {
+			t.Errorf("error doesn't mention %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestListsAndSchedules(t *testing.T) {
+	env := append([]string{
+		"CORS_ALLOWED_ORIGINS=https://a.example,https://b.example",
+		"METRICS_EXCLUDED_PATHS=",
+		"SCHEDULE_PURGE_DELETED_USERS=*/5 * * * *",
+		"REPORT_POLICY_SPAM=hide=4",
+		"TRUST_BASIC=age=72h,chirps=3",
+	}, required...)
+	c, err := Load(nil, env)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(c.CORSAllowedOrigins) != 2 {
+		t.Errorf("CORSAllowedOrigins = %q, want two origins", c.CORSAllowedOrigins)
+	}
+	if len(c.HitExclusions.Paths) != 0 || len(c.HitExclusions.UserAgents) == 0 {
+		t.Errorf("HitExclusions = %+v, want no paths and the default user agents", c.HitExclusions)
+	}
+	if got := c.TaskSchedule("purge_deleted_users", "@hourly"); got != "*/5 * * * *" {
+		t.Errorf("TaskSchedule(purge_deleted_users) = %q, want the override", got)
+	}
+	if got := c.TaskSchedule("flush_metrics", "* * * * *"); got != "* * * * *" {
+		t.Errorf("TaskSchedule(flush_metrics) = %q, want the default", got)
+	}
+	if got := c.ReportPolicies["spam"]; got != (reports.Policy{HideAfter: 4}) {
+		t.Errorf("ReportPolicies[spam] = %+v, want the override", got)
+	}
+	if got := c.ReportPolicies["violence"]; got != reports.DefaultPolicies["violence"] {
+		t.Errorf("ReportPolicies[violence] = %+v, want the default", got)
+	}
+	if reports.DefaultPolicies["spam"].HideAfter == 4 {
+		t.Error("the override changed reports.DefaultPolicies")
+	}
+	want := trust.Rules{Basic: trust.Requirement{MinAge: 72 * time.Hour, MinChirps: 3}, Trusted: trust.DefaultRules.Trusted}
+	if c.TrustRules != want {
+		t.Errorf("TrustRules = %+v, want %+v", c.TrustRules, want)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	c, err := Load(nil, append([]string{"AUTOCERT_HOSTS=chirpy.example", "PORT=443"}, required...))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !c.TLSEnabled() || c.RedirectAddr() != ":80" || c.AutocertCacheDir != "certs" {
+		t.Errorf("TLSEnabled, RedirectAddr, AutocertCacheDir = %t, %q, %q, want true, :80, certs", c.TLSEnabled(), c.RedirectAddr(), c.AutocertCacheDir)
+	}
+
+	c, err = Load([]string{"-tls-cert", "cert.pem", "-tls-key", "key.pem"}, required)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !c.TLSEnabled() || c.RedirectAddr() != "" {
+		t.Errorf("TLSEnabled, RedirectAddr = %t, %q, want true and no redirect listener", c.TLSEnabled(), c.RedirectAddr())
+	}
+
+	for _, env := range [][]string{
+		{"TLS_CERT_FILE=cert.pem"},
+		{"TLS_CERT_FILE=cert.pem", "TLS_KEY_FILE=key.pem", "AUTOCERT_HOSTS=chirpy.example"},
+		{"HTTP_REDIRECT_PORT=80"},
+		{"AUTOCERT_HOSTS=chirpy.example", "HTTP_REDIRECT_PORT=8080"},
+	} {
+		if _, err := Load(nil, append(env, required...)); err == nil {
+			t.Errorf("Load(%q) error = nil, want an error", env)
+		}
+	}
+}
+
+func TestOAuth(t *testing.T) {
+	env := []string{"GITHUB_CLIENT_ID=id", "GITHUB_CLIENT_SECRET=secret", "OAUTH_REDIRECT_BASE_URL=https://chirpy.example"}
+	if _, err := Load(nil, append(env, required...)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, env := range [][]string{
+		{"GOOGLE_CLIENT_ID=id", "OAUTH_REDIRECT_BASE_URL=https://chirpy.example"},
+		{"GITHUB_CLIENT_ID=id", "GITHUB_CLIENT_SECRET=secret"},
+		{"GITHUB_CLIENT_ID=id", "GITHUB_CLIENT_SECRET=secret", "OAUTH_REDIRECT_BASE_URL=chirpy.example"},
+	} {
+		if _, err := Load(nil, append(env, required...)); err == nil {
+			t.Errorf("Load(%q) error = nil, want an error", env)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	_, err := Load([]string{"-h"}, required)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(-h) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestMetricsAddr(t *testing.T) {
+	c, err := Load([]string{"-metrics-addr", "localhost:9090"}, required)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.MetricsAddr != "localhost:9090" {
+		t.Errorf("MetricsAddr = %q, want the flag value", c.MetricsAddr)
+	}
+
+	for _, addr := range []string{"9090", "localhost:", ":8080"} {
+		_, err := Load(nil, append([]string{"METRICS_ADDR=" + addr}, required...))
+		if err == nil || !strings.Contains(err.Error(), "invalid METRICS_ADDR") {
+			t.Errorf("Load(METRICS_ADDR=%s) error = %v, want invalid METRICS_ADDR", addr, err)
+		}
+	}
+}
+
+func TestMediaSigningSecret(t *testing.T) {
+	for _, env := range [][]string{
+		{"DB_URL=postgres://localhost/chirpy", "JWT_SECRET=secret"},
+		{"DB_URL=postgres://localhost/chirpy", "JWT_KEYS=2024-06:new,2024-01:old", "MEDIA_SIGNING_SECRET=old"},
+	} {
+		_, err := Load(nil, env)
+		if err == nil || !strings.Contains(err.Error(), "invalid MEDIA_SIGNING_SECRET") {
+			t.Errorf("Load(%q) error = %v, want invalid MEDIA_SIGNING_SECRET", env, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package contentfilter cleans chirp bodies before they are stored, so
+// deployments can choose their moderation backends in configuration.
+package contentfilter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ErrRejected is returned by filters that refuse a body outright rather
+// than cleaning it.
+var ErrRejected = errors.New("content rejected")
+
+// ContentFilter cleans a chirp body, returning the text to store, or an
+// error wrapping ErrRejected if it mustn't be posted at all.
+type ContentFilter interface {
+	Clean(ctx context.Context, body string) (string, error)
+}
+
+// Chain runs filters in order, each cleaning the previous one's output.
+type Chain []ContentFilter
+
+// Clean -
+func (c Chain) Clean(ctx context.Context, body string) (string, error) {
+	for _, f := range c {
+		var err error
+		body, err = f.Clean(ctx, body)
+		if err != nil {
+			return "", err
+		}
+	}
+	return body, nil
+}
+
+// Rule replaces every match of Pattern with Replacement, which may refer
+// to submatches as in regexp.Regexp.ReplaceAllString.
+type Rule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// Regex applies its rules in order.
+type Regex []Rule
+
+// Clean -
+func (rules Regex) Clean(ctx context.Context, body string) (string, error) {
+	for _, rule := range rules {
+		body = rule.Pattern.ReplaceAllString(body, rule.Replacement)
+	}
+	return body, nil
+}
+
+// ParseRules reads one rule per line, as "<pattern> => <replacement>".
+// Blank lines and lines starting with # are skipped.
+func ParseRules(r io.Reader) (Regex, error) {
+	var rules Regex
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, replacement, ok := strings.Cut(line, " => ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected <pattern> => <replacement>", n)
+		}
+		re, err := regexp.Compile(strings.TrimSpace(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rules = append(rules, Rule{Pattern: re, Replacement: strings.TrimSpace(replacement)})
+	}
+	return rules, scanner.Err()
+}
+
+// API asks an external moderation service. It POSTs {"text": body} to
+// URL and expects {"allowed": bool, "text": string} back, where text is
+// the cleaned body.
+type API struct {
+	URL    string
+	Client *http.Client
+}
+
+// Clean -
+func (a API) Clean(ctx context.Context, body string) (string, error) {
+	dat, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{body})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(dat))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return "", fmt.Errorf("moderation API responded with %s", resp.Status)
+	}
+
+	var verdict struct {
+		Allowed bool    `json:"allowed"`
+		Text    *string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return "", fmt.Errorf("decode moderation API response: %w", err)
+	}
+	if !verdict.Allowed {
+		return "", ErrRejected
+	}
+	if verdict.Text == nil {
+		return body, nil
+	}
+	return *verdict.Text, nil
+}
@@ -0,0 +1,88 @@
+package contentfilter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader(`
+# Phone numbers
+\d{3}-\d{4} => [number]
+
+(?i)buy now => buy later
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	upper := filterFunc(func(ctx context.Context, body string) (string, error) { return strings.ToUpper(body), nil })
+
+	got, err := Chain{rules, upper}.Clean(context.Background(), "Buy Now at 555-1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "BUY LATER AT [NUMBER]"; got != want {
+		t.Errorf("Clean = %q, want %q", got, want)
+	}
+
+	reject := filterFunc(func(ctx context.Context, body string) (string, error) { return "", ErrRejected })
+	if _, err := (Chain{reject, upper}).Clean(context.Background(), "hi"); !errors.Is(err, ErrRejected) {
+		t.Errorf("Clean error = %v, want ErrRejected", err)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	for _, in := range []string{"no arrow", "( => x"} {
+		if _, err := ParseRules(strings.NewReader(in)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("ParseRules(%q) error = %v, want one on line 1", in, err)
+		}
+	}
+}
+
+func TestAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Text {
+		case "spam":
+			w.Write([]byte(`{"allowed": false}`))
+		case "fine":
+			w.Write([]byte(`{"allowed": true}`))
+		case "down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"allowed": true, "text": "cleaned"}`))
+		}
+	}))
+	defer srv.Close()
+	api := API{URL: srv.URL, Client: srv.Client()}
+
+	tests := []struct {
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{body: "fine", want: "fine"},
+		{body: "rude", want: "cleaned"},
+		{body: "spam", wantErr: true},
+		{body: "down", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := api.Clean(context.Background(), tt.body)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Clean(%q) = %q, %v, want %q, error %t", tt.body, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if _, err := api.Clean(context.Background(), "spam"); !errors.Is(err, ErrRejected) {
+		t.Errorf("Clean(spam) error = %v, want ErrRejected", err)
+	}
+}
+
+type filterFunc func(ctx context.Context, body string) (string, error)
+
+func (f filterFunc) Clean(ctx context.Context, body string) (string, error) { return f(ctx, body) }
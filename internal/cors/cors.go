@@ -0,0 +1,128 @@
+// Package cors lets browsers call the API from other origins, following
+// the Fetch standard's CORS protocol.
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy says which cross-origin requests are allowed. The API
+// authenticates with the Authorization header rather than cookies, so
+// credentialed requests are never allowed and "*" is a safe origin.
+type Policy struct {
+	// Origins are allowed origins, e.g. "https://chirpy.example", or "*"
+	// for any. No origins disables CORS.
+	Origins []string
+	// Methods and Headers are what preflight requests may ask for.
+	Methods []string
+	Headers []string
+	// ExposedHeaders are the response headers scripts may read.
+	ExposedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// DefaultPolicy allows what the API uses, but no origins until some are
+// configured.
+var DefaultPolicy = Policy{
+	Methods: []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete},
+	Headers: []string{"Authorization", "Content-Type", "If-Unmodified-Since", "If-Modified-Since", "If-None-Match", "X-Request-ID"},
+	ExposedHeaders: []string{
+		"X-Total-Count", "X-Limit", "X-Offset",
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+		"ETag", "Last-Modified", "Location", "Deprecation", "Link", "X-Request-ID",
+	},
+	MaxAge: 10 * time.Minute,
+}
+
+func (p Policy) allowedOrigin(origin string) (string, bool) {
+	for _, o := range p.Origins {
+		if o == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+func (p Policy) allowsMethod(method string) bool {
+	for _, m := range p.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHeaders checks the comma-separated Access-Control-Request-Headers
+// of a preflight.
+func (p Policy) allowsHeaders(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		allowed := false
+		for _, a := range p.Headers {
+			if strings.EqualFold(a, h) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler applies the policy to requests whose path starts with prefix
+// and passes everything else through. Preflight requests are answered
+// here; other requests from allowed origins get the CORS response
+// headers and go on to next.
+func (p Policy) Handler(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if !strings.HasPrefix(r.URL.Path, prefix) || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		allowOrigin, ok := p.allowedOrigin(origin)
+
+		reqMethod := r.Header.Get("Access-Control-Request-Method")
+		if r.Method == http.MethodOptions && reqMethod != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			reqHeaders := r.Header.Get("Access-Control-Request-Headers")
+			// A preflight without the Allow headers tells the browser no
+			if ok && p.allowsMethod(reqMethod) && p.allowsHeaders(reqHeaders) {
+				h.Set("Access-Control-Allow-Origin", allowOrigin)
+				h.Set("Access-Control-Allow-Methods", strings.Join(p.Methods, ", "))
+				if len(p.Headers) > 0 {
+					h.Set("Access-Control-Allow-Headers", strings.Join(p.Headers, ", "))
+				}
+				if p.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if ok {
+			h.Set("Access-Control-Allow-Origin", allowOrigin)
+			if len(p.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(p.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,179 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	policy := DefaultPolicy
+	policy.Origins = []string{"https://app.example"}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		headers     map[string]string
+		wantCode    int
+		wantNext    bool
+		wantOrigin  string
+		wantMethods bool
+		wantExpose  bool
+	}{
+		{
+			name:     "Same origin",
+			method:   http.MethodGet,
+			path:     "/api/chirps",
+			wantCode: http.StatusOK,
+			wantNext: true,
+		},
+		{
+			name:       "Allowed origin",
+			method:     http.MethodGet,
+			path:       "/api/chirps",
+			headers:    map[string]string{"Origin": "https://app.example"},
+			wantCode:   http.StatusOK,
+			wantNext:   true,
+			wantOrigin: "https://app.example",
+			wantExpose: true,
+		},
+		{
+			name:     "Other origin",
+			method:   http.MethodGet,
+			path:     "/api/chirps",
+			headers:  map[string]string{"Origin": "https://evil.example"},
+			wantCode: http.StatusOK,
+			wantNext: true,
+		},
+		{
+			name:     "Outside prefix",
+			method:   http.MethodGet,
+			path:     "/admin/metrics",
+			headers:  map[string]string{"Origin": "https://app.example"},
+			wantCode: http.StatusOK,
+			wantNext: true,
+		},
+		{
+			name:   "Preflight",
+			method: http.MethodOptions,
+			path:   "/api/chirps/123",
+			headers: map[string]string{
+				"Origin":                         "https://app.example",
+				"Access-Control-Request-Method":  "PUT",
+				"Access-Control-Request-Headers": "authorization, content-type",
+			},
+			wantCode:    http.StatusNoContent,
+			wantOrigin:  "https://app.example",
+			wantMethods: true,
+		},
+		{
+			name:   "Preflight from other origin",
+			method: http.MethodOptions,
+			path:   "/api/chirps",
+			headers: map[string]string{
+				"Origin":                        "https://evil.example",
+				"Access-Control-Request-Method": "POST",
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "Preflight for disallowed method",
+			method: http.MethodOptions,
+			path:   "/api/chirps",
+			headers: map[string]string{
+				"Origin":                        "https://app.example",
+				"Access-Control-Request-Method": "PATCH",
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "Preflight for disallowed header",
+			method: http.MethodOptions,
+			path:   "/api/chirps",
+			headers: map[string]string{
+				"Origin":                         "https://app.example",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "x-secret",
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "Plain OPTIONS request",
+			method:   http.MethodOptions,
+			path:     "/api/chirps",
+			headers:  map[string]string{"Origin": "https://app.example"},
+			wantCode: http.StatusOK,
+			wantNext: true,
+			// Not a preflight, so it is an ordinary cross-origin request
+			wantOrigin: "https://app.example",
+			wantExpose: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			policy.Handler("/api/", next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if called != tt.wantNext {
+				t.Errorf("next called = %v, want %v", called, tt.wantNext)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods") != ""; got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods set = %v, want %v", got, tt.wantMethods)
+			}
+			if got := rec.Header().Get("Access-Control-Expose-Headers") != ""; got != tt.wantExpose {
+				t.Errorf("Access-Control-Expose-Headers set = %v, want %v", got, tt.wantExpose)
+			}
+			if _, ok := tt.headers["Origin"]; ok && tt.path != "/admin/metrics" && rec.Header().Get("Vary") == "" {
+				t.Error("Vary not set on a cross-origin response")
+			}
+		})
+	}
+}
+
+func TestWildcardOrigin(t *testing.T) {
+	policy := DefaultPolicy
+	policy.Origins = []string{"*"}
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/chirps", nil)
+	req.Header.Set("Origin", "https://anywhere.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	policy.Handler("/api/", http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Access-Control-Max-Age = %q, want 600", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want unset", got)
+	}
+}
+
+func TestNoOriginsConfigured(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+	req.Header.Set("Origin", "https://app.example")
+	rec := httptest.NewRecorder()
+	DefaultPolicy.Handler("/api/", http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want unset", got)
+	}
+}
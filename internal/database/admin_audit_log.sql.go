@@ -0,0 +1,86 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: admin_audit_log.sql
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const createAuditLogEntry = `-- name: CreateAuditLogEntry :one
+INSERT INTO admin_audit_log (id, created_at, admin_id, action, target_id, details)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+RETURNING id, created_at, admin_id, action, target_id, details
+`
+
+type CreateAuditLogEntryParams struct {
+	AdminID  uuid.NullUUID
+	Action   string
+	TargetID uuid.UUID
+	Details  json.RawMessage
+}
+
+func (q *Queries) CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) (AdminAuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditLogEntry,
+		arg.AdminID,
+		arg.Action,
+		arg.TargetID,
+		arg.Details,
+	)
+	var i AdminAuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AdminID,
+		&i.Action,
+		&i.TargetID,
+		&i.Details,
+	)
+	return i, err
+}
+
+const listAuditLog = `-- name: ListAuditLog :many
+SELECT id, created_at, admin_id, action, target_id, details FROM admin_audit_log
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListAuditLogParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AdminAuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLog, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AdminAuditLog
+	for rows.Next() {
+		var i AdminAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.AdminID,
+			&i.Action,
+			&i.TargetID,
+			&i.Details,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
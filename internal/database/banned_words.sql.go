@@ -0,0 +1,65 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: banned_words.sql
+
+package database
+
+import (
+	"context"
+)
+
+const addBannedWord = `-- name: AddBannedWord :execrows
+INSERT INTO banned_words (word, created_at)
+VALUES ($1, NOW())
+ON CONFLICT (word) DO NOTHING
+`
+
+func (q *Queries) AddBannedWord(ctx context.Context, word string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addBannedWord, word)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBannedWord = `-- name: DeleteBannedWord :execrows
+DELETE FROM banned_words
+WHERE word = $1
+`
+
+func (q *Queries) DeleteBannedWord(ctx context.Context, word string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBannedWord, word)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listBannedWords = `-- name: ListBannedWords :many
+SELECT word FROM banned_words
+ORDER BY word
+`
+
+func (q *Queries) ListBannedWords(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listBannedWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, err
+		}
+		items = append(items, word)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,146 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: chirp_images.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const chirpImageExists = `-- name: ChirpImageExists :one
+SELECT EXISTS (SELECT 1 FROM chirp_images WHERE path = $1)
+`
+
+func (q *Queries) ChirpImageExists(ctx context.Context, path string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, chirpImageExists, path)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const deleteChirpImagesByUser = `-- name: DeleteChirpImagesByUser :many
+DELETE FROM chirp_images
+USING chirps
+WHERE chirps.id = chirp_images.chirp_id
+AND chirps.user_id = $1
+RETURNING chirp_images.path
+`
+
+// Runs before the user's chirps are deleted, to find the files to remove.
+func (q *Queries) DeleteChirpImagesByUser(ctx context.Context, userID uuid.NullUUID) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, deleteChirpImagesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		items = append(items, path)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpImage = `-- name: GetChirpImage :one
+SELECT chirp_id, created_at, path, followers_only FROM chirp_images
+WHERE chirp_id = $1
+`
+
+func (q *Queries) GetChirpImage(ctx context.Context, chirpID uuid.UUID) (ChirpImage, error) {
+	row := q.db.QueryRowContext(ctx, getChirpImage, chirpID)
+	var i ChirpImage
+	err := row.Scan(
+		&i.ChirpID,
+		&i.CreatedAt,
+		&i.Path,
+		&i.FollowersOnly,
+	)
+	return i, err
+}
+
+const listChirpImages = `-- name: ListChirpImages :many
+SELECT chirp_images.chirp_id, chirp_images.created_at, chirp_images.path, chirp_images.followers_only, chirps.user_id
+FROM chirp_images
+JOIN chirps ON chirps.id = chirp_images.chirp_id
+WHERE chirp_images.chirp_id = ANY($1::uuid[])
+`
+
+type ListChirpImagesRow struct {
+	ChirpID       uuid.UUID
+	CreatedAt     time.Time
+	Path          string
+	FollowersOnly bool
+	UserID        uuid.NullUUID
+}
+
+func (q *Queries) ListChirpImages(ctx context.Context, chirpIds []uuid.UUID) ([]ListChirpImagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpImages, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChirpImagesRow
+	for rows.Next() {
+		var i ListChirpImagesRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.CreatedAt,
+			&i.Path,
+			&i.FollowersOnly,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setChirpImage = `-- name: SetChirpImage :one
+INSERT INTO chirp_images (chirp_id, created_at, path, followers_only)
+VALUES ($1, NOW(), $2, $3)
+ON CONFLICT (chirp_id) DO UPDATE
+SET created_at = NOW(),
+    path = EXCLUDED.path,
+    followers_only = EXCLUDED.followers_only
+RETURNING chirp_id, created_at, path, followers_only
+`
+
+type SetChirpImageParams struct {
+	ChirpID       uuid.UUID
+	Path          string
+	FollowersOnly bool
+}
+
+func (q *Queries) SetChirpImage(ctx context.Context, arg SetChirpImageParams) (ChirpImage, error) {
+	row := q.db.QueryRowContext(ctx, setChirpImage, arg.ChirpID, arg.Path, arg.FollowersOnly)
+	var i ChirpImage
+	err := row.Scan(
+		&i.ChirpID,
+		&i.CreatedAt,
+		&i.Path,
+		&i.FollowersOnly,
+	)
+	return i, err
+}
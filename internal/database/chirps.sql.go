@@ -7,29 +7,109 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpsByAuthor = `-- name: CountChirpsByAuthor :one
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT count(*)
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+`
+
+func (q *Queries) CountChirpsByAuthor(ctx context.Context, postedBy uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByAuthor, postedBy)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countChirpsByUser = `-- name: CountChirpsByUser :one
+SELECT count(*) FROM chirps
+WHERE user_id = $1
+`
+
+func (q *Queries) CountChirpsByUser(ctx context.Context, userID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countReplies = `-- name: CountReplies :one
+SELECT count(*) FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = $1
+AND users.deleted_at IS NULL
+`
+
+func (q *Queries) CountReplies(ctx context.Context, parentChirpID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countReplies, parentChirpID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countVisibleChirps = `-- name: CountVisibleChirps :one
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT count(*)
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+`
+
+func (q *Queries) CountVisibleChirps(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countVisibleChirps)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_chirp_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_chirp_id, legal_hold_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.NullUUID
+	Body          string
+	UserID        uuid.NullUUID
+	ParentChirpID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID, arg.ParentChirpID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +117,9 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentChirpID,
+		&i.LegalHoldAt,
 	)
 	return i, err
 }
@@ -51,9 +134,49 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteChirpsByUsers = `-- name: DeleteChirpsByUsers :many
+DELETE FROM chirps
+WHERE user_id = ANY($1::uuid[])
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_chirp_id, legal_hold_at
+`
+
+func (q *Queries) DeleteChirpsByUsers(ctx context.Context, userIds []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, deleteChirpsByUsers, pq.Array(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+LEFT JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = $1
+AND users.deleted_at IS NULL
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -65,17 +188,449 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentChirpID,
+		&i.LegalHoldAt,
 	)
 	return i, err
 }
 
+const getChirpAuthorUsernames = `-- name: GetChirpAuthorUsernames :many
+SELECT chirps.id, users.username FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = ANY($1::uuid[])
+AND users.username IS NOT NULL
+`
+
+type GetChirpAuthorUsernamesRow struct {
+	ID       uuid.UUID
+	Username sql.NullString
+}
+
+func (q *Queries) GetChirpAuthorUsernames(ctx context.Context, chirpIds []uuid.UUID) ([]GetChirpAuthorUsernamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAuthorUsernames, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpAuthorUsernamesRow
+	for rows.Next() {
+		var i GetChirpAuthorUsernamesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at ASC
+LIMIT $1 OFFSET $2
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetChirpsRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	RechirpID     uuid.NullUUID
+	PostedBy      uuid.NullUUID
+	PostedAt      time.Time
+}
+
+func (q *Queries) GetChirps(ctx context.Context, arg GetChirpsParams) ([]GetChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirps, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsRow
+	for rows.Next() {
+		var i GetChirpsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.RechirpID,
+			&i.PostedBy,
+			&i.PostedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByAuthor = `-- name: GetChirpsByAuthor :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at ASC
+LIMIT $2 OFFSET $3
+`
+
+type GetChirpsByAuthorParams struct {
+	PostedBy uuid.NullUUID
+	Limit    int32
+	Offset   int32
+}
+
+type GetChirpsByAuthorRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	RechirpID     uuid.NullUUID
+	PostedBy      uuid.NullUUID
+	PostedAt      time.Time
+}
+
+func (q *Queries) GetChirpsByAuthor(ctx context.Context, arg GetChirpsByAuthorParams) ([]GetChirpsByAuthorRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, arg.PostedBy, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsByAuthorRow
+	for rows.Next() {
+		var i GetChirpsByAuthorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.RechirpID,
+			&i.PostedBy,
+			&i.PostedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByAuthorDesc = `-- name: GetChirpsByAuthorDesc :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetChirpsByAuthorDescParams struct {
+	PostedBy uuid.NullUUID
+	Limit    int32
+	Offset   int32
+}
+
+type GetChirpsByAuthorDescRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	RechirpID     uuid.NullUUID
+	PostedBy      uuid.NullUUID
+	PostedAt      time.Time
+}
+
+func (q *Queries) GetChirpsByAuthorDesc(ctx context.Context, arg GetChirpsByAuthorDescParams) ([]GetChirpsByAuthorDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorDesc, arg.PostedBy, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsByAuthorDescRow
+	for rows.Next() {
+		var i GetChirpsByAuthorDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.RechirpID,
+			&i.PostedBy,
+			&i.PostedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type GetChirpsDescParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetChirpsDescRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	RechirpID     uuid.NullUUID
+	PostedBy      uuid.NullUUID
+	PostedAt      time.Time
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]GetChirpsDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsDescRow
+	for rows.Next() {
+		var i GetChirpsDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.RechirpID,
+			&i.PostedBy,
+			&i.PostedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplies = `-- name: GetReplies :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at ASC
+LIMIT $2 OFFSET $3
+`
+
+type GetRepliesParams struct {
+	ParentChirpID uuid.NullUUID
+	Limit         int32
+	Offset        int32
+}
+
+func (q *Queries) GetReplies(ctx context.Context, arg GetRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getReplies, arg.ParentChirpID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplyCounts = `-- name: GetReplyCounts :many
+SELECT chirps.parent_chirp_id, count(*) AS reply_count FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = ANY($1::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY chirps.parent_chirp_id
+`
+
+type GetReplyCountsRow struct {
+	ParentChirpID uuid.NullUUID
+	ReplyCount    int64
+}
+
+func (q *Queries) GetReplyCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetReplyCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReplyCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReplyCountsRow
+	for rows.Next() {
+		var i GetReplyCountsRow
+		if err := rows.Scan(
+			&i.ParentChirpID,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByUser = `-- name: ListChirpsByUser :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_chirp_id, legal_hold_at FROM chirps
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListChirpsByUserParams struct {
+	UserID uuid.NullUUID
+	Limit  int32
+	Offset int32
+}
+
+// All of a user's chirps, newest first, even if the account was deleted.
+func (q *Queries) ListChirpsByUser(ctx context.Context, arg ListChirpsByUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByUser, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +644,9 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
 		); err != nil {
 			return nil, err
 		}
@@ -102,3 +660,31 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_chirp_id, legal_hold_at
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID
+	Body string
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentChirpID,
+		&i.LegalHoldAt,
+	)
+	return i, err
+}
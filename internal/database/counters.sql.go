@@ -0,0 +1,71 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: counters.sql
+
+package database
+
+import (
+	"context"
+)
+
+const addToCounter = `-- name: AddToCounter :one
+INSERT INTO counters (name, value, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (name) DO UPDATE
+SET value = counters.value + EXCLUDED.value,
+    updated_at = NOW()
+RETURNING value
+`
+
+type AddToCounterParams struct {
+	Name  string
+	Value int64
+}
+
+func (q *Queries) AddToCounter(ctx context.Context, arg AddToCounterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addToCounter, arg.Name, arg.Value)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getCounters = `-- name: GetCounters :many
+SELECT name, value, updated_at FROM counters
+`
+
+func (q *Queries) GetCounters(ctx context.Context) ([]Counter, error) {
+	rows, err := q.db.QueryContext(ctx, getCounters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Counter
+	for rows.Next() {
+		var i Counter
+		if err := rows.Scan(
+			&i.Name,
+			&i.Value,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetCounters = `-- name: ResetCounters :exec
+DELETE FROM counters
+`
+
+func (q *Queries) ResetCounters(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetCounters)
+	return err
+}
@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: device_tokens.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const deleteDeviceToken = `-- name: DeleteDeviceToken :exec
+DELETE FROM device_tokens WHERE token = $1
+`
+
+func (q *Queries) DeleteDeviceToken(ctx context.Context, token string) error {
+	_, err := q.db.ExecContext(ctx, deleteDeviceToken, token)
+	return err
+}
+
+const getActiveDeviceTokens = `-- name: GetActiveDeviceTokens :many
+SELECT device_tokens.token, device_tokens.platform FROM device_tokens
+JOIN refresh_tokens ON refresh_tokens.token = device_tokens.refresh_token
+WHERE device_tokens.user_id = $1
+AND refresh_tokens.revoked_at IS NULL
+AND refresh_tokens.expires_at > NOW()
+ORDER BY device_tokens.created_at
+`
+
+type GetActiveDeviceTokensRow struct {
+	Token    string
+	Platform string
+}
+
+// Devices of sessions that haven't ended
+func (q *Queries) GetActiveDeviceTokens(ctx context.Context, userID uuid.UUID) ([]GetActiveDeviceTokensRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveDeviceTokens, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveDeviceTokensRow
+	for rows.Next() {
+		var i GetActiveDeviceTokensRow
+		if err := rows.Scan(
+			&i.Token,
+			&i.Platform,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveDeviceTokens = `-- name: MoveDeviceTokens :exec
+UPDATE device_tokens SET refresh_token = $1,
+updated_at = NOW()
+WHERE refresh_token = $2
+`
+
+type MoveDeviceTokensParams struct {
+	NewRefreshToken string
+	OldRefreshToken string
+}
+
+func (q *Queries) MoveDeviceTokens(ctx context.Context, arg MoveDeviceTokensParams) error {
+	_, err := q.db.ExecContext(ctx, moveDeviceTokens, arg.NewRefreshToken, arg.OldRefreshToken)
+	return err
+}
+
+const registerDeviceToken = `-- name: RegisterDeviceToken :exec
+INSERT INTO device_tokens (token, created_at, updated_at, user_id, platform, refresh_token)
+VALUES ($1, NOW(), NOW(), $2, $3, $4)
+ON CONFLICT (token) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    refresh_token = EXCLUDED.refresh_token
+`
+
+type RegisterDeviceTokenParams struct {
+	Token        string
+	UserID       uuid.UUID
+	Platform     string
+	RefreshToken string
+}
+
+func (q *Queries) RegisterDeviceToken(ctx context.Context, arg RegisterDeviceTokenParams) error {
+	_, err := q.db.ExecContext(ctx, registerDeviceToken,
+		arg.Token,
+		arg.UserID,
+		arg.Platform,
+		arg.RefreshToken,
+	)
+	return err
+}
+
+const unregisterDeviceToken = `-- name: UnregisterDeviceToken :execrows
+DELETE FROM device_tokens WHERE token = $1 AND user_id = $2
+`
+
+type UnregisterDeviceTokenParams struct {
+	Token  string
+	UserID uuid.UUID
+}
+
+func (q *Queries) UnregisterDeviceToken(ctx context.Context, arg UnregisterDeviceTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unregisterDeviceToken, arg.Token, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
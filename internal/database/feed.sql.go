@@ -0,0 +1,308 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countFollowedChirpsSince = `-- name: CountFollowedChirpsSince :one
+SELECT count(*) FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+JOIN users ON users.id = chirps.user_id
+WHERE follows.follower_id = $1
+AND chirps.created_at > $2
+AND users.deleted_at IS NULL
+`
+
+type CountFollowedChirpsSinceParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountFollowedChirpsSince(ctx context.Context, arg CountFollowedChirpsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFollowedChirpsSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countNewFollowersSince = `-- name: CountNewFollowersSince :one
+SELECT count(*) FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = $1
+AND follows.created_at > $2
+AND users.deleted_at IS NULL
+`
+
+type CountNewFollowersSinceParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountNewFollowersSince(ctx context.Context, arg CountNewFollowersSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNewFollowersSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getFeedCandidates = `-- name: GetFeedCandidates :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at,
+    (follows.follower_id IS NOT NULL)::boolean AS followed
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+LEFT JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = $1
+WHERE chirps.created_at > $2
+AND chirps.created_at <= $3
+AND chirps.user_id <> $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT $4
+`
+
+type GetFeedCandidatesParams struct {
+	UserID        uuid.UUID
+	Since         time.Time
+	AsOf          time.Time
+	MaxCandidates int32
+}
+
+type GetFeedCandidatesRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	Followed      bool
+}
+
+func (q *Queries) GetFeedCandidates(ctx context.Context, arg GetFeedCandidatesParams) ([]GetFeedCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedCandidates,
+		arg.UserID,
+		arg.Since,
+		arg.AsOf,
+		arg.MaxCandidates,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedCandidatesRow
+	for rows.Next() {
+		var i GetFeedCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.Followed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedLatest = `-- name: GetFeedLatest :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at,
+    (follows.follower_id IS NOT NULL)::boolean AS followed
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+LEFT JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = $1
+WHERE (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+AND chirps.user_id <> $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type GetFeedLatestParams struct {
+	UserID          uuid.UUID
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	PageSize        int32
+}
+
+type GetFeedLatestRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	Followed      bool
+}
+
+func (q *Queries) GetFeedLatest(ctx context.Context, arg GetFeedLatestParams) ([]GetFeedLatestRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedLatest,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedLatestRow
+	for rows.Next() {
+		var i GetFeedLatestRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.Followed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedHighlightsSince = `-- name: GetFollowedHighlightsSince :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+JOIN users ON users.id = chirps.user_id
+WHERE follows.follower_id = $1
+AND chirps.created_at > $2
+AND users.deleted_at IS NULL
+ORDER BY (
+    SELECT count(*) FROM likes
+    JOIN users AS likers ON likers.id = likes.user_id
+    WHERE likes.chirp_id = chirps.id
+    AND likers.deleted_at IS NULL
+) DESC,
+    chirps.created_at DESC
+LIMIT $3
+`
+
+type GetFollowedHighlightsSinceParams struct {
+	UserID        uuid.UUID
+	Since         time.Time
+	MaxHighlights int32
+}
+
+func (q *Queries) GetFollowedHighlightsSince(ctx context.Context, arg GetFollowedHighlightsSinceParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedHighlightsSince, arg.UserID, arg.Since, arg.MaxHighlights)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHomeFeed = `-- name: GetHomeFeed :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = $1
+JOIN users ON users.id = chirps.user_id
+WHERE (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type GetHomeFeedParams struct {
+	UserID          uuid.UUID
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	PageSize        int32
+}
+
+func (q *Queries) GetHomeFeed(ctx context.Context, arg GetHomeFeedParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getHomeFeed,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
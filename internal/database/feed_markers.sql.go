@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_markers.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getFeedMarker = `-- name: GetFeedMarker :one
+SELECT user_id, timeline, chirp_id, chirp_created_at, updated_at FROM feed_markers
+WHERE user_id = $1
+AND timeline = $2
+`
+
+type GetFeedMarkerParams struct {
+	UserID   uuid.UUID
+	Timeline string
+}
+
+func (q *Queries) GetFeedMarker(ctx context.Context, arg GetFeedMarkerParams) (FeedMarker, error) {
+	row := q.db.QueryRowContext(ctx, getFeedMarker, arg.UserID, arg.Timeline)
+	var i FeedMarker
+	err := row.Scan(
+		&i.UserID,
+		&i.Timeline,
+		&i.ChirpID,
+		&i.ChirpCreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getFeedMarkers = `-- name: GetFeedMarkers :many
+SELECT user_id, timeline, chirp_id, chirp_created_at, updated_at FROM feed_markers
+WHERE user_id = $1
+ORDER BY timeline ASC
+`
+
+func (q *Queries) GetFeedMarkers(ctx context.Context, userID uuid.UUID) ([]FeedMarker, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedMarkers, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedMarker
+	for rows.Next() {
+		var i FeedMarker
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Timeline,
+			&i.ChirpID,
+			&i.ChirpCreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertFeedMarker = `-- name: UpsertFeedMarker :one
+INSERT INTO feed_markers (user_id, timeline, chirp_id, chirp_created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+ON CONFLICT (user_id, timeline) DO UPDATE
+SET chirp_id = EXCLUDED.chirp_id,
+    chirp_created_at = EXCLUDED.chirp_created_at,
+    updated_at = NOW()
+WHERE feed_markers.chirp_created_at < EXCLUDED.chirp_created_at
+RETURNING user_id, timeline, chirp_id, chirp_created_at, updated_at
+`
+
+type UpsertFeedMarkerParams struct {
+	UserID         uuid.UUID
+	Timeline       string
+	ChirpID        uuid.UUID
+	ChirpCreatedAt time.Time
+}
+
+func (q *Queries) UpsertFeedMarker(ctx context.Context, arg UpsertFeedMarkerParams) (FeedMarker, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeedMarker,
+		arg.UserID,
+		arg.Timeline,
+		arg.ChirpID,
+		arg.ChirpCreatedAt,
+	)
+	var i FeedMarker
+	err := row.Scan(
+		&i.UserID,
+		&i.Timeline,
+		&i.ChirpID,
+		&i.ChirpCreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
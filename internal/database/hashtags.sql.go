@@ -0,0 +1,113 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: hashtags.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countChirpsByHashtag = `-- name: CountChirpsByHashtag :one
+SELECT count(*) FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_hashtags.tag = $1
+AND users.deleted_at IS NULL
+`
+
+func (q *Queries) CountChirpsByHashtag(ctx context.Context, tag string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsByHashtag, tag)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createHashtags = `-- name: CreateHashtags :exec
+INSERT INTO hashtags (tag, created_at)
+SELECT unnest($1::text[]), NOW()
+ON CONFLICT (tag) DO NOTHING
+`
+
+func (q *Queries) CreateHashtags(ctx context.Context, tags []string) error {
+	_, err := q.db.ExecContext(ctx, createHashtags, pq.Array(tags))
+	return err
+}
+
+const getChirpsByHashtag = `-- name: GetChirpsByHashtag :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_hashtags.tag = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetChirpsByHashtagParams struct {
+	Tag    string
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetChirpsByHashtag(ctx context.Context, arg GetChirpsByHashtagParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByHashtag, arg.Tag, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tagChirp = `-- name: TagChirp :exec
+INSERT INTO chirp_hashtags (chirp_id, tag)
+SELECT $1::uuid, unnest($2::text[])
+ON CONFLICT DO NOTHING
+`
+
+type TagChirpParams struct {
+	ChirpID uuid.UUID
+	Tags    []string
+}
+
+func (q *Queries) TagChirp(ctx context.Context, arg TagChirpParams) error {
+	_, err := q.db.ExecContext(ctx, tagChirp, arg.ChirpID, pq.Array(arg.Tags))
+	return err
+}
+
+const untagChirp = `-- name: UntagChirp :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1
+`
+
+func (q *Queries) UntagChirp(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, untagChirp, chirpID)
+	return err
+}
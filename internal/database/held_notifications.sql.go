@@ -0,0 +1,103 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: held_notifications.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const holdNotification = `-- name: HoldNotification :exec
+INSERT INTO held_notifications (id, created_at, user_id, channel, event, actor_id, chirp_id, text)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5, $6)
+`
+
+type HoldNotificationParams struct {
+	UserID  uuid.UUID
+	Channel string
+	Event   string
+	ActorID uuid.UUID
+	ChirpID uuid.NullUUID
+	Text    string
+}
+
+func (q *Queries) HoldNotification(ctx context.Context, arg HoldNotificationParams) error {
+	_, err := q.db.ExecContext(ctx, holdNotification,
+		arg.UserID,
+		arg.Channel,
+		arg.Event,
+		arg.ActorID,
+		arg.ChirpID,
+		arg.Text,
+	)
+	return err
+}
+
+const listUsersWithHeldNotifications = `-- name: ListUsersWithHeldNotifications :many
+SELECT DISTINCT user_id FROM held_notifications
+`
+
+func (q *Queries) ListUsersWithHeldNotifications(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersWithHeldNotifications)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseHeldNotifications = `-- name: ReleaseHeldNotifications :many
+DELETE FROM held_notifications
+WHERE user_id = $1
+RETURNING id, created_at, user_id, channel, event, actor_id, chirp_id, text
+`
+
+func (q *Queries) ReleaseHeldNotifications(ctx context.Context, userID uuid.UUID) ([]HeldNotification, error) {
+	rows, err := q.db.QueryContext(ctx, releaseHeldNotifications, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HeldNotification
+	for rows.Next() {
+		var i HeldNotification
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Channel,
+			&i.Event,
+			&i.ActorID,
+			&i.ChirpID,
+			&i.Text,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
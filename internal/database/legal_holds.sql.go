@@ -0,0 +1,68 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: legal_holds.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const placeChirpLegalHold = `-- name: PlaceChirpLegalHold :execrows
+UPDATE chirps
+SET legal_hold_at = COALESCE(legal_hold_at, NOW())
+WHERE id = $1
+`
+
+func (q *Queries) PlaceChirpLegalHold(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, placeChirpLegalHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const placeUserLegalHold = `-- name: PlaceUserLegalHold :execrows
+UPDATE users
+SET legal_hold_at = COALESCE(legal_hold_at, NOW())
+WHERE id = $1
+`
+
+func (q *Queries) PlaceUserLegalHold(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, placeUserLegalHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseChirpLegalHold = `-- name: ReleaseChirpLegalHold :execrows
+UPDATE chirps
+SET legal_hold_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleaseChirpLegalHold(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, releaseChirpLegalHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseUserLegalHold = `-- name: ReleaseUserLegalHold :execrows
+UPDATE users
+SET legal_hold_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleaseUserLegalHold(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, releaseUserLegalHold, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
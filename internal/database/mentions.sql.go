@@ -0,0 +1,139 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: mentions.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const clearMentions = `-- name: ClearMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1
+`
+
+func (q *Queries) ClearMentions(ctx context.Context, chirpID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearMentions, chirpID)
+	return err
+}
+
+const countMentions = `-- name: CountMentions :one
+SELECT count(*) FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_mentions.user_id = $1
+AND users.deleted_at IS NULL
+`
+
+func (q *Queries) CountMentions(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countMentions, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getMentionedUserIDs = `-- name: GetMentionedUserIDs :many
+SELECT user_id FROM chirp_mentions
+WHERE chirp_id = $1
+`
+
+func (q *Queries) GetMentionedUserIDs(ctx context.Context, chirpID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getMentionedUserIDs, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMentions = `-- name: GetMentions :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_mentions.user_id = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetMentionsParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetMentions(ctx context.Context, arg GetMentionsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getMentions, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mentionUsers = `-- name: MentionUsers :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT $1::uuid, users.id FROM users
+WHERE (
+    users.username = ANY($2::text[])
+    OR users.id IN (
+        SELECT user_id FROM username_redirects
+        WHERE username = ANY($2::text[])
+        AND (expires_at IS NULL OR expires_at > NOW())
+    )
+)
+AND users.deleted_at IS NULL
+ON CONFLICT DO NOTHING
+`
+
+type MentionUsersParams struct {
+	ChirpID   uuid.UUID
+	Usernames []string
+}
+
+func (q *Queries) MentionUsers(ctx context.Context, arg MentionUsersParams) error {
+	_, err := q.db.ExecContext(ctx, mentionUsers, arg.ChirpID, pq.Array(arg.Usernames))
+	return err
+}
@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: metrics_history.sql
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteMetricsHistoryBefore = `-- name: DeleteMetricsHistoryBefore :execrows
+DELETE FROM metrics_history WHERE recorded_at < $1
+`
+
+func (q *Queries) DeleteMetricsHistoryBefore(ctx context.Context, recordedAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMetricsHistoryBefore, recordedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getMetricsHistory = `-- name: GetMetricsHistory :many
+SELECT date_bin(make_interval(secs => $1::float8), recorded_at, $2::timestamp)::timestamp AS bucket,
+    sum(requests)::bigint AS requests,
+    sum(query_count)::bigint AS query_count,
+    sum(query_seconds)::float8 AS query_seconds,
+    max(users)::bigint AS users,
+    max(chirps)::bigint AS chirps
+FROM metrics_history
+WHERE recorded_at >= $2 AND recorded_at < $3
+GROUP BY bucket
+ORDER BY bucket
+`
+
+type GetMetricsHistoryParams struct {
+	StepSeconds float64
+	FromTime    time.Time
+	ToTime      time.Time
+}
+
+type GetMetricsHistoryRow struct {
+	Bucket       time.Time
+	Requests     int64
+	QueryCount   int64
+	QuerySeconds float64
+	Users        int64
+	Chirps       int64
+}
+
+func (q *Queries) GetMetricsHistory(ctx context.Context, arg GetMetricsHistoryParams) ([]GetMetricsHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMetricsHistory, arg.StepSeconds, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMetricsHistoryRow
+	for rows.Next() {
+		var i GetMetricsHistoryRow
+		if err := rows.Scan(
+			&i.Bucket,
+			&i.Requests,
+			&i.QueryCount,
+			&i.QuerySeconds,
+			&i.Users,
+			&i.Chirps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordMetricsSnapshot = `-- name: RecordMetricsSnapshot :exec
+INSERT INTO metrics_history (id, recorded_at, requests, query_count, query_seconds, users, chirps)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5)
+`
+
+type RecordMetricsSnapshotParams struct {
+	Requests     int64
+	QueryCount   int64
+	QuerySeconds float64
+	Users        int64
+	Chirps       int64
+}
+
+func (q *Queries) RecordMetricsSnapshot(ctx context.Context, arg RecordMetricsSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, recordMetricsSnapshot,
+		arg.Requests,
+		arg.QueryCount,
+		arg.QuerySeconds,
+		arg.Users,
+		arg.Chirps,
+	)
+	return err
+}
@@ -6,32 +6,348 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AdminAuditLog struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	AdminID   uuid.NullUUID
+	Action    string
+	TargetID  uuid.UUID
+	Details   json.RawMessage
+}
+
+type Appeal struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ActionID  uuid.UUID
+	UserID    uuid.UUID
+	Message   string
+	Status    string
+	DecidedAt sql.NullTime
+}
+
+type BannedWord struct {
+	Word      string
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+}
+
+type ChirpHashtag struct {
+	ChirpID uuid.UUID
+	Tag     string
+}
+
+type ChirpImage struct {
+	ChirpID       uuid.UUID
+	CreatedAt     time.Time
+	Path          string
+	FollowersOnly bool
+}
+
+type ChirpMention struct {
+	ChirpID uuid.UUID
+	UserID  uuid.UUID
+}
+
+type ChirpReport struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ChirpID    uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Details    string
+	HidChirp   bool
+	Escalated  bool
+	Status     string
+	ResolvedAt sql.NullTime
+	ResolvedBy uuid.NullUUID
+}
+
+type ChirpShare struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	Channel   string
+}
+
+type Collection struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	Name        string
+	Description string
+}
+
+type CollectionChirp struct {
+	CollectionID uuid.UUID
+	ChirpID      uuid.UUID
+	Position     int32
+	AddedAt      time.Time
+}
+
+type Counter struct {
+	Name      string
+	Value     int64
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.NullUUID
 }
 
-type RefreshToken struct {
-	Token     string
+type DeviceToken struct {
+	Token        string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	Platform     string
+	RefreshToken string
+}
+
+type Digest struct {
+	Day       time.Time
+	CreatedAt time.Time
+}
+
+type DigestChirp struct {
+	Day        time.Time
+	ChirpID    uuid.UUID
+	Rank       int32
+	Engagement int64
+}
+
+type Erasure struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UserID      uuid.UUID
+	Reference   string
+	Report      json.RawMessage
+	CompletedAt sql.NullTime
+}
+
+type FeedMarker struct {
+	UserID         uuid.UUID
+	Timeline       string
+	ChirpID        uuid.UUID
+	ChirpCreatedAt time.Time
+	UpdatedAt      time.Time
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	Tag       string
 	CreatedAt time.Time
+}
+
+type HeldNotification struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Channel   string
+	Event     string
+	ActorID   uuid.UUID
+	ChirpID   uuid.NullUUID
+	Text      string
+}
+
+type Job struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Type        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	LastError   sql.NullString
+	RunAt       time.Time
+	FinishedAt  sql.NullTime
+}
+
+type Like struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type LoginLocation struct {
+	UserID     uuid.UUID
+	Latitude   float64
+	Longitude  float64
+	Country    string
+	LoggedInAt time.Time
+}
+
+type LoginVerification struct {
+	UserID    uuid.UUID
+	CodeHash  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type MetricsHistory struct {
+	ID           uuid.UUID
+	RecordedAt   time.Time
+	Requests     int64
+	QueryCount   int64
+	QuerySeconds float64
+	Users        int64
+	Chirps       int64
+}
+
+type ModerationAction struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Kind       string
+	ReasonCode string
+	ChirpID    uuid.NullUUID
+	Details    json.RawMessage
+	ReversedAt sql.NullTime
+}
+
+type ModerationQueue struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Source     string
+	Signals    json.RawMessage
+	Status     string
+	ResolvedAt sql.NullTime
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID
+	Event     string
+	Channel   string
+	Enabled   bool
 	UpdatedAt time.Time
+}
+
+type NotificationQuietHour struct {
+	UserID      uuid.UUID
+	StartMinute int32
+	EndMinute   int32
+	Timezone    string
+	UpdatedAt   time.Time
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	CreatedAt time.Time
 	UserID    uuid.UUID
 	ExpiresAt time.Time
-	RevokedAt sql.NullTime
+	UsedAt    sql.NullTime
 }
 
-type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
+type Rechirp struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type RecoveryCode struct {
+	UserID    uuid.UUID
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type RefreshToken struct {
+	Token       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	ExpiresAt   time.Time
+	RevokedAt   sql.NullTime
+	RememberMe  bool
+	FamilyID    uuid.UUID
+	ParentToken sql.NullString
+}
+
+type SeenChirp struct {
+	UserID         uuid.UUID
+	CurrentFilter  []byte
+	PreviousFilter []byte
+	RotatedAt      time.Time
 	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
+}
+
+type TwoFactor struct {
+	UserID       uuid.UUID
+	Secret       string
+	CreatedAt    time.Time
+	EnabledAt    sql.NullTime
+	LastUsedStep int64
+}
+
+type User struct {
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Email             string
+	HashedPassword    string
+	DeletedAt         sql.NullTime
+	FeedRanking       string
+	LastSeenAt        sql.NullTime
+	Username          sql.NullString
+	SuspendedAt       sql.NullTime
+	LegalHoldAt       sql.NullTime
+	DisplayName       sql.NullString
+	Bio               sql.NullString
+	AvatarURL         sql.NullString
+	EmailDigest       bool
+	Role              string
+	TrustLevel        string
+	Website           sql.NullString
+	WebsiteVerifiedAt sql.NullTime
+}
+
+type UsernameChange struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	OldUsername sql.NullString
+	NewUsername string
+	ChangedAt   time.Time
+}
+
+type UsernameRedirect struct {
+	Username  string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt sql.NullTime
+}
+
+type WebhookDelivery struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	EventID         uuid.UUID
+	EventType       string
+	Payload         json.RawMessage
+	StatusCode      sql.NullInt32
+	ResponseSnippet string
+	Error           string
+	Succeeded       bool
 }
@@ -0,0 +1,118 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: moderation.sql
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const createModerationEntry = `-- name: CreateModerationEntry :one
+INSERT INTO moderation_queue (id, created_at, updated_at, user_id, source, signals)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING id, created_at, updated_at, user_id, source, signals, status, resolved_at
+`
+
+type CreateModerationEntryParams struct {
+	UserID  uuid.UUID
+	Source  string
+	Signals json.RawMessage
+}
+
+func (q *Queries) CreateModerationEntry(ctx context.Context, arg CreateModerationEntryParams) (ModerationQueue, error) {
+	row := q.db.QueryRowContext(ctx, createModerationEntry, arg.UserID, arg.Source, arg.Signals)
+	var i ModerationQueue
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Source,
+		&i.Signals,
+		&i.Status,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const listModerationEntries = `-- name: ListModerationEntries :many
+SELECT id, created_at, updated_at, user_id, source, signals, status, resolved_at FROM moderation_queue
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type ListModerationEntriesParams struct {
+	Status string
+	Limit  int32
+}
+
+func (q *Queries) ListModerationEntries(ctx context.Context, arg ListModerationEntriesParams) ([]ModerationQueue, error) {
+	rows, err := q.db.QueryContext(ctx, listModerationEntries, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ModerationQueue
+	for rows.Next() {
+		var i ModerationQueue
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Source,
+			&i.Signals,
+			&i.Status,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveModerationEntry = `-- name: ResolveModerationEntry :one
+UPDATE moderation_queue
+SET status = 'resolved',
+    resolved_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND status = 'open'
+RETURNING id, created_at, updated_at, user_id, source, signals, status, resolved_at
+`
+
+func (q *Queries) ResolveModerationEntry(ctx context.Context, id uuid.UUID) (ModerationQueue, error) {
+	row := q.db.QueryRowContext(ctx, resolveModerationEntry, id)
+	var i ModerationQueue
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Source,
+		&i.Signals,
+		&i.Status,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
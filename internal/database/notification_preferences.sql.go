@@ -0,0 +1,126 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: notification_preferences.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const deleteQuietHours = `-- name: DeleteQuietHours :exec
+DELETE FROM notification_quiet_hours
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteQuietHours(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteQuietHours, userID)
+	return err
+}
+
+const getNotificationPreferences = `-- name: GetNotificationPreferences :many
+SELECT user_id, event, channel, enabled, updated_at FROM notification_preferences
+WHERE user_id = $1
+`
+
+func (q *Queries) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) ([]NotificationPreference, error) {
+	rows, err := q.db.QueryContext(ctx, getNotificationPreferences, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationPreference
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Event,
+			&i.Channel,
+			&i.Enabled,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuietHours = `-- name: GetQuietHours :one
+SELECT user_id, start_minute, end_minute, timezone, updated_at FROM notification_quiet_hours
+WHERE user_id = $1
+`
+
+func (q *Queries) GetQuietHours(ctx context.Context, userID uuid.UUID) (NotificationQuietHour, error) {
+	row := q.db.QueryRowContext(ctx, getQuietHours, userID)
+	var i NotificationQuietHour
+	err := row.Scan(
+		&i.UserID,
+		&i.StartMinute,
+		&i.EndMinute,
+		&i.Timezone,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setNotificationPreference = `-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, event, channel, enabled, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id, event, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = EXCLUDED.updated_at
+`
+
+type SetNotificationPreferenceParams struct {
+	UserID  uuid.UUID
+	Event   string
+	Channel string
+	Enabled bool
+}
+
+func (q *Queries) SetNotificationPreference(ctx context.Context, arg SetNotificationPreferenceParams) error {
+	_, err := q.db.ExecContext(ctx, setNotificationPreference,
+		arg.UserID,
+		arg.Event,
+		arg.Channel,
+		arg.Enabled,
+	)
+	return err
+}
+
+const setQuietHours = `-- name: SetQuietHours :exec
+INSERT INTO notification_quiet_hours (user_id, start_minute, end_minute, timezone, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET start_minute = EXCLUDED.start_minute,
+    end_minute = EXCLUDED.end_minute,
+    timezone = EXCLUDED.timezone,
+    updated_at = EXCLUDED.updated_at
+`
+
+type SetQuietHoursParams struct {
+	UserID      uuid.UUID
+	StartMinute int32
+	EndMinute   int32
+	Timezone    string
+}
+
+func (q *Queries) SetQuietHours(ctx context.Context, arg SetQuietHoursParams) error {
+	_, err := q.db.ExecContext(ctx, setQuietHours,
+		arg.UserID,
+		arg.StartMinute,
+		arg.EndMinute,
+		arg.Timezone,
+	)
+	return err
+}
@@ -0,0 +1,123 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: rechirps.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO rechirps (id, user_id, chirp_id, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, chirp_id) DO NOTHING
+RETURNING id, user_id, chirp_id, created_at
+`
+
+type CreateRechirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Rechirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.ChirpID)
+	var i Rechirp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChirpID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :execrows
+DELETE FROM rechirps
+WHERE user_id = $1
+AND chirp_id = $2
+`
+
+type DeleteRechirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, user_id, chirp_id, created_at FROM rechirps
+WHERE user_id = $1
+AND chirp_id = $2
+`
+
+type GetRechirpParams struct {
+	UserID  uuid.UUID
+	ChirpID uuid.UUID
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Rechirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.ChirpID)
+	var i Rechirp
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ChirpID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRechirpCounts = `-- name: GetRechirpCounts :many
+SELECT rechirps.chirp_id, count(*) AS rechirp_count FROM rechirps
+JOIN users ON users.id = rechirps.user_id
+WHERE rechirps.chirp_id = ANY($1::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY rechirps.chirp_id
+`
+
+type GetRechirpCountsRow struct {
+	ChirpID      uuid.UUID
+	RechirpCount int64
+}
+
+func (q *Queries) GetRechirpCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetRechirpCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRechirpCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRechirpCountsRow
+	for rows.Next() {
+		var i GetRechirpCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.RechirpCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
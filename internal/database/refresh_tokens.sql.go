@@ -13,25 +13,33 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, remember_me, family_id)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    $3
+    $3,
+    $4,
+    gen_random_uuid()
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, remember_me, family_id, parent_token
 `
 
 type CreateRefreshTokenParams struct {
-	Token     string
-	UserID    uuid.UUID
-	ExpiresAt time.Time
+	Token      string
+	UserID     uuid.UUID
+	ExpiresAt  time.Time
+	RememberMe bool
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, createRefreshToken, arg.Token, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.Token,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.RememberMe,
+	)
 	var i RefreshToken
 	err := row.Scan(
 		&i.Token,
@@ -40,16 +48,59 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.RememberMe,
+		&i.FamilyID,
+		&i.ParentToken,
 	)
 	return i, err
 }
 
+const deleteStaleRefreshTokens = `-- name: DeleteStaleRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < $1::timestamp
+OR revoked_at < $1::timestamp
+`
+
+func (q *Queries) DeleteStaleRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleRefreshTokens, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const extendRefreshToken = `-- name: ExtendRefreshToken :exec
+UPDATE refresh_tokens
+SET expires_at = GREATEST(expires_at, LEAST(CASE WHEN remember_me THEN $1::timestamp ELSE $2::timestamp END, created_at + $3::bigint * INTERVAL '1 second')),
+    updated_at = NOW()
+WHERE token = $4
+AND revoked_at IS NULL
+`
+
+type ExtendRefreshTokenParams struct {
+	ExpiresAt      time.Time
+	ShortExpiresAt time.Time
+	MaxAgeSeconds  int64
+	Token          string
+}
+
+func (q *Queries) ExtendRefreshToken(ctx context.Context, arg ExtendRefreshTokenParams) error {
+	_, err := q.db.ExecContext(ctx, extendRefreshToken,
+		arg.ExpiresAt,
+		arg.ShortExpiresAt,
+		arg.MaxAgeSeconds,
+		arg.Token,
+	)
+	return err
+}
+
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password FROM users
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.deleted_at, users.feed_ranking, users.last_seen_at, users.username, users.suspended_at, users.legal_hold_at, users.display_name, users.bio, users.avatar_url, users.email_digest, users.role, users.trust_level, users.website, users.website_verified_at FROM users
 JOIN refresh_tokens ON users.id = refresh_tokens.user_id
 WHERE refresh_tokens.token = $1
 AND revoked_at IS NULL
 AND expires_at > NOW()
+AND users.deleted_at IS NULL
 `
 
 func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (User, error) {
@@ -61,15 +112,67 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Us
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
 	)
 	return i, err
 }
 
+const revokeAllRefreshTokensForUser = `-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeAllRefreshTokensForUser, userID)
+	return err
+}
+
+const revokeExcessRefreshTokens = `-- name: RevokeExcessRefreshTokens :execrows
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE token IN (
+    SELECT token FROM refresh_tokens
+    WHERE user_id = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    ORDER BY created_at DESC, token
+    OFFSET $2
+)
+`
+
+type RevokeExcessRefreshTokensParams struct {
+	UserID uuid.UUID
+	Keep   int32
+}
+
+func (q *Queries) RevokeExcessRefreshTokens(ctx context.Context, arg RevokeExcessRefreshTokensParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeExcessRefreshTokens, arg.UserID, arg.Keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const revokeRefreshToken = `-- name: RevokeRefreshToken :one
 UPDATE refresh_tokens SET revoked_at = NOW(),
 updated_at = NOW()
 WHERE token = $1
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, remember_me, family_id, parent_token
 `
 
 func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
@@ -82,6 +185,73 @@ func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) (Refresh
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.RememberMe,
+		&i.FamilyID,
+		&i.ParentToken,
+	)
+	return i, err
+}
+
+const revokeReusedRefreshTokenFamily = `-- name: RevokeReusedRefreshTokenFamily :one
+WITH reused AS (
+    SELECT used.family_id, used.user_id FROM refresh_tokens AS used
+    WHERE used.token = $1
+    AND EXISTS (SELECT 1 FROM refresh_tokens AS child WHERE child.parent_token = used.token)
+), revoked AS (
+    UPDATE refresh_tokens SET revoked_at = NOW(),
+    updated_at = NOW()
+    WHERE family_id IN (SELECT family_id FROM reused)
+    AND revoked_at IS NULL
+)
+SELECT user_id FROM reused
+`
+
+// Revokes every token of the family of a token that was already rotated,
+// returning the user it belongs to. There are no rows if the token wasn't
+// rotated.
+func (q *Queries) RevokeReusedRefreshTokenFamily(ctx context.Context, token string) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, revokeReusedRefreshTokenFamily, token)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const rotateRefreshToken = `-- name: RotateRefreshToken :one
+WITH old AS (
+    UPDATE refresh_tokens SET revoked_at = NOW(),
+    updated_at = NOW()
+    WHERE token = $1
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, remember_me, family_id, parent_token
+)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, remember_me, family_id, parent_token)
+SELECT $2, old.created_at, NOW(), old.user_id, old.expires_at, old.remember_me, old.family_id, old.token
+FROM old
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, remember_me, family_id, parent_token
+`
+
+type RotateRefreshTokenParams struct {
+	OldToken string
+	NewToken string
+}
+
+// Revokes a usable refresh token and issues new_token in its place. The
+// new token keeps created_at, when the session started, so the maximum
+// age of sliding expiration still applies.
+func (q *Queries) RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, rotateRefreshToken, arg.OldToken, arg.NewToken)
+	var i RefreshToken
+	err := row.Scan(
+		&i.Token,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.RememberMe,
+		&i.FamilyID,
+		&i.ParentToken,
 	)
 	return i, err
 }
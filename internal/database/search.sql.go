@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: search.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countSearchChirps = `-- name: CountSearchChirps :one
+SELECT count(*)
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.search_vector @@ websearch_to_tsquery('english', $1)
+AND users.deleted_at IS NULL
+`
+
+func (q *Queries) CountSearchChirps(ctx context.Context, search string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchChirps, search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_chirp_id, chirps.legal_hold_at,
+    ts_rank(chirps.search_vector, query)::real AS rank
+FROM chirps
+JOIN users ON users.id = chirps.user_id,
+    websearch_to_tsquery('english', $1) AS query
+WHERE chirps.search_vector @@ query
+AND users.deleted_at IS NULL
+ORDER BY rank DESC, chirps.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type SearchChirpsParams struct {
+	Search     string
+	MaxResults int32
+	Skip       int32
+}
+
+type SearchChirpsRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Body          string
+	UserID        uuid.NullUUID
+	SearchVector  interface{}
+	ParentChirpID uuid.NullUUID
+	LegalHoldAt   sql.NullTime
+	Rank          float32
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps, arg.Search, arg.MaxResults, arg.Skip)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentChirpID,
+			&i.LegalHoldAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
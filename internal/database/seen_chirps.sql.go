@@ -0,0 +1,58 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: seen_chirps.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getSeenChirps = `-- name: GetSeenChirps :one
+SELECT user_id, current_filter, previous_filter, rotated_at, updated_at FROM seen_chirps
+WHERE user_id = $1
+`
+
+func (q *Queries) GetSeenChirps(ctx context.Context, userID uuid.UUID) (SeenChirp, error) {
+	row := q.db.QueryRowContext(ctx, getSeenChirps, userID)
+	var i SeenChirp
+	err := row.Scan(
+		&i.UserID,
+		&i.CurrentFilter,
+		&i.PreviousFilter,
+		&i.RotatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const saveSeenChirps = `-- name: SaveSeenChirps :exec
+INSERT INTO seen_chirps (user_id, current_filter, previous_filter, rotated_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET current_filter = EXCLUDED.current_filter,
+    previous_filter = EXCLUDED.previous_filter,
+    rotated_at = EXCLUDED.rotated_at,
+    updated_at = NOW()
+`
+
+type SaveSeenChirpsParams struct {
+	UserID         uuid.UUID
+	CurrentFilter  []byte
+	PreviousFilter []byte
+	RotatedAt      time.Time
+}
+
+func (q *Queries) SaveSeenChirps(ctx context.Context, arg SaveSeenChirpsParams) error {
+	_, err := q.db.ExecContext(ctx, saveSeenChirps,
+		arg.UserID,
+		arg.CurrentFilter,
+		arg.PreviousFilter,
+		arg.RotatedAt,
+	)
+	return err
+}
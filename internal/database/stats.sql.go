@@ -0,0 +1,88 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: stats.sql
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const countActiveUsersSince = `-- name: CountActiveUsersSince :one
+SELECT count(DISTINCT user_id) FROM (
+    SELECT chirps.user_id FROM chirps WHERE chirps.created_at > $1
+    UNION
+    SELECT refresh_tokens.user_id FROM refresh_tokens WHERE refresh_tokens.created_at > $1
+) AS active
+`
+
+func (q *Queries) CountActiveUsersSince(ctx context.Context, since time.Time) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveUsersSince, since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countChirps = `-- name: CountChirps :one
+SELECT count(*) FROM chirps
+`
+
+func (q *Queries) CountChirps(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirps)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getTopHashtagsSince = `-- name: GetTopHashtagsSince :many
+SELECT lower(match[1])::text AS tag,
+    count(DISTINCT user_id) AS authors,
+    count(*) AS uses
+FROM chirps, regexp_matches(body, '#(\w+)', 'g') AS match
+WHERE created_at > $1
+GROUP BY 1
+HAVING count(DISTINCT user_id) >= $2::bigint
+ORDER BY uses DESC, tag ASC
+LIMIT $3
+`
+
+type GetTopHashtagsSinceParams struct {
+	Since      time.Time
+	MinAuthors int64
+	MaxTags    int32
+}
+
+type GetTopHashtagsSinceRow struct {
+	Tag     string
+	Authors int64
+	Uses    int64
+}
+
+func (q *Queries) GetTopHashtagsSince(ctx context.Context, arg GetTopHashtagsSinceParams) ([]GetTopHashtagsSinceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTopHashtagsSince, arg.Since, arg.MinAuthors, arg.MaxTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTopHashtagsSinceRow
+	for rows.Next() {
+		var i GetTopHashtagsSinceRow
+		if err := rows.Scan(
+			&i.Tag,
+			&i.Authors,
+			&i.Uses,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
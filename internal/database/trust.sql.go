@@ -0,0 +1,123 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: trust.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getTrustActivity = `-- name: GetTrustActivity :one
+SELECT users.created_at,
+    (SELECT count(*) FROM chirps WHERE chirps.user_id = users.id) AS chirps,
+    (SELECT count(*) FROM likes
+        JOIN chirps ON chirps.id = likes.chirp_id
+        WHERE chirps.user_id = users.id
+        AND likes.user_id <> users.id) AS likes_received,
+    (SELECT count(*) FROM follows WHERE follows.followee_id = users.id) AS followers,
+    (SELECT count(*) FROM moderation_actions
+        WHERE moderation_actions.user_id = users.id
+        AND moderation_actions.reversed_at IS NULL) AS strikes
+FROM users
+WHERE users.id = $1
+`
+
+type GetTrustActivityRow struct {
+	CreatedAt     time.Time
+	Chirps        int64
+	LikesReceived int64
+	Followers     int64
+	Strikes       int64
+}
+
+func (q *Queries) GetTrustActivity(ctx context.Context, id uuid.UUID) (GetTrustActivityRow, error) {
+	row := q.db.QueryRowContext(ctx, getTrustActivity, id)
+	var i GetTrustActivityRow
+	err := row.Scan(
+		&i.CreatedAt,
+		&i.Chirps,
+		&i.LikesReceived,
+		&i.Followers,
+		&i.Strikes,
+	)
+	return i, err
+}
+
+const listTrustActivity = `-- name: ListTrustActivity :many
+SELECT users.id,
+    users.trust_level,
+    users.created_at,
+    (SELECT count(*) FROM chirps WHERE chirps.user_id = users.id) AS chirps,
+    (SELECT count(*) FROM likes
+        JOIN chirps ON chirps.id = likes.chirp_id
+        WHERE chirps.user_id = users.id
+        AND likes.user_id <> users.id) AS likes_received,
+    (SELECT count(*) FROM follows WHERE follows.followee_id = users.id) AS followers,
+    (SELECT count(*) FROM moderation_actions
+        WHERE moderation_actions.user_id = users.id
+        AND moderation_actions.reversed_at IS NULL) AS strikes
+FROM users
+WHERE users.deleted_at IS NULL
+`
+
+type ListTrustActivityRow struct {
+	ID            uuid.UUID
+	TrustLevel    string
+	CreatedAt     time.Time
+	Chirps        int64
+	LikesReceived int64
+	Followers     int64
+	Strikes       int64
+}
+
+func (q *Queries) ListTrustActivity(ctx context.Context) ([]ListTrustActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTrustActivity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTrustActivityRow
+	for rows.Next() {
+		var i ListTrustActivityRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TrustLevel,
+			&i.CreatedAt,
+			&i.Chirps,
+			&i.LikesReceived,
+			&i.Followers,
+			&i.Strikes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserTrustLevel = `-- name: SetUserTrustLevel :exec
+UPDATE users
+SET trust_level = $2
+WHERE id = $1
+`
+
+type SetUserTrustLevelParams struct {
+	ID         uuid.UUID
+	TrustLevel string
+}
+
+func (q *Queries) SetUserTrustLevel(ctx context.Context, arg SetUserTrustLevelParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTrustLevel, arg.ID, arg.TrustLevel)
+	return err
+}
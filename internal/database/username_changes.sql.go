@@ -0,0 +1,46 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: username_changes.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createUsernameChange = `-- name: CreateUsernameChange :exec
+INSERT INTO username_changes (id, user_id, old_username, new_username, changed_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW())
+`
+
+type CreateUsernameChangeParams struct {
+	UserID      uuid.UUID
+	OldUsername sql.NullString
+	NewUsername string
+}
+
+func (q *Queries) CreateUsernameChange(ctx context.Context, arg CreateUsernameChangeParams) error {
+	_, err := q.db.ExecContext(ctx, createUsernameChange, arg.UserID, arg.OldUsername, arg.NewUsername)
+	return err
+}
+
+const lastUsernameChange = `-- name: LastUsernameChange :one
+SELECT changed_at FROM username_changes
+WHERE user_id = $1
+AND old_username IS NOT NULL
+ORDER BY changed_at DESC
+LIMIT 1
+`
+
+// Picking a first username isn't a change from one.
+func (q *Queries) LastUsernameChange(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, lastUsernameChange, userID)
+	var changed_at time.Time
+	err := row.Scan(&changed_at)
+	return changed_at, err
+}
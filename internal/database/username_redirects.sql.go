@@ -0,0 +1,96 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: username_redirects.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createUsernameRedirect = `-- name: CreateUsernameRedirect :exec
+INSERT INTO username_redirects (username, user_id, created_at, expires_at)
+VALUES ($1, $2, NOW(), $3)
+ON CONFLICT (username) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at
+`
+
+type CreateUsernameRedirectParams struct {
+	Username  string
+	UserID    uuid.UUID
+	ExpiresAt sql.NullTime
+}
+
+func (q *Queries) CreateUsernameRedirect(ctx context.Context, arg CreateUsernameRedirectParams) error {
+	_, err := q.db.ExecContext(ctx, createUsernameRedirect, arg.Username, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const deleteUsernameRedirect = `-- name: DeleteUsernameRedirect :exec
+DELETE FROM username_redirects
+WHERE username = $1
+AND user_id = $2
+`
+
+type DeleteUsernameRedirectParams struct {
+	Username string
+	UserID   uuid.UUID
+}
+
+func (q *Queries) DeleteUsernameRedirect(ctx context.Context, arg DeleteUsernameRedirectParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUsernameRedirect, arg.Username, arg.UserID)
+	return err
+}
+
+const resolveUsername = `-- name: ResolveUsername :one
+SELECT id AS user_id, FALSE AS redirected FROM users
+WHERE username = $1::text
+AND deleted_at IS NULL
+UNION ALL
+SELECT user_id, TRUE AS redirected FROM username_redirects
+WHERE username = $1::text
+AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY redirected
+LIMIT 1
+`
+
+type ResolveUsernameRow struct {
+	UserID     uuid.UUID
+	Redirected bool
+}
+
+// The account a username leads to: the one that has it, or else the one
+// it redirects to.
+func (q *Queries) ResolveUsername(ctx context.Context, username string) (ResolveUsernameRow, error) {
+	row := q.db.QueryRowContext(ctx, resolveUsername, username)
+	var i ResolveUsernameRow
+	err := row.Scan(&i.UserID, &i.Redirected)
+	return i, err
+}
+
+const usernameReserved = `-- name: UsernameReserved :one
+SELECT EXISTS (
+    SELECT 1 FROM username_redirects
+    WHERE username = $1
+    AND user_id <> $2
+    AND (expires_at IS NULL OR expires_at > NOW())
+)
+`
+
+type UsernameReservedParams struct {
+	Username string
+	UserID   uuid.UUID
+}
+
+func (q *Queries) UsernameReserved(ctx context.Context, arg UsernameReservedParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, usernameReserved, arg.Username, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -7,29 +7,34 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -37,12 +42,37 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
 	)
 	return i, err
 }
 
+const emailExists = `-- name: EmailExists :one
+SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)
+`
+
+func (q *Queries) EmailExists(ctx context.Context, email string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, emailExists, email)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password FROM users
+SELECT id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at FROM users
 WHERE email = $1
 `
 
@@ -55,6 +85,308 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByIDForUpdate = `-- name: GetUserByIDForUpdate :one
+SELECT id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks the user's row for the rest of the transaction.
+func (q *Queries) GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIDForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const lockPurgeableUsers = `-- name: LockPurgeableUsers :many
+SELECT id FROM users
+WHERE deleted_at < $1
+AND legal_hold_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE chirps.user_id = users.id
+    AND chirps.legal_hold_at IS NOT NULL
+)
+FOR UPDATE
+`
+
+func (q *Queries) LockPurgeableUsers(ctx context.Context, deletedAt sql.NullTime) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockPurgeableUsers, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUsers = `-- name: PurgeUsers :many
+DELETE FROM users
+WHERE id = ANY($1::uuid[])
+RETURNING avatar_url
+`
+
+func (q *Queries) PurgeUsers(ctx context.Context, ids []uuid.UUID) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, purgeUsers, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullString
+	for rows.Next() {
+		var avatar_url sql.NullString
+		if err := rows.Scan(&avatar_url); err != nil {
+			return nil, err
+		}
+		items = append(items, avatar_url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at > $2
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type RestoreUserParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, restoreUser, arg.ID, arg.DeletedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const setUserAvatar = `-- name: SetUserAvatar :one
+UPDATE users
+SET avatar_url = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type SetUserAvatarParams struct {
+	ID        uuid.UUID
+	AvatarURL sql.NullString
+}
+
+func (q *Queries) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserAvatar, arg.ID, arg.AvatarURL)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type SetUserRoleParams struct {
+	ID   uuid.UUID
+	Role string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
 	)
 	return i, err
 }
@@ -63,19 +395,38 @@ const updateUserByID = `-- name: UpdateUserByID :one
 UPDATE users
 SET email = $2,
     hashed_password = $3,
+    username = COALESCE($4, username),
+    display_name = NULLIF(COALESCE($5, display_name), ''),
+    bio = NULLIF(COALESCE($6, bio), ''),
+    website = NULLIF(COALESCE($7, website), ''),
+    website_verified_at = CASE
+        WHEN NULLIF(COALESCE($7, website), '') IS NOT DISTINCT FROM website THEN website_verified_at
+    END,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
 `
 
 type UpdateUserByIDParams struct {
 	ID             uuid.UUID
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
+	DisplayName    sql.NullString
+	Bio            sql.NullString
+	Website        sql.NullString
 }
 
 func (q *Queries) UpdateUserByID(ctx context.Context, arg UpdateUserByIDParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserByID, arg.ID, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, updateUserByID,
+		arg.ID,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Website,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -83,6 +434,254 @@ func (q *Queries) UpdateUserByID(ctx context.Context, arg UpdateUserByIDParams)
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
 	)
 	return i, err
 }
+
+const updateUserByIDIfUnmodified = `-- name: UpdateUserByIDIfUnmodified :one
+UPDATE users
+SET email = $2,
+    hashed_password = $3,
+    username = COALESCE($5, username),
+    display_name = NULLIF(COALESCE($6, display_name), ''),
+    bio = NULLIF(COALESCE($7, bio), ''),
+    website = NULLIF(COALESCE($8, website), ''),
+    website_verified_at = CASE
+        WHEN NULLIF(COALESCE($8, website), '') IS NOT DISTINCT FROM website THEN website_verified_at
+    END,
+    updated_at = NOW()
+WHERE id = $1
+AND updated_at < $4
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type UpdateUserByIDIfUnmodifiedParams struct {
+	ID             uuid.UUID
+	Email          string
+	HashedPassword string
+	UpdatedAt      time.Time
+	Username       sql.NullString
+	DisplayName    sql.NullString
+	Bio            sql.NullString
+	Website        sql.NullString
+}
+
+func (q *Queries) UpdateUserByIDIfUnmodified(ctx context.Context, arg UpdateUserByIDIfUnmodifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserByIDIfUnmodified,
+		arg.ID,
+		arg.Email,
+		arg.HashedPassword,
+		arg.UpdatedAt,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Website,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserEmailDigest = `-- name: UpdateUserEmailDigest :one
+UPDATE users
+SET email_digest = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type UpdateUserEmailDigestParams struct {
+	ID          uuid.UUID
+	EmailDigest bool
+}
+
+func (q *Queries) UpdateUserEmailDigest(ctx context.Context, arg UpdateUserEmailDigestParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmailDigest, arg.ID, arg.EmailDigest)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserFeedRanking = `-- name: UpdateUserFeedRanking :one
+UPDATE users
+SET feed_ranking = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type UpdateUserFeedRankingParams struct {
+	ID          uuid.UUID
+	FeedRanking string
+}
+
+func (q *Queries) UpdateUserFeedRanking(ctx context.Context, arg UpdateUserFeedRankingParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserFeedRanking, arg.ID, arg.FeedRanking)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserLastSeen = `-- name: UpdateUserLastSeen :exec
+UPDATE users
+SET last_seen_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UpdateUserLastSeen(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, updateUserLastSeen, id)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, email, hashed_password, deleted_at, feed_ranking, last_seen_at, username, suspended_at, legal_hold_at, display_name, bio, avatar_url, email_digest, role, trust_level, website, website_verified_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID             uuid.UUID
+	HashedPassword string
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.DeletedAt,
+		&i.FeedRanking,
+		&i.LastSeenAt,
+		&i.Username,
+		&i.SuspendedAt,
+		&i.LegalHoldAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarURL,
+		&i.EmailDigest,
+		&i.Role,
+		&i.TrustLevel,
+		&i.Website,
+		&i.WebsiteVerifiedAt,
+	)
+	return i, err
+}
+
+const usernameExists = `-- name: UsernameExists :one
+SELECT (
+    EXISTS (SELECT 1 FROM users WHERE username = $1)
+    OR EXISTS (
+        SELECT 1 FROM username_redirects
+        WHERE username = $1
+        AND (expires_at IS NULL OR expires_at > NOW())
+    )
+) AS exists
+`
+
+func (q *Queries) UsernameExists(ctx context.Context, username sql.NullString) (bool, error) {
+	row := q.db.QueryRowContext(ctx, usernameExists, username)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const verifyUserWebsite = `-- name: VerifyUserWebsite :execrows
+UPDATE users
+SET website_verified_at = NOW()
+WHERE id = $1
+AND website = $2
+`
+
+type VerifyUserWebsiteParams struct {
+	ID      uuid.UUID
+	Website sql.NullString
+}
+
+func (q *Queries) VerifyUserWebsite(ctx context.Context, arg VerifyUserWebsiteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, verifyUserWebsite, arg.ID, arg.Website)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
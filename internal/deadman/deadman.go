@@ -0,0 +1,182 @@
+package deadman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Alert is sent when a watched worker misses its window, and again
+// with Recovered set once it checks in.
+type Alert struct {
+	Name      string    `json:"name"`
+	LastSeen  time.Time `json:"last_seen"`
+	Window    string    `json:"window"`
+	Recovered bool      `json:"recovered"`
+}
+
+// Alerter delivers alerts.
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// LogAlerter writes alerts to the default slog logger.
+type LogAlerter struct{}
+
+// Alert -
+func (LogAlerter) Alert(ctx context.Context, alert Alert) error {
+	if alert.Recovered {
+		slog.InfoContext(ctx, "Alert resolved: worker checked in again", "worker", alert.Name)
+	} else {
+		slog.ErrorContext(ctx, "Alert: worker has not checked in", "worker", alert.Name, "last_seen", alert.LastSeen, "window", alert.Window)
+	}
+	return nil
+}
+
+// WebhookAlerter POSTs alerts as JSON to a URL.
+type WebhookAlerter struct {
+	URL    string
+	Client *http.Client
+}
+
+// Alert -
+func (a WebhookAlerter) Alert(ctx context.Context, alert Alert) error {
+	dat, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("alert webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// HeartbeatStatus describes a watched worker for the admin API.
+type HeartbeatStatus struct {
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	Window   string    `json:"window"`
+	Overdue  bool      `json:"overdue"`
+}
+
+type watch struct {
+	name     string
+	window   time.Duration
+	lastSeen func() time.Time
+	alerting bool
+}
+
+// Monitor fires an alert when a watched worker hasn't made progress
+// within its expected window.
+type Monitor struct {
+	mu      sync.Mutex
+	watches []*watch
+	alerter Alerter
+	started time.Time
+	now     func() time.Time
+}
+
+// NewMonitor creates a Monitor delivering alerts through alerter.
+func NewMonitor(alerter Alerter) *Monitor {
+	return &Monitor{
+		alerter: alerter,
+		started: time.Now().UTC(),
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Watch registers a worker. lastSeen reports when it last completed
+// successfully; a zero time means it hasn't yet, in which case the
+// window counts from when the monitor was created.
+func (m *Monitor) Watch(name string, window time.Duration, lastSeen func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watches = append(m.watches, &watch{name: name, window: window, lastSeen: lastSeen})
+}
+
+// Run checks every watch once per interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check alerts once for every watch that went overdue, and once more
+// when it recovers.
+func (m *Monitor) Check(ctx context.Context) {
+	m.mu.Lock()
+	var alerts []Alert
+	for _, w := range m.watches {
+		lastSeen := m.lastSeen(w)
+		overdue := m.now().Sub(lastSeen) > w.window
+		if overdue != w.alerting {
+			w.alerting = overdue
+			alerts = append(alerts, Alert{
+				Name:      w.name,
+				LastSeen:  lastSeen,
+				Window:    w.window.String(),
+				Recovered: !overdue,
+			})
+		}
+	}
+	m.mu.Unlock()
+
+	for _, alert := range alerts {
+		if err := m.alerter.Alert(ctx, alert); err != nil {
+			slog.ErrorContext(ctx, "Couldn't deliver alert", "worker", alert.Name, "error", err)
+		}
+	}
+}
+
+// Status reports every watch in registration order.
+func (m *Monitor) Status() []HeartbeatStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]HeartbeatStatus, 0, len(m.watches))
+	for _, w := range m.watches {
+		lastSeen := m.lastSeen(w)
+		out = append(out, HeartbeatStatus{
+			Name:     w.name,
+			LastSeen: lastSeen,
+			Window:   w.window.String(),
+			Overdue:  m.now().Sub(lastSeen) > w.window,
+		})
+	}
+	return out
+}
+
+func (m *Monitor) lastSeen(w *watch) time.Time {
+	lastSeen := w.lastSeen()
+	if lastSeen.Before(m.started) {
+		return m.started
+	}
+	return lastSeen
+}
@@ -0,0 +1,57 @@
+package deadman
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type recordingAlerter struct {
+	alerts []Alert
+}
+
+func (r *recordingAlerter) Alert(ctx context.Context, alert Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestMonitorCheck(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	var lastSeen time.Time
+
+	alerter := &recordingAlerter{}
+	m := NewMonitor(alerter)
+	m.started = start
+	m.now = func() time.Time { return now }
+	m.Watch("purge_deleted_users", time.Hour, func() time.Time { return lastSeen })
+
+	steps := []struct {
+		name       string
+		advance    time.Duration
+		checkIn    bool
+		wantAlerts int
+		wantLast   bool
+	}{
+		{name: "Within window since start", advance: 30 * time.Minute, wantAlerts: 0},
+		{name: "Overdue", advance: time.Hour, wantAlerts: 1, wantLast: false},
+		{name: "Still overdue does not re-alert", advance: time.Hour, wantAlerts: 1, wantLast: false},
+		{name: "Recovered", advance: time.Minute, checkIn: true, wantAlerts: 2, wantLast: true},
+		{name: "Healthy", advance: time.Minute, wantAlerts: 2, wantLast: true},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.checkIn {
+			lastSeen = now
+		}
+		m.Check(context.Background())
+
+		if len(alerter.alerts) != step.wantAlerts {
+			t.Fatalf("%s: got %d alerts, want %d", step.name, len(alerter.alerts), step.wantAlerts)
+		}
+		if step.wantAlerts > 0 && alerter.alerts[len(alerter.alerts)-1].Recovered != step.wantLast {
+			t.Errorf("%s: last alert Recovered = %v, want %v", step.name, !step.wantLast, step.wantLast)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package events publishes lifecycle events to an external event bus so
+// analytics and recommendation services can consume them without polling
+// the API.
+//
+// Every event is a JSON object, and the same envelope is used by every
+// transport that delivers events to integrators:
+//
+//	{
+//	  "id":          "a5a7...",                // unique per event, for de-duplication
+//	  "type":        "chirp.created",          // see the Type constants
+//	  "version":     1,                        // payload schema version, per type
+//	  "occurred_at": "2025-01-01T12:00:00Z",   // RFC 3339, UTC
+//	  "data":        { ... }                   // payload, depends on type and version
+//	}
+//
+// Payloads by type:
+//
+//	chirp.created, chirp.updated,
+//	chirp.deleted                  ChirpData
+//	user.created, user.updated,
+//	user.deleted, user.restored,
+//	user.erased                    UserData
+//	user.merged                    MergeData
+//	follow.created, follow.deleted FollowData
+//	moderation.action_taken        ModerationActionData
+//	appeal.decided                 AppealData
+//
+// Compatibility policy: within a version, payloads only gain fields, so
+// consumers must ignore fields they don't know. Removing, renaming or
+// changing the JSON type of a field is a breaking change and bumps the
+// version. The schema of every published version is recorded under
+// testdata and the tests fail if a payload drifts from it.
+//
+// Events are published to the subject (NATS) or topic (Kafka) named
+// "<prefix>.<type>", e.g. "chirpy.chirp.created". Delivery is at most
+// once: events are dropped, and logged, if the bus is unreachable.
+//
+// The webhook transport POSTs each event to one URL instead, and records
+// every attempt so failed events can be redelivered; see
+// WebhookPublisher.
+package events
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies what happened.
+type Type string
+
+const (
+	ChirpCreated  Type = "chirp.created"
+	ChirpUpdated  Type = "chirp.updated"
+	ChirpDeleted  Type = "chirp.deleted"
+	UserCreated   Type = "user.created"
+	UserUpdated   Type = "user.updated"
+	UserDeleted   Type = "user.deleted"
+	UserRestored  Type = "user.restored"
+	FollowCreated Type = "follow.created"
+	FollowDeleted Type = "follow.deleted"
+
+	ModerationActionTaken Type = "moderation.action_taken"
+	AppealDecided         Type = "appeal.decided"
+
+	// UserErased asks consumers to delete everything they hold about the
+	// user, e.g. copies made from earlier events.
+	UserErased Type = "user.erased"
+	// UserMerged tells consumers an account was merged into another,
+	// which now owns its chirps and follows. The merged account is gone.
+	UserMerged Type = "user.merged"
+)
+
+// Event is the envelope of every event we emit, whatever the transport.
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       Type      `json:"type"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// ChirpData is the payload of chirp events.
+type ChirpData struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Body      string    `json:"body,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserData is the payload of user events. It deliberately carries no
+// contact details.
+type UserData struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FollowData is the payload of follow events.
+type FollowData struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+}
+
+// MergeData is the payload of user.merged.
+type MergeData struct {
+	UserID uuid.UUID `json:"user_id"`
+	IntoID uuid.UUID `json:"into_id"`
+}
+
+// ModerationActionData is the payload of moderation.action_taken, sent
+// so the affected user can be told what happened and why.
+type ModerationActionData struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Kind       string     `json:"kind"`
+	ReasonCode string     `json:"reason_code"`
+	ChirpID    *uuid.UUID `json:"chirp_id,omitempty"`
+}
+
+// AppealData is the payload of appeal.decided. Status is approved, which
+// reversed the action, or denied, which upheld it.
+type AppealData struct {
+	ID       uuid.UUID `json:"id"`
+	ActionID uuid.UUID `json:"action_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Status   string    `json:"status"`
+}
+
+// New creates an event of the given type happening now.
+func New(t Type, data any) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       t,
+		Version:    Version(t),
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher sends events to a bus.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// NopPublisher drops every event. It is used when no bus is configured.
+type NopPublisher struct{}
+
+// Publish -
+func (NopPublisher) Publish(ctx context.Context, e Event) error {
+	return nil
+}
+
+// AsyncPublisher hands events to a background goroutine so request
+// handlers never wait on the bus. Events are dropped when the buffer is
+// full.
+type AsyncPublisher struct {
+	next  Publisher
+	queue chan Event
+}
+
+// NewAsyncPublisher wraps next with a buffer of the given size.
+func NewAsyncPublisher(next Publisher, buffer int) *AsyncPublisher {
+	return &AsyncPublisher{
+		next:  next,
+		queue: make(chan Event, buffer),
+	}
+}
+
+// Publish queues e. It never blocks.
+func (p *AsyncPublisher) Publish(ctx context.Context, e Event) error {
+	select {
+	case p.queue <- e:
+	default:
+		slog.WarnContext(ctx, "Event bus buffer full, dropping event", "event_type", e.Type, "event_id", e.ID)
+	}
+	return nil
+}
+
+// Run forwards queued events until ctx is cancelled.
+func (p *AsyncPublisher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-p.queue:
+			if err := p.next.Publish(ctx, e); err != nil {
+				slog.ErrorContext(ctx, "Couldn't publish event", "event_type", e.Type, "event_id", e.ID, "error", err)
+			}
+		}
+	}
+}
+
+func encode(e Event) ([]byte, error) {
+	return json.Marshal(e)
+}
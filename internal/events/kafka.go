@@ -0,0 +1,60 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// KafkaRESTPublisher produces events through a Kafka REST Proxy
+// (Confluent v2 API), keyed by event ID. Topics are "<prefix>.<event type>".
+type KafkaRESTPublisher struct {
+	baseURL string
+	prefix  string
+	client  *http.Client
+}
+
+// NewKafkaRESTPublisher creates a publisher for the proxy at baseURL.
+func NewKafkaRESTPublisher(baseURL, prefix string, client *http.Client) *KafkaRESTPublisher {
+	return &KafkaRESTPublisher{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		prefix:  prefix,
+		client:  client,
+	}
+}
+
+// Publish -
+func (p *KafkaRESTPublisher) Publish(ctx context.Context, e Event) error {
+	type record struct {
+		Key   string `json:"key"`
+		Value Event  `json:"value"`
+	}
+	type request struct {
+		Records []record `json:"records"`
+	}
+
+	dat, err := json.Marshal(request{Records: []record{{Key: e.ID.String(), Value: e}}})
+	if err != nil {
+		return err
+	}
+
+	topic := p.prefix + "." + string(e.Type)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+topic, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("kafka REST proxy responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher publishes events with the core NATS text protocol.
+// It connects lazily and reconnects on the next publish after a failure.
+type NATSPublisher struct {
+	addr   string
+	user   *url.Userinfo
+	prefix string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewNATSPublisher creates a publisher for a URL like nats://host:4222.
+// Subjects are "<prefix>.<event type>".
+func NewNATSPublisher(rawURL, prefix string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported NATS URL scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATSPublisher{addr: addr, user: u.User, prefix: prefix}, nil
+}
+
+// Publish -
+func (p *NATSPublisher) Publish(ctx context.Context, e Event) error {
+	dat, err := encode(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	subject := p.prefix + "." + string(e.Type)
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\n", subject, len(dat), dat)
+	p.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connect dials the server, consumes its INFO line and sends CONNECT.
+// p.mu must be held.
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected NATS greeting: %q: %w", info, err)
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	connect := `{"verbose":false,"pedantic":false,"name":"chirpy"`
+	if p.user != nil {
+		pass, _ := p.user.Password()
+		connect += fmt.Sprintf(`,"user":%q,"pass":%q`, p.user.Username(), pass)
+	}
+	connect += "}"
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// The server pings idle clients and disconnects them if they don't
+	// answer, so keep reading in the background.
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "PING") {
+				p.mu.Lock()
+				if p.conn == conn {
+					conn.Write([]byte("PONG\r\n"))
+				}
+				p.mu.Unlock()
+			}
+		}
+	}()
+
+	p.conn = conn
+	return nil
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNATSPublisherPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type published struct {
+		subject string
+		payload []byte
+	}
+	got := make(chan published, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("INFO {}\r\n"))
+
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(line, "PUB ") {
+				continue
+			}
+			fields := strings.Fields(line)
+			n, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			got <- published{subject: fields[1], payload: payload[:n]}
+		}
+	}()
+
+	p, err := NewNATSPublisher("nats://"+ln.Addr().String(), "chirpy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chirpID := uuid.New()
+	if err := p.Publish(context.Background(), New(ChirpCreated, ChirpData{ID: chirpID})); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	msg := <-got
+	if msg.subject != "chirpy.chirp.created" {
+		t.Errorf("subject = %q, want %q", msg.subject, "chirpy.chirp.created")
+	}
+
+	var e struct {
+		Type Type      `json:"type"`
+		Data ChirpData `json:"data"`
+	}
+	if err := json.Unmarshal(msg.payload, &e); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if e.Type != ChirpCreated || e.Data.ID != chirpID {
+		t.Errorf("payload = %+v, want chirp.created for %v", e, chirpID)
+	}
+}
@@ -0,0 +1,34 @@
+package events
+
+// schema is the current version of an event type's payload.
+type schema struct {
+	version int
+	// payload is the zero value of the payload type
+	payload any
+}
+
+// schemas lists every event type. Bump a version, and add the matching
+// testdata file, whenever a payload changes incompatibly; see the package
+// documentation for what counts as compatible.
+var schemas = map[Type]schema{
+	ChirpCreated:  {version: 1, payload: ChirpData{}},
+	ChirpUpdated:  {version: 1, payload: ChirpData{}},
+	ChirpDeleted:  {version: 1, payload: ChirpData{}},
+	UserCreated:   {version: 1, payload: UserData{}},
+	UserUpdated:   {version: 1, payload: UserData{}},
+	UserDeleted:   {version: 1, payload: UserData{}},
+	UserRestored:  {version: 1, payload: UserData{}},
+	FollowCreated: {version: 1, payload: FollowData{}},
+	FollowDeleted: {version: 1, payload: FollowData{}},
+
+	ModerationActionTaken: {version: 1, payload: ModerationActionData{}},
+	AppealDecided:         {version: 1, payload: AppealData{}},
+	UserErased:            {version: 1, payload: UserData{}},
+	UserMerged:            {version: 1, payload: MergeData{}},
+}
+
+// Version returns the current schema version of an event type, or 0 for
+// unknown types.
+func Version(t Type) int {
+	return schemas[t].version
+}
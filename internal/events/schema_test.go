@@ -0,0 +1,123 @@
+package events
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record new payload fields in testdata")
+
+// payloadFields maps each JSON field of a payload to its JSON type.
+func payloadFields(t *testing.T, payload any) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	typ := reflect.TypeOf(payload)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// Nil slices and maps marshal as null, so fill them in
+		zero := reflect.Zero(ft)
+		switch ft.Kind() {
+		case reflect.Slice:
+			zero = reflect.MakeSlice(ft, 0, 0)
+		case reflect.Map:
+			zero = reflect.MakeMap(ft)
+		}
+		dat, err := json.Marshal(zero.Interface())
+		if err != nil {
+			t.Fatalf("marshalling %s.%s: %v", typ.Name(), f.Name, err)
+		}
+		fields[name] = jsonType(dat)
+	}
+	return fields
+}
+
+func jsonType(dat []byte) string {
+	switch dat[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+func TestEventSchemasAreCompatible(t *testing.T) {
+	for typ, s := range schemas {
+		if s.version < 1 {
+			t.Errorf("%s: version must be at least 1", typ)
+			continue
+		}
+
+		path := filepath.Join("testdata", fmt.Sprintf("%s.v%d.json", typ, s.version))
+		current := payloadFields(t, s.payload)
+
+		recorded := map[string]string{}
+		dat, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err) && !*update:
+			t.Errorf("%s: no recorded schema at %s; run go test -update to create it", typ, path)
+			continue
+		case err != nil && !os.IsNotExist(err):
+			t.Fatal(err)
+		case err == nil:
+			if err := json.Unmarshal(dat, &recorded); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+
+		broken := false
+		for field, kind := range recorded {
+			if got, ok := current[field]; !ok {
+				t.Errorf("%s v%d: field %q was removed; bump the version instead", typ, s.version, field)
+				broken = true
+			} else if got != kind {
+				t.Errorf("%s v%d: field %q changed from %s to %s; bump the version instead", typ, s.version, field, kind, got)
+				broken = true
+			}
+		}
+		if broken || len(current) == len(recorded) {
+			continue
+		}
+
+		if !*update {
+			t.Errorf("%s v%d: new fields are not recorded in %s; run go test -update", typ, s.version, path)
+			continue
+		}
+		dat, err = json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, append(dat, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewSetsVersion(t *testing.T) {
+	e := New(ChirpCreated, ChirpData{})
+	if e.Version != schemas[ChirpCreated].version {
+		t.Errorf("Version = %d, want %d", e.Version, schemas[ChirpCreated].version)
+	}
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Headers of webhook deliveries.
+const (
+	WebhookEventIDHeader   = "Chirpy-Event-ID"
+	WebhookSignatureHeader = "Chirpy-Signature"
+)
+
+// maxResponseSnippet is how much of a receiver's response is logged.
+const maxResponseSnippet = 512
+
+// WebhookDelivery is one attempt at POSTing an event to the webhook.
+type WebhookDelivery struct {
+	EventID   uuid.UUID
+	EventType Type
+	Payload   []byte
+	// StatusCode is zero when the request failed before a response.
+	StatusCode      int
+	ResponseSnippet string
+	Error           string
+	Succeeded       bool
+}
+
+// DeliveryLog records webhook deliveries.
+type DeliveryLog interface {
+	RecordDelivery(ctx context.Context, d WebhookDelivery) error
+}
+
+// WebhookPublisher POSTs each event as JSON to a URL and records every
+// attempt in Log. It doesn't retry; failed events can be redelivered
+// with Deliver.
+//
+// With a Secret, requests carry a header like
+//
+//	Chirpy-Signature: t=1700000000,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// so receivers can check the body came from us, and reject replays of
+// old requests by their timestamp. Redeliveries are signed afresh and
+// keep the event ID, for receivers to de-duplicate them.
+type WebhookPublisher struct {
+	URL    string
+	Secret string
+	Client *http.Client
+	Log    DeliveryLog
+
+	now func() time.Time
+}
+
+// Publish -
+func (p *WebhookPublisher) Publish(ctx context.Context, e Event) error {
+	dat, err := encode(e)
+	if err != nil {
+		return err
+	}
+	d, err := p.Deliver(ctx, e.ID, e.Type, dat)
+	if err != nil {
+		return err
+	}
+	if !d.Succeeded {
+		return errors.New(d.Error)
+	}
+	return nil
+}
+
+// Deliver POSTs an encoded event and records the attempt. The error is
+// only set when the attempt couldn't be recorded; whether the receiver
+// accepted the event is in the returned delivery.
+func (p *WebhookPublisher) Deliver(ctx context.Context, id uuid.UUID, t Type, payload []byte) (WebhookDelivery, error) {
+	d := WebhookDelivery{EventID: id, EventType: t, Payload: payload}
+	if err := p.post(ctx, &d); err != nil {
+		d.Error = err.Error()
+	} else {
+		d.Succeeded = true
+	}
+	if err := p.Log.RecordDelivery(ctx, d); err != nil {
+		return d, fmt.Errorf("record webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (p *WebhookPublisher) post(ctx context.Context, d *WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventIDHeader, d.EventID.String())
+	if p.Secret != "" {
+		now := time.Now
+		if p.now != nil {
+			now = p.now
+		}
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(p.Secret, now(), d.Payload))
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSnippet))
+	d.StatusCode = resp.StatusCode
+	d.ResponseSnippet = string(bytes.ToValidUTF8(snippet, nil))
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the Chirpy-Signature header of a body sent at t.
+func SignWebhook(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
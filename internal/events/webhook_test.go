@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type deliveryRecorder []WebhookDelivery
+
+func (r *deliveryRecorder) RecordDelivery(ctx context.Context, d WebhookDelivery) error {
+	*r = append(*r, d)
+	return nil
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	status := http.StatusOK
+	var gotHeader http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		io.WriteString(w, strings.Repeat("x", 2*maxResponseSnippet))
+	}))
+	defer srv.Close()
+
+	var log deliveryRecorder
+	now := time.Unix(1700000000, 0)
+	p := &WebhookPublisher{URL: srv.URL, Secret: "secret", Client: srv.Client(), Log: &log, now: func() time.Time { return now }}
+
+	e := New(ChirpCreated, ChirpData{ID: uuid.New()})
+	if err := p.Publish(context.Background(), e); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if got := gotHeader.Get(WebhookEventIDHeader); got != e.ID.String() {
+		t.Errorf("%s = %q, want %q", WebhookEventIDHeader, got, e.ID)
+	}
+	if got, want := gotHeader.Get(WebhookSignatureHeader), SignWebhook("secret", now, gotBody); got != want || !strings.HasPrefix(got, "t=1700000000,v1=") {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+	}
+
+	status = http.StatusBadGateway
+	if err := p.Publish(context.Background(), e); err == nil {
+		t.Error("Publish to a failing webhook = nil, want an error")
+	}
+
+	if len(log) != 2 {
+		t.Fatalf("recorded %d deliveries, want 2", len(log))
+	}
+	if d := log[0]; !d.Succeeded || d.StatusCode != http.StatusOK || d.EventID != e.ID || string(d.Payload) != string(gotBody) {
+		t.Errorf("first delivery = %+v, want a success with the event", d)
+	}
+	if d := log[1]; d.Succeeded || d.StatusCode != http.StatusBadGateway || d.Error == "" || len(d.ResponseSnippet) != maxResponseSnippet {
+		t.Errorf("second delivery = %+v, want a failure with a %d byte snippet", d, maxResponseSnippet)
+	}
+}
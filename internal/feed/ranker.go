@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Candidate is a chirp that may be shown in a user's feed, along with
+// the signals rankers can use to order it. Engagement counts its likes
+// and shares.
+type Candidate struct {
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	AuthorID   uuid.UUID `json:"author_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Followed   bool      `json:"followed"`
+	Engagement int64     `json:"engagement"`
+}
+
+// Ranker orders candidates for a user, best first. Implementations may
+// drop candidates but must not add any.
+type Ranker interface {
+	Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]Candidate, error)
+}
+
+// HeuristicRanker scores candidates by engagement, decayed by age, with
+// a boost for authors the user follows.
+type HeuristicRanker struct {
+	// HalfLife is the age at which a chirp's score has halved.
+	HalfLife time.Duration
+	// FollowedBoost multiplies the score of chirps from followed authors.
+	FollowedBoost float64
+	now           func() time.Time
+}
+
+// NewHeuristicRanker creates a HeuristicRanker with sensible defaults.
+func NewHeuristicRanker() *HeuristicRanker {
+	return &HeuristicRanker{
+		HalfLife:      6 * time.Hour,
+		FollowedBoost: 3,
+		now:           time.Now,
+	}
+}
+
+// Rank -
+func (h *HeuristicRanker) Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]Candidate, error) {
+	now := h.now()
+	scores := make(map[uuid.UUID]float64, len(candidates))
+	for _, c := range candidates {
+		score := math.Log1p(float64(c.Engagement)) + 1
+		if c.Followed {
+			score *= h.FollowedBoost
+		}
+		age := now.Sub(c.CreatedAt)
+		scores[c.ChirpID] = score * math.Pow(0.5, age.Hours()/h.HalfLife.Hours())
+	}
+
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].ChirpID] > scores[ranked[j].ChirpID]
+	})
+	return ranked, nil
+}
+
+// ExternalRanker asks a scorer service to rank candidates, falling back
+// to another ranker when the service fails.
+//
+// The service receives {"user_id": ..., "candidates": [...]} and must
+// respond with {"chirp_ids": [...]} ordered best first.
+type ExternalRanker struct {
+	URL      string
+	Client   *http.Client
+	Fallback Ranker
+}
+
+// Rank -
+func (e *ExternalRanker) Rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]Candidate, error) {
+	ranked, err := e.rank(ctx, userID, candidates)
+	if err != nil && e.Fallback != nil {
+		return e.Fallback.Rank(ctx, userID, candidates)
+	}
+	return ranked, err
+}
+
+func (e *ExternalRanker) rank(ctx context.Context, userID uuid.UUID, candidates []Candidate) ([]Candidate, error) {
+	type request struct {
+		UserID     uuid.UUID   `json:"user_id"`
+		Candidates []Candidate `json:"candidates"`
+	}
+	type response struct {
+		ChirpIDs []uuid.UUID `json:"chirp_ids"`
+	}
+
+	dat, err := json.Marshal(request{UserID: userID, Candidates: candidates})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(dat))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("ranker service responded with %s", resp.Status)
+	}
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]Candidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.ChirpID] = c
+	}
+	ranked := make([]Candidate, 0, len(body.ChirpIDs))
+	for _, id := range body.ChirpIDs {
+		if c, ok := byID[id]; ok {
+			ranked = append(ranked, c)
+			delete(byID, id)
+		}
+	}
+	return ranked, nil
+}
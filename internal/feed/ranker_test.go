@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHeuristicRankerRank(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fresh := Candidate{ChirpID: uuid.New(), CreatedAt: now.Add(-time.Hour)}
+	old := Candidate{ChirpID: uuid.New(), CreatedAt: now.Add(-48 * time.Hour)}
+	followed := Candidate{ChirpID: uuid.New(), CreatedAt: now.Add(-2 * time.Hour), Followed: true}
+	popular := Candidate{ChirpID: uuid.New(), CreatedAt: now.Add(-3 * time.Hour), Engagement: 1000}
+
+	r := NewHeuristicRanker()
+	r.now = func() time.Time { return now }
+
+	got, err := r.Rank(context.Background(), uuid.New(), []Candidate{old, fresh, followed, popular})
+	if err != nil {
+		t.Fatalf("Rank() error = %v", err)
+	}
+
+	want := []uuid.UUID{popular.ChirpID, followed.ChirpID, fresh.ChirpID, old.ChirpID}
+	for i, c := range got {
+		if c.ChirpID != want[i] {
+			t.Errorf("Rank()[%d] = %v, want %v", i, c.ChirpID, want[i])
+		}
+	}
+}
+
+func TestExternalRankerFallsBack(t *testing.T) {
+	a := Candidate{ChirpID: uuid.New(), CreatedAt: time.Now()}
+	b := Candidate{ChirpID: uuid.New(), CreatedAt: time.Now().Add(-time.Hour)}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    []uuid.UUID
+	}{
+		{
+			name: "Service order is used",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{"chirp_ids": []uuid.UUID{b.ChirpID, uuid.New(), a.ChirpID}})
+			},
+			want: []uuid.UUID{b.ChirpID, a.ChirpID},
+		},
+		{
+			name: "Fallback on error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: []uuid.UUID{a.ChirpID, b.ChirpID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			r := &ExternalRanker{URL: srv.URL, Client: srv.Client(), Fallback: NewHeuristicRanker()}
+			got, err := r.Rank(context.Background(), uuid.New(), []Candidate{a, b})
+			if err != nil {
+				t.Fatalf("Rank() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Rank() returned %d candidates, want %d", len(got), len(tt.want))
+			}
+			for i, c := range got {
+				if c.ChirpID != tt.want[i] {
+					t.Errorf("Rank()[%d] = %v, want %v", i, c.ChirpID, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package feed
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// seenFilterSize is the size in bytes of each Bloom filter in Seen.
+	// With seenHashes hashes, one holds about 1,700 chirps before one in
+	// a hundred unseen chirps is taken for seen.
+	seenFilterSize = 2048
+	seenHashes     = 7
+)
+
+// Seen remembers which chirps a user was served recently, in a fixed
+// 4 KiB however many there were. It is a pair of Bloom filters: chirps
+// are added to Current, and Rotate drops Previous and starts a new
+// Current once a window has passed, so a chirp is remembered for one to
+// two windows.
+//
+// Like any Bloom filter it has false positives: a few chirps the user
+// never saw count as seen. It has no false negatives.
+type Seen struct {
+	Current   []byte
+	Previous  []byte
+	RotatedAt time.Time
+}
+
+// NewSeen returns a Seen with no chirps, starting its window at now.
+func NewSeen(now time.Time) Seen {
+	return Seen{
+		Current:   make([]byte, seenFilterSize),
+		Previous:  make([]byte, seenFilterSize),
+		RotatedAt: now,
+	}
+}
+
+// Valid reports whether s has filters of the right size, as stored ones
+// won't if seenFilterSize changes.
+func (s Seen) Valid() bool {
+	return len(s.Current) == seenFilterSize && len(s.Previous) == seenFilterSize
+}
+
+// Rotate starts a new window if the current one has lasted window.
+func (s *Seen) Rotate(now time.Time, window time.Duration) {
+	age := now.Sub(s.RotatedAt)
+	switch {
+	case age >= 2*window:
+		*s = NewSeen(now)
+	case age >= window:
+		s.Previous = s.Current
+		s.Current = make([]byte, seenFilterSize)
+		s.RotatedAt = now
+	}
+}
+
+// Add records id as seen.
+func (s Seen) Add(id uuid.UUID) {
+	for _, bit := range seenBits(id) {
+		s.Current[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Has reports whether id was probably seen.
+func (s Seen) Has(id uuid.UUID) bool {
+	return hasAll(s.Current, id) || hasAll(s.Previous, id)
+}
+
+func hasAll(filter []byte, id uuid.UUID) bool {
+	for _, bit := range seenBits(id) {
+		if filter[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// seenBits returns the filter bits of id by double hashing. Chirp IDs
+// are random, so their halves already make good hashes.
+func seenBits(id uuid.UUID) [seenHashes]uint64 {
+	h1 := binary.BigEndian.Uint64(id[:8])
+	h2 := binary.BigEndian.Uint64(id[8:]) | 1
+	var bits [seenHashes]uint64
+	for i := range bits {
+		bits[i] = (h1 + uint64(i)*h2) % (seenFilterSize * 8)
+	}
+	return bits
+}
@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSeen(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 24 * time.Hour
+	s := NewSeen(start)
+
+	old, recent := uuid.New(), uuid.New()
+	s.Add(old)
+	if !s.Has(old) {
+		t.Fatal("Has(old) = false right after Add")
+	}
+
+	s.Rotate(start.Add(window), window)
+	s.Add(recent)
+	if !s.Has(old) || !s.Has(recent) {
+		t.Error("chirps from the previous window were forgotten after one rotation")
+	}
+
+	s.Rotate(start.Add(2*window), window)
+	if s.Has(old) {
+		t.Error("Has(old) = true two windows later")
+	}
+	if !s.Has(recent) {
+		t.Error("Has(recent) = false one window later")
+	}
+
+	s.Rotate(start.Add(5*window), window)
+	if s.Has(recent) {
+		t.Error("Has(recent) = true after a long absence")
+	}
+}
+
+func TestSeenFalsePositives(t *testing.T) {
+	s := NewSeen(time.Now())
+	for range 1000 {
+		s.Add(uuid.New())
+	}
+	falsePositives := 0
+	for range 10000 {
+		if s.Has(uuid.New()) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 100 {
+		t.Errorf("%d of 10000 unseen chirps taken for seen, want under 1%%", falsePositives)
+	}
+}
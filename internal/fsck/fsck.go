@@ -0,0 +1,75 @@
+// Package fsck finds and repairs referential inconsistencies in the
+// database, such as rows left behind after manual DB surgery with
+// foreign keys disabled.
+package fsck
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Check looks for one kind of inconsistency.
+type Check struct {
+	Name string
+	// Find returns a description of each inconsistent row.
+	Find func(ctx context.Context) ([]string, error)
+	// Repair removes the inconsistent rows and returns how many it removed.
+	Repair func(ctx context.Context) (int64, error)
+}
+
+// Result is the outcome of running one check.
+type Result struct {
+	Check    string
+	Problems []string
+	Repaired int64
+}
+
+// Run runs the checks in order, repairing what they find if fix is set.
+// Order matters when repairing: removing orphaned chirps can orphan the
+// likes on them, so checks for dependent rows should come later.
+func Run(ctx context.Context, checks []Check, fix bool) ([]Result, error) {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		problems, err := c.Find(ctx)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		res := Result{Check: c.Name, Problems: problems}
+		if fix && len(problems) > 0 {
+			res.Repaired, err = c.Repair(ctx)
+			if err != nil {
+				return results, fmt.Errorf("%s: repair: %w", c.Name, err)
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Clean reports whether no check found anything.
+func Clean(results []Result) bool {
+	for _, res := range results {
+		if len(res.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Report writes a human-readable summary of results to w.
+func Report(w io.Writer, results []Result) {
+	for _, res := range results {
+		switch {
+		case len(res.Problems) == 0:
+			fmt.Fprintf(w, "%s: ok\n", res.Check)
+		case res.Repaired > 0:
+			fmt.Fprintf(w, "%s: %d found, %d repaired\n", res.Check, len(res.Problems), res.Repaired)
+		default:
+			fmt.Fprintf(w, "%s: %d found\n", res.Check, len(res.Problems))
+		}
+		for _, p := range res.Problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package fsck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func fakeCheck(name string, problems []string, repaired *bool) Check {
+	return Check{
+		Name: name,
+		Find: func(ctx context.Context) ([]string, error) {
+			if *repaired {
+				return nil, nil
+			}
+			return problems, nil
+		},
+		Repair: func(ctx context.Context) (int64, error) {
+			*repaired = true
+			return int64(len(problems)), nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		fix          bool
+		wantRepaired int64
+		wantReport   string
+	}{
+		{
+			name:       "Report only",
+			fix:        false,
+			wantReport: "orphaned_chirps: 2 found\n  chirp a\n  chirp b\norphaned_likes: ok\n",
+		},
+		{
+			name:         "Fix",
+			fix:          true,
+			wantRepaired: 2,
+			wantReport:   "orphaned_chirps: 2 found, 2 repaired\n  chirp a\n  chirp b\norphaned_likes: ok\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repaired, likesRepaired bool
+			checks := []Check{
+				fakeCheck("orphaned_chirps", []string{"chirp a", "chirp b"}, &repaired),
+				fakeCheck("orphaned_likes", nil, &likesRepaired),
+			}
+
+			results, err := Run(context.Background(), checks, tt.fix)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if repaired != tt.fix {
+				t.Errorf("repaired = %v, want %v", repaired, tt.fix)
+			}
+			if likesRepaired {
+				t.Error("Repair called for a clean check")
+			}
+			if results[0].Repaired != tt.wantRepaired {
+				t.Errorf("Repaired = %d, want %d", results[0].Repaired, tt.wantRepaired)
+			}
+			if Clean(results) {
+				t.Error("Clean() = true, want false")
+			}
+
+			var buf bytes.Buffer
+			Report(&buf, results)
+			if buf.String() != tt.wantReport {
+				t.Errorf("Report() = %q, want %q", buf.String(), tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestRunStopsOnError(t *testing.T) {
+	errBoom := errors.New("boom")
+	ran := false
+	checks := []Check{
+		{Name: "broken", Find: func(ctx context.Context) ([]string, error) { return nil, errBoom }},
+		{Name: "after", Find: func(ctx context.Context) ([]string, error) { ran = true; return nil, nil }},
+	}
+
+	_, err := Run(context.Background(), checks, false)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Run() error = %v, want %v", err, errBoom)
+	}
+	if ran {
+		t.Error("checks after a failure should not run")
+	}
+}
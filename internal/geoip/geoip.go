@@ -0,0 +1,182 @@
+// Package geoip looks up where IP addresses are in a local MaxMind DB
+// file (.mmdb), such as GeoLite2 City, and tells whether two logins are
+// too far apart for the time between them.
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"time"
+)
+
+// metadataMarker precedes the metadata at the end of the file.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// Location is where an IP address is, as precise as the database gets.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Country   string // ISO 3166-1 code, if known
+}
+
+// Reader looks up addresses in a database held in memory.
+type Reader struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	data       decoder // the data section
+	ipv4Start  uint    // node where IPv4 addresses start in an IPv6 tree
+}
+
+// Open reads the database at path.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(buf)
+}
+
+// New parses a database.
+func New(buf []byte) (*Reader, error) {
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("geoip: not a MaxMind DB file")
+	}
+	meta, _, err := decoder{buf: buf[i+len(metadataMarker):]}.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: invalid metadata: %w", err)
+	}
+	m, ok := meta.(map[string]any)
+	if !ok {
+		return nil, errors.New("geoip: invalid metadata")
+	}
+	r := &Reader{
+		buf:        buf,
+		nodeCount:  uint(asUint(m["node_count"])),
+		recordSize: uint(asUint(m["record_size"])),
+		ipVersion:  uint(asUint(m["ip_version"])),
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("geoip: unsupported record size %d", r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("geoip: unsupported IP version %d", r.ipVersion)
+	}
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+16 > uint(i) {
+		return nil, errors.New("geoip: search tree is truncated")
+	}
+	r.data = decoder{buf: buf[treeSize+16 : i]}
+
+	if r.ipVersion == 6 {
+		// IPv4 addresses are looked up as ::a.b.c.d
+		for n := 0; n < 96 && r.ipv4Start < r.nodeCount; n++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of a node.
+func (r *Reader) record(node uint, bit byte) uint {
+	switch r.recordSize {
+	case 24:
+		b := r.buf[node*6+uint(bit)*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := r.buf[node*7:]
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b := r.buf[node*8+uint(bit)*4:]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+}
+
+// Lookup returns the location of ip. ok is false if the database doesn't
+// know where it is.
+func (r *Reader) Lookup(ip netip.Addr) (loc Location, ok bool, err error) {
+	ip = ip.Unmap()
+	var node uint
+	var addr []byte
+	switch {
+	case ip.Is4() && r.ipVersion == 6:
+		node = r.ipv4Start
+		a := ip.As4()
+		addr = a[:]
+	case ip.Is4():
+		a := ip.As4()
+		addr = a[:]
+	case r.ipVersion == 6:
+		a := ip.As16()
+		addr = a[:]
+	default:
+		return Location{}, false, nil
+	}
+
+	for i := 0; i < len(addr)*8 && node < r.nodeCount; i++ {
+		node = r.record(node, addr[i/8]>>(7-i%8)&1)
+	}
+	if node <= r.nodeCount {
+		return Location{}, false, nil
+	}
+
+	v, _, err := r.data.decode(node - r.nodeCount - 16)
+	if err != nil {
+		return Location{}, false, fmt.Errorf("geoip: invalid record for %s: %w", ip, err)
+	}
+	rec, _ := v.(map[string]any)
+	location, _ := rec["location"].(map[string]any)
+	lat, hasLat := location["latitude"].(float64)
+	lon, hasLon := location["longitude"].(float64)
+	if !hasLat || !hasLon {
+		return Location{}, false, nil
+	}
+	country, _ := rec["country"].(map[string]any)
+	code, _ := country["iso_code"].(string)
+	return Location{Latitude: lat, Longitude: lon, Country: code}, true, nil
+}
+
+// earthRadius is the mean radius of the Earth in km.
+const earthRadius = 6371.0
+
+// Distance returns the great-circle distance between a and b in km.
+func Distance(a, b Location) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Accuracy is how far from the truth a lookup may be, in km. IP
+// locations are often the provider's nearest city or data centre, so
+// this much of the distance between two logins is forgiven.
+const Accuracy = 250.0
+
+// ImpossibleTravel reports whether getting from a to b within elapsed
+// takes more than maxSpeed km/h, allowing for the Accuracy of both
+// lookups.
+func ImpossibleTravel(a, b Location, elapsed time.Duration, maxSpeed float64) bool {
+	distance := Distance(a, b) - 2*Accuracy
+	if distance <= 0 {
+		return false
+	}
+	if elapsed <= 0 {
+		return true
+	}
+	return distance/elapsed.Hours() > maxSpeed
+}
+
+func asUint(v any) uint64 {
+	n, _ := v.(uint64)
+	return n
+}
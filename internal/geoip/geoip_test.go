@@ -0,0 +1,228 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// encode writes v in the data section format.
+func encode(v any) []byte {
+	var payload []byte
+	var typ, size int
+	switch v := v.(type) {
+	case string:
+		typ, payload = typeString, []byte(v)
+	case float64:
+		typ, payload = typeDouble, binary.BigEndian.AppendUint64(nil, math.Float64bits(v))
+	case uint16:
+		typ, payload = typeUint16, binary.BigEndian.AppendUint16(nil, v)
+	case uint32:
+		typ, payload = typeUint32, binary.BigEndian.AppendUint32(nil, v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			payload = append(payload, encode(k)...)
+			payload = append(payload, encode(v[k])...)
+		}
+		typ, size = typeMap, len(v)
+	case []any:
+		for _, e := range v {
+			payload = append(payload, encode(e)...)
+		}
+		typ, size = typeArray, len(v)
+	default:
+		panic("unsupported type")
+	}
+	if typ != typeMap && typ != typeArray {
+		size = len(payload)
+	}
+
+	var head []byte
+	switch {
+	case size < 29:
+		head = []byte{byte(size)}
+	case size < 285:
+		head = []byte{29, byte(size - 29)}
+	default:
+		head = []byte{30, byte((size - 285) >> 8), byte(size - 285)}
+	}
+	if typ < 8 {
+		head[0] |= byte(typ) << 5
+	} else {
+		head = append([]byte{head[0]}, append([]byte{byte(typ - 7)}, head[1:]...)...)
+	}
+	return append(head, payload...)
+}
+
+// buildIPv4 writes a database with 24 bit records mapping each prefix
+// to its record.
+func buildIPv4(t *testing.T, records map[string]map[string]any) []byte {
+	t.Helper()
+	const empty = -1
+	nodes := [][2]int{{empty, empty}}
+	var data []byte
+	// Record values below -1 refer to data at offset -value-2
+	for prefix, rec := range records {
+		p := netip.MustParsePrefix(prefix)
+		addr := p.Addr().As4()
+		dataRef := -len(data) - 2
+		data = append(data, encode(rec)...)
+
+		node := 0
+		for i := 0; i < p.Bits(); i++ {
+			bit := addr[i/8] >> (7 - i%8) & 1
+			if i == p.Bits()-1 {
+				nodes[node][bit] = dataRef
+				break
+			}
+			if nodes[node][bit] == empty {
+				nodes = append(nodes, [2]int{empty, empty})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	n := len(nodes)
+	var buf []byte
+	for _, node := range nodes {
+		for _, rec := range node {
+			v := rec
+			switch {
+			case rec == empty:
+				v = n
+			case rec < empty:
+				v = n + 16 + (-rec - 2)
+			}
+			buf = append(buf, byte(v>>16), byte(v>>8), byte(v))
+		}
+	}
+	buf = append(buf, make([]byte, 16)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	buf = append(buf, encode(map[string]any{
+		"node_count":    uint32(n),
+		"record_size":   uint16(24),
+		"ip_version":    uint16(4),
+		"database_type": "Test-City",
+	})...)
+	return buf
+}
+
+func TestLookup(t *testing.T) {
+	r, err := New(buildIPv4(t, map[string]map[string]any{
+		"81.2.69.0/24": {
+			"country":  map[string]any{"iso_code": "GB"},
+			"location": map[string]any{"latitude": 51.5142, "longitude": -0.0931},
+		},
+		"89.160.20.0/24": {
+			"country":  map[string]any{"iso_code": "SE"},
+			"location": map[string]any{"latitude": 58.4167, "longitude": 15.6167},
+		},
+		"10.0.0.0/8": {"country": map[string]any{"iso_code": "ZZ"}},
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		ip     string
+		want   Location
+		wantOK bool
+	}{
+		{"81.2.69.160", Location{51.5142, -0.0931, "GB"}, true},
+		{"::ffff:89.160.20.1", Location{58.4167, 15.6167, "SE"}, true},
+		{"10.1.2.3", Location{}, false}, // no location
+		{"192.0.2.1", Location{}, false},
+		{"2001:db8::1", Location{}, false},
+	}
+	for _, tt := range tests {
+		got, ok, err := r.Lookup(netip.MustParseAddr(tt.ip))
+		if err != nil {
+			t.Errorf("Lookup(%s) error = %v", tt.ip, err)
+		}
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%s) = %+v, %v, want %+v, %v", tt.ip, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewRejectsOtherFiles(t *testing.T) {
+	if _, err := New([]byte("not a database")); err == nil {
+		t.Error("New() error = nil, want an error")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want any
+	}{
+		{"String", encode("hello"), "hello"},
+		{"Long string", encode(string(make([]byte, 300))), string(make([]byte, 300))},
+		{"Uint32", encode(uint32(70000)), uint64(70000)},
+		{"Array", encode([]any{"a", uint16(2)}), []any{"a", uint64(2)}},
+		{"Int32", []byte{0x04, 0x01, 0xff, 0xff, 0xff, 0xfe}, int64(-2)},
+		{"Uint128", []byte{0x02, 0x03, 0x01, 0x00}, big.NewInt(256)},
+		{"Bool", []byte{0x01, 0x07}, true},
+		// A pointer to offset 3, followed by the string it points to
+		{"Pointer", append([]byte{0x20, 0x03, 0x00}, encode("there")...), "there"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := decoder{buf: tt.buf}.decode(0)
+			if err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsPointerLoops(t *testing.T) {
+	if _, _, err := (decoder{buf: []byte{0x20, 0x00}}).decode(0); err == nil {
+		t.Error("decode() error = nil, want an error")
+	}
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	london := Location{Latitude: 51.5072, Longitude: -0.1276}
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+	sydney := Location{Latitude: -33.8688, Longitude: 151.2093}
+
+	if d := Distance(london, paris); d < 340 || d > 350 {
+		t.Errorf("Distance(London, Paris) = %.0f km, want about 344", d)
+	}
+
+	tests := []struct {
+		name    string
+		a, b    Location
+		elapsed time.Duration
+		want    bool
+	}{
+		{"Nearby within the accuracy", london, paris, time.Minute, false},
+		{"Flight time", london, sydney, 24 * time.Hour, false},
+		{"Too fast", london, sydney, time.Hour, true},
+		{"Same instant", london, sydney, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImpossibleTravel(tt.a, tt.b, tt.elapsed, 1000); got != tt.want {
+				t.Errorf("ImpossibleTravel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the state of a component at the time it was sampled.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckFunc reports whether a component is healthy. A nil error means up.
+type CheckFunc func(ctx context.Context) error
+
+// Sample is the result of a single check.
+type Sample struct {
+	Time    time.Time     `json:"time"`
+	Status  Status        `json:"status"`
+	Latency time.Duration `json:"latency_ns"`
+	Error   string        `json:"-"`
+}
+
+// Bucket aggregates the samples taken during one hour.
+type Bucket struct {
+	Start    time.Time `json:"start"`
+	Samples  int       `json:"samples"`
+	Failures int       `json:"failures"`
+}
+
+// ComponentStatus is a point-in-time view of a component and its history.
+type ComponentStatus struct {
+	Name    string   `json:"name"`
+	Current *Sample  `json:"current"`
+	Uptime  float64  `json:"uptime"`
+	History []Bucket `json:"history"`
+}
+
+type component struct {
+	name    string
+	check   CheckFunc
+	samples []Sample
+}
+
+// Checker periodically runs the registered checks and keeps their
+// samples in memory for the retention window.
+type Checker struct {
+	mu         sync.RWMutex
+	components []*component
+	retention  time.Duration
+	timeout    time.Duration
+	now        func() time.Time
+}
+
+// NewChecker creates a Checker keeping samples for retention and giving
+// each check at most timeout to complete.
+func NewChecker(retention, timeout time.Duration) *Checker {
+	return &Checker{
+		retention: retention,
+		timeout:   timeout,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Register adds a component to be checked. Components are reported in
+// registration order.
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components = append(c.components, &component{name: name, check: check})
+}
+
+// Run checks every component once per interval until ctx is cancelled.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	c.RunOnce(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce checks every component and records the results.
+func (c *Checker) RunOnce(ctx context.Context) {
+	c.mu.RLock()
+	components := append([]*component(nil), c.components...)
+	c.mu.RUnlock()
+
+	for _, comp := range components {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		start := time.Now()
+		err := comp.check(checkCtx)
+		cancel()
+
+		sample := Sample{
+			Time:    c.now(),
+			Status:  StatusUp,
+			Latency: time.Since(start),
+		}
+		if err != nil {
+			sample.Status = StatusDown
+			sample.Error = err.Error()
+		}
+		c.record(comp, sample)
+	}
+}
+
+func (c *Checker) record(comp *component, sample Sample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	comp.samples = append(comp.samples, sample)
+
+	cutoff := sample.Time.Add(-c.retention)
+	i := 0
+	for i < len(comp.samples) && comp.samples[i].Time.Before(cutoff) {
+		i++
+	}
+	comp.samples = comp.samples[i:]
+}
+
+// Snapshot returns the current status of every component along with
+// hourly buckets covering the retention window.
+func (c *Checker) Snapshot() []ComponentStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	out := make([]ComponentStatus, 0, len(c.components))
+	for _, comp := range c.components {
+		status := ComponentStatus{
+			Name:    comp.name,
+			History: []Bucket{},
+		}
+		if len(comp.samples) == 0 {
+			out = append(out, status)
+			continue
+		}
+
+		current := comp.samples[len(comp.samples)-1]
+		status.Current = &current
+
+		failures := 0
+		buckets := map[time.Time]*Bucket{}
+		for _, s := range comp.samples {
+			start := s.Time.Truncate(time.Hour)
+			b, ok := buckets[start]
+			if !ok {
+				b = &Bucket{Start: start}
+				buckets[start] = b
+			}
+			b.Samples++
+			if s.Status != StatusUp {
+				b.Failures++
+				failures++
+			}
+		}
+		status.Uptime = float64(len(comp.samples)-failures) / float64(len(comp.samples))
+
+		for start := now.Add(-c.retention).Truncate(time.Hour); !start.After(now); start = start.Add(time.Hour) {
+			if b, ok := buckets[start]; ok {
+				status.History = append(status.History, *b)
+			}
+		}
+		out = append(out, status)
+	}
+	return out
+}
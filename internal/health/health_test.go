@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerRecordsSamples(t *testing.T) {
+	checker := NewChecker(24*time.Hour, time.Second)
+	checker.Register("api", func(ctx context.Context) error { return nil })
+	checker.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	checker.RunOnce(context.Background())
+	snapshot := checker.Snapshot()
+
+	if len(snapshot) != 2 {
+		t.Fatalf("Snapshot() returned %d components, want 2", len(snapshot))
+	}
+
+	tests := []struct {
+		name       string
+		wantStatus Status
+		wantUptime float64
+	}{
+		{name: "api", wantStatus: StatusUp, wantUptime: 1},
+		{name: "database", wantStatus: StatusDown, wantUptime: 0},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshot[i]
+			if got.Name != tt.name {
+				t.Errorf("Name = %v, want %v", got.Name, tt.name)
+			}
+			if got.Current == nil || got.Current.Status != tt.wantStatus {
+				t.Errorf("Current = %v, want status %v", got.Current, tt.wantStatus)
+			}
+			if got.Uptime != tt.wantUptime {
+				t.Errorf("Uptime = %v, want %v", got.Uptime, tt.wantUptime)
+			}
+		})
+	}
+}
+
+func TestCheckerPrunesOldSamples(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	checker := NewChecker(time.Hour, time.Second)
+	checker.now = func() time.Time { return now }
+	checker.Register("api", func(ctx context.Context) error { return nil })
+
+	checker.RunOnce(context.Background())
+	now = now.Add(2 * time.Hour)
+	checker.RunOnce(context.Background())
+
+	snapshot := checker.Snapshot()
+	if got := len(snapshot[0].History); got != 1 {
+		t.Fatalf("History has %d buckets, want 1", got)
+	}
+	if got := snapshot[0].History[0].Samples; got != 1 {
+		t.Errorf("Bucket has %d samples, want 1", got)
+	}
+}
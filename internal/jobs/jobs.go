@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"main.go/internal/database"
+)
+
+// Job statuses as stored in the jobs table.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// DefaultMaxAttempts is how many times a job is tried before it is
+// marked failed.
+const DefaultMaxAttempts = 5
+
+// staleAfter is how long a job may stay running before it is assumed
+// its worker died and it is put back in the queue.
+const staleAfter = 15 * time.Minute
+
+// Handler processes the payload of a single job.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Queue is a Postgres-backed job queue. Workers claim jobs with
+// SELECT ... FOR UPDATE SKIP LOCKED, so several instances can share it.
+type Queue struct {
+	db       *database.Queries
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	lastPoll atomic.Int64
+}
+
+// NewQueue creates a Queue backed by db.
+func NewQueue(db *database.Queries) *Queue {
+	return &Queue{
+		db:       db,
+		handlers: map[string]Handler{},
+	}
+}
+
+// Handle registers the handler for a job type.
+func (q *Queue) Handle(jobType string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = h
+}
+
+// Enqueue schedules a job of the given type to run as soon as possible.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any) (database.Job, error) {
+	dat, err := json.Marshal(payload)
+	if err != nil {
+		return database.Job{}, fmt.Errorf("couldn't encode job payload: %w", err)
+	}
+	return q.db.EnqueueJob(ctx, database.EnqueueJobParams{
+		Type:        jobType,
+		Payload:     dat,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       time.Now().UTC(),
+	})
+}
+
+// LastPoll returns when the worker last checked the queue.
+func (q *Queue) LastPoll() time.Time {
+	return time.Unix(0, q.lastPoll.Load())
+}
+
+// Run works through due jobs, polling every interval, until ctx is
+// cancelled.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		q.lastPoll.Store(time.Now().UnixNano())
+
+		n, err := q.db.RequeueStaleJobs(ctx, time.Now().UTC().Add(-staleAfter))
+		if err != nil {
+			slog.ErrorContext(ctx, "Couldn't requeue stale jobs", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "Requeued stale jobs", "count", n)
+		}
+
+		for q.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs a single job. It reports whether a job was
+// found, so the caller can keep draining the queue.
+func (q *Queue) runNext(ctx context.Context) bool {
+	job, err := q.db.ClaimNextJob(ctx)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.ErrorContext(ctx, "Couldn't claim job", "error", err)
+		}
+		return false
+	}
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %q", job.Type)
+	} else {
+		err = handler(ctx, job.Payload)
+	}
+
+	if err == nil {
+		if err := q.db.CompleteJob(ctx, job.ID); err != nil {
+			slog.ErrorContext(ctx, "Couldn't complete job", "job_id", job.ID, "job_type", job.Type, "error", err)
+		}
+		return true
+	}
+
+	slog.WarnContext(ctx, "Job failed", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "max_attempts", job.MaxAttempts, "error", err)
+	err = q.db.FailJob(ctx, database.FailJobParams{
+		ID:        job.ID,
+		LastError: sql.NullString{String: err.Error(), Valid: true},
+		RunAt:     time.Now().UTC().Add(backoff(job.Attempts)),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Couldn't record job failure", "job_id", job.ID, "job_type", job.Type, "error", err)
+	}
+	return true
+}
+
+// backoff grows quadratically with the number of attempts.
+func backoff(attempts int32) time.Duration {
+	return time.Duration(attempts*attempts) * 10 * time.Second
+}
@@ -0,0 +1,30 @@
+// Package mail sends email to users.
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogMailer writes messages to the default slog logger instead of sending
+// them, for development until a real mail service is configured.
+type LogMailer struct{}
+
+// Send -
+func (LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Mail", "to", msg.To, "subject", msg.Subject, "body", strings.TrimRight(msg.Body, "\n"))
+	return nil
+}
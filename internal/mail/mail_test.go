@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := LogMailer{}.Send(context.Background(), Message{
+		To:      "walt@example.com",
+		Subject: "Reset your password",
+		Body:    "Your token is abc123\n",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{"walt@example.com", "Reset your password", "Your token is abc123"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+}
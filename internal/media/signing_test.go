@@ -0,0 +1,39 @@
+package media
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyURL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	signed := SignURL("/media/private/a.png", "secret", now.Add(time.Hour))
+	_, rawQuery, _ := strings.Cut(signed, "?")
+	query, _ := url.ParseQuery(rawQuery)
+
+	tests := []struct {
+		name    string
+		path    string
+		query   url.Values
+		secret  string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Valid signature", path: "/media/private/a.png", query: query, secret: "secret", now: now, wantErr: nil},
+		{name: "Other path", path: "/media/private/b.png", query: query, secret: "secret", now: now, wantErr: ErrInvalidSignature},
+		{name: "Wrong secret", path: "/media/private/a.png", query: query, secret: "other", now: now, wantErr: ErrInvalidSignature},
+		{name: "Expired", path: "/media/private/a.png", query: query, secret: "secret", now: now.Add(2 * time.Hour), wantErr: ErrExpiredSignature},
+		{name: "Missing signature", path: "/media/private/a.png", query: url.Values{}, secret: "secret", now: now, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyURL(tt.path, tt.query, tt.secret, tt.now)
+			if err != tt.wantErr {
+				t.Errorf("VerifyURL() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package media
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedType is returned by ImageExtension for anything but the
+// image formats browsers display everywhere.
+var ErrUnsupportedType = errors.New("unsupported image type")
+
+// imageExtensions maps the sniffed content types we accept to the
+// extension files are saved with.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ImageExtension sniffs the content type of a file from its first bytes,
+// ignoring whatever the uploader claimed, and returns the extension to
+// save it with.
+func ImageExtension(head []byte) (string, error) {
+	ext, ok := imageExtensions[http.DetectContentType(head)]
+	if !ok {
+		return "", ErrUnsupportedType
+	}
+	return ext, nil
+}
+
+// Store keeps uploaded files on disk, under the root the media handler
+// serves from.
+type Store struct {
+	root string
+}
+
+// NewStore returns a Store that saves files under root.
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+// Save writes r to a new file with a random name in dir and returns its
+// slash-separated path relative to the root. Readers never see a
+// partially written file.
+func (s *Store) Save(dir, ext string, r io.Reader) (string, error) {
+	if !fs.ValidPath(dir) {
+		return "", fs.ErrInvalid
+	}
+	if err := os.MkdirAll(filepath.Join(s.root, filepath.FromSlash(dir)), 0o755); err != nil {
+		return "", err
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	name := path.Join(dir, hex.EncodeToString(b[:])+ext)
+
+	tmp, err := os.CreateTemp(filepath.Join(s.root, filepath.FromSlash(dir)), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.root, filepath.FromSlash(name))); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Remove deletes a file returned by Save. A missing file is not an
+// error.
+func (s *Store) Remove(name string) error {
+	if !fs.ValidPath(name) {
+		return fs.ErrInvalid
+	}
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Files returns the files in dir, named as Save returns them, that were
+// last modified before the given time. Temporary files of uploads in
+// progress are left out, and a missing dir has no files.
+func (s *Store) Files(dir string, before time.Time) ([]string, error) {
+	if !fs.ValidPath(dir) {
+		return nil, fs.ErrInvalid
+	}
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.FromSlash(dir)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.ModTime().Before(before) {
+			names = append(names, path.Join(dir, e.Name()))
+		}
+	}
+	return names, nil
+}
+
+// Check reports whether files can be written under the root, for the
+// health checker.
+func (s *Store) Check(ctx context.Context) error {
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.root, ".healthcheck-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
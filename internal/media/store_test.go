@@ -0,0 +1,82 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImageExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    []byte
+		want    string
+		wantErr error
+	}{
+		{name: "PNG", head: []byte("\x89PNG\r\n\x1a\n0000"), want: ".png"},
+		{name: "JPEG", head: []byte("\xff\xd8\xff\xe0"), want: ".jpg"},
+		{name: "GIF", head: []byte("GIF89a"), want: ".gif"},
+		{name: "WebP", head: []byte("RIFF0000WEBPVP8 "), want: ".webp"},
+		{name: "HTML", head: []byte("<html><script>"), wantErr: ErrUnsupportedType},
+		{name: "SVG", head: []byte(`<svg xmlns="http://www.w3.org/2000/svg">`), wantErr: ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImageExtension(tt.head)
+			if err != tt.wantErr || got != tt.want {
+				t.Errorf("ImageExtension() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root)
+
+	if err := store.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	name, err := store.Save("avatars", ".png", strings.NewReader("image"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !strings.HasPrefix(name, "avatars/") || !strings.HasSuffix(name, ".png") {
+		t.Errorf("Save() = %q, want avatars/*.png", name)
+	}
+	got, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil || !bytes.Equal(got, []byte("image")) {
+		t.Errorf("saved file = %q, %v, want %q", got, err, "image")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(root, "avatars"))
+	if len(entries) != 1 {
+		t.Errorf("avatars has %d files, want 1 (temporary files left behind?)", len(entries))
+	}
+
+	if files, err := store.Files("avatars", time.Now().Add(time.Minute)); err != nil || len(files) != 1 || files[0] != name {
+		t.Errorf("Files() = %q, %v, want [%s]", files, err, name)
+	}
+	if files, err := store.Files("avatars", time.Now().Add(-time.Minute)); err != nil || len(files) != 0 {
+		t.Errorf("Files() before the upload = %q, %v, want none", files, err)
+	}
+	if files, err := store.Files("chirps", time.Now()); err != nil || len(files) != 0 {
+		t.Errorf("Files() of a missing dir = %q, %v, want none", files, err)
+	}
+
+	if err := store.Remove(name); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if err := store.Remove(name); err != nil {
+		t.Errorf("Remove() of a missing file error = %v, want nil", err)
+	}
+	if _, err := store.Save("../outside", ".png", strings.NewReader("image")); err == nil {
+		t.Error("Save() outside the root succeeded")
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Exclusions describes traffic that isn't a real visitor, such as health
+// checks, monitoring probes and crawlers, so it can be left out of hit
+// counters.
+type Exclusions struct {
+	// Paths are URL path prefixes.
+	Paths []string
+	// UserAgents are matched case-insensitively anywhere in the
+	// User-Agent header.
+	UserAgents []string
+}
+
+// DefaultExclusions leaves out the usual uptime monitors, load balancer
+// and orchestrator probes, metrics scrapers and crawlers.
+var DefaultExclusions = Exclusions{
+	Paths: []string{"/api/healthz", "/api/v1/healthz", "/admin/metrics", "/metrics", "/status", "/robots.txt", "/app/robots.txt"},
+	UserAgents: []string{
+		"bot", "crawler", "spider",
+		"kube-probe", "ELB-HealthChecker", "GoogleHC",
+		"UptimeRobot", "Pingdom", "StatusCake", "Site24x7",
+		"Prometheus", "Blackbox Exporter",
+	},
+}
+
+// ParseList splits a comma-separated setting, ignoring blank entries.
+func ParseList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Excluded reports whether r should not be counted.
+func (e Exclusions) Excluded(r *http.Request) bool {
+	for _, p := range e.Paths {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return true
+		}
+	}
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return false
+	}
+	for _, u := range e.UserAgents {
+		if strings.Contains(ua, strings.ToLower(u)) {
+			return true
+		}
+	}
+	return false
+}
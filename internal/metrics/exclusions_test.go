@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		userAgent string
+		want      bool
+	}{
+		{name: "Browser", path: "/app/", userAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", want: false},
+		{name: "No user agent", path: "/app/", want: false},
+		{name: "Health check path", path: "/api/healthz", userAgent: "curl/8.5.0", want: true},
+		{name: "Versioned health check path", path: "/api/v1/healthz", userAgent: "curl/8.5.0", want: true},
+		{name: "Metrics scrape", path: "/metrics", userAgent: "Prometheus/2.53.0", want: true},
+		{name: "Crawler", path: "/app/", userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)", want: true},
+		{name: "Monitor", path: "/app/", userAgent: "Mozilla/5.0+(compatible; UptimeRobot/2.0)", want: true},
+		{name: "Kubernetes probe", path: "/app/", userAgent: "kube-probe/1.30", want: true},
+		{name: "Case insensitive", path: "/app/", userAgent: "SOMESPIDER/1.0", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.path, nil)
+			if tt.userAgent != "" {
+				r.Header.Set("User-Agent", tt.userAgent)
+			}
+			if got := DefaultExclusions.Excluded(r); got != tt.want {
+				t.Errorf("Excluded(%s, %q) = %v, want %v", tt.path, tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmptyExclusions(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/healthz", nil)
+	r.Header.Set("User-Agent", "Googlebot")
+	if (Exclusions{}).Excluded(r) {
+		t.Error("empty Exclusions excluded a request")
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "bot", want: []string{"bot"}},
+		{in: " bot, ,kube-probe ,", want: []string{"bot", "kube-probe"}},
+	}
+	for _, tt := range tests {
+		if got := ParseList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseList(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package metrics keeps named counters that survive restarts. Counters
+// are incremented in memory and the increments are periodically added to
+// a Store, so several instances can share the same all-time totals.
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Store persists counter totals.
+type Store interface {
+	// LoadCounters returns the stored total of every counter.
+	LoadCounters(ctx context.Context) (map[string]int64, error)
+	// AddToCounter adds delta to a counter and returns its new total.
+	AddToCounter(ctx context.Context, name string, delta int64) (int64, error)
+	// ResetCounters sets every stored total to zero.
+	ResetCounters(ctx context.Context) error
+}
+
+// Counter counts events since boot and all time.
+type Counter struct {
+	name      string
+	sinceBoot atomic.Int64
+	pending   atomic.Int64 // not yet added to the store
+
+	mu     sync.Mutex
+	stored int64
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.sinceBoot.Add(1)
+	c.pending.Add(1)
+}
+
+// SinceBoot returns the count since this process started.
+func (c *Counter) SinceBoot() int64 {
+	return c.sinceBoot.Load()
+}
+
+// AllTime returns the stored total plus what this process hasn't flushed.
+func (c *Counter) AllTime() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stored + c.pending.Load()
+}
+
+// Snapshot is a counter's values at a point in time.
+type Snapshot struct {
+	Name      string `json:"name"`
+	SinceBoot int64  `json:"since_boot"`
+	AllTime   int64  `json:"all_time"`
+}
+
+// Registry holds the counters backed by one store.
+type Registry struct {
+	store Store
+
+	mu       sync.Mutex
+	counters map[string]*Counter
+}
+
+// NewRegistry creates an empty registry backed by store.
+func NewRegistry(store Store) *Registry {
+	return &Registry{
+		store:    store,
+		counters: map[string]*Counter{},
+	}
+}
+
+// Counter returns the counter with the given name, creating it if needed.
+func (r *Registry) Counter(name string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counters[name]
+	if !ok {
+		c = &Counter{name: name}
+		r.counters[name] = c
+	}
+	return c
+}
+
+func (r *Registry) list() []*Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*Counter, 0, len(r.counters))
+	for _, c := range r.counters {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// Load reads the stored totals. Call it at startup; counters registered
+// later pick up their total on the next Flush.
+func (r *Registry) Load(ctx context.Context) error {
+	totals, err := r.store.LoadCounters(ctx)
+	if err != nil {
+		return err
+	}
+	for name, total := range totals {
+		c := r.Counter(name)
+		c.mu.Lock()
+		c.stored = total
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// Flush adds each counter's unflushed increments to the store. Increments
+// that fail to save are kept for the next Flush.
+func (r *Registry) Flush(ctx context.Context) error {
+	for _, c := range r.list() {
+		if err := c.flush(ctx, r.store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Counter) flush(ctx context.Context, store Store) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delta := c.pending.Swap(0)
+	if delta == 0 {
+		return nil
+	}
+	total, err := store.AddToCounter(ctx, c.name, delta)
+	if err != nil {
+		c.pending.Add(delta)
+		return err
+	}
+	c.stored = total
+	return nil
+}
+
+// Reset zeroes every counter, in memory and in the store.
+func (r *Registry) Reset(ctx context.Context) error {
+	if err := r.store.ResetCounters(ctx); err != nil {
+		return err
+	}
+	for _, c := range r.list() {
+		c.mu.Lock()
+		c.sinceBoot.Store(0)
+		c.pending.Store(0)
+		c.stored = 0
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// Snapshot returns every counter's values, ordered by name.
+func (r *Registry) Snapshot() []Snapshot {
+	counters := r.list()
+	out := make([]Snapshot, 0, len(counters))
+	for _, c := range counters {
+		out = append(out, Snapshot{Name: c.name, SinceBoot: c.SinceBoot(), AllTime: c.AllTime()})
+	}
+	return out
+}
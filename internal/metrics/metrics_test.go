@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type memStore struct {
+	totals map[string]int64
+	fail   bool
+}
+
+func (s *memStore) LoadCounters(ctx context.Context) (map[string]int64, error) {
+	out := map[string]int64{}
+	for k, v := range s.totals {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (s *memStore) AddToCounter(ctx context.Context, name string, delta int64) (int64, error) {
+	if s.fail {
+		return 0, errors.New("store unavailable")
+	}
+	s.totals[name] += delta
+	return s.totals[name], nil
+}
+
+func (s *memStore) ResetCounters(ctx context.Context) error {
+	s.totals = map[string]int64{}
+	return nil
+}
+
+func TestCounterSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{totals: map[string]int64{"fileserver_hits": 40}}
+
+	r := NewRegistry(store)
+	hits := r.Counter("fileserver_hits")
+	if err := r.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	hits.Inc()
+	hits.Inc()
+
+	if got := hits.SinceBoot(); got != 2 {
+		t.Errorf("SinceBoot() = %d, want 2", got)
+	}
+	if got := hits.AllTime(); got != 42 {
+		t.Errorf("AllTime() before flush = %d, want 42", got)
+	}
+	if err := r.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := store.totals["fileserver_hits"]; got != 42 {
+		t.Errorf("stored total = %d, want 42", got)
+	}
+
+	// Another instance flushed in the meantime
+	store.totals["fileserver_hits"] += 10
+	hits.Inc()
+	if err := r.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := hits.AllTime(); got != 53 {
+		t.Errorf("AllTime() = %d, want 53", got)
+	}
+
+	// A restart starts since-boot over but keeps the total
+	restarted := NewRegistry(store)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Snapshot{{Name: "fileserver_hits", SinceBoot: 0, AllTime: 53}}
+	if got := restarted.Snapshot(); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("Snapshot() = %v, want %v", got, want)
+	}
+}
+
+func TestFlushKeepsIncrementsOnError(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{totals: map[string]int64{}, fail: true}
+	r := NewRegistry(store)
+	hits := r.Counter("fileserver_hits")
+	hits.Inc()
+
+	if err := r.Flush(ctx); err == nil {
+		t.Fatal("Flush() error = nil, want error")
+	}
+	store.fail = false
+	if err := r.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := store.totals["fileserver_hits"]; got != 1 {
+		t.Errorf("stored total = %d, want 1", got)
+	}
+}
+
+func TestReset(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{totals: map[string]int64{"fileserver_hits": 5}}
+	r := NewRegistry(store)
+	hits := r.Counter("fileserver_hits")
+	r.Load(ctx)
+	hits.Inc()
+
+	if err := r.Reset(ctx); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if hits.SinceBoot() != 0 || hits.AllTime() != 0 || len(store.totals) != 0 {
+		t.Errorf("after Reset: since boot %d, all time %d, store %v", hits.SinceBoot(), hits.AllTime(), store.totals)
+	}
+}
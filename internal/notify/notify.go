@@ -0,0 +1,177 @@
+// Package notify tells users about activity that concerns them, over the
+// channels they have allowed for each kind of event.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a kind of activity users can be notified of.
+type Event string
+
+const (
+	Mention Event = "mention"
+	Like    Event = "like"
+	Follow  Event = "follow"
+	DM      Event = "dm"
+	Rechirp Event = "rechirp"
+)
+
+// Events lists every Event.
+var Events = []Event{Mention, Like, Follow, DM, Rechirp}
+
+// Channel is a way of delivering notifications.
+type Channel string
+
+const (
+	InApp Channel = "in_app"
+	Email Channel = "email"
+	Push  Channel = "push"
+)
+
+// Channels lists every Channel.
+var Channels = []Channel{InApp, Email, Push}
+
+// Valid reports whether e is one of Events.
+func (e Event) Valid() bool {
+	for _, v := range Events {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid reports whether c is one of Channels.
+func (c Channel) Valid() bool {
+	for _, v := range Channels {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is one thing to tell a user.
+type Notification struct {
+	// UserID is the recipient.
+	UserID uuid.UUID
+	Event  Event
+	// ActorID is the user whose action caused the notification.
+	ActorID uuid.UUID
+	// ChirpID is the chirp concerned, if any.
+	ChirpID uuid.NullUUID
+	// Text is a one-line description for the recipient.
+	Text string
+}
+
+// Sender delivers notifications over one channel.
+type Sender interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// LogSender writes notifications to the default slog logger instead of
+// delivering them, for channels without a real service configured.
+type LogSender struct {
+	Channel Channel
+}
+
+// Send -
+func (s LogSender) Send(ctx context.Context, n Notification) error {
+	slog.InfoContext(ctx, "Notification", "channel", s.Channel, "user_id", n.UserID, "text", n.Text)
+	return nil
+}
+
+// Preferences are a user's choices about notifications.
+type Preferences struct {
+	// channels holds the channels turned on or off per event. Pairs
+	// missing from it take DefaultEnabled.
+	channels map[Event]map[Channel]bool
+	// QuietHours holds back email and push notifications while on.
+	QuietHours QuietHours
+}
+
+// DefaultEnabled reports whether c is on for e until the user says
+// otherwise. Email is opt-in, so busy accounts don't flood inboxes.
+func DefaultEnabled(e Event, c Channel) bool {
+	return c != Email
+}
+
+// Enabled reports whether the user wants e delivered over c.
+func (p Preferences) Enabled(e Event, c Channel) bool {
+	if enabled, ok := p.channels[e][c]; ok {
+		return enabled
+	}
+	return DefaultEnabled(e, c)
+}
+
+// Set records whether the user wants e delivered over c.
+func (p *Preferences) Set(e Event, c Channel, enabled bool) {
+	if p.channels == nil {
+		p.channels = map[Event]map[Channel]bool{}
+	}
+	if p.channels[e] == nil {
+		p.channels[e] = map[Channel]bool{}
+	}
+	p.channels[e][c] = enabled
+}
+
+// PreferenceStore loads the preferences of a user.
+type PreferenceStore func(ctx context.Context, userID uuid.UUID) (Preferences, error)
+
+// Dispatcher is the one place notifications are delivered from, so
+// every sender honours the recipient's preferences.
+type Dispatcher struct {
+	prefs   PreferenceStore
+	senders map[Channel]Sender
+	queue   Queue
+}
+
+// NewDispatcher returns a Dispatcher delivering over senders. Channels
+// without a sender are skipped. Notifications held back by quiet hours
+// wait in queue until DeliverHeld; with a nil queue quiet hours are
+// ignored.
+func NewDispatcher(prefs PreferenceStore, senders map[Channel]Sender, queue Queue) *Dispatcher {
+	return &Dispatcher{prefs: prefs, senders: senders, queue: queue}
+}
+
+// Dispatch delivers n over every channel the recipient has enabled for
+// its event, or holds it back for channels that quiet hours silence.
+// Users aren't notified of their own actions. Delivery goes on over the
+// remaining channels when one fails, and the failures are returned
+// together.
+func (d *Dispatcher) Dispatch(ctx context.Context, n Notification) error {
+	if n.UserID == n.ActorID {
+		return nil
+	}
+
+	prefs, err := d.prefs(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("load notification preferences: %w", err)
+	}
+
+	quiet := d.queue != nil && prefs.QuietHours.Active(time.Now())
+	var errs []error
+	for _, c := range Channels {
+		sender, ok := d.senders[c]
+		if !ok || !prefs.Enabled(n.Event, c) {
+			continue
+		}
+		if quiet && Silenced(c) {
+			if err := d.queue.Hold(ctx, c, n); err != nil {
+				errs = append(errs, fmt.Errorf("hold %s notification: %w", c, err))
+			}
+			continue
+		}
+		if err := sender.Send(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("send %s notification: %w", c, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type recordingSender struct {
+	channel Channel
+	sent    *[]Channel
+	err     error
+}
+
+func (s recordingSender) Send(ctx context.Context, n Notification) error {
+	*s.sent = append(*s.sent, s.channel)
+	return s.err
+}
+
+func TestDispatch(t *testing.T) {
+	recipient, actor := uuid.New(), uuid.New()
+	stored := Preferences{}
+	stored.Set(Like, Push, false)
+	stored.Set(Like, Email, true)
+	store := func(ctx context.Context, userID uuid.UUID) (Preferences, error) {
+		if userID != recipient {
+			t.Fatalf("loaded preferences of %s, want %s", userID, recipient)
+		}
+		return stored, nil
+	}
+
+	tests := []struct {
+		name    string
+		n       Notification
+		pushErr error
+		want    []Channel
+		wantErr bool
+	}{
+		{name: "Defaults", n: Notification{UserID: recipient, ActorID: actor, Event: Follow}, want: []Channel{InApp, Push}},
+		{name: "Overridden", n: Notification{UserID: recipient, ActorID: actor, Event: Like}, want: []Channel{InApp, Email}},
+		{name: "Own action", n: Notification{UserID: recipient, ActorID: recipient, Event: Follow}},
+		{name: "Failed channel", n: Notification{UserID: recipient, ActorID: actor, Event: Mention}, pushErr: errors.New("down"), want: []Channel{InApp, Push}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []Channel
+			d := NewDispatcher(store, map[Channel]Sender{
+				InApp: recordingSender{channel: InApp, sent: &sent},
+				Email: recordingSender{channel: Email, sent: &sent},
+				Push:  recordingSender{channel: Push, sent: &sent, err: tt.pushErr},
+			}, nil)
+			err := d.Dispatch(context.Background(), tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Dispatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(sent, tt.want) {
+				t.Errorf("sent over %v, want %v", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchSkipsChannelsWithoutSender(t *testing.T) {
+	var sent []Channel
+	d := NewDispatcher(func(context.Context, uuid.UUID) (Preferences, error) { return Preferences{}, nil }, map[Channel]Sender{
+		Push: recordingSender{channel: Push, sent: &sent},
+	}, nil)
+	if err := d.Dispatch(context.Background(), Notification{UserID: uuid.New(), ActorID: uuid.New(), Event: DM}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if !slices.Equal(sent, []Channel{Push}) {
+		t.Errorf("sent over %v, want [push]", sent)
+	}
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// Platform is a native push service.
+type Platform string
+
+// Platforms that device tokens are registered for.
+const (
+	APNs Platform = "apns" // Apple Push Notification service
+	FCM  Platform = "fcm"  // Firebase Cloud Messaging
+)
+
+// Valid reports whether p is a known platform.
+func (p Platform) Valid() bool {
+	return p == APNs || p == FCM
+}
+
+// Device is an app installation that receives pushes.
+type Device struct {
+	Token    string
+	Platform Platform
+}
+
+// ErrInvalidToken is returned by a PushProvider when the service says a
+// device token will never work again, e.g. because the app was
+// uninstalled.
+var ErrInvalidToken = errors.New("notify: invalid device token")
+
+// PushProvider delivers a notification to one device through a
+// platform's push service.
+type PushProvider interface {
+	Push(ctx context.Context, token string, n Notification) error
+}
+
+// DeviceStore keeps the devices registered by users.
+type DeviceStore interface {
+	// Devices returns the devices a user should get pushes on.
+	Devices(ctx context.Context, userID uuid.UUID) ([]Device, error)
+	// RemoveDevice forgets a device token.
+	RemoveDevice(ctx context.Context, token string) error
+}
+
+// PushSender is the Sender of the Push channel. It pushes to every
+// device of the recipient, and removes devices whose token the provider
+// rejects as invalid.
+type PushSender struct {
+	Devices   DeviceStore
+	Providers map[Platform]PushProvider
+}
+
+// Send -
+func (s PushSender) Send(ctx context.Context, n Notification) error {
+	devices, err := s.Devices.Devices(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("load devices: %w", err)
+	}
+	var errs []error
+	for _, d := range devices {
+		provider, ok := s.Providers[d.Platform]
+		if !ok {
+			continue
+		}
+		err := provider.Push(ctx, d.Token, n)
+		if errors.Is(err, ErrInvalidToken) {
+			err = s.Devices.RemoveDevice(ctx, d.Token)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("push to %s device: %w", d.Platform, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogPushProvider writes pushes to the default slog logger instead of
+// delivering them, for platforms without credentials configured.
+type LogPushProvider struct {
+	Platform Platform
+}
+
+// Push -
+func (p LogPushProvider) Push(ctx context.Context, token string, n Notification) error {
+	slog.InfoContext(ctx, "Push notification", "platform", p.Platform, "user_id", n.UserID, "token", token, "text", n.Text)
+	return nil
+}
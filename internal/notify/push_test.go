@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type memoryDevices struct {
+	devices []Device
+}
+
+func (m *memoryDevices) Devices(ctx context.Context, userID uuid.UUID) ([]Device, error) {
+	return slices.Clone(m.devices), nil
+}
+
+func (m *memoryDevices) RemoveDevice(ctx context.Context, token string) error {
+	m.devices = slices.DeleteFunc(m.devices, func(d Device) bool { return d.Token == token })
+	return nil
+}
+
+type pushFunc func(ctx context.Context, token string, n Notification) error
+
+func (f pushFunc) Push(ctx context.Context, token string, n Notification) error {
+	return f(ctx, token, n)
+}
+
+func TestPushSender(t *testing.T) {
+	store := &memoryDevices{devices: []Device{
+		{Token: "phone", Platform: APNs},
+		{Token: "uninstalled", Platform: FCM},
+		{Token: "tablet", Platform: FCM},
+		{Token: "broken", Platform: FCM},
+	}}
+	var pushed []string
+	push := pushFunc(func(ctx context.Context, token string, n Notification) error {
+		pushed = append(pushed, token)
+		switch token {
+		case "uninstalled":
+			return ErrInvalidToken
+		case "broken":
+			return errors.New("service unavailable")
+		}
+		return nil
+	})
+	s := PushSender{Devices: store, Providers: map[Platform]PushProvider{APNs: push, FCM: push}}
+
+	err := s.Send(context.Background(), Notification{UserID: uuid.New(), Text: "hi"})
+	if err == nil {
+		t.Error("Send() error = nil, want the broken device's error")
+	}
+	if want := []string{"phone", "uninstalled", "tablet", "broken"}; !slices.Equal(pushed, want) {
+		t.Errorf("pushed to %v, want %v", pushed, want)
+	}
+	for _, d := range store.devices {
+		if d.Token == "uninstalled" {
+			t.Error("invalid token wasn't removed")
+		}
+	}
+	if len(store.devices) != 3 {
+		t.Errorf("%d devices left, want 3", len(store.devices))
+	}
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuietHours is a daily period, in the user's time zone, during which
+// email and push notifications are held back. It may span midnight, as
+// in 22:00 to 07:00.
+type QuietHours struct {
+	Start, End time.Duration // since midnight
+	// Location is the user's time zone. Nil means no quiet hours.
+	Location *time.Location
+}
+
+// Enabled reports whether the user has quiet hours.
+func (q QuietHours) Enabled() bool {
+	return q.Location != nil && q.Start != q.End
+}
+
+// Active reports whether t falls within the quiet hours.
+func (q QuietHours) Active(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	local := t.In(q.Location)
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	if q.Start < q.End {
+		return clock >= q.Start && clock < q.End
+	}
+	return clock >= q.Start || clock < q.End
+}
+
+// Silenced reports whether quiet hours hold back notifications over c.
+// In-app notifications don't interrupt anyone, so they never are.
+func Silenced(c Channel) bool {
+	return c == Email || c == Push
+}
+
+// HeldNotification is a notification waiting for quiet hours to end.
+type HeldNotification struct {
+	Channel      Channel
+	Notification Notification
+}
+
+// Queue keeps the notifications held back during quiet hours.
+type Queue interface {
+	// Hold keeps n for delivery over c later.
+	Hold(ctx context.Context, c Channel, n Notification) error
+	// Users lists the users with held notifications.
+	Users(ctx context.Context) ([]uuid.UUID, error)
+	// Release removes and returns the notifications held for a user.
+	Release(ctx context.Context, userID uuid.UUID) ([]HeldNotification, error)
+}
+
+// DeliverHeld sends every user whose quiet hours are over at now one
+// summary per channel of the notifications held for them.
+func (d *Dispatcher) DeliverHeld(ctx context.Context, now time.Time) error {
+	if d.queue == nil {
+		return nil
+	}
+	userIDs, err := d.queue.Users(ctx)
+	if err != nil {
+		return fmt.Errorf("list held notifications: %w", err)
+	}
+
+	var errs []error
+	for _, userID := range userIDs {
+		prefs, err := d.prefs(ctx, userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("load notification preferences: %w", err))
+			continue
+		}
+		if prefs.QuietHours.Active(now) {
+			continue
+		}
+
+		held, err := d.queue.Release(ctx, userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release held notifications: %w", err))
+			continue
+		}
+		byChannel := map[Channel][]Notification{}
+		for _, h := range held {
+			byChannel[h.Channel] = append(byChannel[h.Channel], h.Notification)
+		}
+		for _, c := range Channels {
+			sender, ok := d.senders[c]
+			if !ok || len(byChannel[c]) == 0 {
+				continue
+			}
+			err := sender.Send(ctx, Notification{UserID: userID, Text: Summary(byChannel[c])})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("send %s summary: %w", c, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eventNouns names what each event brings the user, in the singular.
+var eventNouns = map[Event]string{
+	Mention: "mention",
+	Like:    "like",
+	Follow:  "new follower",
+	DM:      "direct message",
+	Rechirp: "rechirp",
+}
+
+// Summary describes held notifications in one line, e.g. "While your
+// quiet hours were on you got 2 likes and 1 new follower".
+func Summary(ns []Notification) string {
+	counts := map[Event]int{}
+	for _, n := range ns {
+		counts[n.Event]++
+	}
+	var parts []string
+	for _, e := range Events {
+		switch counts[e] {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+eventNouns[e])
+		default:
+			parts = append(parts, fmt.Sprintf("%d %ss", counts[e], eventNouns[e]))
+		}
+	}
+	list := strings.Join(parts, ", ")
+	if i := strings.LastIndex(list, ", "); i >= 0 {
+		list = list[:i] + " and " + list[i+2:]
+	}
+	return "While your quiet hours were on you got " + list
+}
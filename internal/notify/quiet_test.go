@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	overnight := QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour, Location: paris}
+	daytime := QuietHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.UTC}
+
+	tests := []struct {
+		name string
+		q    QuietHours
+		t    time.Time
+		want bool
+	}{
+		{name: "Overnight, late evening", q: overnight, t: time.Date(2026, 1, 10, 21, 30, 0, 0, time.UTC), want: true},
+		{name: "Overnight, early morning", q: overnight, t: time.Date(2026, 1, 10, 5, 59, 0, 0, time.UTC), want: true},
+		{name: "Overnight, at the end", q: overnight, t: time.Date(2026, 1, 10, 6, 0, 0, 0, time.UTC), want: false},
+		{name: "Overnight, afternoon", q: overnight, t: time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC), want: false},
+		{name: "Daytime", q: daytime, t: time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "Daytime, evening", q: daytime, t: time.Date(2026, 1, 10, 17, 0, 0, 0, time.UTC), want: false},
+		{name: "None", q: QuietHours{}, t: time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.Active(tt.t); got != tt.want {
+				t.Errorf("Active(%s) = %t, want %t", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+type senderFunc func(ctx context.Context, n Notification) error
+
+func (f senderFunc) Send(ctx context.Context, n Notification) error { return f(ctx, n) }
+
+type memQueue struct {
+	held map[uuid.UUID][]HeldNotification
+}
+
+func (q *memQueue) Hold(ctx context.Context, c Channel, n Notification) error {
+	q.held[n.UserID] = append(q.held[n.UserID], HeldNotification{Channel: c, Notification: n})
+	return nil
+}
+
+func (q *memQueue) Users(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for id := range q.held {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (q *memQueue) Release(ctx context.Context, userID uuid.UUID) ([]HeldNotification, error) {
+	held := q.held[userID]
+	delete(q.held, userID)
+	return held, nil
+}
+
+func TestQuietHoursHoldAndDeliver(t *testing.T) {
+	recipient := uuid.New()
+	prefs := Preferences{QuietHours: QuietHours{Start: 0, End: 24 * time.Hour, Location: time.UTC}}
+	prefs.Set(Like, Email, true)
+	store := func(context.Context, uuid.UUID) (Preferences, error) { return prefs, nil }
+
+	var sent []Channel
+	var texts []string
+	senders := map[Channel]Sender{}
+	for _, c := range Channels {
+		senders[c] = senderFunc(func(ctx context.Context, n Notification) error {
+			sent = append(sent, c)
+			texts = append(texts, n.Text)
+			return nil
+		})
+	}
+	queue := &memQueue{held: map[uuid.UUID][]HeldNotification{}}
+	d := NewDispatcher(store, senders, queue)
+
+	for _, e := range []Event{Like, Like, Follow} {
+		if err := d.Dispatch(context.Background(), Notification{UserID: recipient, ActorID: uuid.New(), Event: e}); err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+	}
+	if !slices.Equal(sent, []Channel{InApp, InApp, InApp}) {
+		t.Fatalf("sent over %v during quiet hours, want in-app only", sent)
+	}
+
+	// Still quiet: nothing is released
+	sent, texts = nil, nil
+	if err := d.DeliverHeld(context.Background(), time.Now()); err != nil {
+		t.Fatalf("DeliverHeld() error = %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("sent over %v during quiet hours, want nothing", sent)
+	}
+
+	prefs.QuietHours = QuietHours{}
+	if err := d.DeliverHeld(context.Background(), time.Now()); err != nil {
+		t.Fatalf("DeliverHeld() error = %v", err)
+	}
+	if !slices.Equal(sent, []Channel{Email, Push}) {
+		t.Errorf("summaries sent over %v, want email and push", sent)
+	}
+	want := []string{
+		"While your quiet hours were on you got 2 likes",
+		"While your quiet hours were on you got 2 likes and 1 new follower",
+	}
+	if !slices.Equal(texts, want) {
+		t.Errorf("summaries = %q, want %q", texts, want)
+	}
+	if len(queue.held) != 0 {
+		t.Errorf("queue still holds %v", queue.held)
+	}
+}
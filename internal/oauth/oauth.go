@@ -0,0 +1,189 @@
+// Package oauth signs users in with an external identity provider using
+// the OAuth 2.0 authorization code flow.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnverifiedEmail is returned by Provider.Identity when the provider
+// hasn't verified any email address of the account.
+var ErrUnverifiedEmail = errors.New("oauth: no verified email address")
+
+// Identity is who the provider says signed in.
+type Identity struct {
+	Subject string // the provider's ID of the account
+	Email   string // verified
+}
+
+// Provider is an OAuth 2.0 identity provider registered for Chirpy.
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	// UserURL is where the signed-in account is looked up: the userinfo
+	// endpoint of Google, or the API root of GitHub
+	UserURL string
+	Scopes  []string
+	Client  *http.Client
+
+	// identity looks up the signed-in account with its access token
+	identity func(ctx context.Context, p *Provider, accessToken string) (Identity, error)
+}
+
+// Google returns the Google provider.
+func Google(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserURL:      "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:       []string{"openid", "email"},
+		Client:       http.DefaultClient,
+		identity:     googleIdentity,
+	}
+}
+
+// GitHub returns the GitHub provider.
+func GitHub(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         "github",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserURL:      "https://api.github.com",
+		Scopes:       []string{"read:user", "user:email"},
+		Client:       http.DefaultClient,
+		identity:     githubIdentity,
+	}
+}
+
+// AuthCodeURL is where to send the user to sign in. state is echoed back
+// to the redirect URL and must be checked there.
+func (p *Provider) AuthCodeURL(state string) string {
+	v := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {p.RedirectURL},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+	return p.AuthURL + "?" + v.Encode()
+}
+
+// Exchange trades the code the provider redirected back with for an
+// access token.
+func (p *Provider) Exchange(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return "", fmt.Errorf("oauth: exchange code: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("oauth: exchange code: %s", token.Error)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("oauth: exchange code: no access token")
+	}
+	return token.AccessToken, nil
+}
+
+// Identity returns the account accessToken belongs to.
+func (p *Provider) Identity(ctx context.Context, accessToken string) (Identity, error) {
+	return p.identity(ctx, p, accessToken)
+}
+
+// get fetches a provider API URL on behalf of the user into v.
+func (p *Provider) get(ctx context.Context, url, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return p.do(req, v)
+}
+
+// do sends req and decodes its JSON response into v.
+func (p *Provider) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func googleIdentity(ctx context.Context, p *Provider, accessToken string) (Identity, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := p.get(ctx, p.UserURL, accessToken, &info); err != nil {
+		return Identity{}, fmt.Errorf("oauth: get google user: %w", err)
+	}
+	if info.Email == "" || !info.EmailVerified {
+		return Identity{}, ErrUnverifiedEmail
+	}
+	return Identity{Subject: info.Sub, Email: info.Email}, nil
+}
+
+// githubIdentity uses the primary email address of the account, which
+// unlike the public profile email is always present.
+func githubIdentity(ctx context.Context, p *Provider, accessToken string) (Identity, error) {
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.get(ctx, p.UserURL+"/user", accessToken, &user); err != nil {
+		return Identity{}, fmt.Errorf("oauth: get github user: %w", err)
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, p.UserURL+"/user/emails", accessToken, &emails); err != nil {
+		return Identity{}, fmt.Errorf("oauth: get github emails: %w", err)
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return Identity{Subject: fmt.Sprint(user.ID), Email: e.Email}, nil
+		}
+	}
+	return Identity{}, ErrUnverifiedEmail
+}
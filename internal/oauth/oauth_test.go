@@ -0,0 +1,124 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	p := GitHub("client", "secret", "https://chirpy.example.com/api/v1/auth/github/callback")
+	u, err := url.Parse(p.AuthCodeURL("xyz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{
+		"client_id":     "client",
+		"redirect_uri":  "https://chirpy.example.com/api/v1/auth/github/callback",
+		"response_type": "code",
+		"scope":         "read:user user:email",
+		"state":         "xyz",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// fakeProvider serves a token endpoint accepting the code "good" and the
+// user endpoints of both providers.
+func fakeProvider(t *testing.T, googleVerified bool, githubEmails string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_secret") != "secret" {
+			t.Errorf("client_secret = %q, want secret", r.FormValue("client_secret"))
+		}
+		if r.FormValue("code") != "good" {
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+	})
+	authorized := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /userinfo", authorized(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"sub": "g-1", "email": "walt@example.com", "email_verified": googleVerified})
+	}))
+	mux.HandleFunc("GET /user", authorized(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42}`))
+	}))
+	mux.HandleFunc("GET /user/emails", authorized(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(githubEmails))
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSignIn(t *testing.T) {
+	tests := []struct {
+		name           string
+		provider       func(id, secret, redirect string) *Provider
+		userPath       string
+		googleVerified bool
+		githubEmails   string
+		want           Identity
+		wantErr        error
+	}{
+		{name: "Google", provider: Google, userPath: "/userinfo", googleVerified: true, want: Identity{Subject: "g-1", Email: "walt@example.com"}},
+		{name: "Google unverified", provider: Google, userPath: "/userinfo", wantErr: ErrUnverifiedEmail},
+		{
+			name: "GitHub", provider: GitHub,
+			githubEmails: `[{"email": "old@example.com", "primary": false, "verified": true}, {"email": "walt@example.com", "primary": true, "verified": true}]`,
+			want:         Identity{Subject: "42", Email: "walt@example.com"},
+		},
+		{
+			name: "GitHub unverified", provider: GitHub,
+			githubEmails: `[{"email": "walt@example.com", "primary": true, "verified": false}]`,
+			wantErr:      ErrUnverifiedEmail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeProvider(t, tt.googleVerified, tt.githubEmails)
+			p := tt.provider("client", "secret", "https://chirpy.example.com/callback")
+			p.TokenURL = srv.URL + "/token"
+			p.UserURL = srv.URL + tt.userPath
+			p.Client = srv.Client()
+
+			token, err := p.Exchange(context.Background(), "good")
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			got, err := p.Identity(context.Background(), token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Identity() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Identity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectedCode(t *testing.T) {
+	srv := fakeProvider(t, true, "")
+	p := Google("client", "secret", "https://chirpy.example.com/callback")
+	p.TokenURL = srv.URL + "/token"
+	p.Client = srv.Client()
+	if _, err := p.Exchange(context.Background(), "bad"); err == nil {
+		t.Error("Exchange() accepted a rejected code")
+	}
+}
@@ -0,0 +1,272 @@
+// Package openapi builds OpenAPI 3 documents, deriving the schemas of
+// request and response bodies from Go types so that the structs stay the
+// only definition of the API's shapes.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Document is an OpenAPI 3.0 document, with the fields this package
+// fills in.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+// Operation is one method on one path.
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	// Security lists the schemes that authorize the operation. An empty,
+	// non-nil list marks it public.
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body an operation accepts.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one possible response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema is a JSON schema, or a reference to one in Components.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the named schemas and the security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authorizing requests.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Builder assembles a Document.
+type Builder struct {
+	doc   Document
+	types map[string]reflect.Type // schema name -> the type it was made from
+}
+
+// NewBuilder starts a document for the API called title.
+func NewBuilder(title, version string) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI: "3.0.3",
+			Info:    Info{Title: title, Version: version},
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas:         make(map[string]*Schema),
+				SecuritySchemes: make(map[string]SecurityScheme),
+			},
+		},
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// AddSecurityScheme registers a scheme operations can name.
+func (b *Builder) AddSecurityScheme(name string, s SecurityScheme) {
+	b.doc.Components.SecuritySchemes[name] = s
+}
+
+// AddOperation adds op under method and path, a path template such as
+// /api/chirps/{chirpID}.
+func (b *Builder) AddOperation(method, path string, op *Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Document returns the document built so far.
+func (b *Builder) Document() *Document {
+	return &b.doc
+}
+
+// JSONBody describes a JSON body of the type of v, e.g. a zero value of
+// a request struct.
+func (b *Builder) JSONBody(v any) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: b.Schema(reflect.TypeOf(v))}}
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Schema returns the schema of t as encoding/json would marshal it.
+// Named structs are added to the components and referenced.
+func (b *Builder) Schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && t.Implements(textMarshalerType):
+		return &Schema{Type: "string", Format: stringFormat(t)}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.Schema(t.Elem())
+		if s.Ref != "" {
+			// $ref siblings are ignored in OpenAPI 3.0
+			return s
+		}
+		nullable := *s
+		nullable.Nullable = true
+		return &nullable
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := b.schemaName(t)
+		if _, ok := b.doc.Components.Schemas[name]; !ok {
+			// Placeholder first, for types that refer to themselves
+			b.doc.Components.Schemas[name] = &Schema{}
+			*b.doc.Components.Schemas[name] = *b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces and anything else: any value
+		return &Schema{}
+	}
+}
+
+// schemaName names t's schema after the type, qualified with its package
+// if another package has a type of the same name.
+func (b *Builder) schemaName(t reflect.Type) string {
+	name := t.Name()
+	if other, ok := b.types[name]; ok && other != t {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	b.types[name] = t
+	return name
+}
+
+// structSchema lists the fields of t the way encoding/json sees them.
+// Fields tagged omitempty are optional; the rest are required.
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			// Embedded: its fields are listed individually
+			continue
+		}
+		if !f.IsExported() || !promoted(t, f.Index[:len(f.Index)-1]) {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = b.Schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// promoted reports whether encoding/json lifts a field out of the
+// embedded structs at index, which it does unless one of them is tagged
+// with a name.
+func promoted(t reflect.Type, index []int) bool {
+	for _, i := range index {
+		f := t.Field(i)
+		if f.Tag.Get("json") != "" {
+			return false
+		}
+		t = f.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+	return true
+}
+
+// stringFormat guesses the format of a type that marshals to text.
+func stringFormat(t reflect.Type) string {
+	if t.Name() == "UUID" {
+		return "uuid"
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type base struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type node struct {
+	base
+	Name     string           `json:"name"`
+	Note     *string          `json:"note"`
+	Parent   *node            `json:"parent,omitempty"`
+	Children []node           `json:"children"`
+	Labels   map[string]int64 `json:"labels,omitempty"`
+	Extra    json.RawMessage  `json:"extra"`
+	Secret   string           `json:"-"`
+	hidden   string
+}
+
+func TestSchema(t *testing.T) {
+	b := NewBuilder("test", "1")
+	ref := b.Schema(reflect.TypeFor[[]node]())
+	if ref.Type != "array" || ref.Items.Ref != "#/components/schemas/node" {
+		t.Fatalf("Schema([]node) = %+v, want an array of node references", ref)
+	}
+
+	s := b.Document().Components.Schemas["node"]
+	if s == nil {
+		t.Fatal("node wasn't added to the components")
+	}
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	want := []string{"children", "created_at", "extra", "id", "labels", "name", "note", "parent"}
+	if !slices.Equal(names, want) {
+		t.Errorf("properties = %q, want %q", names, want)
+	}
+	if !slices.Equal(s.Required, []string{"id", "created_at", "name", "note", "children", "extra"}) {
+		t.Errorf("required = %q", s.Required)
+	}
+
+	for name, want := range map[string]Schema{
+		"id":         {Type: "string", Format: "uuid"},
+		"created_at": {Type: "string", Format: "date-time"},
+		"note":       {Type: "string", Nullable: true},
+		"parent":     {Ref: "#/components/schemas/node"},
+		"extra":      {},
+	} {
+		if got := *s.Properties[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+	if s.Properties["labels"].AdditionalProperties.Format != "int64" {
+		t.Errorf("labels = %+v, want a map of int64", s.Properties["labels"])
+	}
+}
+
+func TestAddOperation(t *testing.T) {
+	b := NewBuilder("test", "1")
+	b.AddOperation("GET", "/things/{id}", &Operation{Summary: "get"})
+	b.AddOperation("DELETE", "/things/{id}", &Operation{Summary: "delete"})
+
+	item := b.Document().Paths["/things/{id}"]
+	if item["get"].Summary != "get" || item["delete"].Summary != "delete" {
+		t.Errorf("path item = %+v, want get and delete", item)
+	}
+	if _, err := json.Marshal(b.Document()); err != nil {
+		t.Errorf("json.Marshal() error = %v", err)
+	}
+}
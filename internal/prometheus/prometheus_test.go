@@ -0,0 +1,140 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	c.Inc("/b", "200")
+	c.Inc("/a", "200")
+	c.Add(2, "/a", "200")
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 3
+requests_total{route="/b",status="200"} 1
+`
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("duration_seconds", "Durations.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	var out strings.Builder
+	r.Write(&out)
+	want := `# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a",le="0.1"} 2
+duration_seconds_bucket{route="/a",le="1"} 3
+duration_seconds_bucket{route="/a",le="+Inf"} 4
+duration_seconds_sum{route="/a"} 3.65
+duration_seconds_count{route="/a"} 4
+`
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("odd_total", "Help with a \\ and\na newline.", "label")
+	c.Inc("quote \" backslash \\ newline \n")
+
+	var out strings.Builder
+	r.Write(&out)
+	for _, want := range []string{
+		`# HELP odd_total Help with a \\ and\na newline.`,
+		`odd_total{label="quote \" backslash \\ newline \n"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Requests served.", "route")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with too many label values did not panic")
+		}
+	}()
+	c.Inc("/a", "extra")
+}
+
+func TestGauges(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("in_flight", "Requests being served.", "route")
+	g.Inc("/a")
+	g.Inc("/a")
+	g.Inc("/b")
+	g.Dec("/b")
+	open := 4
+	r.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return float64(open) })
+	open = 5
+
+	var out strings.Builder
+	r.Write(&out)
+	want := `# HELP in_flight Requests being served.
+# TYPE in_flight gauge
+in_flight{route="/a"} 2
+in_flight{route="/b"} 0
+# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 5
+`
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+	if got := g.NonZero(); len(got) != 1 || got["/a"] != 2 {
+		t.Errorf("NonZero() = %v, want map[/a:2]", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("unused_total", "Never incremented.")
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+	if !strings.Contains(w.Body.String(), "# TYPE unused_total counter") {
+		t.Errorf("body = %q, want the unused_total family", w.Body.String())
+	}
+}
+
+func TestTotals(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Requests served.", "route")
+	c.Inc("/a")
+	c.Add(2, "/b")
+	if got := c.Total(); got != 3 {
+		t.Errorf("CounterVec.Total() = %v, want 3", got)
+	}
+
+	h := r.NewHistogramVec("duration_seconds", "Durations.", []float64{1}, "route")
+	h.Observe(0.5, "/a")
+	h.Observe(2, "/b")
+	if count, sum := h.Total(); count != 2 || sum != 2.5 {
+		t.Errorf("HistogramVec.Total() = %d, %v, want 2, 2.5", count, sum)
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Result describes the state of a key's quota after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the quota is replenished.
+	Reset time.Duration
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter allows up to Limit requests per key in each fixed window.
+type Limiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New creates a Limiter allowing limit requests per key in each period of length per.
+func New(limit int, per time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  per,
+		windows: map[string]*window{},
+		now:     time.Now,
+	}
+}
+
+// Allow records a request for key and reports whether it is within quota.
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	res := Result{
+		Limit: l.limit,
+		Reset: w.start.Add(l.window).Sub(now),
+	}
+	if w.count >= l.limit {
+		return res
+	}
+	w.count++
+	res.Allowed = true
+	res.Remaining = l.limit - w.count
+	return res
+}
+
+// sweep forgets windows that have ended, at most once per window.
+// l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		name          string
+		key           string
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantReset     time.Duration
+	}{
+		{name: "First request", key: "a", wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+		{name: "Second request", key: "a", advance: 10 * time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 50 * time.Second},
+		{name: "Over quota", key: "a", advance: 10 * time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 40 * time.Second},
+		{name: "Other key has its own quota", key: "b", wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+		{name: "New window", key: "a", advance: time.Minute, wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		got := l.Allow(step.key)
+		if got.Allowed != step.wantAllowed || got.Remaining != step.wantRemaining || got.Reset != step.wantReset {
+			t.Errorf("%s: Allow() = %+v, want allowed=%v remaining=%d reset=%v",
+				step.name, got, step.wantAllowed, step.wantRemaining, step.wantReset)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package relme verifies that a user owns the website on their profile,
+// the way Mastodon and IndieWeb sites do: the site links back to the
+// profile with rel="me".
+package relme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxPageSize is how much of a page is searched for links.
+const maxPageSize = 1 << 20
+
+// UserAgent identifies the requests for site owners.
+const UserAgent = "Chirpy-LinkVerifier/1.0 (+rel=me)"
+
+// ErrPrivateAddress is returned for sites that resolve to loopback,
+// private or link-local addresses, which the server must not be made to
+// request.
+var ErrPrivateAddress = errors.New("address is not public")
+
+// NewClient returns a client for fetching users' sites. It only connects
+// to public addresses, which is checked after DNS resolution, and gives
+// up after timeout.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+				return fmt.Errorf("%s: %w", host, ErrPrivateAddress)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to %s URL", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// Verify fetches the page at website and reports whether it links to any
+// of profiles with rel="me". Errors are for pages that couldn't be
+// fetched, which may be worth trying again; a page without the link
+// returns false and no error.
+func Verify(ctx context.Context, client *http.Client, website string, profiles []string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, website, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return false, fmt.Errorf("%s responded %s", website, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return false, nil
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return false, err
+	}
+	for _, link := range Links(string(page), resp.Request.URL) {
+		for _, profile := range profiles {
+			if SameURL(link, profile) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+var (
+	tagPattern  = regexp.MustCompile(`(?is)<(?:a|link)\s[^>]*>`)
+	attrPattern = regexp.MustCompile(`(?is)([a-z][a-z0-9-]*)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+)
+
+// Links returns the targets of the <a> and <link> elements in page whose
+// rel includes "me", resolved against base.
+func Links(page string, base *url.URL) []string {
+	var links []string
+	for _, tag := range tagPattern.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, m := range attrPattern.FindAllStringSubmatch(tag, -1) {
+			name := strings.ToLower(m[1])
+			if _, ok := attrs[name]; !ok {
+				attrs[name] = html.UnescapeString(strings.Trim(m[2], `"'`))
+			}
+		}
+		isMe := false
+		for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+			isMe = isMe || rel == "me"
+		}
+		href, ok := attrs["href"]
+		if !isMe || !ok {
+			continue
+		}
+		u, err := base.Parse(strings.TrimSpace(href))
+		if err != nil {
+			continue
+		}
+		links = append(links, u.String())
+	}
+	return links
+}
+
+// SameURL reports whether two links are the same page: schemes and hosts
+// are compared without case, and a trailing slash or fragment doesn't
+// matter.
+func SameURL(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) &&
+		strings.EqualFold(ua.Host, ub.Host) &&
+		strings.TrimSuffix(ua.EscapedPath(), "/") == strings.TrimSuffix(ub.EscapedPath(), "/") &&
+		ua.RawQuery == ub.RawQuery
+}
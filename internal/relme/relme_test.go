@@ -0,0 +1,104 @@
+package relme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLinks(t *testing.T) {
+	base, _ := url.Parse("https://gopher.example/about/")
+	page := `<html><head>
+<link rel="me" href="https://chirpy.example/api/v1/users/1">
+<link rel="stylesheet" href="/style.css">
+</head><body>
+<a href='/elsewhere' REL='nofollow ME'>me too</a>
+<a href="https://other.example">not me</a>
+<a rel=me href=https://social.example/@gopher>mastodon</a>
+<a rel="me">no href</a>
+</body></html>`
+
+	got := Links(page, base)
+	want := []string{
+		"https://chirpy.example/api/v1/users/1",
+		"https://gopher.example/elsewhere",
+		"https://social.example/@gopher",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Links() = %q, want %q", got, want)
+	}
+}
+
+func TestSameURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://chirpy.example/api/v1/users/1", "https://chirpy.example/api/v1/users/1", true},
+		{"HTTPS://Chirpy.Example/api/v1/users/1/", "https://chirpy.example/api/v1/users/1", true},
+		{"https://chirpy.example/api/v1/users/1#top", "https://chirpy.example/api/v1/users/1", true},
+		{"http://chirpy.example/api/v1/users/1", "https://chirpy.example/api/v1/users/1", false},
+		{"https://chirpy.example/api/v1/users/12", "https://chirpy.example/api/v1/users/1", false},
+		{"https://chirpy.example.evil/api/v1/users/1", "https://chirpy.example/api/v1/users/1", false},
+	}
+	for _, tt := range tests {
+		if got := SameURL(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameURL(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const profile = "https://chirpy.example/api/v1/users/1"
+	pages := map[string]string{
+		"/linked":   `<a rel="me" href="` + profile + `">Chirpy</a>`,
+		"/unlinked": `<a href="` + profile + `">Chirpy</a>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		want    bool
+		wantErr bool
+	}{
+		{path: "/linked", want: true},
+		{path: "/unlinked"},
+		{path: "/missing"},
+		{path: "/down", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Verify(context.Background(), srv.Client(), srv.URL+tt.path, []string{profile})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Verify(%s) = %t, %v, want %t, error %t", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := Verify(context.Background(), NewClient(time.Second), srv.URL, nil)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Verify(%s) error = %v, want ErrPrivateAddress", srv.URL, err)
+	}
+}
@@ -0,0 +1,95 @@
+// Package reports defines why chirps can be reported, which is also why
+// moderators can act on them, and the policy of automated actions as
+// reports of each reason add up.
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reason is a category of report.
+type Reason struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// Reasons is the taxonomy, in the order clients should offer it.
+var Reasons = []Reason{
+	{"spam", "Unsolicited advertising, scams or repetitive posts"},
+	{"harassment", "Targeted abuse or bullying of a person"},
+	{"hate_speech", "Attacks on people for who they are"},
+	{"violence", "Threats or glorification of violence"},
+	{"nsfw", "Sexual or graphic content"},
+	{"impersonation", "Pretending to be someone else"},
+	{"illegal_content", "Content that is against the law"},
+	{"other", "Anything else"},
+}
+
+// Known reports whether code is one of the Reasons.
+func Known(code string) bool {
+	for _, r := range Reasons {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy says what happens to a chirp as reports of one reason add up.
+// A threshold of 0 disables the action.
+type Policy struct {
+	// HideAfter is how many reports remove the chirp pending review
+	HideAfter int
+	// EscalateAfter is how many reports put the chirp in the moderation
+	// queue
+	EscalateAfter int
+}
+
+// Apply returns the actions the report that brings a chirp to count
+// reports triggers. Each action happens once, when its threshold is
+// reached.
+func (p Policy) Apply(count int) (hide, escalate bool) {
+	return p.HideAfter > 0 && count == p.HideAfter,
+		p.EscalateAfter > 0 && count == p.EscalateAfter
+}
+
+// DefaultPolicies escalate a few reports of anything, and hide chirps
+// that many people flag as spam or NSFW before a moderator gets to them.
+// Reasons missing from the map have no automated actions.
+var DefaultPolicies = map[string]Policy{
+	"spam":            {HideAfter: 10, EscalateAfter: 3},
+	"harassment":      {EscalateAfter: 2},
+	"hate_speech":     {EscalateAfter: 2},
+	"violence":        {EscalateAfter: 1},
+	"nsfw":            {HideAfter: 5, EscalateAfter: 3},
+	"impersonation":   {EscalateAfter: 3},
+	"illegal_content": {HideAfter: 3, EscalateAfter: 1},
+	"other":           {EscalateAfter: 5},
+}
+
+// ParsePolicy reads a policy such as "hide=5,escalate=3". Actions left
+// out are disabled.
+func ParsePolicy(s string) (Policy, error) {
+	var p Policy
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return Policy{}, fmt.Errorf("%q is not action=count", part)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Policy{}, fmt.Errorf("%q is not a report count", v)
+		}
+		switch k {
+		case "hide":
+			p.HideAfter = n
+		case "escalate":
+			p.EscalateAfter = n
+		default:
+			return Policy{}, fmt.Errorf("unknown action %q, expected hide or escalate", k)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,59 @@
+package reports
+
+import "testing"
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Policy
+		wantErr bool
+	}{
+		{in: "hide=5,escalate=3", want: Policy{HideAfter: 5, EscalateAfter: 3}},
+		{in: "escalate=1", want: Policy{EscalateAfter: 1}},
+		{in: " hide=2 , escalate=0", want: Policy{HideAfter: 2}},
+		{in: "hide", wantErr: true},
+		{in: "hide=-1", wantErr: true},
+		{in: "ban=3", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicy(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePolicy(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParsePolicy(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	p := Policy{HideAfter: 3, EscalateAfter: 2}
+	var hides, escalations []int
+	for count := 1; count <= 5; count++ {
+		hide, escalate := p.Apply(count)
+		if hide {
+			hides = append(hides, count)
+		}
+		if escalate {
+			escalations = append(escalations, count)
+		}
+	}
+	if len(hides) != 1 || hides[0] != 3 {
+		t.Errorf("hidden at %v, want [3]", hides)
+	}
+	if len(escalations) != 1 || escalations[0] != 2 {
+		t.Errorf("escalated at %v, want [2]", escalations)
+	}
+
+	if hide, escalate := (Policy{}).Apply(1); hide || escalate {
+		t.Error("zero Policy triggered an action")
+	}
+}
+
+func TestDefaultPoliciesUseKnownReasons(t *testing.T) {
+	for code := range DefaultPolicies {
+		if !Known(code) {
+			t.Errorf("DefaultPolicies has unknown reason %q", code)
+		}
+	}
+}
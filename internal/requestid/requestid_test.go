@@ -0,0 +1,71 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "Generated", incoming: "", wantSame: false},
+		{name: "Honored", incoming: "abc-123", wantSame: true},
+		{name: "Too long", incoming: strings.Repeat("a", maxLength+1), wantSame: false},
+		{name: "Spaces", incoming: "abc 123", wantSame: false},
+		{name: "Control characters", incoming: "abc\n123", wantSame: false},
+		{name: "Non-ASCII", incoming: "abc-é", wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inContext string
+			h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				inContext = FromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+			if tt.incoming != "" {
+				req.Header.Set(Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(Header)
+			if got == "" {
+				t.Fatal("no request ID in the response")
+			}
+			if got != inContext {
+				t.Errorf("response ID %q differs from context ID %q", got, inContext)
+			}
+			if (got == tt.incoming) != tt.wantSame {
+				t.Errorf("request ID = %q, incoming %q, want honored %v", got, tt.incoming, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(LogHandler{slog.NewTextHandler(&buf, nil)}).With("component", "test")
+
+	logger.InfoContext(NewContext(context.Background(), "req-1"), "with ID")
+	logger.Info("without ID")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "request_id=req-1") || !strings.Contains(lines[0], "component=test") {
+		t.Errorf("first line %q lacks the request ID or the logger's attributes", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("second line %q has a request ID", lines[1])
+	}
+}
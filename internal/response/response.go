@@ -0,0 +1,187 @@
+// Package response writes the API's JSON responses, so that every
+// endpoint sends the same headers and errors are logged the same way.
+//
+// Error logging policy: 4xx responses are the client's doing and are
+// not logged here. 5xx responses are logged with the underlying error and
+// the handler frames that produced them. Either way the error is handed
+// to an ErrorRecorder, if the response writer is one, so request logs can
+// show it. The error itself never reaches the client, which only sees the
+// message the handler chose.
+package response
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"main.go/api"
+	"main.go/internal/requestid"
+)
+
+// ContentTypeJSON is the Content-Type of every JSON response, including
+// HEAD responses that stand in for one.
+const ContentTypeJSON = "application/json; charset=utf-8"
+
+// Cache-Control of responses that don't set their own. Successful
+// responses may be stored but must be revalidated; errors are not
+// stored at all.
+const (
+	defaultCacheControl = "no-cache"
+	errorCacheControl   = "no-store"
+)
+
+// maxStackFrames limits how much of the call stack is logged with a 5xx.
+const maxStackFrames = 5
+
+// Option adjusts a response before it is written.
+type Option func(h http.Header)
+
+// CacheControl sets the Cache-Control directives of a response, e.g.
+// "public, max-age=60", instead of the default.
+func CacheControl(directives string) Option {
+	return func(h http.Header) {
+		h.Set("Cache-Control", directives)
+	}
+}
+
+// JSON writes payload as the JSON body of a response with status code.
+// A Cache-Control header set by the handler beforehand is kept, unless
+// an option replaces it.
+func JSON(w http.ResponseWriter, code int, payload any, opts ...Option) {
+	dat, err := json.Marshal(payload)
+	if err != nil {
+		logError("Error marshalling JSON", slog.Any("error", err))
+		code = http.StatusInternalServerError
+		dat, _ = json.Marshal(api.ErrorResponse{Error: "Something went wrong", RequestID: w.Header().Get(requestid.Header)})
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ContentTypeJSON)
+	if h.Get("Cache-Control") == "" {
+		if code >= 400 {
+			h.Set("Cache-Control", errorCacheControl)
+		} else {
+			h.Set("Cache-Control", defaultCacheControl)
+		}
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	w.WriteHeader(code)
+	w.Write(dat)
+}
+
+// ETag returns a weak entity tag for a representation described by
+// parts, e.g. a row's ID and updated_at, and anything else in the body
+// that changes without them.
+func ETag(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// NotModified sets the ETag of a response and reports whether the
+// request's If-None-Match already has it, in which case it has written a
+// 304 and the handler is done. Tags are compared weakly, as RFC 9110
+// asks for If-None-Match.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	h := w.Header()
+	h.Set("ETag", etag)
+	if !matchesETag(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", defaultCacheControl)
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matchesETag reports whether an If-None-Match header lists etag, or is
+// "*".
+func matchesETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorRecorder is implemented by response writers that want to know
+// why a request failed, e.g. to log it with the request.
+type ErrorRecorder interface {
+	RecordError(msg string, err error)
+}
+
+// Error writes an api.ErrorResponse with msg, and the request ID if
+// requestid.Handler set one. err is what went wrong internally; see the
+// package documentation for when it is logged.
+func Error(w http.ResponseWriter, code int, msg string, err error, opts ...Option) {
+	if rec, ok := w.(ErrorRecorder); ok {
+		rec.RecordError(msg, err)
+	}
+	requestID := w.Header().Get(requestid.Header)
+	if code >= 500 {
+		attrs := []slog.Attr{slog.Int("status", code), slog.String("message", msg)}
+		if requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		logError("Responding with server error", attrs...)
+	}
+	// Errors must not be cached even where the handler's successful
+	// responses would be
+	w.Header().Set("Cache-Control", errorCacheControl)
+	JSON(w, code, api.ErrorResponse{Error: msg, RequestID: requestID}, opts...)
+}
+
+// logError logs msg with the frames of the code that called into this
+// package, up to the HTTP server.
+func logError(msg string, attrs ...slog.Attr) {
+	var stack []string
+	for _, frame := range callers() {
+		stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+	}
+	attrs = append(attrs, slog.Any("stack", stack))
+	slog.LogAttrs(context.Background(), slog.LevelError, msg, attrs...)
+}
+
+// callers returns the stack from the caller of JSON or Error up to the
+// handler, without the frames of net/http below it.
+func callers() []runtime.Frame {
+	pcs := make([]uintptr, 32)
+	// Skips runtime.Callers, callers, logError and JSON or Error
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []runtime.Frame
+	for len(out) < maxStackFrames {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "net/http.") || strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		out = append(out, frame)
+		if !more {
+			break
+		}
+	}
+	return out
+}
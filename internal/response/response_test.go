@@ -0,0 +1,219 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"main.go/api"
+	"main.go/internal/requestid"
+)
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          int
+		preset        string
+		opts          []Option
+		wantCode      int
+		wantCache     string
+		wantBodyMatch string
+	}{
+		{name: "Default caching", code: http.StatusOK, wantCode: http.StatusOK, wantCache: "no-cache", wantBodyMatch: `{"ok":true}`},
+		{name: "Option", code: http.StatusOK, opts: []Option{CacheControl("public, max-age=60")}, wantCode: http.StatusOK, wantCache: "public, max-age=60", wantBodyMatch: `{"ok":true}`},
+		{name: "Preset header kept", code: http.StatusOK, preset: "no-store", wantCode: http.StatusOK, wantCache: "no-store", wantBodyMatch: `{"ok":true}`},
+		{name: "Client error", code: http.StatusTooManyRequests, wantCode: http.StatusTooManyRequests, wantCache: "no-store", wantBodyMatch: `{"ok":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.preset != "" {
+				rec.Header().Set("Cache-Control", tt.preset)
+			}
+			JSON(rec, tt.code, map[string]bool{"ok": true}, tt.opts...)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ContentTypeJSON {
+				t.Errorf("Content-Type = %q, want %q", got, ContentTypeJSON)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := rec.Body.String(); got != tt.wantBodyMatch {
+				t.Errorf("body = %s, want %s", got, tt.wantBodyMatch)
+			}
+		})
+	}
+}
+
+func TestJSONMarshalFailure(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&bytes.Buffer{})
+
+	rec := httptest.NewRecorder()
+	JSON(rec, http.StatusOK, map[string]any{"f": func() {}})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	var body api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == "" {
+		t.Errorf("body = %s, want an error response", rec.Body.String())
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		err     error
+		wantLog bool
+	}{
+		{name: "Client error not logged", code: http.StatusBadRequest, err: errors.New("bad input"), wantLog: false},
+		{name: "Server error logged", code: http.StatusInternalServerError, err: errors.New("connection refused"), wantLog: true},
+		{name: "Server error without cause", code: http.StatusServiceUnavailable, wantLog: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defer log.SetOutput(log.Writer())
+			log.SetOutput(&logs)
+
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Cache-Control", "public, max-age=60")
+			Error(rec, tt.code, "Failed to do the thing", tt.err)
+
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+			var body api.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", rec.Body.String(), err)
+			}
+			if body.Error != "Failed to do the thing" {
+				t.Errorf("error = %q, want the handler's message", body.Error)
+			}
+			if tt.err != nil && strings.Contains(rec.Body.String(), tt.err.Error()) {
+				t.Errorf("body %s leaks the internal error", rec.Body.String())
+			}
+
+			logged := logs.String()
+			if (logged != "") != tt.wantLog {
+				t.Fatalf("logged %q, want logging %v", logged, tt.wantLog)
+			}
+			if !tt.wantLog {
+				return
+			}
+			if tt.err != nil && !strings.Contains(logged, tt.err.Error()) {
+				t.Errorf("log %q does not contain the error", logged)
+			}
+			if !strings.Contains(logged, "TestError") {
+				t.Errorf("log %q does not show the calling function", logged)
+			}
+			if strings.Contains(logged, "internal/response.Error") {
+				t.Errorf("log %q shows frames of the response package", logged)
+			}
+		})
+	}
+}
+
+type recordingWriter struct {
+	*httptest.ResponseRecorder
+	msg string
+	err error
+}
+
+func (w *recordingWriter) RecordError(msg string, err error) {
+	w.msg, w.err = msg, err
+}
+
+func TestErrorRecorder(t *testing.T) {
+	w := &recordingWriter{ResponseRecorder: httptest.NewRecorder()}
+	cause := errors.New("token is expired")
+	Error(w, http.StatusUnauthorized, "Invalid token", cause)
+
+	if w.msg != "Invalid token" || w.err != cause {
+		t.Errorf("recorded (%q, %v), want (%q, %v)", w.msg, w.err, "Invalid token", cause)
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestErrorIncludesRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(requestid.Header, "req-42")
+	Error(rec, http.StatusNotFound, "Chirp not found", nil)
+
+	var body api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body %s: %v", rec.Body.String(), err)
+	}
+	if body.RequestID != "req-42" {
+		t.Errorf("request_id = %q, want req-42", body.RequestID)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := ETag("chirp-1", "2026-01-02T03:04:05Z")
+	if etag != ETag("chirp-1", "2026-01-02T03:04:05Z") {
+		t.Fatal("ETag isn't deterministic")
+	}
+	if etag == ETag("chirp-1", "2026-01-02T03:04:06Z") {
+		t.Fatal("ETag didn't change with its parts")
+	}
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("ETag = %s, want a weak tag", etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "No header", want: false},
+		{name: "Same tag", ifNoneMatch: etag, want: true},
+		{name: "Strong form", ifNoneMatch: strings.TrimPrefix(etag, "W/"), want: true},
+		{name: "In a list", ifNoneMatch: `"other", ` + etag, want: true},
+		{name: "Any", ifNoneMatch: "*", want: true},
+		{name: "Other tag", ifNoneMatch: `W/"other"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			got := NotModified(rec, req, etag)
+
+			if got != tt.want {
+				t.Errorf("NotModified = %v, want %v", got, tt.want)
+			}
+			if h := rec.Header().Get("ETag"); h != etag {
+				t.Errorf("ETag = %q, want %q", h, etag)
+			}
+			if tt.want {
+				if rec.Code != http.StatusNotModified {
+					t.Errorf("status = %d, want 304", rec.Code)
+				}
+				if rec.Body.Len() != 0 {
+					t.Errorf("body = %q, want none", rec.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package sanitize cleans up user supplied profile text and links with an
+// allowlist, so that they are safe to show in a page as HTML.
+package sanitize
+
+import (
+	"errors"
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Policy lists the tags, and for each tag the attributes, that HTML may
+// keep. Everything else is dropped: tags that aren't listed lose their
+// markup but keep their text, except for the ones in dropContent, whose
+// content goes too.
+type Policy map[string][]string
+
+// urlAttributes are checked with URL before they're kept.
+var urlAttributes = map[string]bool{"href": true}
+
+// dropContent are the tags whose content is code or markup rather than
+// text.
+var dropContent = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"template": true,
+	"noscript": true,
+	"noembed":  true,
+	"noframes": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+	"svg":      true,
+	"math":     true,
+}
+
+// Text allows no tags at all.
+var Text = Policy{}
+
+// Inline allows simple formatting and links.
+var Inline = Policy{
+	"a":      {"href"},
+	"b":      nil,
+	"br":     nil,
+	"em":     nil,
+	"i":      nil,
+	"strong": nil,
+}
+
+// HTML returns s with only the tags and attributes p allows. Text is
+// escaped, entities are normalized and links are rel="nofollow ugc", so
+// p.HTML(p.HTML(s)) == p.HTML(s).
+func (p Policy) HTML(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.Map(func(r rune) rune {
+		if r == 0 || unicode.Is(unicode.Cc, r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			writeText(&b, s)
+			break
+		}
+		writeText(&b, s[:i])
+		s = s[i:]
+
+		t, n := parseTag(s)
+		if n == 0 {
+			b.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = s[n:]
+		if t.name == "" {
+			// comments, doctypes and processing instructions
+			continue
+		}
+		if dropContent[t.name] {
+			if !t.closing && !t.selfClosing {
+				s = skipElement(s, t.name)
+			}
+			continue
+		}
+		attrs, ok := p[t.name]
+		if !ok {
+			continue
+		}
+		p.writeTag(&b, t, attrs)
+	}
+	return b.String()
+}
+
+func writeText(b *strings.Builder, s string) {
+	s = html.UnescapeString(s)
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	b.WriteString(s)
+}
+
+func (p Policy) writeTag(b *strings.Builder, t tag, allowed []string) {
+	b.WriteByte('<')
+	if t.closing {
+		b.WriteByte('/')
+		b.WriteString(t.name)
+		b.WriteByte('>')
+		return
+	}
+	b.WriteString(t.name)
+	for _, name := range allowed {
+		value, ok := t.attrs[name]
+		if !ok {
+			continue
+		}
+		if urlAttributes[name] {
+			u, err := URL(value)
+			if err != nil {
+				continue
+			}
+			value = u
+		}
+		b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+	if t.name == "a" {
+		b.WriteString(` rel="nofollow ugc"`)
+	}
+	b.WriteByte('>')
+}
+
+type tag struct {
+	name        string
+	closing     bool
+	selfClosing bool
+	attrs       map[string]string
+}
+
+// parseTag reads the tag at the start of s, which starts with '<', the
+// way browsers do. It returns how many bytes the tag took, or 0 if s
+// doesn't start with one and the '<' is text. Comments and other markup
+// declarations have an empty name.
+func parseTag(s string) (tag, int) {
+	var t tag
+	i := 1
+	if strings.HasPrefix(s, "<!--") {
+		end := strings.Index(s[4:], "-->")
+		if end < 0 {
+			return t, len(s)
+		}
+		return t, 4 + end + 3
+	}
+	if i < len(s) && (s[i] == '!' || s[i] == '?') {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return t, len(s)
+		}
+		return t, end + 1
+	}
+	if i < len(s) && s[i] == '/' {
+		t.closing = true
+		i++
+	}
+	if i >= len(s) || !isASCIILetter(s[i]) {
+		return t, 0
+	}
+	start := i
+	for i < len(s) && !isSpace(s[i]) && s[i] != '/' && s[i] != '>' {
+		i++
+	}
+	t.name = strings.ToLower(s[start:i])
+
+	t.attrs = make(map[string]string)
+	for {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			t.selfClosing = s[i] == '/'
+			i++
+		}
+		if i >= len(s) {
+			// An unterminated tag swallows the rest of the input.
+			return t, len(s)
+		}
+		if s[i] == '>' {
+			return t, i + 1
+		}
+		t.selfClosing = false
+
+		start := i
+		i++
+		for i < len(s) && !isSpace(s[i]) && s[i] != '/' && s[i] != '>' && s[i] != '=' {
+			i++
+		}
+		name := strings.ToLower(s[start:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		var value string
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return t, len(s)
+				}
+				value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[start:i]
+			}
+		}
+		if _, ok := t.attrs[name]; !ok {
+			t.attrs[name] = html.UnescapeString(value)
+		}
+	}
+}
+
+// skipElement returns what follows the end tag of name in s, or "" if
+// there isn't one.
+func skipElement(s, name string) string {
+	// Only ASCII is lowered, so that offsets in lower are offsets in s.
+	lower := strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+	for i := 0; ; {
+		j := strings.Index(lower[i:], "</"+name)
+		if j < 0 {
+			return ""
+		}
+		i += j + 2 + len(name)
+		if i == len(s) || isSpace(s[i]) || s[i] == '/' || s[i] == '>' {
+			_, n := parseTag(s[i-len(name)-2:])
+			return s[i-len(name)-2+n:]
+		}
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// Errors returned by URL.
+var (
+	ErrScheme = errors.New("link must use http or https")
+	ErrURL    = errors.New("invalid link")
+)
+
+// URL normalizes a link: whitespace and control characters that browsers
+// ignore are removed, links without a scheme get https, and the scheme
+// and host are lowercased. Only absolute http and https links with a host
+// and without credentials are allowed.
+func URL(s string) (string, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Cc, r) {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "", ErrURL
+	}
+	if !strings.Contains(s, ":") || strings.HasPrefix(s, "//") {
+		s = "https://" + strings.TrimPrefix(s, "//")
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ErrURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		// host:port without a scheme parses as an opaque URL
+		if u.Opaque != "" && '0' <= u.Opaque[0] && u.Opaque[0] <= '9' {
+			return URL("https://" + s)
+		}
+		return "", ErrScheme
+	}
+	if u.Host == "" || u.User != nil || u.Opaque != "" {
+		return "", ErrURL
+	}
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "Every minute", spec: "* * * * *", wantErr: false},
+		{name: "Steps, ranges and lists", spec: "*/15 9-17 * * 1,3,5", wantErr: false},
+		{name: "Descriptor", spec: "@daily", wantErr: false},
+		{name: "Sunday as 7", spec: "0 0 * * 7", wantErr: false},
+		{name: "Too few fields", spec: "* * * *", wantErr: true},
+		{name: "Minute out of range", spec: "60 * * * *", wantErr: true},
+		{name: "Inverted range", spec: "* 5-1 * * *", wantErr: true},
+		{name: "Zero step", spec: "*/0 * * * *", wantErr: true},
+		{name: "Garbage", spec: "a b c d e", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCron(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 1, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{name: "Every minute", spec: "* * * * *", want: time.Date(2025, 1, 1, 10, 8, 0, 0, time.UTC)},
+		{name: "Every 15 minutes", spec: "*/15 * * * *", want: time.Date(2025, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{name: "Hourly", spec: "@hourly", want: time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{name: "Daily at 3am", spec: "0 3 * * *", want: time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)},
+		{name: "Sundays", spec: "0 0 * * 0", want: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "Sundays as 7", spec: "0 0 * * 7", want: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "First of month or Friday", spec: "0 0 1 * 5", want: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "Leap day", spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.spec, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
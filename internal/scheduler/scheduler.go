@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTaskNotFound is returned when triggering an unknown task.
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskRunning is returned when triggering a task that is already running.
+	ErrTaskRunning = errors.New("task is already running")
+)
+
+// TaskFunc is the unit of work run by a scheduled task.
+type TaskFunc func(ctx context.Context) error
+
+// TaskStatus describes a task for the admin API.
+type TaskStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Running      bool       `json:"running"`
+	LastRun      *time.Time `json:"last_run"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastSuccess  *time.Time `json:"last_success"`
+	NextRun      time.Time  `json:"next_run"`
+}
+
+type task struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       TaskFunc
+
+	running      bool
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+	lastSuccess  time.Time
+	nextRun      time.Time
+}
+
+// Scheduler runs tasks on cron schedules. A task never overlaps with
+// itself: if it is still running when it is due again, that run is skipped.
+type Scheduler struct {
+	mu    sync.Mutex
+	tasks []*task
+	ctx   context.Context
+	now   func() time.Time
+	wake  chan struct{}
+}
+
+// New creates an empty Scheduler.
+func New() *Scheduler {
+	return &Scheduler{
+		ctx:  context.Background(),
+		now:  func() time.Time { return time.Now().UTC() },
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Add registers a task under a unique name with a cron expression.
+func (s *Scheduler) Add(name, spec string, fn TaskFunc) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if t.name == name {
+			return fmt.Errorf("task %s is already registered", name)
+		}
+	}
+	s.tasks = append(s.tasks, &task{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+		nextRun:  schedule.Next(s.now()),
+	})
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run starts due tasks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		now := s.now()
+		next := now.Add(time.Hour)
+		for _, t := range s.tasks {
+			if !t.nextRun.After(now) {
+				t.nextRun = t.schedule.Next(now)
+				if !t.running {
+					s.start(ctx, t)
+				} else {
+					slog.WarnContext(ctx, "Skipping scheduled task, previous run still in progress", "task", t.name)
+				}
+			}
+			if t.nextRun.Before(next) {
+				next = t.nextRun
+			}
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Trigger runs a task immediately, outside of its schedule.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if t.name != name {
+			continue
+		}
+		if t.running {
+			return ErrTaskRunning
+		}
+		s.start(s.ctx, t)
+		return nil
+	}
+	return ErrTaskNotFound
+}
+
+// start runs t in the background. s.mu must be held.
+func (s *Scheduler) start(ctx context.Context, t *task) {
+	t.running = true
+	go func() {
+		start := s.now()
+		err := t.fn(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Scheduled task failed", "task", t.name, "error", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		t.running = false
+		t.lastRun = start
+		t.lastDuration = s.now().Sub(start)
+		t.lastErr = err
+		if err == nil {
+			t.lastSuccess = s.now()
+		}
+	}()
+}
+
+// LastSuccess returns when the named task last completed without error,
+// or the zero time if it hasn't yet.
+func (s *Scheduler) LastSuccess(name string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if t.name == name {
+			return t.lastSuccess
+		}
+	}
+	return time.Time{}
+}
+
+// Status reports every task in registration order.
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		status := TaskStatus{
+			Name:     t.name,
+			Schedule: t.spec,
+			Running:  t.running,
+			NextRun:  t.nextRun,
+		}
+		if !t.lastRun.IsZero() {
+			lastRun := t.lastRun
+			status.LastRun = &lastRun
+			status.LastDuration = t.lastDuration.String()
+		}
+		if t.lastErr != nil {
+			status.LastError = t.lastErr.Error()
+		}
+		if !t.lastSuccess.IsZero() {
+			lastSuccess := t.lastSuccess
+			status.LastSuccess = &lastSuccess
+		}
+		out = append(out, status)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// Package tags finds #hashtags and @mentions in chirp bodies, and checks
+// that usernames can be @mentioned.
+package tags
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Username length limits, in characters.
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 20
+)
+
+// ErrInvalidUsername is returned by NormalizeUsername.
+var ErrInvalidUsername = errors.New("usernames must be 3 to 20 letters, digits or underscores")
+
+// Same definition as the \w+ used by the stats queries in Postgres.
+var hashtagRe = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+// Usernames are ASCII letters, digits and underscores. The @ must not
+// follow a word character, so email addresses aren't mentions.
+var mentionRe = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_]+)`)
+
+var usernameRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Hashtags returns the distinct hashtags in s, lowercased and without the
+// leading #, in order of first appearance.
+func Hashtags(s string) []string {
+	return distinctLower(hashtagRe.FindAllStringSubmatch(s, -1))
+}
+
+// Mentions returns the distinct usernames mentioned in s, lowercased and
+// without the leading @, in order of first appearance.
+func Mentions(s string) []string {
+	return distinctLower(mentionRe.FindAllStringSubmatch(s, -1))
+}
+
+// NormalizeUsername lowercases a username and checks that it can be
+// @mentioned. A leading @ is dropped.
+func NormalizeUsername(s string) (string, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "@")
+	if len(s) < MinUsernameLength || len(s) > MaxUsernameLength || !usernameRe.MatchString(s) {
+		return "", ErrInvalidUsername
+	}
+	return strings.ToLower(s), nil
+}
+
+func distinctLower(matches [][]string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range matches {
+		v := strings.ToLower(m[1])
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// Normalize turns user input such as "#GoLang" into the stored form of a
+// tag, or "" if it isn't a valid tag.
+func Normalize(s string) string {
+	s = strings.TrimPrefix(s, "#")
+	if m := hashtagRe.FindStringSubmatch("#" + s); m == nil || m[1] != s {
+		return ""
+	}
+	return strings.ToLower(s)
+}
@@ -0,0 +1,101 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "None", body: "just a chirp", want: nil},
+		{name: "Lowercased", body: "Learning #GoLang today", want: []string{"golang"}},
+		{name: "Deduplicated", body: "#go #Go #GO", want: []string{"go"}},
+		{name: "Punctuation ends a tag", body: "#one, #two! (#three)", want: []string{"one", "two", "three"}},
+		{name: "Unicode", body: "#café #日本", want: []string{"café", "日本"}},
+		{name: "Lone hash", body: "# not a tag", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hashtags(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hashtags(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "None", body: "just a chirp", want: nil},
+		{name: "Lowercased", body: "@Alice said hi", want: []string{"alice"}},
+		{name: "Deduplicated", body: "@bob @BOB", want: []string{"bob"}},
+		{name: "Punctuation ends a mention", body: "cc @one, @two_2!", want: []string{"one", "two_2"}},
+		{name: "Email is not a mention", body: "mail me at me@example.com", want: nil},
+		{name: "Lone at", body: "meet @ noon", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mentions(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"GoLang":    "golang",
+		"#GoLang":   "golang",
+		"two words": "",
+		"":          "",
+		"#":         "",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "alice", want: "alice"},
+		{in: "Alice_99", want: "alice_99"},
+		{in: "@bob", want: "bob"},
+		{in: "  carol ", want: "carol"},
+		{in: "ab", wantErr: true},
+		{in: "abcdefghijklmnopqrstu", wantErr: true},
+		{in: "dan.smith", wantErr: true},
+		{in: "émile", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeUsername(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeUsername(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeUsername(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+		if m := Mentions("@" + got); len(m) != 1 || m[0] != got {
+			t.Errorf("username %q can't be mentioned: Mentions = %v", got, m)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC's 8 digit codes, cut to their last 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	tests := []struct {
+		name string
+		code string
+		at   time.Time
+		want bool
+	}{
+		{name: "Current step", code: "005924", at: now, want: true},
+		{name: "Previous step", code: "005924", at: now.Add(Period), want: true},
+		{name: "Too old", code: "005924", at: now.Add(2 * Period), want: false},
+		{name: "Wrong code", code: "123456", at: now, want: false},
+		{name: "Wrong length", code: "5924", at: now, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, tt.at)
+			if ok != tt.want {
+				t.Errorf("Validate() = %t, want %t", ok, tt.want)
+			}
+			if ok && step != Step(now) {
+				t.Errorf("step = %d, want %d", step, Step(now))
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("Code() of a generated secret: %v", err)
+	}
+	if u := URL("Chirpy", "walt@example.com", secret); !strings.HasPrefix(u, "otpauth://totp/Chirpy:walt@example.com?") || !strings.Contains(u, "secret="+secret) {
+		t.Errorf("URL() = %s", u)
+	}
+}
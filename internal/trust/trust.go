@@ -0,0 +1,163 @@
+// Package trust decides how far a user is trusted from how long they have
+// been around and how the community has received them. Higher levels
+// unlock capabilities that spammers would abuse on a fresh account.
+package trust
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Level is how far a user is trusted.
+type Level string
+
+// Levels, from least to most trusted.
+const (
+	New     Level = "new"
+	Basic   Level = "basic"
+	Trusted Level = "trusted"
+)
+
+var levels = []Level{New, Basic, Trusted}
+
+func (l Level) rank() int {
+	for i, level := range levels {
+		if level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid reports whether l is a known level.
+func (l Level) Valid() bool {
+	return l.rank() >= 0
+}
+
+// Capability is something only users of some level can do.
+type Capability string
+
+// Capabilities that trust unlocks.
+const (
+	PostLinks       Capability = "post_links"
+	UploadMedia     Capability = "upload_media"
+	HigherRateLimit Capability = "higher_rate_limit"
+)
+
+// Unlocks is the level each capability needs.
+var Unlocks = map[Capability]Level{
+	PostLinks:       Basic,
+	UploadMedia:     Basic,
+	HigherRateLimit: Trusted,
+}
+
+// Can reports whether users of level l have capability c.
+func (l Level) Can(c Capability) bool {
+	need, ok := Unlocks[c]
+	return ok && l.rank() >= need.rank()
+}
+
+// Capabilities returns what users of level l can do, in a stable order.
+func (l Level) Capabilities() []Capability {
+	var caps []Capability
+	for _, c := range []Capability{PostLinks, UploadMedia, HigherRateLimit} {
+		if l.Can(c) {
+			caps = append(caps, c)
+		}
+	}
+	return caps
+}
+
+// Activity is what a user's level is decided from.
+type Activity struct {
+	AccountAge    time.Duration
+	Chirps        int
+	LikesReceived int // from other users
+	Followers     int
+	Strikes       int // moderation actions that weren't reversed
+}
+
+// Requirement is what a user must have done to reach a level.
+type Requirement struct {
+	MinAge           time.Duration
+	MinChirps        int
+	MinLikesReceived int
+	MinFollowers     int
+	MaxStrikes       int
+}
+
+// Met reports whether a satisfies q.
+func (q Requirement) Met(a Activity) bool {
+	return a.AccountAge >= q.MinAge &&
+		a.Chirps >= q.MinChirps &&
+		a.LikesReceived >= q.MinLikesReceived &&
+		a.Followers >= q.MinFollowers &&
+		a.Strikes <= q.MaxStrikes
+}
+
+// Rules are the requirements of the levels above New.
+type Rules struct {
+	Basic   Requirement
+	Trusted Requirement
+}
+
+// DefaultRules trust a user with links and media after a day and a chirp,
+// and with more requests once they have been around for a month and
+// others engage with them.
+var DefaultRules = Rules{
+	Basic:   Requirement{MinAge: 24 * time.Hour, MinChirps: 1, MaxStrikes: 1},
+	Trusted: Requirement{MinAge: 30 * 24 * time.Hour, MinChirps: 20, MinLikesReceived: 20, MinFollowers: 5},
+}
+
+// Evaluate returns the level a user with activity a has. A level needs the
+// requirements of the levels below it as well, so users lose levels
+// again if, say, they collect strikes.
+func (r Rules) Evaluate(a Activity) Level {
+	switch {
+	case !r.Basic.Met(a):
+		return New
+	case !r.Trusted.Met(a):
+		return Basic
+	}
+	return Trusted
+}
+
+// ParseRequirement reads a requirement such as
+// "age=72h,chirps=5,likes=10,followers=2,max_strikes=0". Minimums left
+// out are 0, and so is max_strikes.
+func ParseRequirement(s string) (Requirement, error) {
+	var q Requirement
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return Requirement{}, fmt.Errorf("%q is not name=value", part)
+		}
+		if k == "age" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return Requirement{}, fmt.Errorf("%q is not an account age", v)
+			}
+			q.MinAge = d
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Requirement{}, fmt.Errorf("%q is not a count", v)
+		}
+		switch k {
+		case "chirps":
+			q.MinChirps = n
+		case "likes":
+			q.MinLikesReceived = n
+		case "followers":
+			q.MinFollowers = n
+		case "max_strikes":
+			q.MaxStrikes = n
+		default:
+			return Requirement{}, fmt.Errorf("unknown requirement %q, expected age, chirps, likes, followers or max_strikes", k)
+		}
+	}
+	return q, nil
+}
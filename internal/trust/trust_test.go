@@ -0,0 +1,53 @@
+package trust
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name string
+		a    Activity
+		want Level
+	}{
+		{"brand new", Activity{}, New},
+		{"a day and a chirp", Activity{AccountAge: day, Chirps: 1}, Basic},
+		{"old but silent", Activity{AccountAge: 365 * day}, New},
+		{"established", Activity{AccountAge: 60 * day, Chirps: 50, LikesReceived: 100, Followers: 10}, Trusted},
+		{"established with a strike", Activity{AccountAge: 60 * day, Chirps: 50, LikesReceived: 100, Followers: 10, Strikes: 1}, Basic},
+		{"repeat offender", Activity{AccountAge: 60 * day, Chirps: 50, LikesReceived: 100, Followers: 10, Strikes: 2}, New},
+	}
+	for _, tt := range tests {
+		if got := DefaultRules.Evaluate(tt.a); got != tt.want {
+			t.Errorf("%s: Evaluate(%+v) = %s, want %s", tt.name, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	if caps := New.Capabilities(); len(caps) != 0 {
+		t.Errorf("New.Capabilities() = %v, want none", caps)
+	}
+	if caps, want := Basic.Capabilities(), []Capability{PostLinks, UploadMedia}; !slices.Equal(caps, want) {
+		t.Errorf("Basic.Capabilities() = %v, want %v", caps, want)
+	}
+	if !Trusted.Can(HigherRateLimit) || Level("admin").Can(PostLinks) {
+		t.Error("Can() doesn't follow Unlocks")
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	got, err := ParseRequirement("age=72h, chirps=5,likes=10,followers=2,max_strikes=1")
+	want := Requirement{MinAge: 72 * time.Hour, MinChirps: 5, MinLikesReceived: 10, MinFollowers: 2, MaxStrikes: 1}
+	if err != nil || got != want {
+		t.Errorf("ParseRequirement() = %+v, %v, want %+v", got, err, want)
+	}
+	for _, s := range []string{"age=soon", "chirps=-1", "karma=5", "chirps"} {
+		if _, err := ParseRequirement(s); err == nil {
+			t.Errorf("ParseRequirement(%q) error = nil", s)
+		}
+	}
+}
@@ -1,74 +1,462 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	// Users' quiet hours are in their own time zone, which has to load
+	// even where the system has no zone database
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"main.go/internal/anomaly"
+	"main.go/internal/autocert"
+	"main.go/internal/broadcast"
+	"main.go/internal/chaos"
+	"main.go/internal/compress"
+	"main.go/internal/config"
+	"main.go/internal/contentfilter"
+	"main.go/internal/cors"
 	"main.go/internal/database"
+	"main.go/internal/deadman"
+	"main.go/internal/events"
+	"main.go/internal/feed"
+	"main.go/internal/geoip"
+	"main.go/internal/health"
+	"main.go/internal/jobs"
+	"main.go/internal/mail"
+	"main.go/internal/media"
+	"main.go/internal/metrics"
+	"main.go/internal/notify"
+	"main.go/internal/oauth"
+	"main.go/internal/ratelimit"
+	"main.go/internal/relme"
+	"main.go/internal/requestid"
+	"main.go/internal/scheduler"
 )
 
 func main() {
-	const filepathRoot = "."
-	const port = "8080"
-
-	// Load environment variables
+	// Load environment variables, then the configuration from them and
+	// the flags
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
+	conf, err := config.Load(os.Args[1:], os.Environ())
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	// Sliding expiration can't cap sessions below their initial lifetime
+	if conf.RefreshTokenMaxAge != 0 && conf.RefreshTokenMaxAge < refreshTokenTTL {
+		log.Fatalf("Invalid REFRESH_TOKEN_MAX_AGE %s, expected at least %s", conf.RefreshTokenMaxAge, refreshTokenTTL)
+	}
+
+	// Structured logs, as text or as JSON for log collectors, tagged with
+	// the request ID where there is one. The standard logger writes
+	// through the same handler.
+	var logHandler slog.Handler = slog.NewTextHandler(os.Stderr, nil)
+	if conf.LogFormat == "json" {
+		logHandler = slog.NewJSONHandler(os.Stderr, nil)
+	}
+	slog.SetDefault(slog.New(requestid.LogHandler{Handler: logHandler}))
 
 	// Connect to PostgreSQL
-	dbURL := os.Getenv("DB_URL")
-	db, err := sql.Open("postgres", dbURL)
+	db, err := sql.Open("postgres", conf.DBURL)
 	if err != nil {
 		log.Fatal("Can't connect to database:", err)
 	}
 
-	// Create SQLC query handler
-	dbQueries := database.New(db)
+	// Create SQLC query handler, timing every query
+	promMetrics := newServerMetrics()
+	promMetrics.watchDB(db)
+	dbQueries := database.New(timedDB{db: db, durations: promMetrics.queryDuration})
+
+	// Maintenance commands run instead of the server
+	if len(conf.Args) > 0 {
+		switch conf.Args[0] {
+		case "fsck":
+			os.Exit(runFsck(context.Background(), db, conf.Args[1:]))
+		case "set-role":
+			os.Exit(runSetRole(context.Background(), dbQueries, conf.Args[1:]))
+		default:
+			log.Fatalf("Unknown command %q, expected fsck or set-role", conf.Args[0])
+		}
+	}
+
+	// Background workers run until shutdown cancels bgCtx
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(bgCtx)
+		}()
+	}
+
+	// Background job queue
+	const jobPollInterval = 5 * time.Second
+	jobQueue := jobs.NewQueue(dbQueries)
+	runWorker(func(ctx context.Context) { jobQueue.Run(ctx, jobPollInterval) })
+
+	// Uploaded media
+	mediaStore := media.NewStore(conf.MediaRoot)
+
+	// Sample component health for the status page
+	healthChecker := health.NewChecker(24*time.Hour, 5*time.Second)
+	healthChecker.Register("api", func(ctx context.Context) error { return nil })
+	healthChecker.Register("database", db.PingContext)
+	healthChecker.Register("job_queue", func(ctx context.Context) error {
+		if time.Since(jobQueue.LastPoll()) > 3*jobPollInterval {
+			return errors.New("job worker is not polling")
+		}
+		return nil
+	})
+	healthChecker.Register("storage", mediaStore.Check)
+	runWorker(func(ctx context.Context) { healthChecker.Run(ctx, 30*time.Second) })
+
+	// Optional event bus for lifecycle events
+	var eventPublisher events.Publisher = events.NopPublisher{}
+	var webhooks *events.WebhookPublisher
+	switch conf.EventBus {
+	case "nats":
+		natsPublisher, err := events.NewNATSPublisher(conf.NATSURL, conf.EventSubjectPrefix)
+		if err != nil {
+			log.Fatal("Invalid NATS_URL:", err)
+		}
+		eventPublisher = natsPublisher
+	case "kafka":
+		eventPublisher = events.NewKafkaRESTPublisher(conf.KafkaRESTURL, conf.EventSubjectPrefix, &http.Client{Timeout: 10 * time.Second})
+	case "webhook":
+		webhooks = &events.WebhookPublisher{
+			URL:    conf.EventWebhookURL,
+			Secret: conf.EventWebhookSecret,
+			Client: &http.Client{Timeout: 10 * time.Second},
+			Log:    webhookDeliveryLog{db: dbQueries},
+		}
+		eventPublisher = webhooks
+	}
+	asyncPublisher := events.NewAsyncPublisher(eventPublisher, 1024)
+	runWorker(asyncPublisher.Run)
 
-	// 🔐 Load JWT secret from env
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET not set in environment")
+	// Ranking strategy for the For You feed
+	var ranker feed.Ranker = feed.NewHeuristicRanker()
+	if conf.FeedRankerURL != "" {
+		ranker = &feed.ExternalRanker{
+			URL:      conf.FeedRankerURL,
+			Client:   &http.Client{Timeout: 2 * time.Second},
+			Fallback: ranker,
+		}
+	}
+
+	// Cross-origin access for browser clients of the API
+	corsPolicy := cors.DefaultPolicy
+	if conf.CORSAllowedOrigins != nil {
+		corsPolicy.Origins = conf.CORSAllowedOrigins
+	}
+	if conf.CORSAllowedMethods != nil {
+		corsPolicy.Methods = conf.CORSAllowedMethods
+	}
+	if conf.CORSAllowedHeaders != nil {
+		corsPolicy.Headers = conf.CORSAllowedHeaders
+	}
+
+	// Gzip for clients that accept it
+	compressPolicy := compress.DefaultPolicy
+	compressPolicy.MinSize = conf.CompressionMinSize
+	if conf.CompressionTypes != nil {
+		compressPolicy.Types = conf.CompressionTypes
+	}
+
+	// Counters persisted across restarts
+	metricsRegistry := metrics.NewRegistry(counterStore{db: dbQueries})
+	fileserverHits := metricsRegistry.Counter("fileserver_hits")
+	excludedHits := metricsRegistry.Counter("fileserver_hits_excluded")
+	if err := metricsRegistry.Load(context.Background()); err != nil {
+		slog.Error("Failed to load metrics", "error", err)
 	}
 
 	// Create API config with DB access and JWT secret
 	apiCfg := &apiConfig{
-		DB:        dbQueries,
-		PLATFORM:  os.Getenv("PLATFORM"),
-		jwtSecret: jwtSecret, // 🔐 Add this line
-	}
-
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("GET /api/healthz", HealthzHandler)
-	mux.HandleFunc("GET /admin/metrics", apiCfg.adminMetricsHandler)
-	mux.HandleFunc("POST /admin/reset", apiCfg.resetHandler)
-	mux.HandleFunc("POST /api/validate_chirp", handlerChirpsValidate)
-	mux.HandleFunc("/api/users", apiCfg.createUserHandler)
-	mux.HandleFunc("POST /api/chirps", apiCfg.createChirpHandler)
-	mux.HandleFunc("GET /api/chirps", apiCfg.getChirpsHandler)
-	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.getChirpByIDHandler)
-	mux.HandleFunc("/api/login", apiCfg.handlerLogin)
-	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
-	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
-	mux.HandleFunc("PUT /api/users", apiCfg.updateUserHandler)
-	mux.HandleFunc("/api/chirps/{chirpID}", apiCfg.deleteChirpHandler)
-
-	// Wrap file server with the metrics increment middleware
-	fileServer := http.FileServer(http.Dir(filepathRoot))
-	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", fileServer)))
+		metrics:             metricsRegistry,
+		prom:                promMetrics,
+		fileserverHits:      fileserverHits,
+		excludedHits:        excludedHits,
+		hitExclusions:       conf.HitExclusions,
+		DB:                  dbQueries,
+		sqlDB:               db,
+		PLATFORM:            conf.Platform,
+		jwtKeys:             conf.JWTKeys,
+		polkaKey:            conf.PolkaKey,
+		deletionGracePeriod: conf.AccountDeletionGracePeriod,
+		usernameGracePeriod: conf.UsernameRedirectPeriod,
+		usernameCooldown:    conf.UsernameChangeInterval,
+		chirpStream:         broadcast.New[events.Event](conf.StreamMaxClients),
+		streamBuffer:        conf.StreamBuffer,
+		maxSessions:         conf.MaxSessionsPerUser,
+		maxBodySize:         conf.MaxBodySize,
+		refreshTokenMaxAge:  conf.RefreshTokenMaxAge,
+		health:              healthChecker,
+		jobs:                jobQueue,
+		scheduler:           scheduler.New(),
+		events:              asyncPublisher,
+		webhooks:            webhooks,
+		ranker:              ranker,
+		publicURL:           conf.PublicURL,
+		websiteClient:       relme.NewClient(10 * time.Second),
+		mediaRoot:           conf.MediaRoot,
+		mediaStore:          mediaStore,
+		mediaSigningSecret:  conf.MediaSigningSecret,
+		mediaAllowedHosts:   conf.MediaAllowedHosts,
+		rateLimiter:         ratelimit.New(conf.RateLimitRequests, conf.RateLimitWindow),
+		trustedRateLimiter:  ratelimit.New(conf.RateLimitTrustedRequests, conf.RateLimitWindow),
+		anonRateLimiter:     ratelimit.New(conf.RateLimitAnonymousRequests, conf.RateLimitWindow),
+		existsRateLimiter:   ratelimit.New(10, time.Minute),
+		resetRateLimiter:    ratelimit.New(5, time.Hour),
+		mailer:              mail.LogMailer{},
+		profanity:           newProfanityFilter(conf.ProfanityMode, dbQueries.ListBannedWords),
+		anomalies:           anomaly.NewDetector(anomaly.DefaultRules),
+		reportPolicies:      conf.ReportPolicies,
+		trustRules:          conf.TrustRules,
+	}
+
+	apiCfg.readOnly.Store(conf.ReadOnly)
+
+	// Content filters, in the configured order
+	var filters contentfilter.Chain
+	for _, name := range conf.ContentFilters {
+		switch name {
+		case "words":
+			filters = append(filters, apiCfg.profanity)
+		case "regex":
+			f, err := os.Open(conf.ContentFilterRulesFile)
+			if err != nil {
+				log.Fatal("Failed to open content filter rules:", err)
+			}
+			rules, err := contentfilter.ParseRules(f)
+			f.Close()
+			if err != nil {
+				log.Fatal("Invalid content filter rules:", err)
+			}
+			filters = append(filters, rules)
+		case "api":
+			filters = append(filters, contentfilter.API{URL: conf.ContentFilterAPIURL, Client: &http.Client{Timeout: 5 * time.Second}})
+		}
+	}
+	apiCfg.contentFilter = filters
+	if conf.Platform == "dev" || conf.Platform == "staging" {
+		apiCfg.chaos = chaos.New()
+	}
+
+	// Social login with the providers that have credentials
+	apiCfg.oauthProviders = map[string]*oauth.Provider{}
+	oauthClient := &http.Client{Timeout: 10 * time.Second}
+	for _, p := range []*oauth.Provider{
+		oauth.Google(conf.GoogleClientID, conf.GoogleClientSecret, conf.OAuthRedirectBaseURL+"/api/v1/auth/google/callback"),
+		oauth.GitHub(conf.GitHubClientID, conf.GitHubClientSecret, conf.OAuthRedirectBaseURL+"/api/v1/auth/github/callback"),
+	} {
+		if p.ClientID != "" {
+			p.Client = oauthClient
+			apiCfg.oauthProviders[p.Name] = p
+		}
+	}
+
+	// Geo-velocity checks at login
+	if conf.GeoIPDatabase != "" {
+		apiCfg.geoip, err = geoip.Open(conf.GeoIPDatabase)
+		if err != nil {
+			log.Fatal("Failed to open GeoIP database:", err)
+		}
+		apiCfg.loginMaxTravelSpeed = float64(conf.LoginMaxTravelSpeed)
+	}
+
+	// In-app delivery, APNs and FCM are logged until those services exist
+	apiCfg.notifier = notify.NewDispatcher(apiCfg.notificationPreferences, map[notify.Channel]notify.Sender{
+		notify.InApp: notify.LogSender{Channel: notify.InApp},
+		notify.Email: emailSender{cfg: apiCfg},
+		notify.Push: notify.PushSender{
+			Devices: deviceStore{db: dbQueries},
+			Providers: map[notify.Platform]notify.PushProvider{
+				notify.APNs: notify.LogPushProvider{Platform: notify.APNs},
+				notify.FCM:  notify.LogPushProvider{Platform: notify.FCM},
+			},
+		},
+	}, notificationQueue{db: dbQueries})
+
+	// Alert when critical workers stop making progress
+	var alerter deadman.Alerter = deadman.LogAlerter{}
+	if conf.AlertWebhookURL != "" {
+		alerter = deadman.WebhookAlerter{URL: conf.AlertWebhookURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	}
+	apiCfg.deadman = deadman.NewMonitor(alerter)
+	apiCfg.deadman.Watch("job_queue", 10*jobPollInterval, jobQueue.LastPoll)
+
+	// Periodic maintenance tasks. Tasks with a window are critical: an
+	// alert fires if they haven't succeeded within it.
+	tasks := []struct {
+		name   string
+		spec   string
+		fn     scheduler.TaskFunc
+		window time.Duration
+	}{
+		{"purge_deleted_users", "@hourly", apiCfg.purgeDeletedUsersTask, 3 * time.Hour},
+		{"cleanup_refresh_tokens", "30 3 * * *", apiCfg.cleanupRefreshTokensTask, 0},
+		{"cleanup_password_reset_tokens", "45 3 * * *", apiCfg.cleanupPasswordResetTokensTask, 0},
+		{"flush_metrics", "* * * * *", apiCfg.flushMetricsTask, 0},
+		{"record_metrics_history", "* * * * *", apiCfg.recordMetricsHistoryTask, 0},
+		{"compute_digest", "0 6 * * *", apiCfg.computeDigestTask, 26 * time.Hour},
+		{"deliver_held_notifications", "*/5 * * * *", apiCfg.deliverHeldNotificationsTask, 0},
+		{"evaluate_trust_levels", "0 4 * * *", apiCfg.evaluateTrustLevelsTask, 0},
+		{"cleanup_webhook_deliveries", "15 4 * * *", apiCfg.cleanupWebhookDeliveriesTask, 0},
+		{"cleanup_chirp_images", "45 4 * * *", apiCfg.cleanupChirpImagesTask, 0},
+	}
+	for _, t := range tasks {
+		if err := apiCfg.scheduler.Add(t.name, conf.TaskSchedule(t.name, t.spec), t.fn); err != nil {
+			log.Fatal("Invalid task schedule:", err)
+		}
+		if t.window > 0 {
+			name := t.name
+			apiCfg.deadman.Watch(name, t.window, func() time.Time { return apiCfg.scheduler.LastSuccess(name) })
+		}
+	}
+	jobQueue.Handle(emailDigestJob, apiCfg.emailDigest)
+	jobQueue.Handle(verifyWebsiteJob, apiCfg.verifyWebsite)
+
+	runWorker(apiCfg.scheduler.Run)
+	runWorker(func(ctx context.Context) { apiCfg.deadman.Run(ctx, time.Minute) })
+
+	mux := apiCfg.routes(conf.FileRoot)
+
+	handler := apiCfg.middlewareLogging(corsPolicy.Handler("/api/", apiCfg.middlewareChaos(mux.ServeMux, apiCfg.middlewareRateLimit(apiCfg.middlewareMaxBody(apiCfg.middlewareReadOnly(apiCfg.middlewareSuspended(apiCfg.middlewareAuthorize(mux.ServeMux))))))))
+	// Compression goes around the request log, whose writer handlers
+	// record errors on
+	if conf.Compression {
+		handler = compressPolicy.Handler(handler)
+	}
 
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
+		Addr:              conf.Addr(),
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		ReadTimeout:       conf.ReadTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		Handler:           requestid.Handler(apiCfg.middlewarePrometheus(mux.ServeMux, handler)),
+	}
+	// Streams would otherwise hold up Shutdown until it times out
+	srv.RegisterOnShutdown(apiCfg.chirpStream.Close)
+
+	// HTTPS, from the certificate files or from Let's Encrypt, with plain
+	// HTTP redirected to it
+	var certManager *autocert.Manager
+	if conf.AutocertHosts != nil {
+		certManager = autocert.NewManager(conf.AutocertHosts, conf.AutocertCacheDir, conf.AutocertEmail, conf.AutocertDirectoryURL)
+		srv.TLSConfig = &tls.Config{GetCertificate: certManager.GetCertificate}
+		runWorker(func(ctx context.Context) { certManager.Run(ctx, 12*time.Hour) })
+	}
+	var redirectSrv *http.Server
+	if addr := conf.RedirectAddr(); addr != "" {
+		var redirect http.Handler = redirectHTTPS(conf.Port)
+		if certManager != nil {
+			redirect = certManager.HTTPHandler(redirect)
+		}
+		redirectSrv = &http.Server{Addr: addr, Handler: redirect, ReadHeaderTimeout: 10 * time.Second}
 	}
 
-	log.Printf("Serving files from %s at http://localhost:%s\n", filepathRoot, port)
-	log.Fatal(srv.ListenAndServe())
+	// Prometheus metrics, kept off the public listener
+	var metricsSrv *http.Server
+	if conf.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.HandleFunc("GET /metrics", apiCfg.prometheusMetricsHandler)
+		metricsSrv = &http.Server{Addr: conf.MetricsAddr, Handler: metricsMux, ReadHeaderTimeout: 10 * time.Second}
+	}
+
+	// Stop on SIGINT or SIGTERM: drain in-flight requests, stop the
+	// background workers, save counters and close the pool
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		var err error
+		if !conf.TLSEnabled() {
+			slog.Info("Serving files", "root", conf.FileRoot, "addr", "http://localhost"+conf.Addr())
+			err = srv.ListenAndServe()
+		} else {
+			slog.Info("Serving files", "root", conf.FileRoot, "addr", "https://localhost"+conf.Addr())
+			// Empty with autocert, which supplies certificates through TLSConfig
+			err = srv.ListenAndServeTLS(conf.TLSCertFile, conf.TLSKeyFile)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("Redirecting HTTP to HTTPS", "addr", redirectSrv.Addr)
+			if err := redirectSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+	if metricsSrv != nil {
+		go func() {
+			slog.Info("Serving metrics", "addr", "http://"+metricsSrv.Addr+"/metrics")
+			if err := metricsSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	stop() // a second signal kills the process
+	slog.Info("Shutting down", "timeout", conf.ShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer cancel()
+	drainCtx, stopDrainLog := context.WithCancel(shutdownCtx)
+	go apiCfg.logDraining(drainCtx, 5*time.Second)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Couldn't drain in-flight requests", "error", err)
+		apiCfg.logInFlight(context.Background(), "Abandoned in-flight requests")
+	}
+	stopDrainLog()
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(shutdownCtx)
+	}
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(shutdownCtx)
+	}
+
+	stopBackground()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		slog.Error("Background workers didn't stop in time")
+	}
+
+	if err := apiCfg.metrics.Flush(shutdownCtx); err != nil {
+		slog.Error("Failed to flush metrics", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("Couldn't close database", "error", err)
+	}
+	slog.Info("Shutdown complete")
 }
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"main.go/internal/database"
+	"main.go/internal/prometheus"
+	"main.go/internal/response"
+)
+
+// counterStore persists metrics counters in the counters table.
+type counterStore struct {
+	db *database.Queries
+}
+
+func (s counterStore) LoadCounters(ctx context.Context) (map[string]int64, error) {
+	rows, err := s.db.GetCounters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		totals[row.Name] = row.Value
+	}
+	return totals, nil
+}
+
+func (s counterStore) AddToCounter(ctx context.Context, name string, delta int64) (int64, error) {
+	return s.db.AddToCounter(ctx, database.AddToCounterParams{Name: name, Value: delta})
+}
+
+func (s counterStore) ResetCounters(ctx context.Context) error {
+	return s.db.ResetCounters(ctx)
+}
+
+// flushMetricsTask saves counter increments so they survive a restart.
+// Whatever was counted since the last run is lost if the process dies.
+func (cfg *apiConfig) flushMetricsTask(ctx context.Context) error {
+	return cfg.metrics.Flush(ctx)
+}
+
+// serverMetrics are the request, query and auth metrics scraped from
+// GET /metrics. Unlike the counters above they start from zero on every
+// restart, which is what Prometheus expects.
+type serverMetrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	authFailures    *prometheus.CounterVec
+	inFlight        *prometheus.GaugeVec
+	streams         *prometheus.GaugeVec
+
+	historyMu   sync.Mutex
+	historySent historyTotals // included in metrics_history so far
+}
+
+func newServerMetrics() *serverMetrics {
+	r := prometheus.NewRegistry()
+	return &serverMetrics{
+		registry: r,
+		requests: r.NewCounterVec("chirpy_http_requests_total",
+			"HTTP requests by route pattern, method and status code.", "route", "method", "status"),
+		requestDuration: r.NewHistogramVec("chirpy_http_request_duration_seconds",
+			"Time to serve HTTP requests by route pattern.", prometheus.DefaultBuckets, "route"),
+		queryDuration: r.NewHistogramVec("chirpy_db_query_duration_seconds",
+			"Time to run database queries by query name.", prometheus.DefaultBuckets, "query"),
+		authFailures: r.NewCounterVec("chirpy_auth_failures_total",
+			"Requests rejected as unauthenticated by route pattern.", "route"),
+		inFlight: r.NewGaugeVec("chirpy_http_requests_in_flight",
+			"Requests being served by route pattern.", "route"),
+		streams: r.NewGaugeVec("chirpy_streaming_connections",
+			"Open streaming responses, such as exports, by route pattern.", "route"),
+	}
+}
+
+// watchDB adds gauges of the connection pool's state.
+func (m *serverMetrics) watchDB(db *sql.DB) {
+	m.registry.NewGaugeFunc("chirpy_db_open_connections",
+		"Open database connections, in use or idle.", func() float64 { return float64(db.Stats().OpenConnections) })
+	m.registry.NewGaugeFunc("chirpy_db_in_use_connections",
+		"Database connections running a query or transaction.", func() float64 { return float64(db.Stats().InUse) })
+}
+
+// knownMethods keeps the method label bounded when a route accepts any
+// method.
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// observeRequest records a served request. route is the ServeMux
+// pattern, so /api/chirps/{chirpID} is one series however many chirps
+// there are.
+func (m *serverMetrics) observeRequest(route, method string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	if !knownMethods[method] {
+		method = "other"
+	}
+	m.requests.Inc(route, method, strconv.Itoa(status))
+	m.requestDuration.Observe(elapsed.Seconds(), route)
+	if status == http.StatusUnauthorized {
+		m.authFailures.Inc(route)
+	}
+}
+
+// historyTotals are request and query totals since boot.
+type historyTotals struct {
+	requests     float64
+	queries      uint64
+	querySeconds float64
+}
+
+// historyDelta returns the requests and queries served since it was
+// last called.
+func (m *serverMetrics) historyDelta() historyTotals {
+	now := historyTotals{requests: m.requests.Total()}
+	now.queries, now.querySeconds = m.queryDuration.Total()
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	delta := historyTotals{
+		requests:     now.requests - m.historySent.requests,
+		queries:      now.queries - m.historySent.queries,
+		querySeconds: now.querySeconds - m.historySent.querySeconds,
+	}
+	m.historySent = now
+	return delta
+}
+
+// logInFlight logs what the server is busy with: requests in flight by
+// route, open streams and database connections in use. Shutdown logs it
+// while draining, so a stuck deploy shows what it is waiting on.
+func (cfg *apiConfig) logInFlight(ctx context.Context, msg string) {
+	slog.InfoContext(ctx, msg,
+		"requests", cfg.prom.inFlight.NonZero(),
+		"streams", cfg.prom.streams.NonZero(),
+		"db_in_use", cfg.sqlDB.Stats().InUse,
+	)
+}
+
+// logDraining calls logInFlight every interval until ctx is done.
+func (cfg *apiConfig) logDraining(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			cfg.logInFlight(ctx, "Waiting for in-flight requests")
+		}
+	}
+}
+
+// timedDB times every query run through it. The query label is the name
+// sqlc gives the query, taken from its "-- name:" comment.
+type timedDB struct {
+	db        database.DBTX
+	durations *prometheus.HistogramVec
+}
+
+func (t timedDB) observe(query string, start time.Time) {
+	t.durations.Observe(time.Since(start).Seconds(), queryName(query))
+}
+
+func (t timedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer t.observe(query, time.Now())
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t timedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t timedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer t.observe(query, time.Now())
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t timedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer t.observe(query, time.Now())
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+// GET /metrics
+// Serves request, query and auth failure metrics in the Prometheus text
+// format. It is served on the METRICS_ADDR listener only, as scrapes
+// carry no token.
+func (cfg *apiConfig) prometheusMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	cfg.prom.registry.Handler().ServeHTTP(w, r)
+}
+
+const (
+	// Rows in metrics_history are deleted after this long.
+	metricsHistoryRetention = 90 * 24 * time.Hour
+	// metricsHistoryMaxPoints bounds the points GET /admin/metrics/history
+	// returns.
+	metricsHistoryMaxPoints = 10_000
+)
+
+// recordMetricsHistoryTask adds a row to metrics_history with the
+// requests and queries this instance served since the last run and the
+// current user and chirp counts. Every instance adds its own rows, so
+// they are summed when read. Whatever was served since the last run is
+// lost if saving fails.
+func (cfg *apiConfig) recordMetricsHistoryTask(ctx context.Context) error {
+	users, err := cfg.DB.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
+	chirps, err := cfg.DB.CountChirps(ctx)
+	if err != nil {
+		return err
+	}
+
+	delta := cfg.prom.historyDelta()
+	err = cfg.DB.RecordMetricsSnapshot(ctx, database.RecordMetricsSnapshotParams{
+		Requests:     int64(delta.requests),
+		QueryCount:   int64(delta.queries),
+		QuerySeconds: delta.querySeconds,
+		Users:        users,
+		Chirps:       chirps,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = cfg.DB.DeleteMetricsHistoryBefore(ctx, time.Now().UTC().Add(-metricsHistoryRetention))
+	return err
+}
+
+type metricsHistoryPoint struct {
+	Time             time.Time `json:"time"`
+	RequestRate      float64   `json:"request_rate"`       // per second
+	DBLatencySeconds float64   `json:"db_latency_seconds"` // mean per query
+	Users            int64     `json:"users"`
+	Chirps           int64     `json:"chirps"`
+}
+
+type metricsHistoryResponse struct {
+	From   time.Time             `json:"from"`
+	To     time.Time             `json:"to"`
+	Step   string                `json:"step"`
+	Points []metricsHistoryPoint `json:"points"`
+}
+
+// GET /admin/metrics/history
+// Time series of request rate, mean query latency and user and chirp
+// counts between from and to (RFC 3339, defaulting to the last day), one
+// point per step (a duration such as 5m or 1h, at least 1m). Steps
+// without any recorded rows are left out.
+func (cfg *apiConfig) adminMetricsHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "to must be an RFC 3339 time", err)
+			return
+		}
+		to = t.UTC()
+	}
+	from := to.Add(-24 * time.Hour)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "from must be an RFC 3339 time", err)
+			return
+		}
+		from = t.UTC()
+	}
+	step := 5 * time.Minute
+	if v := q.Get("step"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Minute {
+			response.Error(w, http.StatusBadRequest, "step must be a duration of at least 1m", err)
+			return
+		}
+		step = d
+	}
+	if !from.Before(to) {
+		response.Error(w, http.StatusBadRequest, "from must be before to", nil)
+		return
+	}
+	if to.Sub(from)/step > metricsHistoryMaxPoints {
+		response.Error(w, http.StatusBadRequest, "Too many points, use a larger step", nil)
+		return
+	}
+
+	rows, err := cfg.DB.GetMetricsHistory(r.Context(), database.GetMetricsHistoryParams{
+		StepSeconds: step.Seconds(),
+		FromTime:    from,
+		ToTime:      to,
+	})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to fetch metrics history", err)
+		return
+	}
+
+	points := make([]metricsHistoryPoint, 0, len(rows))
+	for _, row := range rows {
+		p := metricsHistoryPoint{
+			Time:        row.Bucket,
+			RequestRate: float64(row.Requests) / step.Seconds(),
+			Users:       row.Users,
+			Chirps:      row.Chirps,
+		}
+		if row.QueryCount > 0 {
+			p.DBLatencySeconds = row.QuerySeconds / float64(row.QueryCount)
+		}
+		points = append(points, p)
+	}
+	response.JSON(w, http.StatusOK, metricsHistoryResponse{
+		From:   from,
+		To:     to,
+		Step:   step.String(),
+		Points: points,
+	})
+}
@@ -1,13 +1,359 @@
 package main
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"main.go/api"
+	"main.go/internal/auth"
+	"main.go/internal/ratelimit"
+	"main.go/internal/requestid"
+	"main.go/internal/response"
+	"main.go/internal/trust"
 )
 
-// Middleware to increment fileserverHits counter on each request
+// Middleware to increment fileserverHits counter on each request. Health
+// checks, monitors and bots are counted in excludedHits instead, so the
+// hit count reflects real visitors.
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cfg.fileserverHits.Add(1)
+		if cfg.hitExclusions.Excluded(r) {
+			cfg.excludedHits.Inc()
+		} else {
+			cfg.fileserverHits.Inc()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loggingResponseWriter notes what a handler responded, for the request
+// log.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	errMsg string
+	err    error
+}
+
+func (w *loggingResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// RecordError implements response.ErrorRecorder.
+func (w *loggingResponseWriter) RecordError(msg string, err error) {
+	w.errMsg, w.err = msg, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// middlewareLogging logs every request once it has been handled: method,
+// path, status, latency, the user if the request has a valid access
+// token, and why it failed if it did. Failed requests are logged as
+// warnings (4xx) or errors (5xx).
+func (cfg *apiConfig) middlewareLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lw := &loggingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+
+		status := lw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if tokenStr, err := auth.GetBearerToken(r.Header); err == nil {
+			if userID, err := auth.ValidateJWT(tokenStr, cfg.jwtKeys); err == nil {
+				attrs = append(attrs, slog.String("user_id", userID.String()))
+			}
+		}
+		if lw.errMsg != "" {
+			attrs = append(attrs, slog.String("error_message", lw.errMsg))
+		}
+		if lw.err != nil {
+			attrs = append(attrs, slog.String("error", lw.err.Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// middlewarePrometheus records each request in cfg.prom under the routes
+// pattern it matches, counting it in flight while it is served. The pattern is looked up before next runs so that
+// requests turned away by the rate limiter or CORS still count against
+// their route.
+func (cfg *apiConfig) middlewarePrometheus(routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		cfg.prom.inFlight.Inc(route)
+		defer cfg.prom.inFlight.Dec(route)
+		start := time.Now()
+		sw := &loggingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		cfg.prom.observeRequest(route, r.Method, status, time.Since(start))
+	})
+}
+
+// requireWebhookKey guards an incoming webhook: the request must carry
+// "Authorization: ApiKey <POLKA_KEY>", or it gets a 401. Without a
+// configured key every request is rejected.
+func (cfg *apiConfig) requireWebhookKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := auth.GetAPIKey(r.Header)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, "Missing or invalid API key", err)
+			return
+		}
+		if cfg.polkaKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.polkaKey)) != 1 {
+			response.Error(w, http.StatusUnauthorized, "Missing or invalid API key", nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// middlewareRateLimit applies a quota to /api/ requests. Requests with
+// a valid access token count against their user's quota; everything else
+// shares the stricter anonymous quota of its IP. See checkRateLimit for
+// the headers and the over-quota response.
+func (cfg *apiConfig) middlewareRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limiter, key := cfg.rateLimitTier(r)
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !checkRateLimit(w, limiter, key) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readOnlyExempt lists the writes that work in read-only mode: managing
+// sessions, so users can keep reading and admins can log in to turn it
+// off, and the switch itself. Paths are unversioned.
+var readOnlyExempt = map[string]bool{
+	"/api/login":       true,
+	"/api/refresh":     true,
+	"/api/revoke":      true,
+	"/api/logout":      true,
+	"/admin/read_only": true,
+}
+
+// middlewareReadOnly refuses writes to /api/ and /admin/ with a 503 while
+// the server is in read-only mode, apart from readOnlyExempt. The body's
+// code is api.CodeReadOnly.
+func (cfg *apiConfig) middlewareReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		write := r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+		guarded := strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/admin/")
+		if !cfg.readOnly.Load() || !write || !guarded || readOnlyExempt[routeKey(r.URL.Path)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		response.JSON(w, http.StatusServiceUnavailable, api.ErrorResponse{
+			Error:     "Chirpy is read-only for maintenance, try again later",
+			Code:      api.CodeReadOnly,
+			RequestID: w.Header().Get(requestid.Header),
+		}, response.CacheControl("no-store"))
+	})
+}
+
+// middlewareChaos injects the faults set with PUT /admin/chaos into
+// requests, by the pattern of routes they match. It does nothing unless
+// cfg.chaos is set, which only happens in dev and staging.
+func (cfg *apiConfig) middlewareChaos(routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.chaos == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		_, pattern := routes.Handler(r)
+		latency, status := cfg.chaos.Inject(routeKey(pattern))
+		if latency > 0 {
+			t := time.NewTimer(latency)
+			select {
+			case <-t.C:
+			case <-r.Context().Done():
+				t.Stop()
+				return
+			}
+		}
+		if status != 0 {
+			response.Error(w, status, "Injected fault", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bodyLimitExempt lists the routes that take larger bodies and limit
+// them themselves. Paths are unversioned.
+var bodyLimitExempt = map[string]bool{
+	"/api/users/me/avatar": true,
+}
+
+// middlewareMaxBody refuses request bodies to /api/ and /admin/ over
+// cfg.maxBodySize with a 413, apart from bodyLimitExempt. The body is
+// read up front, so handlers decoding it never see it cut short and
+// answer 400 instead. The body's code is api.CodeBodyTooLarge.
+func (cfg *apiConfig) middlewareMaxBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		guarded := strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/admin/")
+		if !guarded || r.Body == nil || r.Body == http.NoBody || bodyLimitExempt[routeKey(r.URL.Path)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.maxBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.JSON(w, http.StatusRequestEntityTooLarge, api.ErrorResponse{
+				Error:     fmt.Sprintf("Request body must be at most %d bytes", cfg.maxBodySize),
+				Code:      api.CodeBodyTooLarge,
+				RequestID: w.Header().Get(requestid.Header),
+			}, response.CacheControl("no-store"))
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Couldn't read request body", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// suspensionExempt lists the writes a suspended account can still make:
+// appealing, and managing its session. Paths are unversioned.
+var suspensionExempt = map[string]bool{
+	"/api/appeals": true,
+	"/api/refresh": true,
+	"/api/revoke":  true,
+	"/api/logout":  true,
+}
+
+// middlewareSuspended makes suspended accounts read-only: writes to /api/
+// with their access token get a 403, apart from suspensionExempt.
+func (cfg *apiConfig) middlewareSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.Method == http.MethodGet || r.Method == http.MethodHead || suspensionExempt[routeKey(r.URL.Path)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tokenStr, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, err := auth.ValidateJWT(tokenStr, cfg.jwtKeys)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err == nil && user.SuspendedAt.Valid {
+			response.Error(w, http.StatusForbidden, "Account is suspended", nil)
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// checkRateLimit counts a request against key's quota and reports
+// whether it may proceed. It sets RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset (seconds until the quota refills). Requests over
+// quota get a 429 with a Retry-After header and a body of the form:
+//
+//	{"error": "Rate limit exceeded", "retry_after": 30}
+func checkRateLimit(w http.ResponseWriter, limiter *ratelimit.Limiter, key string) bool {
+	res := limiter.Allow(key)
+	resetSeconds := int(math.Ceil(res.Reset.Seconds()))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+	if !res.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+		response.JSON(w, http.StatusTooManyRequests, api.ErrorResponse{
+			Error:      "Rate limit exceeded",
+			RetryAfter: resetSeconds,
+			RequestID:  w.Header().Get(requestid.Header),
+		})
+		return false
+	}
+	return true
+}
+
+// rateLimitTier picks the limiter and key for a request from its auth
+// context. Handlers still do their own authentication; an invalid token
+// just falls back to the anonymous tier here.
+func (cfg *apiConfig) rateLimitTier(r *http.Request) (*ratelimit.Limiter, string) {
+	if tokenStr, err := auth.GetBearerToken(r.Header); err == nil {
+		if token, err := auth.ParseAccessToken(tokenStr, cfg.jwtKeys); err == nil {
+			if trust.Level(token.TrustLevel).Can(trust.HigherRateLimit) {
+				return cfg.trustedRateLimiter, "user:" + token.UserID.String()
+			}
+			return cfg.rateLimiter, "user:" + token.UserID.String()
+		}
+	}
+	return cfg.anonRateLimiter, "ip:" + clientIP(r)
+}
+
+// clientIP returns the remote address of the request without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
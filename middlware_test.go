@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"main.go/api"
+)
+
+func TestMiddlewareMaxBody(t *testing.T) {
+	cfg := &apiConfig{maxBodySize: 16}
+	var gotBody string
+	h := cfg.middlewareMaxBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dat, _ := io.ReadAll(r.Body)
+		gotBody = string(dat)
+	}))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "Small body", path: "/api/chirps", body: `{"body":"hi"}`, wantStatus: http.StatusOK},
+		{name: "Large body", path: "/api/v1/chirps", body: `{"body":"hello, world"}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "Exempt route", path: "/api/v1/users/me/avatar", body: strings.Repeat("x", 64), wantStatus: http.StatusOK},
+		{name: "Outside the API", path: "/app/upload", body: strings.Repeat("x", 64), wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = ""
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && gotBody != tt.body {
+				t.Errorf("handler read %q, want %q", gotBody, tt.body)
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge {
+				var resp api.ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Code != api.CodeBodyTooLarge {
+					t.Errorf("body = %+v, %v, want code %q", resp, err, api.CodeBodyTooLarge)
+				}
+			}
+		})
+	}
+}
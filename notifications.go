@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/database"
+	"main.go/internal/mail"
+	"main.go/internal/notify"
+)
+
+// notificationPreferences loads the channels a user has chosen per
+// event, and their quiet hours. It is the dispatcher's
+// notify.PreferenceStore.
+func (cfg *apiConfig) notificationPreferences(ctx context.Context, userID uuid.UUID) (notify.Preferences, error) {
+	rows, err := cfg.DB.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return notify.Preferences{}, err
+	}
+	prefs := notify.Preferences{}
+	for _, row := range rows {
+		prefs.Set(notify.Event(row.Event), notify.Channel(row.Channel), row.Enabled)
+	}
+
+	quiet, err := cfg.DB.GetQuietHours(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return prefs, nil
+	}
+	if err != nil {
+		return notify.Preferences{}, err
+	}
+	loc, err := time.LoadLocation(quiet.Timezone)
+	if err != nil {
+		return notify.Preferences{}, err
+	}
+	prefs.QuietHours = notify.QuietHours{
+		Start:    time.Duration(quiet.StartMinute) * time.Minute,
+		End:      time.Duration(quiet.EndMinute) * time.Minute,
+		Location: loc,
+	}
+	return prefs, nil
+}
+
+// notificationQueue keeps notifications held back by quiet hours in
+// held_notifications.
+type notificationQueue struct {
+	db *database.Queries
+}
+
+// Hold -
+func (q notificationQueue) Hold(ctx context.Context, c notify.Channel, n notify.Notification) error {
+	return q.db.HoldNotification(ctx, database.HoldNotificationParams{
+		UserID:  n.UserID,
+		Channel: string(c),
+		Event:   string(n.Event),
+		ActorID: n.ActorID,
+		ChirpID: n.ChirpID,
+		Text:    n.Text,
+	})
+}
+
+// Users -
+func (q notificationQueue) Users(ctx context.Context) ([]uuid.UUID, error) {
+	return q.db.ListUsersWithHeldNotifications(ctx)
+}
+
+// Release -
+func (q notificationQueue) Release(ctx context.Context, userID uuid.UUID) ([]notify.HeldNotification, error) {
+	rows, err := q.db.ReleaseHeldNotifications(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	held := make([]notify.HeldNotification, len(rows))
+	for i, row := range rows {
+		held[i] = notify.HeldNotification{
+			Channel: notify.Channel(row.Channel),
+			Notification: notify.Notification{
+				UserID:  row.UserID,
+				Event:   notify.Event(row.Event),
+				ActorID: row.ActorID,
+				ChirpID: row.ChirpID,
+				Text:    row.Text,
+			},
+		}
+	}
+	return held, nil
+}
+
+// deliverHeldNotificationsTask sends the summaries of notifications held
+// for users whose quiet hours have ended.
+func (cfg *apiConfig) deliverHeldNotificationsTask(ctx context.Context) error {
+	return cfg.notifier.DeliverHeld(ctx, time.Now())
+}
+
+// emailSender delivers notifications to the recipient's email address.
+type emailSender struct {
+	cfg *apiConfig
+}
+
+// Send -
+func (s emailSender) Send(ctx context.Context, n notify.Notification) error {
+	user, err := s.cfg.DB.GetUserByID(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+	return s.cfg.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: n.Text,
+		Body:    n.Text + "\n\nChange which notifications you get in your Chirpy settings.\n",
+	})
+}
+
+// deviceStore is the push sender's notify.DeviceStore, backed by
+// device_tokens.
+type deviceStore struct {
+	db *database.Queries
+}
+
+// Devices -
+func (s deviceStore) Devices(ctx context.Context, userID uuid.UUID) ([]notify.Device, error) {
+	rows, err := s.db.GetActiveDeviceTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]notify.Device, len(rows))
+	for i, row := range rows {
+		devices[i] = notify.Device{Token: row.Token, Platform: notify.Platform(row.Platform)}
+	}
+	return devices, nil
+}
+
+// RemoveDevice -
+func (s deviceStore) RemoveDevice(ctx context.Context, token string) error {
+	return s.db.DeleteDeviceToken(ctx, token)
+}
+
+// notify hands n to the dispatcher. Failures are logged rather than
+// failing the request that caused the notification.
+func (cfg *apiConfig) notify(ctx context.Context, n notify.Notification) {
+	if err := cfg.notifier.Dispatch(ctx, n); err != nil {
+		slog.ErrorContext(ctx, "Failed to deliver notification", "user_id", n.UserID, "event", n.Event, "error", err)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"main.go/api"
+	"main.go/internal/deadman"
+	"main.go/internal/events"
+	"main.go/internal/openapi"
+	"main.go/internal/response"
+	"main.go/internal/scheduler"
+)
+
+// routeDoc describes a route for the OpenAPI specification. Bodies are
+// given as zero values of the structs the handler decodes and encodes,
+// so the specification can't drift from them.
+type routeDoc struct {
+	summary string
+	// methods documents patterns registered without a method
+	methods []string
+	query   []string
+	// request is the JSON body, nil for none
+	request any
+	// response is the JSON body of a success, nil for none
+	response any
+	// contentType overrides JSON for responses that aren't
+	contentType string
+	// status defaults to 200, or 204 without a response
+	status int
+}
+
+// queryDocs describes the query parameters used in routeDocs.
+var queryDocs = map[string]string{
+	"limit":             "Page size",
+	"offset":            "Number of items to skip",
+	"cursor":            "next_cursor of the previous page",
+	"sort":              "asc or desc by creation time",
+	"author_id":         "Only chirps by this user",
+	"user_id":           "The user whose items to list",
+	"q":                 "Search terms",
+	"ranking":           "latest or top; defaults to the user's setting",
+	"status":            "Only entries with this status",
+	"format":            "json for a JSON response",
+	"email":             "Email to look up",
+	"username":          "Username to look up",
+	"code":              "Authorization code from the provider",
+	"state":             "State from the sign-in redirect",
+	"totp_code":         "Two-factor code, when the callback asked for one",
+	"from":              "Start time, RFC 3339",
+	"to":                "End time, RFC 3339",
+	"step":              "Duration per point, such as 5m or 1h",
+	"recovery_code":     "Two-factor recovery code, in place of totp_code",
+	"verification_code": "Code emailed for a sign-in from an unusual location",
+}
+
+// routeDocs documents every route, by routeKey. TestEveryRouteIsDocumented
+// checks it against the routes.
+var routeDocs = map[string]routeDoc{
+	"GET /api/healthz":      {summary: "Liveness check", contentType: "text/plain"},
+	"GET /api/stats":        {summary: "Public usage statistics", response: api.Stats{}},
+	"GET /status":           {summary: "Component health page", query: []string{"format"}, response: statusResponse{}, contentType: "text/html"},
+	"GET /media/{path...}":  {summary: "Uploaded media, with a signed URL", contentType: "application/octet-stream"},
+	"/app/":                 {summary: "Static web app files", methods: []string{"GET"}, contentType: "text/html"},
+	"GET /api/openapi.json": {summary: "This specification", response: map[string]any{}},
+	"GET /admin/docs":       {summary: "Interactive API documentation", contentType: "text/html"},
+
+	"POST /admin/reset": {summary: "Delete all users and reset the hit counter", response: map[string]string{}},
+
+	"GET /admin/metrics":                                 {summary: "Hit counters page", contentType: "text/html"},
+	"GET /admin/metrics/history":                         {summary: "Metrics time series for capacity planning", query: []string{"from", "to", "step"}, response: metricsHistoryResponse{}},
+	"GET /admin/jobs":                                    {summary: "List background jobs", query: []string{"status", "limit"}, response: []jobResponse{}},
+	"GET /admin/jobs/stats":                              {summary: "Job queue statistics", response: []jobTypeStats{}},
+	"GET /admin/jobs/{jobID}":                            {summary: "Get a job", response: jobResponse{}},
+	"POST /admin/jobs/{jobID}/retry":                     {summary: "Retry a failed job", response: jobResponse{}},
+	"POST /admin/jobs/{jobID}/cancel":                    {summary: "Cancel a pending job", response: jobResponse{}},
+	"GET /admin/tasks":                                   {summary: "List scheduled tasks", response: []scheduler.TaskStatus{}},
+	"POST /admin/tasks/{taskName}/run":                   {summary: "Run a scheduled task now", response: map[string]string{}, status: http.StatusAccepted},
+	"GET /admin/heartbeats":                              {summary: "Dead man's switch status", response: []deadman.HeartbeatStatus{}},
+	"GET /admin/read_only":                               {summary: "Whether writes are refused", response: readOnlyResponse{}},
+	"PUT /admin/read_only":                               {summary: "Refuse or allow writes", request: readOnlyRequest{}, response: readOnlyResponse{}},
+	"GET /admin/chaos":                                   {summary: "List injected faults (dev and staging only)", response: chaosFaults{}},
+	"PUT /admin/chaos":                                   {summary: "Replace the injected faults (dev and staging only)", request: chaosFaults{}, response: chaosFaults{}},
+	"DELETE /admin/chaos":                                {summary: "Stop injecting faults (dev and staging only)"},
+	"GET /admin/moderation":                              {summary: "List the moderation queue", query: []string{"status", "limit"}, response: []moderationResponse{}},
+	"GET /admin/moderation/stats":                        {summary: "Reports and automated actions per reason", response: []reportStatsResponse{}},
+	"GET /admin/export/chirps":                           {summary: "Export every chirp as NDJSON", contentType: "application/x-ndjson"},
+	"GET /admin/export/users":                            {summary: "Export every account as NDJSON", contentType: "application/x-ndjson"},
+	"GET /admin/webhook_deliveries/failed":               {summary: "List events whose webhook delivery failed", query: []string{"limit", "offset"}, response: []webhookDeliveryResponse{}},
+	"POST /admin/webhook_deliveries/{eventID}/redeliver": {summary: "Deliver an event to the webhook again", response: webhookDeliveryResponse{}},
+	"GET /admin/banned_words":                            {summary: "List banned words", response: []string{}},
+	"POST /admin/banned_words":                           {summary: "Ban a word", request: bannedWordRequest{}, status: http.StatusCreated},
+	"DELETE /admin/banned_words/{word}":                  {summary: "Unban a word"},
+	"GET /admin/reports":                                 {summary: "List chirp reports", query: []string{"status", "limit", "offset"}, response: []reportResponse{}},
+	"POST /admin/reports/{reportID}/resolve":             {summary: "Resolve a chirp report", response: reportResponse{}},
+	"POST /admin/moderation/{entryID}/resolve":           {summary: "Resolve a moderation queue entry", response: moderationResponse{}},
+	"GET /admin/chirps":                                  {summary: "List a user's chirps", query: []string{"user_id", "limit", "offset"}, response: []api.Chirp{}},
+	"DELETE /admin/chirps/{chirpID}":                     {summary: "Delete any chirp"},
+	"GET /admin/audit_log":                               {summary: "What admins did, newest first", query: []string{"limit", "offset"}, response: []auditLogResponse{}},
+	"POST /admin/chirps/{chirpID}/remove":                {summary: "Remove a chirp", request: moderationActionRequest{}, response: api.ModerationAction{}, status: http.StatusCreated},
+	"POST /admin/users/{userID}/suspend":                 {summary: "Suspend a user", request: moderationActionRequest{}, response: api.ModerationAction{}, status: http.StatusCreated},
+	"PUT /admin/users/{userID}/role":                     {summary: "Set a user's role", request: roleRequest{}, response: roleResponse{}},
+	"PUT /admin/users/{userID}/legal_hold":               {summary: "Place a user under legal hold"},
+	"DELETE /admin/users/{userID}/legal_hold":            {summary: "Release a user's legal hold"},
+	"PUT /admin/chirps/{chirpID}/legal_hold":             {summary: "Place a chirp under legal hold"},
+	"DELETE /admin/chirps/{chirpID}/legal_hold":          {summary: "Release a chirp's legal hold"},
+	"POST /admin/users/{userID}/erase":                   {summary: "Erase a user's personal data", request: erasureRequest{}, response: erasureResponse{}},
+	"POST /admin/users/{userID}/merge":                   {summary: "Merge a duplicate account into another", request: mergeRequest{}, response: mergeReport{}},
+	"GET /admin/erasures":                                {summary: "List erasures", query: []string{"limit"}, response: []erasureResponse{}},
+	"GET /admin/appeals":                                 {summary: "List appeals", query: []string{"status", "limit"}, response: []api.Appeal{}},
+	"POST /admin/appeals/{appealID}/approve":             {summary: "Approve an appeal, reverting its action", response: api.Appeal{}},
+	"POST /admin/appeals/{appealID}/deny":                {summary: "Deny an appeal", response: api.Appeal{}},
+
+	"/api/users":                        {summary: "Sign up", methods: []string{"POST"}, request: api.Credentials{}, response: api.User{}, status: http.StatusCreated},
+	"/api/login":                        {summary: "Log in", methods: []string{"POST"}, request: api.Credentials{}, response: api.LoginResponse{}},
+	"POST /api/users/restore":           {summary: "Restore an account pending deletion", request: api.Credentials{}, response: api.User{}},
+	"GET /api/users/exists":             {summary: "Check whether an email or username is taken", query: []string{"email", "username"}, response: api.UserExists{}},
+	"POST /api/password_reset/request":  {summary: "Email a password reset link", request: api.PasswordResetRequest{}, status: http.StatusAccepted},
+	"POST /api/password_reset/confirm":  {summary: "Set a new password with a reset token", request: api.PasswordResetConfirmRequest{}},
+	"POST /api/refresh":                 {summary: "Get a new access token", response: api.RefreshResponse{}},
+	"POST /api/revoke":                  {summary: "Revoke a refresh token"},
+	"POST /api/logout":                  {summary: "End the current session"},
+	"POST /api/logout_all":              {summary: "End every session of the user"},
+	"POST /api/devices":                 {summary: "Register a device for push notifications in the session of the refresh token", request: api.DeviceRegistration{}},
+	"DELETE /api/devices/{token}":       {summary: "Stop push notifications to a device"},
+	"GET /api/auth/{provider}/login":    {summary: "Sign in with Google or GitHub", query: []string{"totp_code", "recovery_code", "verification_code"}, status: http.StatusFound},
+	"GET /api/auth/{provider}/callback": {summary: "Complete a sign-in with Google or GitHub", query: []string{"code", "state"}, response: api.LoginResponse{}},
+	"PUT /api/users":                    {summary: "Update the account", request: api.Credentials{}, response: api.User{}},
+	"DELETE /api/users":                 {summary: "Delete the account after a grace period"},
+	"GET /api/users/me/settings":        {summary: "Get settings", response: api.Settings{}},
+	"PUT /api/users/me/settings":        {summary: "Update settings", request: api.SettingsRequest{}, response: api.Settings{}},
+	"GET /api/users/me/mentions":        {summary: "Chirps mentioning the user", query: []string{"limit", "offset"}, response: []api.Chirp{}},
+	"POST /api/users/me/avatar":         {summary: "Upload an avatar as the multipart field avatar", response: api.User{}},
+	"POST /api/users/me/2fa/enable":     {summary: "Start enrolling a TOTP secret for two-factor authentication", response: api.TwoFactorSetup{}},
+	"POST /api/users/me/2fa/confirm":    {summary: "Enable two-factor authentication with a first code, returning recovery codes", request: api.TwoFactorConfirmRequest{}, response: api.RecoveryCodes{}},
+	"GET /api/users/me/moderation":      {summary: "Moderation actions taken against the user", response: []api.ModerationAction{}},
+	"GET /api/users/me/trust":           {summary: "The user's trust level and what it allows", response: api.TrustLevel{}},
+	"POST /api/appeals":                 {summary: "Appeal a moderation action", request: api.AppealRequest{}, response: api.Appeal{}, status: http.StatusCreated},
+
+	"GET /api/users/{userID}":           {summary: "Get a profile", response: api.Profile{}},
+	"GET /api/usernames/{username}":     {summary: "Get a profile by username, following old usernames", response: api.Profile{}},
+	"POST /api/users/{userID}/follow":   {summary: "Follow a user", status: http.StatusCreated},
+	"DELETE /api/users/{userID}/follow": {summary: "Unfollow a user"},
+
+	"POST /api/validate_chirp":             {summary: "Check and clean a chirp body", request: api.ValidateChirpRequest{}, response: api.ValidateChirpResponse{}},
+	"GET /api/chirps":                      {summary: "List chirps", query: []string{"author_id", "sort", "limit", "offset"}, response: []api.Chirp{}},
+	"GET /api/chirps/search":               {summary: "Search chirps", query: []string{"q", "limit", "offset"}, response: []api.Chirp{}},
+	"GET /api/chirps/stream":               {summary: "Stream new chirps as server-sent events", response: events.Event{}, contentType: "text/event-stream"},
+	"GET /api/hashtags/{tag}/chirps":       {summary: "Chirps with a hashtag", query: []string{"limit", "offset"}, response: []api.Chirp{}},
+	"GET /api/chirps/{chirpID}":            {summary: "Get a chirp", response: api.Chirp{}},
+	"GET /api/report_reasons":              {summary: "Reasons chirps can be reported for", response: []api.ReportReason{}},
+	"GET /api/chirps/{chirpID}/replies":    {summary: "Replies to a chirp", query: []string{"limit", "offset"}, response: []api.Chirp{}},
+	"POST /api/chirps":                     {summary: "Post a chirp", request: api.ChirpRequest{}, response: api.Chirp{}, status: http.StatusCreated},
+	"PUT /api/chirps/{chirpID}":            {summary: "Edit a chirp", request: api.ChirpRequest{}, response: api.Chirp{}},
+	"/api/chirps/{chirpID}":                {summary: "Delete a chirp", methods: []string{"DELETE"}},
+	"POST /api/chirps/{chirpID}/like":      {summary: "Like a chirp", status: http.StatusCreated},
+	"DELETE /api/chirps/{chirpID}/like":    {summary: "Unlike a chirp"},
+	"POST /api/chirps/{chirpID}/rechirp":   {summary: "Rechirp a chirp", response: api.Rechirp{}, status: http.StatusCreated},
+	"DELETE /api/chirps/{chirpID}/rechirp": {summary: "Undo a rechirp"},
+	"POST /api/chirps/{chirpID}/report":    {summary: "Report a chirp to moderators", request: api.ReportRequest{}, status: http.StatusCreated},
+	"POST /api/chirps/{chirpID}/reports":   {summary: "Report a chirp to moderators, same as POST /api/chirps/{chirpID}/report", request: api.ReportRequest{}, status: http.StatusCreated},
+	"POST /api/chirps/{chirpID}/share":     {summary: "Record a share outside Chirpy", request: api.ShareRequest{}, status: http.StatusCreated},
+	"PUT /api/chirps/{chirpID}/image":      {summary: "Upload an image of a chirp as the multipart field image, with followers_only=true to keep it to followers", response: api.Chirp{}},
+
+	"GET /api/collections/{collectionID}":                     {summary: "Get a collection with its chirps", response: api.Collection{}},
+	"GET /api/users/{userID}/collections":                     {summary: "A user's collections", query: []string{"limit", "offset"}, response: []api.Collection{}},
+	"POST /api/collections":                                   {summary: "Create a collection", request: api.CollectionRequest{}, response: api.Collection{}, status: http.StatusCreated},
+	"PUT /api/collections/{collectionID}":                     {summary: "Rename a collection", request: api.CollectionRequest{}, response: api.Collection{}},
+	"DELETE /api/collections/{collectionID}":                  {summary: "Delete a collection"},
+	"POST /api/collections/{collectionID}/chirps":             {summary: "Add a chirp to a collection", request: api.CollectionChirpRequest{}},
+	"PUT /api/collections/{collectionID}/chirps":              {summary: "Replace or reorder the chirps of a collection", request: api.CollectionChirpsRequest{}},
+	"DELETE /api/collections/{collectionID}/chirps/{chirpID}": {summary: "Remove a chirp from a collection"},
+
+	"GET /api/feed":                    {summary: "Chirps from followed users", query: []string{"cursor", "limit"}, response: api.FeedPage{}},
+	"GET /api/feed/for_you":            {summary: "Ranked feed", query: []string{"ranking", "cursor", "limit"}, response: api.FeedPage{}},
+	"GET /api/feed/summary":            {summary: "Unread counts per timeline", response: api.FeedSummary{}},
+	"GET /api/feed/markers":            {summary: "Read positions", response: []api.FeedMarker{}},
+	"GET /api/digest":                  {summary: "Daily digest of popular chirps", response: api.Digest{}},
+	"PUT /api/feed/markers/{timeline}": {summary: "Save a read position", request: api.FeedMarkerRequest{}, response: api.FeedMarker{}},
+}
+
+// Security schemes of the specification, by access policy.
+var accessSecurity = map[access][]map[string][]string{
+	accessUser:         {{"accessToken": {}}},
+	accessRefreshToken: {{"refreshToken": {}}},
+	accessDev:          {{"accessToken": {}}},
+	accessAdmin:        {{"accessToken": {}}},
+	accessWebhook:      {{"webhookKey": {}}},
+}
+
+// buildOpenAPI generates the specification of the given patterns from
+// routeDocs and routePolicies. Unversioned API paths are marked
+// deprecated in favour of /api/v1.
+func buildOpenAPI(patterns []string) *openapi.Document {
+	b := openapi.NewBuilder("Chirpy", "1.0")
+	b.AddSecurityScheme("accessToken", openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Access token from POST /api/login or POST /api/refresh",
+	})
+	b.AddSecurityScheme("refreshToken", openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "Refresh token from POST /api/login",
+	})
+	b.AddSecurityScheme("webhookKey", openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          "header",
+		Name:        "Authorization",
+		Description: `"ApiKey <key>", with the POLKA_KEY of the server`,
+	})
+	errorBody := b.JSONBody(api.ErrorResponse{})
+
+	for _, pattern := range patterns {
+		key := routeKey(pattern)
+		doc := routeDocs[key]
+		method, path, ok := strings.Cut(pattern, " ")
+		methods := []string{method}
+		if !ok {
+			path, methods = pattern, doc.methods
+		}
+		path = strings.ReplaceAll(path, "...}", "}")
+
+		op := &openapi.Operation{
+			Summary:   doc.summary,
+			Tags:      []string{routeTag(path)},
+			Responses: map[string]openapi.Response{"default": {Description: "Error", Content: errorBody}},
+			Security:  accessSecurity[routePolicies[key]],
+			// Unversioned paths are aliases kept for older clients
+			Deprecated: key == pattern && strings.HasPrefix(path, "/api/"),
+		}
+		if op.Security == nil {
+			op.Security = []map[string][]string{}
+		}
+		for _, name := range pathParams(path) {
+			param := openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+			if strings.HasSuffix(name, "ID") {
+				param.Schema.Format = "uuid"
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		for _, name := range doc.query {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:        name,
+				In:          "query",
+				Description: queryDocs[name],
+				Schema:      &openapi.Schema{Type: "string"},
+			})
+		}
+		if doc.request != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: b.JSONBody(doc.request)}
+		}
+
+		status, success := doc.status, openapi.Response{Description: "Success"}
+		switch {
+		case doc.response != nil && doc.contentType != "":
+			// JSON on request, e.g. /status?format=json
+			success.Content = b.JSONBody(doc.response)
+			success.Content[doc.contentType] = openapi.MediaType{}
+		case doc.response != nil:
+			success.Content = b.JSONBody(doc.response)
+		case doc.contentType != "":
+			success.Content = map[string]openapi.MediaType{doc.contentType: {Schema: &openapi.Schema{Type: "string"}}}
+		case status == 0:
+			status = http.StatusNoContent
+		}
+		if status == 0 {
+			status = http.StatusOK
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		for _, m := range methods {
+			b.AddOperation(m, path, op)
+		}
+	}
+	return b.Document()
+}
+
+// routeTag groups operations by their first path segment under /api/ or
+// /api/vN/, with everything outside /api/ under its own first segment.
+func routeTag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(routeKey(path), "/api"), "/")
+	if len(segments) < 2 || segments[1] == "" {
+		return "site"
+	}
+	return segments[1]
+}
+
+// pathParams lists the {names} in a path template.
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
+
+// openAPIHandler serves the specification of the routes of mux, built on
+// the first request, once every route is registered.
+//
+// GET /api/openapi.json
+func openAPIHandler(mux *router) http.HandlerFunc {
+	var once sync.Once
+	var spec *openapi.Document
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { spec = buildOpenAPI(mux.patterns) })
+		response.JSON(w, http.StatusOK, spec)
+	}
+}
+
+var apiDocsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <title>Chirpy API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script>SwaggerUIBundle({url: {{.}}, dom_id: "#swagger-ui"});</script>
+  </body>
+</html>
+`))
+
+// GET /admin/docs
+// Swagger UI for the specification, loaded from a CDN.
+func apiDocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	apiDocsPage.Execute(w, "/api/openapi.json")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	mux := (&apiConfig{}).routes(".")
+	var keys []string
+	for _, pattern := range mux.patterns {
+		keys = append(keys, routeKey(pattern))
+		doc, ok := routeDocs[routeKey(pattern)]
+		if !ok {
+			t.Errorf("route %q has no entry in routeDocs", pattern)
+			continue
+		}
+		if doc.summary == "" {
+			t.Errorf("route %q has no summary", pattern)
+		}
+	}
+	for key := range routeDocs {
+		if !slices.Contains(keys, key) {
+			t.Errorf("routeDocs has an entry for %q, which isn't registered", key)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	mux := (&apiConfig{}).routes(".")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Security   []map[string][]string      `json:"security"`
+			Deprecated bool                       `json:"deprecated"`
+			Responses  map[string]json.RawMessage `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	createChirp := spec.Paths["/api/chirps"]["post"]
+	if len(createChirp.Security) != 1 || createChirp.Security[0]["accessToken"] == nil {
+		t.Errorf("POST /api/chirps security = %v, want accessToken", createChirp.Security)
+	}
+	if _, ok := createChirp.Responses["201"]; !ok {
+		t.Errorf("POST /api/chirps responses = %v, want 201", createChirp.Responses)
+	}
+	if !createChirp.Deprecated {
+		t.Error("POST /api/chirps isn't deprecated in favour of /api/v1/chirps")
+	}
+	if v1 := spec.Paths["/api/v1/chirps"]["post"]; v1.Responses == nil || v1.Deprecated {
+		t.Errorf("POST /api/v1/chirps = %+v, want a current operation", v1)
+	}
+	if _, ok := spec.Paths["/api/chirps/{chirpID}"]["delete"]; !ok {
+		t.Error("DELETE /api/chirps/{chirpID} is missing")
+	}
+	if _, ok := spec.Paths["/media/{path}"]["get"]; !ok {
+		t.Error("GET /media/{path} is missing")
+	}
+	for _, name := range []string{"Chirp", "ChirpRequest", "ErrorResponse", "jobResponse"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"main.go/internal/response"
+)
+
+// parseLimit reads the limit query parameter, defaulting to def and
+// capped at max. It responds with an error and returns ok=false if the
+// parameter is invalid.
+func parseLimit(w http.ResponseWriter, r *http.Request, def, max int) (limit int, ok bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > max {
+		response.Error(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(max), err)
+		return 0, false
+	}
+	return n, true
+}
+
+// parseOffset reads the offset query parameter, defaulting to 0. It
+// responds with an error and returns ok=false if the parameter is invalid.
+func parseOffset(w http.ResponseWriter, r *http.Request) (offset int, ok bool) {
+	v := r.URL.Query().Get("offset")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		response.Error(w, http.StatusBadRequest, "offset must be a non-negative integer", err)
+		return 0, false
+	}
+	return n, true
+}
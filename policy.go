@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"main.go/internal/auth"
+	"main.go/internal/response"
+)
+
+// access says who may call a route.
+type access int
+
+const (
+	// accessPublic routes are open to anyone.
+	accessPublic access = iota
+	// accessUser routes need a valid access token. Handlers get the
+	// user it was issued to from requestUserID.
+	accessUser
+	// accessRefreshToken routes take a refresh token in place of an
+	// access token. The handler looks it up, since only the database
+	// knows whether it is still valid.
+	accessRefreshToken
+	// accessDev routes are accessAdmin routes that only work when
+	// PLATFORM is dev.
+	accessDev
+	// accessAdmin routes are for operators: they need the access token
+	// of a user with the admin role. The role is read from the token, so
+	// a demoted admin keeps access until it expires.
+	accessAdmin
+	// accessWebhook routes receive incoming webhooks, which carry
+	// "Authorization: ApiKey <POLKA_KEY>" instead of a token; see
+	// requireWebhookKey. They live under webhookPrefix, and every route
+	// there must have this policy.
+	accessWebhook
+)
+
+// webhookPrefix is where incoming webhook routes are registered.
+const webhookPrefix = "/api/webhooks/"
+
+// routePolicies lists the access policy of every route, by routeKey.
+// middlewareAuthorize refuses routes missing from it.
+var routePolicies = map[string]access{
+	"GET /api/healthz":      accessPublic,
+	"GET /api/stats":        accessPublic,
+	"GET /status":           accessPublic,
+	"GET /media/{path...}":  accessPublic,
+	"/app/":                 accessPublic,
+	"GET /api/openapi.json": accessPublic,
+
+	"POST /admin/reset": accessDev,
+
+	"GET /admin/docs":                                    accessAdmin,
+	"GET /admin/metrics":                                 accessAdmin,
+	"GET /admin/read_only":                               accessAdmin,
+	"PUT /admin/read_only":                               accessAdmin,
+	"GET /admin/chaos":                                   accessAdmin,
+	"PUT /admin/chaos":                                   accessAdmin,
+	"DELETE /admin/chaos":                                accessAdmin,
+	"GET /admin/metrics/history":                         accessAdmin,
+	"GET /admin/jobs":                                    accessAdmin,
+	"GET /admin/jobs/stats":                              accessAdmin,
+	"GET /admin/jobs/{jobID}":                            accessAdmin,
+	"POST /admin/jobs/{jobID}/retry":                     accessAdmin,
+	"POST /admin/jobs/{jobID}/cancel":                    accessAdmin,
+	"GET /admin/tasks":                                   accessAdmin,
+	"POST /admin/tasks/{taskName}/run":                   accessAdmin,
+	"GET /admin/heartbeats":                              accessAdmin,
+	"GET /admin/moderation":                              accessAdmin,
+	"GET /admin/moderation/stats":                        accessAdmin,
+	"GET /admin/reports":                                 accessAdmin,
+	"GET /admin/export/chirps":                           accessAdmin,
+	"GET /admin/export/users":                            accessAdmin,
+	"GET /admin/webhook_deliveries/failed":               accessAdmin,
+	"POST /admin/webhook_deliveries/{eventID}/redeliver": accessAdmin,
+	"GET /admin/banned_words":                            accessAdmin,
+	"POST /admin/banned_words":                           accessAdmin,
+	"DELETE /admin/banned_words/{word}":                  accessAdmin,
+	"POST /admin/reports/{reportID}/resolve":             accessAdmin,
+	"POST /admin/moderation/{entryID}/resolve":           accessAdmin,
+	"GET /admin/chirps":                                  accessAdmin,
+	"DELETE /admin/chirps/{chirpID}":                     accessAdmin,
+	"POST /admin/chirps/{chirpID}/remove":                accessAdmin,
+	"GET /admin/audit_log":                               accessAdmin,
+	"POST /admin/users/{userID}/suspend":                 accessAdmin,
+	"PUT /admin/users/{userID}/role":                     accessAdmin,
+	"PUT /admin/users/{userID}/legal_hold":               accessAdmin,
+	"DELETE /admin/users/{userID}/legal_hold":            accessAdmin,
+	"PUT /admin/chirps/{chirpID}/legal_hold":             accessAdmin,
+	"DELETE /admin/chirps/{chirpID}/legal_hold":          accessAdmin,
+	"POST /admin/users/{userID}/erase":                   accessAdmin,
+	"POST /admin/users/{userID}/merge":                   accessAdmin,
+	"GET /admin/erasures":                                accessAdmin,
+	"GET /admin/appeals":                                 accessAdmin,
+	"POST /admin/appeals/{appealID}/approve":             accessAdmin,
+	"POST /admin/appeals/{appealID}/deny":                accessAdmin,
+
+	// Accounts and sessions
+	"/api/users":                        accessPublic,
+	"/api/login":                        accessPublic,
+	"POST /api/users/restore":           accessPublic,
+	"GET /api/users/exists":             accessPublic,
+	"POST /api/password_reset/request":  accessPublic,
+	"POST /api/password_reset/confirm":  accessPublic,
+	"POST /api/refresh":                 accessRefreshToken,
+	"POST /api/revoke":                  accessRefreshToken,
+	"POST /api/logout":                  accessRefreshToken,
+	"POST /api/logout_all":              accessUser,
+	"POST /api/devices":                 accessRefreshToken,
+	"DELETE /api/devices/{token}":       accessUser,
+	"GET /api/auth/{provider}/login":    accessPublic,
+	"GET /api/auth/{provider}/callback": accessPublic,
+	"PUT /api/users":                    accessUser,
+	"DELETE /api/users":                 accessUser,
+	"GET /api/users/me/settings":        accessUser,
+	"PUT /api/users/me/settings":        accessUser,
+	"GET /api/users/me/mentions":        accessUser,
+	"POST /api/users/me/avatar":         accessUser,
+	"POST /api/users/me/2fa/enable":     accessUser,
+	"POST /api/users/me/2fa/confirm":    accessUser,
+	"GET /api/users/me/moderation":      accessUser,
+	"GET /api/users/me/trust":           accessUser,
+	"POST /api/appeals":                 accessUser,
+
+	// Profiles and follows
+	"GET /api/users/{userID}":           accessPublic,
+	"GET /api/usernames/{username}":     accessPublic,
+	"POST /api/users/{userID}/follow":   accessUser,
+	"DELETE /api/users/{userID}/follow": accessUser,
+
+	// Chirps
+	"POST /api/validate_chirp":             accessPublic,
+	"GET /api/chirps":                      accessPublic,
+	"GET /api/chirps/search":               accessPublic,
+	"GET /api/chirps/stream":               accessPublic,
+	"GET /api/hashtags/{tag}/chirps":       accessPublic,
+	"GET /api/chirps/{chirpID}":            accessPublic,
+	"GET /api/chirps/{chirpID}/replies":    accessPublic,
+	"GET /api/report_reasons":              accessPublic,
+	"POST /api/chirps":                     accessUser,
+	"PUT /api/chirps/{chirpID}":            accessUser,
+	"/api/chirps/{chirpID}":                accessUser,
+	"POST /api/chirps/{chirpID}/like":      accessUser,
+	"DELETE /api/chirps/{chirpID}/like":    accessUser,
+	"POST /api/chirps/{chirpID}/rechirp":   accessUser,
+	"DELETE /api/chirps/{chirpID}/rechirp": accessUser,
+	"POST /api/chirps/{chirpID}/share":     accessUser,
+	"PUT /api/chirps/{chirpID}/image":      accessUser,
+	"POST /api/chirps/{chirpID}/report":    accessUser,
+	"POST /api/chirps/{chirpID}/reports":   accessUser,
+
+	// Collections
+	"GET /api/collections/{collectionID}":                     accessPublic,
+	"GET /api/users/{userID}/collections":                     accessPublic,
+	"POST /api/collections":                                   accessUser,
+	"PUT /api/collections/{collectionID}":                     accessUser,
+	"DELETE /api/collections/{collectionID}":                  accessUser,
+	"POST /api/collections/{collectionID}/chirps":             accessUser,
+	"PUT /api/collections/{collectionID}/chirps":              accessUser,
+	"DELETE /api/collections/{collectionID}/chirps/{chirpID}": accessUser,
+
+	// Feeds
+	"GET /api/feed":                    accessUser,
+	"GET /api/feed/for_you":            accessUser,
+	"GET /api/feed/summary":            accessUser,
+	"GET /api/feed/markers":            accessUser,
+	"GET /api/digest":                  accessUser,
+	"PUT /api/feed/markers/{timeline}": accessUser,
+}
+
+// middlewareAuthorize serves routes, first enforcing the routePolicies
+// entry of the pattern the request matches. Requests that match no
+// pattern go straight through, for routes to answer 404 or 405.
+func (cfg *apiConfig) middlewareAuthorize(routes *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := routes.Handler(r)
+		if pattern == "" {
+			routes.ServeHTTP(w, r)
+			return
+		}
+
+		policy, ok := routePolicies[routeKey(pattern)]
+		if !ok {
+			response.Error(w, http.StatusInternalServerError, "Couldn't authorize request", fmt.Errorf("no access policy for %q", pattern))
+			return
+		}
+		if policy == accessDev && cfg.PLATFORM != "dev" {
+			response.Error(w, http.StatusForbidden, "Forbidden: only allowed in the dev environment", nil)
+			return
+		}
+		switch policy {
+		case accessUser, accessDev, accessAdmin:
+			tokenStr, err := auth.GetBearerToken(r.Header)
+			if err != nil {
+				response.Error(w, http.StatusUnauthorized, "Missing or invalid authorization header", err)
+				return
+			}
+			token, err := auth.ParseAccessToken(tokenStr, cfg.jwtKeys)
+			if err != nil {
+				response.Error(w, http.StatusUnauthorized, "Invalid token", err)
+				return
+			}
+			if policy != accessUser && token.Role != auth.RoleAdmin {
+				response.Error(w, http.StatusForbidden, "Forbidden: admin role required", nil)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), userIDKey{}, token.UserID))
+		case accessWebhook:
+			cfg.requireWebhookKey(routes.ServeHTTP)(w, r)
+			return
+		case accessPublic:
+			// Anyone may call these, but a valid token still says who is
+			// asking, e.g. to show followers-only media
+			if tokenStr, err := auth.GetBearerToken(r.Header); err == nil {
+				if token, err := auth.ParseAccessToken(tokenStr, cfg.jwtKeys); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), userIDKey{}, token.UserID))
+				}
+			}
+		}
+		routes.ServeHTTP(w, r)
+	})
+}
+
+type userIDKey struct{}
+
+// requestUserID returns the user whose access token authorized r. On
+// public routes it is uuid.Nil for requests without a valid one.
+func requestUserID(r *http.Request) uuid.UUID {
+	return contextUserID(r.Context())
+}
+
+// contextUserID is requestUserID, from the context of the request.
+func contextUserID(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(userIDKey{}).(uuid.UUID)
+	return id
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"main.go/internal/auth"
+)
+
+func TestEveryRouteHasAPolicy(t *testing.T) {
+	mux := (&apiConfig{}).routes(".")
+	var keys []string
+	for _, pattern := range mux.patterns {
+		keys = append(keys, routeKey(pattern))
+		if _, ok := routePolicies[routeKey(pattern)]; !ok {
+			t.Errorf("route %q has no entry in routePolicies", pattern)
+		}
+	}
+	for key := range routePolicies {
+		if !slices.Contains(keys, key) {
+			t.Errorf("routePolicies has an entry for %q, which isn't registered", key)
+		}
+	}
+}
+
+func TestWebhookRoutesNeedAPIKey(t *testing.T) {
+	mux := (&apiConfig{}).routes(".")
+	for _, pattern := range mux.patterns {
+		key := routeKey(pattern)
+		_, path, _ := strings.Cut(key, " ")
+		if strings.HasPrefix(path, webhookPrefix) != (routePolicies[key] == accessWebhook) {
+			t.Errorf("route %q: only routes under %s may, and must, use accessWebhook", pattern, webhookPrefix)
+		}
+	}
+}
+
+func TestMiddlewareAuthorizeWebhook(t *testing.T) {
+	routePolicies["POST /api/webhooks/test"] = accessWebhook
+	t.Cleanup(func() { delete(routePolicies, "POST /api/webhooks/test") })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/webhooks/test", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name       string
+		polkaKey   string
+		header     string
+		wantStatus int
+	}{
+		{name: "Missing header", polkaKey: "key", wantStatus: http.StatusUnauthorized},
+		{name: "Bearer token", polkaKey: "key", header: "Bearer key", wantStatus: http.StatusUnauthorized},
+		{name: "Wrong key", polkaKey: "key", header: "ApiKey nope", wantStatus: http.StatusUnauthorized},
+		{name: "Key not configured", header: "ApiKey key", wantStatus: http.StatusUnauthorized},
+		{name: "Right key", polkaKey: "key", header: "ApiKey key", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := (&apiConfig{polkaKey: tt.polkaKey}).middlewareAuthorize(mux)
+			req := httptest.NewRequest(http.MethodPost, "/api/webhooks/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMiddlewareAuthorize(t *testing.T) {
+	keys := auth.Keyring{{ID: "1", Secret: []byte("secret")}}
+	cfg := &apiConfig{jwtKeys: keys, PLATFORM: "prod"}
+	userID := uuid.New()
+	token, _ := auth.MakeJWT(auth.AccessToken{UserID: userID, Role: auth.RoleUser}, keys, time.Hour)
+	adminToken, _ := auth.MakeJWT(auth.AccessToken{UserID: userID, Role: auth.RoleAdmin}, keys, time.Hour)
+
+	var gotUserID uuid.UUID
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { gotUserID = requestUserID(r) }
+	mux.HandleFunc("GET /api/healthz", ok)
+	mux.HandleFunc("GET /api/feed", ok)
+	mux.HandleFunc("GET /api/v1/feed", ok)
+	mux.HandleFunc("POST /admin/reset", ok)
+	mux.HandleFunc("GET /admin/jobs", ok)
+	mux.HandleFunc("GET /unlisted", ok)
+	h := cfg.middlewareAuthorize(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantUserID uuid.UUID
+	}{
+		{name: "Public route", method: "GET", path: "/api/healthz", wantStatus: http.StatusOK},
+		{name: "User route without token", method: "GET", path: "/api/feed", wantStatus: http.StatusUnauthorized},
+		{name: "User route with bad token", method: "GET", path: "/api/feed", token: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "User route with token", method: "GET", path: "/api/feed", token: token, wantStatus: http.StatusOK, wantUserID: userID},
+		{name: "Versioned user route without token", method: "GET", path: "/api/v1/feed", wantStatus: http.StatusUnauthorized},
+		{name: "Versioned user route with token", method: "GET", path: "/api/v1/feed", token: token, wantStatus: http.StatusOK, wantUserID: userID},
+		{name: "Dev route outside dev", method: "POST", path: "/admin/reset", token: adminToken, wantStatus: http.StatusForbidden},
+		{name: "Admin route without token", method: "GET", path: "/admin/jobs", wantStatus: http.StatusUnauthorized},
+		{name: "Admin route with user token", method: "GET", path: "/admin/jobs", token: token, wantStatus: http.StatusForbidden},
+		{name: "Admin route with admin token", method: "GET", path: "/admin/jobs", token: adminToken, wantStatus: http.StatusOK, wantUserID: userID},
+		{name: "Route without a policy", method: "GET", path: "/unlisted", wantStatus: http.StatusInternalServerError},
+		{name: "Unknown route", method: "GET", path: "/nowhere", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUserID = uuid.Nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if gotUserID != tt.wantUserID {
+				t.Errorf("requestUserID = %s, want %s", gotUserID, tt.wantUserID)
+			}
+		})
+	}
+}
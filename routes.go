@@ -0,0 +1,186 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// router is a ServeMux that remembers the patterns registered on it, so
+// they can be checked against routePolicies.
+type router struct {
+	*http.ServeMux
+	patterns []string
+}
+
+// Handle -
+func (m *router) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, handler)
+}
+
+// HandleFunc -
+func (m *router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.HandleFunc(pattern, handler)
+}
+
+// apiVersion registers the routes of one version of the API under its
+// prefix, e.g. /api/v1. Each version registers its own handlers, so a
+// later version can change a route's request or response shape while
+// earlier versions keep serving the old one.
+type apiVersion struct {
+	mux    *router
+	prefix string
+	// legacy also serves the version at the unversioned /api/ paths,
+	// which predate versioning
+	legacy bool
+}
+
+// HandleFunc registers handler for an /api/ pattern, such as
+// "GET /api/chirps", under the version's prefix.
+func (v apiVersion) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	v.mux.HandleFunc(strings.Replace(pattern, "/api/", v.prefix+"/", 1), handler)
+	if v.legacy {
+		v.mux.HandleFunc(pattern, handler)
+	}
+}
+
+// apiVersionPattern matches the version segment of versioned patterns
+// and paths.
+var apiVersionPattern = regexp.MustCompile(`^([A-Z]+ )?/api/v[0-9]+/`)
+
+// routeKey identifies the route a pattern belongs to regardless of API
+// version: "GET /api/v1/chirps" and "GET /api/chirps" both give
+// "GET /api/chirps". routePolicies and routeDocs are keyed by it.
+func routeKey(pattern string) string {
+	return apiVersionPattern.ReplaceAllString(pattern, "${1}/api/")
+}
+
+// routes registers every endpoint. Who may call each one is declared in
+// routePolicies. fileRoot is served under /app/.
+func (cfg *apiConfig) routes(fileRoot string) *router {
+	mux := &router{ServeMux: http.NewServeMux()}
+	v1 := apiVersion{mux: mux, prefix: "/api/v1", legacy: true}
+
+	v1.HandleFunc("GET /api/healthz", HealthzHandler)
+	v1.HandleFunc("GET /api/stats", cfg.publicStatsHandler)
+	mux.HandleFunc("GET /status", cfg.statusHandler)
+	mux.HandleFunc("GET /admin/metrics", cfg.adminMetricsHandler)
+	mux.HandleFunc("GET /admin/metrics/history", cfg.adminMetricsHistoryHandler)
+	mux.HandleFunc("POST /admin/reset", cfg.resetHandler)
+	mux.HandleFunc("GET /admin/read_only", cfg.adminGetReadOnlyHandler)
+	mux.HandleFunc("PUT /admin/read_only", cfg.adminSetReadOnlyHandler)
+	mux.HandleFunc("GET /admin/chaos", cfg.adminGetChaosHandler)
+	mux.HandleFunc("PUT /admin/chaos", cfg.adminSetChaosHandler)
+	mux.HandleFunc("DELETE /admin/chaos", cfg.adminClearChaosHandler)
+	mux.HandleFunc("GET /admin/jobs", cfg.adminListJobsHandler)
+	mux.HandleFunc("GET /admin/jobs/stats", cfg.adminJobStatsHandler)
+	mux.HandleFunc("GET /admin/jobs/{jobID}", cfg.adminGetJobHandler)
+	mux.HandleFunc("POST /admin/jobs/{jobID}/retry", cfg.adminRetryJobHandler)
+	mux.HandleFunc("POST /admin/jobs/{jobID}/cancel", cfg.adminCancelJobHandler)
+	mux.HandleFunc("GET /admin/tasks", cfg.adminListTasksHandler)
+	mux.HandleFunc("POST /admin/tasks/{taskName}/run", cfg.adminRunTaskHandler)
+	mux.HandleFunc("GET /admin/heartbeats", cfg.adminHeartbeatsHandler)
+	mux.HandleFunc("GET /admin/moderation", cfg.adminListModerationHandler)
+	mux.HandleFunc("GET /admin/moderation/stats", cfg.adminReportStatsHandler)
+	mux.HandleFunc("GET /admin/reports", cfg.adminListReportsHandler)
+	mux.HandleFunc("GET /admin/export/chirps", cfg.adminExportChirpsHandler)
+	mux.HandleFunc("GET /admin/export/users", cfg.adminExportUsersHandler)
+	mux.HandleFunc("GET /admin/webhook_deliveries/failed", cfg.adminListFailedWebhookDeliveriesHandler)
+	mux.HandleFunc("POST /admin/webhook_deliveries/{eventID}/redeliver", cfg.adminRedeliverWebhookHandler)
+	mux.HandleFunc("GET /admin/banned_words", cfg.adminListBannedWordsHandler)
+	mux.HandleFunc("POST /admin/banned_words", cfg.adminAddBannedWordHandler)
+	mux.HandleFunc("DELETE /admin/banned_words/{word}", cfg.adminDeleteBannedWordHandler)
+	mux.HandleFunc("POST /admin/reports/{reportID}/resolve", cfg.adminResolveReportHandler)
+	mux.HandleFunc("POST /admin/moderation/{entryID}/resolve", cfg.adminResolveModerationHandler)
+	mux.HandleFunc("GET /admin/chirps", cfg.adminListChirpsHandler)
+	mux.HandleFunc("DELETE /admin/chirps/{chirpID}", cfg.adminDeleteChirpHandler)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/remove", cfg.adminRemoveChirpHandler)
+	mux.HandleFunc("GET /admin/audit_log", cfg.adminListAuditLogHandler)
+	mux.HandleFunc("POST /admin/users/{userID}/suspend", cfg.adminSuspendUserHandler)
+	mux.HandleFunc("PUT /admin/users/{userID}/role", cfg.adminSetRoleHandler)
+	mux.HandleFunc("PUT /admin/users/{userID}/legal_hold", cfg.adminPlaceUserLegalHoldHandler)
+	mux.HandleFunc("DELETE /admin/users/{userID}/legal_hold", cfg.adminReleaseUserLegalHoldHandler)
+	mux.HandleFunc("PUT /admin/chirps/{chirpID}/legal_hold", cfg.adminPlaceChirpLegalHoldHandler)
+	mux.HandleFunc("DELETE /admin/chirps/{chirpID}/legal_hold", cfg.adminReleaseChirpLegalHoldHandler)
+	mux.HandleFunc("POST /admin/users/{userID}/erase", cfg.adminEraseUserHandler)
+	mux.HandleFunc("POST /admin/users/{userID}/merge", cfg.adminMergeUserHandler)
+	mux.HandleFunc("GET /admin/erasures", cfg.adminListErasuresHandler)
+	mux.HandleFunc("GET /admin/appeals", cfg.adminListAppealsHandler)
+	mux.HandleFunc("POST /admin/appeals/{appealID}/approve", cfg.adminApproveAppealHandler)
+	mux.HandleFunc("POST /admin/appeals/{appealID}/deny", cfg.adminDenyAppealHandler)
+	v1.HandleFunc("POST /api/validate_chirp", cfg.handlerChirpsValidate)
+	v1.HandleFunc("/api/users", cfg.createUserHandler)
+	v1.HandleFunc("POST /api/chirps", cfg.createChirpHandler)
+	v1.HandleFunc("GET /api/chirps", cfg.getChirpsHandler)
+	v1.HandleFunc("GET /api/chirps/search", cfg.searchChirpsHandler)
+	v1.HandleFunc("GET /api/chirps/stream", cfg.streamChirpsHandler)
+	v1.HandleFunc("GET /api/hashtags/{tag}/chirps", cfg.getHashtagChirpsHandler)
+	v1.HandleFunc("GET /api/chirps/{chirpID}", cfg.getChirpByIDHandler)
+	v1.HandleFunc("GET /api/chirps/{chirpID}/replies", cfg.getRepliesHandler)
+	v1.HandleFunc("GET /api/report_reasons", cfg.getReportReasonsHandler)
+	v1.HandleFunc("/api/login", cfg.handlerLogin)
+	v1.HandleFunc("POST /api/refresh", cfg.handlerRefresh)
+	v1.HandleFunc("POST /api/revoke", cfg.handlerRevoke)
+	v1.HandleFunc("POST /api/logout", cfg.handlerLogout)
+	v1.HandleFunc("POST /api/logout_all", cfg.handlerLogoutAll)
+	v1.HandleFunc("POST /api/devices", cfg.registerDeviceHandler)
+	v1.HandleFunc("DELETE /api/devices/{token}", cfg.unregisterDeviceHandler)
+	v1.HandleFunc("GET /api/auth/{provider}/login", cfg.oauthLoginHandler)
+	v1.HandleFunc("GET /api/auth/{provider}/callback", cfg.oauthCallbackHandler)
+	v1.HandleFunc("POST /api/password_reset/request", cfg.requestPasswordResetHandler)
+	v1.HandleFunc("POST /api/password_reset/confirm", cfg.confirmPasswordResetHandler)
+	v1.HandleFunc("PUT /api/users", cfg.updateUserHandler)
+	v1.HandleFunc("DELETE /api/users", cfg.deleteUserHandler)
+	v1.HandleFunc("POST /api/users/restore", cfg.restoreUserHandler)
+	v1.HandleFunc("GET /api/users/exists", cfg.userExistsHandler)
+	v1.HandleFunc("GET /api/users/{userID}", cfg.getProfileHandler)
+	v1.HandleFunc("GET /api/usernames/{username}", cfg.getProfileByUsernameHandler)
+	v1.HandleFunc("GET /api/users/me/settings", cfg.getSettingsHandler)
+	v1.HandleFunc("PUT /api/users/me/settings", cfg.updateSettingsHandler)
+	v1.HandleFunc("GET /api/users/me/mentions", cfg.getMentionsHandler)
+	v1.HandleFunc("POST /api/users/me/avatar", cfg.uploadAvatarHandler)
+	v1.HandleFunc("POST /api/users/me/2fa/enable", cfg.enableTwoFactorHandler)
+	v1.HandleFunc("POST /api/users/me/2fa/confirm", cfg.confirmTwoFactorHandler)
+	v1.HandleFunc("GET /api/users/me/moderation", cfg.getMyModerationActionsHandler)
+	v1.HandleFunc("GET /api/users/me/trust", cfg.getMyTrustHandler)
+	v1.HandleFunc("POST /api/appeals", cfg.createAppealHandler)
+	v1.HandleFunc("POST /api/users/{userID}/follow", cfg.followUserHandler)
+	v1.HandleFunc("DELETE /api/users/{userID}/follow", cfg.unfollowUserHandler)
+	v1.HandleFunc("GET /api/feed", cfg.homeFeedHandler)
+	v1.HandleFunc("GET /api/feed/for_you", cfg.forYouFeedHandler)
+	v1.HandleFunc("GET /api/feed/summary", cfg.feedSummaryHandler)
+	v1.HandleFunc("GET /api/feed/markers", cfg.getFeedMarkersHandler)
+	v1.HandleFunc("GET /api/digest", cfg.digestHandler)
+	v1.HandleFunc("PUT /api/feed/markers/{timeline}", cfg.updateFeedMarkerHandler)
+	v1.HandleFunc("PUT /api/chirps/{chirpID}", cfg.updateChirpHandler)
+	v1.HandleFunc("POST /api/chirps/{chirpID}/like", cfg.likeChirpHandler)
+	v1.HandleFunc("DELETE /api/chirps/{chirpID}/like", cfg.unlikeChirpHandler)
+	v1.HandleFunc("POST /api/chirps/{chirpID}/rechirp", cfg.rechirpHandler)
+	v1.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", cfg.undoRechirpHandler)
+	v1.HandleFunc("POST /api/chirps/{chirpID}/share", cfg.shareChirpHandler)
+	v1.HandleFunc("PUT /api/chirps/{chirpID}/image", cfg.setChirpImageHandler)
+	v1.HandleFunc("POST /api/chirps/{chirpID}/report", cfg.reportChirpHandler)
+	v1.HandleFunc("POST /api/chirps/{chirpID}/reports", cfg.reportChirpHandler) // alias of /report
+	v1.HandleFunc("/api/chirps/{chirpID}", cfg.deleteChirpHandler)
+
+	v1.HandleFunc("POST /api/collections", cfg.createCollectionHandler)
+	v1.HandleFunc("GET /api/collections/{collectionID}", cfg.getCollectionHandler)
+	v1.HandleFunc("PUT /api/collections/{collectionID}", cfg.updateCollectionHandler)
+	v1.HandleFunc("DELETE /api/collections/{collectionID}", cfg.deleteCollectionHandler)
+	v1.HandleFunc("POST /api/collections/{collectionID}/chirps", cfg.addCollectionChirpHandler)
+	v1.HandleFunc("PUT /api/collections/{collectionID}/chirps", cfg.setCollectionChirpsHandler)
+	v1.HandleFunc("DELETE /api/collections/{collectionID}/chirps/{chirpID}", cfg.removeCollectionChirpHandler)
+	v1.HandleFunc("GET /api/users/{userID}/collections", cfg.listUserCollectionsHandler)
+
+	mux.HandleFunc("GET /media/{path...}", cfg.mediaHandler)
+	v1.HandleFunc("GET /api/openapi.json", openAPIHandler(mux))
+	mux.HandleFunc("GET /admin/docs", apiDocsHandler)
+
+	// Wrap file server with the metrics increment middleware
+	fileServer := http.FileServer(http.Dir(fileRoot))
+	mux.Handle("/app/", cfg.middlewareMetricsInc(http.StripPrefix("/app", fileServer)))
+
+	return mux
+}
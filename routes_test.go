@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestRouteKey(t *testing.T) {
+	tests := map[string]string{
+		"GET /api/v1/chirps":          "GET /api/chirps",
+		"GET /api/chirps":             "GET /api/chirps",
+		"/api/v2/users":               "/api/users",
+		"GET /admin/metrics":          "GET /admin/metrics",
+		"GET /api/v1/users/{userID}":  "GET /api/users/{userID}",
+		"GET /api/hashtags/v1/chirps": "GET /api/hashtags/v1/chirps",
+	}
+	for pattern, want := range tests {
+		if got := routeKey(pattern); got != want {
+			t.Errorf("routeKey(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
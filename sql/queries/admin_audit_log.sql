@@ -0,0 +1,9 @@
+-- name: CreateAuditLogEntry :one
+INSERT INTO admin_audit_log (id, created_at, admin_id, action, target_id, details)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListAuditLog :many
+SELECT * FROM admin_audit_log
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
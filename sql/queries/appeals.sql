@@ -0,0 +1,77 @@
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, updated_at, user_id, kind, reason_code, chirp_id, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetModerationAction :one
+SELECT * FROM moderation_actions
+WHERE id = $1;
+
+-- name: GetModerationActionsByUser :many
+SELECT * FROM moderation_actions
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ReverseModerationAction :one
+UPDATE moderation_actions
+SET reversed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND reversed_at IS NULL
+RETURNING *;
+
+-- name: SuspendUser :execrows
+UPDATE users
+SET suspended_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND suspended_at IS NULL
+AND deleted_at IS NULL;
+
+-- name: UnsuspendUser :exec
+UPDATE users
+SET suspended_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RestoreRemovedChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_chirp_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CreateAppeal :one
+INSERT INTO appeals (id, created_at, updated_at, action_id, user_id, message)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (action_id) DO NOTHING
+RETURNING *;
+
+-- name: ListAppeals :many
+SELECT * FROM appeals
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: DecideAppeal :one
+UPDATE appeals
+SET status = $2,
+    decided_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,29 @@
+-- name: GetChirpImage :one
+SELECT * FROM chirp_images
+WHERE chirp_id = $1;
+
+-- name: SetChirpImage :one
+INSERT INTO chirp_images (chirp_id, created_at, path, followers_only)
+VALUES ($1, NOW(), $2, $3)
+ON CONFLICT (chirp_id) DO UPDATE
+SET created_at = NOW(),
+    path = EXCLUDED.path,
+    followers_only = EXCLUDED.followers_only
+RETURNING *;
+
+-- name: ListChirpImages :many
+SELECT chirp_images.*, chirps.user_id
+FROM chirp_images
+JOIN chirps ON chirps.id = chirp_images.chirp_id
+WHERE chirp_images.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: ChirpImageExists :one
+SELECT EXISTS (SELECT 1 FROM chirp_images WHERE path = $1);
+
+-- name: DeleteChirpImagesByUser :many
+-- Runs before the user's chirps are deleted, to find the files to remove.
+DELETE FROM chirp_images
+USING chirps
+WHERE chirps.id = chirp_images.chirp_id
+AND chirps.user_id = $1
+RETURNING chirp_images.path;
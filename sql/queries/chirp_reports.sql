@@ -0,0 +1,50 @@
+-- name: CountChirpReports :one
+SELECT count(*) FROM chirp_reports
+WHERE chirp_id = $1
+AND reason = $2;
+
+-- name: CreateChirpReport :one
+-- There are no rows if the reporter already reported the chirp.
+INSERT INTO chirp_reports (id, created_at, chirp_id, reporter_id, reason, details)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+ON CONFLICT (chirp_id, reporter_id) DO NOTHING
+RETURNING *;
+
+-- name: GetChirpReport :one
+SELECT * FROM chirp_reports WHERE id = $1;
+
+-- name: GetReportStats :many
+SELECT reason,
+    count(*) AS reports,
+    count(DISTINCT chirp_id) AS chirps,
+    count(*) FILTER (WHERE created_at > sqlc.arg(since)) AS recent_reports,
+    count(*) FILTER (WHERE hid_chirp) AS hidden,
+    count(*) FILTER (WHERE escalated) AS escalated
+FROM chirp_reports
+GROUP BY reason
+ORDER BY reason;
+
+-- name: ListChirpReports :many
+SELECT * FROM chirp_reports
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3;
+
+-- name: LockChirp :one
+-- Serializes reports of a chirp, so each threshold is reached once.
+SELECT id FROM chirps WHERE id = $1 FOR UPDATE;
+
+-- name: ResolveChirpReport :one
+UPDATE chirp_reports
+SET status = 'resolved',
+    resolved_at = NOW(),
+    resolved_by = $2
+WHERE id = $1
+AND status = 'open'
+RETURNING *;
+
+-- name: SetChirpReportActions :exec
+UPDATE chirp_reports
+SET hid_chirp = $2,
+    escalated = $3
+WHERE id = $1;
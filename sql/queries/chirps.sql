@@ -1,22 +1,177 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_chirp_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
 -- name: GetChirps :many
-SELECT * FROM chirps
-ORDER BY created_at ASC;
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.*, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at ASC
+LIMIT $1 OFFSET $2;
+
+-- name: GetChirpsDesc :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.*, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetChirpsByAuthor :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.*, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: GetChirpsByAuthorDesc :many
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT chirps.*, items.rechirp_id, items.posted_by, items.posted_at
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL
+ORDER BY items.posted_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountChirpsByAuthor :one
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT count(*)
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE items.posted_by = $1
+AND authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL;
+
+-- name: CountVisibleChirps :one
+WITH items AS (
+    SELECT chirps.id AS chirp_id, NULL::uuid AS rechirp_id, chirps.user_id AS posted_by, chirps.created_at AS posted_at
+    FROM chirps
+    UNION ALL
+    SELECT rechirps.chirp_id, rechirps.id, rechirps.user_id, rechirps.created_at
+    FROM rechirps
+)
+SELECT count(*)
+FROM items
+JOIN chirps ON chirps.id = items.chirp_id
+LEFT JOIN users AS authors ON authors.id = chirps.user_id
+LEFT JOIN users AS posters ON posters.id = items.posted_by
+WHERE authors.deleted_at IS NULL
+AND posters.deleted_at IS NULL;
+
+-- name: GetReplies :many
+SELECT chirps.* FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountReplies :one
+SELECT count(*) FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = $1
+AND users.deleted_at IS NULL;
+
+-- name: GetReplyCounts :many
+SELECT chirps.parent_chirp_id, count(*) AS reply_count FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.parent_chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY chirps.parent_chirp_id;
 
 -- name: GetChirp :one
-SELECT * FROM chirps
-WHERE id = $1;
+SELECT chirps.* FROM chirps
+LEFT JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = $1
+AND users.deleted_at IS NULL;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
 
 -- name: DeleteChirp :exec
 DELETE FROM chirps
 WHERE id = $1;
+
+-- name: DeleteChirpsByUsers :many
+DELETE FROM chirps
+WHERE user_id = ANY(sqlc.arg(user_ids)::uuid[])
+RETURNING *;
+
+-- name: CountChirpsByUser :one
+SELECT count(*) FROM chirps
+WHERE user_id = $1;
+
+-- name: GetChirpAuthorUsernames :many
+SELECT chirps.id, users.username FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.id = ANY(sqlc.arg(chirp_ids)::uuid[])
+AND users.username IS NOT NULL;
+
+-- name: ListChirpsByUser :many
+-- All of a user's chirps, newest first, even if the account was deleted.
+SELECT * FROM chirps
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
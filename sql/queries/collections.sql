@@ -0,0 +1,87 @@
+-- name: CreateCollection :one
+INSERT INTO collections (id, created_at, updated_at, user_id, name, description)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: GetCollection :one
+SELECT collections.* FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.id = $1
+AND users.deleted_at IS NULL;
+
+-- name: ListCollectionsByUser :many
+SELECT collections.* FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.user_id = $1
+AND users.deleted_at IS NULL
+ORDER BY collections.created_at DESC, collections.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountCollectionsByUser :one
+SELECT count(*) FROM collections
+WHERE user_id = $1;
+
+-- name: UpdateCollection :one
+UPDATE collections
+SET name = $2, description = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: TouchCollection :exec
+UPDATE collections
+SET updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = $1;
+
+-- name: GetCollectionChirps :many
+SELECT chirps.* FROM collection_chirps
+JOIN chirps ON chirps.id = collection_chirps.chirp_id
+LEFT JOIN users ON users.id = chirps.user_id
+WHERE collection_chirps.collection_id = $1
+AND users.deleted_at IS NULL
+ORDER BY collection_chirps.position ASC, collection_chirps.added_at ASC;
+
+-- name: CountCollectionChirps :one
+SELECT count(*) FROM collection_chirps
+WHERE collection_id = $1;
+
+-- name: AddChirpToCollection :execrows
+INSERT INTO collection_chirps (collection_id, chirp_id, position, added_at)
+VALUES (
+    sqlc.arg(collection_id),
+    sqlc.arg(chirp_id),
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM collection_chirps WHERE collection_id = sqlc.arg(collection_id)),
+    NOW()
+)
+ON CONFLICT (collection_id, chirp_id) DO NOTHING;
+
+-- name: RemoveChirpFromCollection :execrows
+DELETE FROM collection_chirps
+WHERE collection_id = $1
+AND chirp_id = $2;
+
+-- name: SetCollectionChirps :exec
+INSERT INTO collection_chirps (collection_id, chirp_id, position, added_at)
+SELECT sqlc.arg(collection_id), t.chirp_id, t.position, NOW()
+FROM unnest(sqlc.arg(chirp_ids)::uuid[]) WITH ORDINALITY AS t(chirp_id, position)
+ON CONFLICT (collection_id, chirp_id) DO UPDATE
+SET position = EXCLUDED.position;
+
+-- name: RemoveChirpsNotInCollection :exec
+DELETE FROM collection_chirps
+WHERE collection_id = sqlc.arg(collection_id)
+AND NOT (chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]));
+
+-- name: CountChirpsByIDs :one
+SELECT count(*) FROM chirps
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
@@ -0,0 +1,13 @@
+-- name: GetCounters :many
+SELECT * FROM counters;
+
+-- name: AddToCounter :one
+INSERT INTO counters (name, value, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (name) DO UPDATE
+SET value = counters.value + EXCLUDED.value,
+    updated_at = NOW()
+RETURNING value;
+
+-- name: ResetCounters :exec
+DELETE FROM counters;
@@ -0,0 +1,28 @@
+-- name: DeleteDeviceToken :exec
+DELETE FROM device_tokens WHERE token = $1;
+
+-- name: GetActiveDeviceTokens :many
+-- Devices of sessions that haven't ended
+SELECT device_tokens.token, device_tokens.platform FROM device_tokens
+JOIN refresh_tokens ON refresh_tokens.token = device_tokens.refresh_token
+WHERE device_tokens.user_id = $1
+AND refresh_tokens.revoked_at IS NULL
+AND refresh_tokens.expires_at > NOW()
+ORDER BY device_tokens.created_at;
+
+-- name: MoveDeviceTokens :exec
+UPDATE device_tokens SET refresh_token = sqlc.arg(new_refresh_token),
+updated_at = NOW()
+WHERE refresh_token = sqlc.arg(old_refresh_token);
+
+-- name: RegisterDeviceToken :exec
+INSERT INTO device_tokens (token, created_at, updated_at, user_id, platform, refresh_token)
+VALUES ($1, NOW(), NOW(), $2, $3, $4)
+ON CONFLICT (token) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    refresh_token = EXCLUDED.refresh_token;
+
+-- name: UnregisterDeviceToken :execrows
+DELETE FROM device_tokens WHERE token = $1 AND user_id = $2;
@@ -0,0 +1,62 @@
+-- name: CreateDigest :exec
+INSERT INTO digests (day, created_at)
+VALUES ($1, NOW())
+ON CONFLICT (day) DO UPDATE SET created_at = NOW();
+
+-- name: DeleteDigestChirps :exec
+DELETE FROM digest_chirps
+WHERE day = $1;
+
+-- name: InsertDigestChirps :execrows
+INSERT INTO digest_chirps (day, chirp_id, rank, engagement)
+SELECT sqlc.arg(day)::date, ranked.id, row_number() OVER (ORDER BY ranked.engagement DESC, ranked.created_at DESC), ranked.engagement
+FROM (
+    SELECT chirps.id, chirps.created_at,
+        (SELECT count(*) FROM likes WHERE likes.chirp_id = chirps.id)
+        + (SELECT count(*) FROM rechirps WHERE rechirps.chirp_id = chirps.id)
+        + (SELECT count(*) FROM chirp_shares WHERE chirp_shares.chirp_id = chirps.id)
+        + (SELECT count(*) FROM chirps AS replies WHERE replies.parent_chirp_id = chirps.id) AS engagement
+    FROM chirps
+    JOIN users ON users.id = chirps.user_id
+    WHERE chirps.created_at > sqlc.arg(since)
+    AND users.deleted_at IS NULL
+    AND users.suspended_at IS NULL
+) AS ranked
+WHERE ranked.engagement > 0
+ORDER BY ranked.engagement DESC, ranked.created_at DESC
+LIMIT sqlc.arg(max_chirps);
+
+-- name: DeleteDigestsBefore :execrows
+DELETE FROM digests
+WHERE day < $1;
+
+-- name: GetLatestDigestDay :one
+SELECT day FROM digests
+ORDER BY day DESC
+LIMIT 1;
+
+-- name: GetDigestChirps :many
+SELECT chirps.* FROM digest_chirps
+JOIN chirps ON chirps.id = digest_chirps.chirp_id
+JOIN users ON users.id = chirps.user_id
+WHERE digest_chirps.day = sqlc.arg(day)
+AND users.deleted_at IS NULL
+ORDER BY digest_chirps.rank
+LIMIT sqlc.arg(max_chirps);
+
+-- name: GetNetworkDigestChirps :many
+SELECT chirps.* FROM digest_chirps
+JOIN chirps ON chirps.id = digest_chirps.chirp_id
+JOIN users ON users.id = chirps.user_id
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE digest_chirps.day = sqlc.arg(day)
+AND follows.follower_id = sqlc.arg(user_id)
+AND users.deleted_at IS NULL
+ORDER BY digest_chirps.rank
+LIMIT sqlc.arg(max_chirps);
+
+-- name: ListDigestSubscribers :many
+SELECT id FROM users
+WHERE email_digest
+AND deleted_at IS NULL
+AND suspended_at IS NULL;
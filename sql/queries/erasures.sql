@@ -0,0 +1,44 @@
+-- name: CountUserData :one
+SELECT
+    (SELECT count(*) FROM chirps WHERE chirps.user_id = sqlc.arg(user_id)::uuid) AS chirps,
+    (SELECT count(*) FROM likes WHERE likes.user_id = sqlc.arg(user_id)::uuid) AS likes,
+    (SELECT count(*) FROM rechirps WHERE rechirps.user_id = sqlc.arg(user_id)::uuid) AS rechirps,
+    (SELECT count(*) FROM follows WHERE follows.follower_id = sqlc.arg(user_id)::uuid OR follows.followee_id = sqlc.arg(user_id)::uuid) AS follows,
+    (SELECT count(*) FROM chirp_mentions WHERE chirp_mentions.user_id = sqlc.arg(user_id)::uuid) AS mentions,
+    (SELECT count(*) FROM refresh_tokens WHERE refresh_tokens.user_id = sqlc.arg(user_id)::uuid) AS refresh_tokens,
+    (SELECT count(*) FROM feed_markers WHERE feed_markers.user_id = sqlc.arg(user_id)::uuid) AS feed_markers,
+    (SELECT count(*) FROM moderation_queue WHERE moderation_queue.user_id = sqlc.arg(user_id)::uuid) AS moderation_queue,
+    (SELECT count(*) FROM moderation_actions WHERE moderation_actions.user_id = sqlc.arg(user_id)::uuid) AS moderation_actions,
+    (SELECT count(*) FROM appeals WHERE appeals.user_id = sqlc.arg(user_id)::uuid) AS appeals,
+    (SELECT count(*) FROM collections WHERE collections.user_id = sqlc.arg(user_id)::uuid) AS collections,
+    (SELECT count(*) FROM chirp_shares WHERE chirp_shares.user_id = sqlc.arg(user_id)::uuid) AS shares;
+
+-- name: UserHasHeldChirps :one
+SELECT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE user_id = $1
+    AND legal_hold_at IS NOT NULL
+);
+
+-- name: CreateErasure :one
+INSERT INTO erasures (id, created_at, user_id, reference, report)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: CompleteErasure :one
+UPDATE erasures
+SET report = $2,
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListErasures :many
+SELECT * FROM erasures
+ORDER BY created_at DESC
+LIMIT $1;
@@ -0,0 +1,67 @@
+-- name: GetFeedCandidates :many
+SELECT chirps.*,
+    (follows.follower_id IS NOT NULL)::boolean AS followed
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+LEFT JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = sqlc.arg(user_id)
+WHERE chirps.created_at > sqlc.arg(since)
+AND chirps.created_at <= sqlc.arg(as_of)
+AND chirps.user_id <> sqlc.arg(user_id)
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT sqlc.arg(max_candidates);
+
+-- name: GetFeedLatest :many
+SELECT chirps.*,
+    (follows.follower_id IS NOT NULL)::boolean AS followed
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+LEFT JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = sqlc.arg(user_id)
+WHERE (chirps.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+AND chirps.user_id <> sqlc.arg(user_id)
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: CountFollowedChirpsSince :one
+SELECT count(*) FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+JOIN users ON users.id = chirps.user_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+AND chirps.created_at > sqlc.arg(since)
+AND users.deleted_at IS NULL;
+
+-- name: CountNewFollowersSince :one
+SELECT count(*) FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg(user_id)
+AND follows.created_at > sqlc.arg(since)
+AND users.deleted_at IS NULL;
+
+-- name: GetFollowedHighlightsSince :many
+SELECT chirps.* FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+JOIN users ON users.id = chirps.user_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+AND chirps.created_at > sqlc.arg(since)
+AND users.deleted_at IS NULL
+ORDER BY (
+    SELECT count(*) FROM likes
+    JOIN users AS likers ON likers.id = likes.user_id
+    WHERE likes.chirp_id = chirps.id
+    AND likers.deleted_at IS NULL
+) DESC,
+    chirps.created_at DESC
+LIMIT sqlc.arg(max_highlights);
+
+-- name: GetHomeFeed :many
+SELECT chirps.* FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+    AND follows.follower_id = sqlc.arg(user_id)
+JOIN users ON users.id = chirps.user_id
+WHERE (chirps.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,25 @@
+-- name: UpsertFeedMarker :one
+INSERT INTO feed_markers (user_id, timeline, chirp_id, chirp_created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+ON CONFLICT (user_id, timeline) DO UPDATE
+SET chirp_id = EXCLUDED.chirp_id,
+    chirp_created_at = EXCLUDED.chirp_created_at,
+    updated_at = NOW()
+WHERE feed_markers.chirp_created_at < EXCLUDED.chirp_created_at
+RETURNING *;
+
+-- name: GetFeedMarker :one
+SELECT * FROM feed_markers
+WHERE user_id = $1
+AND timeline = $2;
+
+-- name: GetFeedMarkers :many
+SELECT * FROM feed_markers
+WHERE user_id = $1
+ORDER BY timeline ASC;
@@ -0,0 +1,19 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+WHERE follower_id = $1
+AND followee_id = $2;
+
+-- name: ListFollowedAmong :many
+-- The users among followee_ids that the follower follows.
+SELECT followee_id FROM follows
+WHERE follower_id = $1
+AND followee_id = ANY(sqlc.arg(followee_ids)::uuid[]);
@@ -0,0 +1,63 @@
+-- name: FindOrphanedChirps :many
+SELECT id FROM chirps
+WHERE user_id IS NULL
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id)
+ORDER BY created_at;
+
+-- name: DeleteOrphanedChirps :execrows
+DELETE FROM chirps
+WHERE user_id IS NULL
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id);
+
+-- name: FindOrphanedLikes :many
+SELECT user_id, chirp_id FROM likes
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = likes.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = likes.user_id)
+ORDER BY created_at;
+
+-- name: DeleteOrphanedLikes :execrows
+DELETE FROM likes
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = likes.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = likes.user_id);
+
+-- name: FindOrphanedRechirps :many
+SELECT id FROM rechirps
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = rechirps.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = rechirps.user_id)
+ORDER BY created_at;
+
+-- name: DeleteOrphanedRechirps :execrows
+DELETE FROM rechirps
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = rechirps.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = rechirps.user_id);
+
+-- name: FindOrphanedChirpHashtags :many
+SELECT chirp_id, tag FROM chirp_hashtags
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = chirp_hashtags.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM hashtags WHERE hashtags.tag = chirp_hashtags.tag)
+ORDER BY chirp_id, tag;
+
+-- name: DeleteOrphanedChirpHashtags :execrows
+DELETE FROM chirp_hashtags
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = chirp_hashtags.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM hashtags WHERE hashtags.tag = chirp_hashtags.tag);
+
+-- name: FindOrphanedMentions :many
+SELECT chirp_id, user_id FROM chirp_mentions
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = chirp_mentions.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = chirp_mentions.user_id)
+ORDER BY chirp_id, user_id;
+
+-- name: DeleteOrphanedMentions :execrows
+DELETE FROM chirp_mentions
+WHERE NOT EXISTS (SELECT 1 FROM chirps WHERE chirps.id = chirp_mentions.chirp_id)
+OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = chirp_mentions.user_id);
+
+-- name: FindDanglingRefreshTokens :many
+SELECT user_id, expires_at FROM refresh_tokens
+WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = refresh_tokens.user_id)
+ORDER BY created_at;
+
+-- name: DeleteDanglingRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = refresh_tokens.user_id);
@@ -0,0 +1,29 @@
+-- name: CreateHashtags :exec
+INSERT INTO hashtags (tag, created_at)
+SELECT unnest(sqlc.arg(tags)::text[]), NOW()
+ON CONFLICT (tag) DO NOTHING;
+
+-- name: TagChirp :exec
+INSERT INTO chirp_hashtags (chirp_id, tag)
+SELECT sqlc.arg(chirp_id)::uuid, unnest(sqlc.arg(tags)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: UntagChirp :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: GetChirpsByHashtag :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_hashtags.tag = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountChirpsByHashtag :one
+SELECT count(*) FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_hashtags.tag = $1
+AND users.deleted_at IS NULL;
@@ -0,0 +1,11 @@
+-- name: HoldNotification :exec
+INSERT INTO held_notifications (id, created_at, user_id, channel, event, actor_id, chirp_id, text)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5, $6);
+
+-- name: ListUsersWithHeldNotifications :many
+SELECT DISTINCT user_id FROM held_notifications;
+
+-- name: ReleaseHeldNotifications :many
+DELETE FROM held_notifications
+WHERE user_id = $1
+RETURNING *;
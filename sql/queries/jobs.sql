@@ -0,0 +1,93 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (id, created_at, updated_at, type, payload, status, max_attempts, run_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    'pending',
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: ClaimNextJob :one
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE status = 'pending'
+    AND run_at <= NOW()
+    ORDER BY run_at ASC
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'succeeded',
+    last_error = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: FailJob :exec
+UPDATE jobs
+SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'pending' END,
+    finished_at = CASE WHEN attempts >= max_attempts THEN NOW() ELSE NULL END,
+    last_error = $2,
+    run_at = $3,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RequeueStaleJobs :execrows
+UPDATE jobs
+SET status = 'pending',
+    updated_at = NOW()
+WHERE status = 'running'
+AND updated_at < $1;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1;
+
+-- name: ListJobsByStatus :many
+SELECT * FROM jobs
+WHERE status = $1
+ORDER BY run_at ASC
+LIMIT $2;
+
+-- name: RetryJob :one
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    last_error = NULL,
+    finished_at = NULL,
+    run_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND status IN ('failed', 'cancelled')
+RETURNING *;
+
+-- name: CancelJob :one
+UPDATE jobs
+SET status = 'cancelled',
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND status = 'pending'
+RETURNING *;
+
+-- name: GetJobStats :many
+SELECT type,
+    count(*) FILTER (WHERE status = 'pending') AS pending,
+    count(*) FILTER (WHERE status = 'running') AS running,
+    count(*) FILTER (WHERE status = 'succeeded' AND finished_at > sqlc.arg(since)::timestamp) AS succeeded,
+    count(*) FILTER (WHERE status = 'failed' AND finished_at > sqlc.arg(since)::timestamp) AS failed
+FROM jobs
+GROUP BY type
+ORDER BY type ASC;
@@ -0,0 +1,19 @@
+-- name: PlaceUserLegalHold :execrows
+UPDATE users
+SET legal_hold_at = COALESCE(legal_hold_at, NOW())
+WHERE id = $1;
+
+-- name: ReleaseUserLegalHold :execrows
+UPDATE users
+SET legal_hold_at = NULL
+WHERE id = $1;
+
+-- name: PlaceChirpLegalHold :execrows
+UPDATE chirps
+SET legal_hold_at = COALESCE(legal_hold_at, NOW())
+WHERE id = $1;
+
+-- name: ReleaseChirpLegalHold :execrows
+UPDATE chirps
+SET legal_hold_at = NULL
+WHERE id = $1;
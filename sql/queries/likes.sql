@@ -0,0 +1,20 @@
+-- name: LikeChirp :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM likes
+WHERE user_id = $1
+AND chirp_id = $2;
+
+-- name: GetLikeCounts :many
+SELECT likes.chirp_id, count(*) AS likes_count FROM likes
+JOIN users ON users.id = likes.user_id
+WHERE likes.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY likes.chirp_id;
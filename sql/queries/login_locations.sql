@@ -0,0 +1,24 @@
+-- name: CreateLoginVerification :exec
+INSERT INTO login_verifications (user_id, code_hash, created_at, expires_at)
+VALUES ($1, $2, NOW(), $3)
+ON CONFLICT (user_id) DO UPDATE
+SET code_hash = EXCLUDED.code_hash,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: GetLoginLocation :one
+SELECT * FROM login_locations WHERE user_id = $1;
+
+-- name: SetLoginLocation :exec
+INSERT INTO login_locations (user_id, latitude, longitude, country, logged_in_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET latitude = EXCLUDED.latitude,
+    longitude = EXCLUDED.longitude,
+    country = EXCLUDED.country,
+    logged_in_at = EXCLUDED.logged_in_at;
+
+-- name: TakeLoginVerification :one
+DELETE FROM login_verifications
+WHERE user_id = $1
+RETURNING *;
@@ -0,0 +1,37 @@
+-- name: MentionUsers :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT sqlc.arg(chirp_id)::uuid, users.id FROM users
+WHERE (
+    users.username = ANY(sqlc.arg(usernames)::text[])
+    OR users.id IN (
+        SELECT user_id FROM username_redirects
+        WHERE username = ANY(sqlc.arg(usernames)::text[])
+        AND (expires_at IS NULL OR expires_at > NOW())
+    )
+)
+AND users.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- name: ClearMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
+
+-- name: GetMentions :many
+SELECT chirps.* FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_mentions.user_id = $1
+AND users.deleted_at IS NULL
+ORDER BY chirps.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountMentions :one
+SELECT count(*) FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+JOIN users ON users.id = chirps.user_id
+WHERE chirp_mentions.user_id = $1
+AND users.deleted_at IS NULL;
+
+-- name: GetMentionedUserIDs :many
+SELECT user_id FROM chirp_mentions
+WHERE chirp_id = $1;
@@ -0,0 +1,70 @@
+-- Moving a merged account's data to the account it is merged into. Rows
+-- the target already has an equivalent of are left behind, to go with
+-- the merged account.
+
+-- name: MergeChirps :execrows
+UPDATE chirps
+SET user_id = sqlc.arg(to_id)::uuid
+WHERE user_id = sqlc.arg(from_id)::uuid;
+
+-- name: MergeFollowing :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+SELECT sqlc.arg(to_id)::uuid, followee_id, created_at FROM follows
+WHERE follower_id = sqlc.arg(from_id)::uuid
+AND followee_id <> sqlc.arg(to_id)::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: MergeFollowers :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+SELECT follower_id, sqlc.arg(to_id)::uuid, created_at FROM follows
+WHERE followee_id = sqlc.arg(from_id)::uuid
+AND follower_id <> sqlc.arg(to_id)::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: MergeLikes :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+SELECT sqlc.arg(to_id)::uuid, chirp_id, created_at FROM likes
+WHERE user_id = sqlc.arg(from_id)::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: MergeRechirps :execrows
+UPDATE rechirps
+SET user_id = sqlc.arg(to_id)::uuid
+WHERE user_id = sqlc.arg(from_id)::uuid
+AND NOT EXISTS (
+    SELECT 1 FROM rechirps AS existing
+    WHERE existing.user_id = sqlc.arg(to_id)::uuid
+    AND existing.chirp_id = rechirps.chirp_id
+);
+
+-- name: MergeMentions :execrows
+UPDATE chirp_mentions
+SET user_id = sqlc.arg(to_id)::uuid
+WHERE user_id = sqlc.arg(from_id)::uuid
+AND NOT EXISTS (
+    SELECT 1 FROM chirp_mentions AS existing
+    WHERE existing.user_id = sqlc.arg(to_id)::uuid
+    AND existing.chirp_id = chirp_mentions.chirp_id
+);
+
+-- name: MergeCollections :execrows
+UPDATE collections
+SET user_id = sqlc.arg(to_id)::uuid,
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(from_id)::uuid;
+
+-- name: MergeShares :execrows
+UPDATE chirp_shares
+SET user_id = sqlc.arg(to_id)::uuid
+WHERE user_id = sqlc.arg(from_id)::uuid
+AND NOT EXISTS (
+    SELECT 1 FROM chirp_shares AS existing
+    WHERE existing.user_id = sqlc.arg(to_id)::uuid
+    AND existing.chirp_id = chirp_shares.chirp_id
+    AND existing.channel = chirp_shares.channel
+);
+
+-- name: MergeUsernameRedirects :execrows
+UPDATE username_redirects
+SET user_id = sqlc.arg(to_id)::uuid
+WHERE user_id = sqlc.arg(from_id)::uuid;
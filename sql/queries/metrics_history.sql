@@ -0,0 +1,21 @@
+-- name: CountUsers :one
+SELECT count(*) FROM users;
+
+-- name: DeleteMetricsHistoryBefore :execrows
+DELETE FROM metrics_history WHERE recorded_at < $1;
+
+-- name: GetMetricsHistory :many
+SELECT date_bin(make_interval(secs => sqlc.arg(step_seconds)::float8), recorded_at, sqlc.arg(from_time)::timestamp)::timestamp AS bucket,
+    sum(requests)::bigint AS requests,
+    sum(query_count)::bigint AS query_count,
+    sum(query_seconds)::float8 AS query_seconds,
+    max(users)::bigint AS users,
+    max(chirps)::bigint AS chirps
+FROM metrics_history
+WHERE recorded_at >= sqlc.arg(from_time) AND recorded_at < sqlc.arg(to_time)
+GROUP BY bucket
+ORDER BY bucket;
+
+-- name: RecordMetricsSnapshot :exec
+INSERT INTO metrics_history (id, recorded_at, requests, query_count, query_seconds, users, chirps)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5);
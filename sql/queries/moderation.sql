@@ -0,0 +1,26 @@
+-- name: CreateModerationEntry :one
+INSERT INTO moderation_queue (id, created_at, updated_at, user_id, source, signals)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: ListModerationEntries :many
+SELECT * FROM moderation_queue
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: ResolveModerationEntry :one
+UPDATE moderation_queue
+SET status = 'resolved',
+    resolved_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND status = 'open'
+RETURNING *;
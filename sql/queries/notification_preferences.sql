@@ -0,0 +1,27 @@
+-- name: GetNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, event, channel, enabled, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id, event, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: GetQuietHours :one
+SELECT * FROM notification_quiet_hours
+WHERE user_id = $1;
+
+-- name: SetQuietHours :exec
+INSERT INTO notification_quiet_hours (user_id, start_minute, end_minute, timezone, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET start_minute = EXCLUDED.start_minute,
+    end_minute = EXCLUDED.end_minute,
+    timezone = EXCLUDED.timezone,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteQuietHours :exec
+DELETE FROM notification_quiet_hours
+WHERE user_id = $1;
@@ -0,0 +1,27 @@
+-- name: CreateRechirp :one
+INSERT INTO rechirps (id, user_id, chirp_id, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, chirp_id) DO NOTHING
+RETURNING *;
+
+-- name: GetRechirp :one
+SELECT * FROM rechirps
+WHERE user_id = $1
+AND chirp_id = $2;
+
+-- name: DeleteRechirp :execrows
+DELETE FROM rechirps
+WHERE user_id = $1
+AND chirp_id = $2;
+
+-- name: GetRechirpCounts :many
+SELECT rechirps.chirp_id, count(*) AS rechirp_count FROM rechirps
+JOIN users ON users.id = rechirps.user_id
+WHERE rechirps.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY rechirps.chirp_id;
@@ -1,11 +1,13 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, remember_me, family_id)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    $3
+    $3,
+    $4,
+    gen_random_uuid()
 )
 RETURNING *;
 
@@ -15,9 +17,73 @@ updated_at = NOW()
 WHERE token = $1
 RETURNING *;
 
+-- name: RotateRefreshToken :one
+-- Revokes a usable refresh token and issues new_token in its place. The
+-- new token keeps created_at, when the session started, so the maximum
+-- age of sliding expiration still applies.
+WITH old AS (
+    UPDATE refresh_tokens SET revoked_at = NOW(),
+    updated_at = NOW()
+    WHERE token = sqlc.arg(old_token)
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    RETURNING *
+)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, remember_me, family_id, parent_token)
+SELECT sqlc.arg(new_token), old.created_at, NOW(), old.user_id, old.expires_at, old.remember_me, old.family_id, old.token
+FROM old
+RETURNING *;
+
+-- name: RevokeReusedRefreshTokenFamily :one
+-- Revokes every token of the family of a token that was already rotated,
+-- returning the user it belongs to. There are no rows if the token wasn't
+-- rotated.
+WITH reused AS (
+    SELECT used.family_id, used.user_id FROM refresh_tokens AS used
+    WHERE used.token = $1
+    AND EXISTS (SELECT 1 FROM refresh_tokens AS child WHERE child.parent_token = used.token)
+), revoked AS (
+    UPDATE refresh_tokens SET revoked_at = NOW(),
+    updated_at = NOW()
+    WHERE family_id IN (SELECT family_id FROM reused)
+    AND revoked_at IS NULL
+)
+SELECT user_id FROM reused;
+
 -- name: GetUserFromRefreshToken :one
 SELECT users.* FROM users
 JOIN refresh_tokens ON users.id = refresh_tokens.user_id
 WHERE refresh_tokens.token = $1
 AND revoked_at IS NULL
-AND expires_at > NOW();
+AND expires_at > NOW()
+AND users.deleted_at IS NULL;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL;
+
+-- name: ExtendRefreshToken :exec
+UPDATE refresh_tokens
+SET expires_at = GREATEST(expires_at, LEAST(CASE WHEN remember_me THEN sqlc.arg(expires_at)::timestamp ELSE sqlc.arg(short_expires_at)::timestamp END, created_at + sqlc.arg(max_age_seconds)::bigint * INTERVAL '1 second')),
+    updated_at = NOW()
+WHERE token = sqlc.arg(token)
+AND revoked_at IS NULL;
+
+-- name: RevokeExcessRefreshTokens :execrows
+UPDATE refresh_tokens SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE token IN (
+    SELECT token FROM refresh_tokens
+    WHERE user_id = sqlc.arg(user_id)
+    AND revoked_at IS NULL
+    AND expires_at > NOW()
+    ORDER BY created_at DESC, token
+    OFFSET sqlc.arg(keep)
+);
+
+-- name: DeleteStaleRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < sqlc.arg(before)::timestamp
+OR revoked_at < sqlc.arg(before)::timestamp;
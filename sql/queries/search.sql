@@ -0,0 +1,17 @@
+-- name: SearchChirps :many
+SELECT chirps.*,
+    ts_rank(chirps.search_vector, query)::real AS rank
+FROM chirps
+JOIN users ON users.id = chirps.user_id,
+    websearch_to_tsquery('english', sqlc.arg(search)) AS query
+WHERE chirps.search_vector @@ query
+AND users.deleted_at IS NULL
+ORDER BY rank DESC, chirps.created_at DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+
+-- name: CountSearchChirps :one
+SELECT count(*)
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.search_vector @@ websearch_to_tsquery('english', sqlc.arg(search))
+AND users.deleted_at IS NULL;
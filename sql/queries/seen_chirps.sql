@@ -0,0 +1,12 @@
+-- name: GetSeenChirps :one
+SELECT * FROM seen_chirps
+WHERE user_id = $1;
+
+-- name: SaveSeenChirps :exec
+INSERT INTO seen_chirps (user_id, current_filter, previous_filter, rotated_at, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET current_filter = EXCLUDED.current_filter,
+    previous_filter = EXCLUDED.previous_filter,
+    rotated_at = EXCLUDED.rotated_at,
+    updated_at = NOW();
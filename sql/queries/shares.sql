@@ -0,0 +1,24 @@
+-- name: RecordShare :execrows
+INSERT INTO chirp_shares (id, created_at, user_id, chirp_id, channel)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, chirp_id, channel) DO NOTHING;
+
+-- name: GetShareCounts :many
+SELECT chirp_shares.chirp_id, count(*) AS share_count FROM chirp_shares
+JOIN users ON users.id = chirp_shares.user_id
+WHERE chirp_shares.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+AND users.deleted_at IS NULL
+GROUP BY chirp_shares.chirp_id;
+
+-- name: CountSharesOfUserChirps :one
+SELECT count(*) FROM chirp_shares
+JOIN chirps ON chirps.id = chirp_shares.chirp_id
+JOIN users ON users.id = chirp_shares.user_id
+WHERE chirps.user_id = $1
+AND users.deleted_at IS NULL;
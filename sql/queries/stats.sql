@@ -0,0 +1,20 @@
+-- name: CountChirps :one
+SELECT count(*) FROM chirps;
+
+-- name: CountActiveUsersSince :one
+SELECT count(DISTINCT user_id) FROM (
+    SELECT chirps.user_id FROM chirps WHERE chirps.created_at > sqlc.arg(since)
+    UNION
+    SELECT refresh_tokens.user_id FROM refresh_tokens WHERE refresh_tokens.created_at > sqlc.arg(since)
+) AS active;
+
+-- name: GetTopHashtagsSince :many
+SELECT lower(match[1])::text AS tag,
+    count(DISTINCT user_id) AS authors,
+    count(*) AS uses
+FROM chirps, regexp_matches(body, '#(\w+)', 'g') AS match
+WHERE created_at > sqlc.arg(since)
+GROUP BY 1
+HAVING count(DISTINCT user_id) >= sqlc.arg(min_authors)::bigint
+ORDER BY uses DESC, tag ASC
+LIMIT sqlc.arg(max_tags);
@@ -0,0 +1,34 @@
+-- name: GetTrustActivity :one
+SELECT users.created_at,
+    (SELECT count(*) FROM chirps WHERE chirps.user_id = users.id) AS chirps,
+    (SELECT count(*) FROM likes
+        JOIN chirps ON chirps.id = likes.chirp_id
+        WHERE chirps.user_id = users.id
+        AND likes.user_id <> users.id) AS likes_received,
+    (SELECT count(*) FROM follows WHERE follows.followee_id = users.id) AS followers,
+    (SELECT count(*) FROM moderation_actions
+        WHERE moderation_actions.user_id = users.id
+        AND moderation_actions.reversed_at IS NULL) AS strikes
+FROM users
+WHERE users.id = $1;
+
+-- name: ListTrustActivity :many
+SELECT users.id,
+    users.trust_level,
+    users.created_at,
+    (SELECT count(*) FROM chirps WHERE chirps.user_id = users.id) AS chirps,
+    (SELECT count(*) FROM likes
+        JOIN chirps ON chirps.id = likes.chirp_id
+        WHERE chirps.user_id = users.id
+        AND likes.user_id <> users.id) AS likes_received,
+    (SELECT count(*) FROM follows WHERE follows.followee_id = users.id) AS followers,
+    (SELECT count(*) FROM moderation_actions
+        WHERE moderation_actions.user_id = users.id
+        AND moderation_actions.reversed_at IS NULL) AS strikes
+FROM users
+WHERE users.deleted_at IS NULL;
+
+-- name: SetUserTrustLevel :exec
+UPDATE users
+SET trust_level = $2
+WHERE id = $1;
@@ -0,0 +1,48 @@
+-- name: StartTwoFactorEnrollment :execrows
+INSERT INTO two_factor (user_id, secret, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+    created_at = EXCLUDED.created_at,
+    last_used_step = 0
+WHERE two_factor.enabled_at IS NULL;
+
+-- name: GetTwoFactor :one
+SELECT * FROM two_factor
+WHERE user_id = $1;
+
+-- name: EnableTwoFactor :execrows
+UPDATE two_factor
+SET enabled_at = NOW(),
+    last_used_step = sqlc.arg(step)
+WHERE user_id = sqlc.arg(user_id)
+AND enabled_at IS NULL;
+
+-- name: UseTwoFactorStep :execrows
+UPDATE two_factor
+SET last_used_step = sqlc.arg(step)
+WHERE user_id = sqlc.arg(user_id)
+AND last_used_step < sqlc.arg(step);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash, created_at)
+VALUES ($1, $2, NOW());
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1
+AND code_hash = $2
+AND used_at IS NULL;
+
+-- name: HasRecoveryCode :one
+SELECT EXISTS (
+    SELECT 1 FROM recovery_codes
+    WHERE user_id = $1
+    AND code_hash = $2
+    AND used_at IS NULL
+);
@@ -0,0 +1,12 @@
+-- name: CreateUsernameChange :exec
+INSERT INTO username_changes (id, user_id, old_username, new_username, changed_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW());
+
+-- name: LastUsernameChange :one
+-- Picking a first username isn't a change from one.
+SELECT changed_at FROM username_changes
+WHERE user_id = $1
+AND old_username IS NOT NULL
+ORDER BY changed_at DESC
+LIMIT 1;
+
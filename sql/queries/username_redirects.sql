@@ -0,0 +1,33 @@
+-- name: CreateUsernameRedirect :exec
+INSERT INTO username_redirects (username, user_id, created_at, expires_at)
+VALUES ($1, $2, NOW(), $3)
+ON CONFLICT (username) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: UsernameReserved :one
+SELECT EXISTS (
+    SELECT 1 FROM username_redirects
+    WHERE username = $1
+    AND user_id <> $2
+    AND (expires_at IS NULL OR expires_at > NOW())
+);
+
+-- name: DeleteUsernameRedirect :exec
+DELETE FROM username_redirects
+WHERE username = $1
+AND user_id = $2;
+
+-- name: ResolveUsername :one
+-- The account a username leads to: the one that has it, or else the one
+-- it redirects to.
+SELECT id AS user_id, FALSE AS redirected FROM users
+WHERE username = sqlc.arg(username)::text
+AND deleted_at IS NULL
+UNION ALL
+SELECT user_id, TRUE AS redirected FROM username_redirects
+WHERE username = sqlc.arg(username)::text
+AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY redirected
+LIMIT 1;
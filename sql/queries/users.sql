@@ -1,11 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
@@ -17,6 +18,132 @@ WHERE email = $1;
 UPDATE users
 SET email = $2,
     hashed_password = $3,
+    username = COALESCE($4, username),
+    display_name = NULLIF(COALESCE($5, display_name), ''),
+    bio = NULLIF(COALESCE($6, bio), ''),
+    website = NULLIF(COALESCE($7, website), ''),
+    website_verified_at = CASE
+        WHEN NULLIF(COALESCE($7, website), '') IS NOT DISTINCT FROM website THEN website_verified_at
+    END,
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: UpdateUserByIDIfUnmodified :one
+UPDATE users
+SET email = $2,
+    hashed_password = $3,
+    username = COALESCE($5, username),
+    display_name = NULLIF(COALESCE($6, display_name), ''),
+    bio = NULLIF(COALESCE($7, bio), ''),
+    website = NULLIF(COALESCE($8, website), ''),
+    website_verified_at = CASE
+        WHEN NULLIF(COALESCE($8, website), '') IS NOT DISTINCT FROM website THEN website_verified_at
+    END,
+    updated_at = NOW()
+WHERE id = $1
+AND updated_at < $4
+RETURNING *;
+
+-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at > $2
+RETURNING *;
+
+-- name: LockPurgeableUsers :many
+SELECT id FROM users
+WHERE deleted_at < $1
+AND legal_hold_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE chirps.user_id = users.id
+    AND chirps.legal_hold_at IS NOT NULL
+)
+FOR UPDATE;
+
+-- name: PurgeUsers :many
+DELETE FROM users
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+RETURNING avatar_url;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByIDForUpdate :one
+-- Locks the user's row for the rest of the transaction.
+SELECT * FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateUserFeedRanking :one
+UPDATE users
+SET feed_ranking = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserLastSeen :exec
+UPDATE users
+SET last_seen_at = NOW()
+WHERE id = $1;
+
+-- name: EmailExists :one
+SELECT EXISTS (SELECT 1 FROM users WHERE email = $1);
+
+-- name: UsernameExists :one
+SELECT (
+    EXISTS (SELECT 1 FROM users WHERE username = $1)
+    OR EXISTS (
+        SELECT 1 FROM username_redirects
+        WHERE username = $1
+        AND (expires_at IS NULL OR expires_at > NOW())
+    )
+) AS exists;
+
+-- name: SetUserAvatar :one
+UPDATE users
+SET avatar_url = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserEmailDigest :one
+UPDATE users
+SET email_digest = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2,
+    updated_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: VerifyUserWebsite :execrows
+UPDATE users
+SET website_verified_at = NOW()
+WHERE id = $1
+AND website = $2;
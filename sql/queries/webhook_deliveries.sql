@@ -0,0 +1,35 @@
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, created_at, event_id, event_type, payload, status_code, response_snippet, error, succeeded)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5, $6, $7);
+
+-- name: DeleteOldWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries
+WHERE created_at < $1;
+
+-- name: GetLatestWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE event_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListFailedWebhookDeliveries :many
+-- The latest attempt at each event whose latest attempt failed, newest
+-- first.
+SELECT latest.* FROM (
+    SELECT DISTINCT ON (event_id) * FROM webhook_deliveries
+    ORDER BY event_id, created_at DESC
+) latest
+WHERE NOT latest.succeeded
+ORDER BY latest.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: DeleteWebhookDeliveriesAbout :execrows
+-- Attempts at delivering events about a user or their chirps, given by
+-- ID, for erasure.
+DELETE FROM webhook_deliveries
+WHERE payload->'data'->>'user_id' = sqlc.arg(user_id)::text
+OR payload->'data'->>'follower_id' = sqlc.arg(user_id)::text
+OR payload->'data'->>'followee_id' = sqlc.arg(user_id)::text
+OR payload->'data'->>'id' = sqlc.arg(user_id)::text
+OR payload->'data'->>'id' = ANY(sqlc.arg(chirp_ids)::text[])
+OR payload->'data'->>'chirp_id' = ANY(sqlc.arg(chirp_ids)::text[]);
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN feed_ranking TEXT NOT NULL DEFAULT 'top';
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN feed_ranking;
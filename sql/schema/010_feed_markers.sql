@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE feed_markers (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    timeline TEXT NOT NULL,
+    chirp_id UUID NOT NULL,
+    chirp_created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, timeline)
+);
+
+-- +goose Down
+DROP TABLE feed_markers;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- No foreign key: replies keep pointing at a deleted parent, so the
+-- thread can still be listed.
+ALTER TABLE chirps
+ADD COLUMN parent_chirp_id UUID;
+
+CREATE INDEX chirps_parent_chirp_id_idx ON chirps (parent_chirp_id);
+
+-- +goose Down
+DROP INDEX chirps_parent_chirp_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN parent_chirp_id;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Tags are stored lowercased, so lookups are case-insensitive.
+CREATE TABLE hashtags (
+    tag TEXT PRIMARY KEY CHECK (tag = lower(tag)),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE chirp_hashtags (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL REFERENCES hashtags(tag) ON DELETE CASCADE,
+    PRIMARY KEY (chirp_id, tag)
+);
+
+CREATE INDEX chirp_hashtags_tag_idx ON chirp_hashtags (tag);
+
+-- +goose Down
+DROP TABLE chirp_hashtags;
+DROP TABLE hashtags;
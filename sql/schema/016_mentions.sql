@@ -0,0 +1,16 @@
+-- +goose Up
+-- Usernames are stored lowercased, so mentions are case-insensitive.
+-- Existing accounts have none until they pick one.
+ALTER TABLE users ADD COLUMN username TEXT UNIQUE CHECK (username = lower(username));
+
+CREATE TABLE chirp_mentions (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX chirp_mentions_user_id_idx ON chirp_mentions (user_id);
+
+-- +goose Down
+DROP TABLE chirp_mentions;
+ALTER TABLE users DROP COLUMN username;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Serves the home feed: the newest chirps of each followed user.
+CREATE INDEX chirps_user_id_created_at_idx ON chirps (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX chirps_user_id_created_at_idx;
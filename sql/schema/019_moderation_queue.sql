@@ -0,0 +1,18 @@
+-- +goose Up
+-- Accounts and content waiting for a moderator. source says what raised
+-- the entry (e.g. anomaly) and signals holds the evidence.
+CREATE TABLE moderation_queue (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    signals JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX moderation_queue_status_created_at_idx ON moderation_queue (status, created_at);
+
+-- +goose Down
+DROP TABLE moderation_queue;
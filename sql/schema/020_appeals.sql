@@ -0,0 +1,39 @@
+-- +goose Up
+-- Suspended accounts can sign in and read, but only write appeals.
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+
+-- Actions moderators took against a user. Removed chirps are deleted,
+-- and kept in details so an approved appeal can put them back. chirp_id
+-- has no foreign key for the same reason.
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('remove_chirp', 'suspend_account')),
+    reason_code TEXT NOT NULL,
+    chirp_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    reversed_at TIMESTAMP
+);
+
+CREATE INDEX moderation_actions_user_id_idx ON moderation_actions (user_id);
+
+-- One appeal per action
+CREATE TABLE appeals (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    action_id UUID NOT NULL UNIQUE REFERENCES moderation_actions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied')),
+    decided_at TIMESTAMP
+);
+
+CREATE INDEX appeals_status_created_at_idx ON appeals (status, created_at);
+
+-- +goose Down
+DROP TABLE appeals;
+DROP TABLE moderation_actions;
+ALTER TABLE users DROP COLUMN suspended_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Set by admins to preserve an account or chirp, e.g. for a court order.
+-- Held rows can't be deleted by their owner, by moderators or by the
+-- purge of deleted accounts.
+ALTER TABLE users ADD COLUMN legal_hold_at TIMESTAMP;
+ALTER TABLE chirps ADD COLUMN legal_hold_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE chirps DROP COLUMN legal_hold_at;
+ALTER TABLE users DROP COLUMN legal_hold_at;
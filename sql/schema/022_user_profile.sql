@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN display_name TEXT CHECK (char_length(display_name) <= 50);
+ALTER TABLE users ADD COLUMN bio TEXT CHECK (char_length(bio) <= 160);
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
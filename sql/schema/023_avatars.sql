@@ -0,0 +1,6 @@
+-- +goose Up
+-- URL path of the user's avatar under /media, if they uploaded one.
+ALTER TABLE users ADD COLUMN avatar_url TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN avatar_url;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Audit trail of right-to-be-forgotten erasures. Only the ID the account
+-- had is kept, with the reference of the request (e.g. a ticket number)
+-- and the report of what was removed. After restoring a database backup,
+-- every erasure listed here has to be run again.
+CREATE TABLE erasures (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    reference TEXT NOT NULL,
+    report JSONB NOT NULL DEFAULT '{}',
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX erasures_created_at_idx ON erasures (created_at);
+
+-- +goose Down
+DROP TABLE erasures;
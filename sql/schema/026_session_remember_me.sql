@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE refresh_tokens ADD COLUMN remember_me BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN remember_me;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Public, user-curated lists of chirps. position orders the chirps of a
+-- collection; chirps appended later get a higher one.
+CREATE TABLE collections (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX collections_user_id_created_at_idx ON collections (user_id, created_at DESC);
+
+CREATE TABLE collection_chirps (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (collection_id, chirp_id)
+);
+
+CREATE INDEX collection_chirps_chirp_id_idx ON collection_chirps (chirp_id);
+
+-- +goose Down
+DROP TABLE collection_chirps;
+DROP TABLE collections;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE chirp_shares (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL CHECK (channel IN ('link', 'email', 'sms', 'x', 'facebook', 'whatsapp', 'other')),
+    UNIQUE (user_id, chirp_id, channel)
+);
+
+CREATE INDEX chirp_shares_chirp_id_idx ON chirp_shares (chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_shares;
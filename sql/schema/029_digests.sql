@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE digests (
+    day DATE PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE digest_chirps (
+    day DATE NOT NULL REFERENCES digests(day) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    engagement BIGINT NOT NULL,
+    PRIMARY KEY (day, chirp_id)
+);
+
+ALTER TABLE users
+ADD COLUMN email_digest BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN email_digest;
+
+DROP TABLE digest_chirps;
+DROP TABLE digests;
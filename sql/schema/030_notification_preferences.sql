@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event TEXT NOT NULL CHECK (event IN ('mention', 'like', 'follow', 'dm', 'rechirp')),
+    channel TEXT NOT NULL CHECK (channel IN ('in_app', 'email', 'push')),
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, event, channel)
+);
+
+-- +goose Down
+DROP TABLE notification_preferences;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE notification_quiet_hours (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    start_minute INTEGER NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute INTEGER NOT NULL CHECK (end_minute BETWEEN 0 AND 1439),
+    timezone TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE held_notifications (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    event TEXT NOT NULL,
+    actor_id UUID NOT NULL,
+    chirp_id UUID,
+    text TEXT NOT NULL
+);
+
+CREATE INDEX held_notifications_user_id_idx ON held_notifications (user_id);
+
+-- +goose Down
+DROP TABLE held_notifications;
+DROP TABLE notification_quiet_hours;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE metrics_history (
+    id UUID PRIMARY KEY,
+    recorded_at TIMESTAMP NOT NULL,
+    requests BIGINT NOT NULL,
+    query_count BIGINT NOT NULL,
+    query_seconds DOUBLE PRECISION NOT NULL,
+    users BIGINT NOT NULL,
+    chirps BIGINT NOT NULL
+);
+
+CREATE INDEX metrics_history_recorded_at_idx ON metrics_history (recorded_at);
+
+-- +goose Down
+DROP TABLE metrics_history;
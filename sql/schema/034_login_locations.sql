@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE login_locations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    country TEXT NOT NULL,
+    logged_in_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE login_verifications (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE login_verifications;
+DROP TABLE login_locations;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE device_tokens (
+    token TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform TEXT NOT NULL,
+    refresh_token TEXT NOT NULL REFERENCES refresh_tokens(token) ON DELETE CASCADE
+);
+
+CREATE INDEX device_tokens_user_id_idx ON device_tokens (user_id);
+CREATE INDEX device_tokens_refresh_token_idx ON device_tokens (refresh_token);
+
+-- +goose Down
+DROP TABLE device_tokens;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Reports keep chirp_id after the chirp is gone, for the per reason
+-- stats. hid_chirp and escalated mark the report that triggered an
+-- automated action.
+CREATE TABLE chirp_reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL,
+    hid_chirp BOOLEAN NOT NULL DEFAULT FALSE,
+    escalated BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+-- +goose Down
+DROP TABLE chirp_reports;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN trust_level TEXT NOT NULL DEFAULT 'new' CHECK (trust_level IN ('new', 'basic', 'trusted'));
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN trust_level;
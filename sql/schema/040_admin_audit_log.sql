@@ -0,0 +1,17 @@
+-- +goose Up
+-- What admins did, kept after the admin's account and the target are
+-- gone. details holds whatever is needed to tell what was affected, such
+-- as a copy of a deleted chirp.
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    admin_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    target_id UUID NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX admin_audit_log_created_at_idx ON admin_audit_log (created_at);
+
+-- +goose Down
+DROP TABLE admin_audit_log;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Reports are queued for moderators until one of them resolves it.
+ALTER TABLE chirp_reports
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    ADD COLUMN resolved_at TIMESTAMP,
+    ADD COLUMN resolved_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX chirp_reports_status_created_at_idx ON chirp_reports (status, created_at);
+
+-- +goose Down
+DROP INDEX chirp_reports_status_created_at_idx;
+ALTER TABLE chirp_reports
+    DROP COLUMN status,
+    DROP COLUMN resolved_at,
+    DROP COLUMN resolved_by;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Every attempt at POSTing an event to EVENT_WEBHOOK_URL. status_code is
+-- NULL when no response came back; error says why an attempt failed.
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status_code INTEGER,
+    response_snippet TEXT NOT NULL,
+    error TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_event_id_created_at_idx ON webhook_deliveries (event_id, created_at);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- The chirps each user was recently served in a feed, as the pair of
+-- Bloom filters of feed.Seen: a fixed 4 KiB per user rather than a row
+-- per chirp.
+CREATE TABLE seen_chirps (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_filter BYTEA NOT NULL,
+    previous_filter BYTEA NOT NULL,
+    rotated_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE seen_chirps;
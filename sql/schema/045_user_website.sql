@@ -0,0 +1,6 @@
+-- +goose Up
+-- Link shown on the user's profile, an absolute http or https URL.
+ALTER TABLE users ADD COLUMN website TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN website;
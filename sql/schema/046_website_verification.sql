@@ -0,0 +1,7 @@
+-- +goose Up
+-- When the user's website was found linking back to their profile with
+-- rel="me". Changing the website clears it.
+ALTER TABLE users ADD COLUMN website_verified_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN website_verified_at;
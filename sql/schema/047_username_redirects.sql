@@ -0,0 +1,15 @@
+-- +goose Up
+-- Usernames that no longer belong to an account but still lead to one,
+-- such as that of an account merged into another. Nobody else can take a
+-- redirected username until it expires; NULL never expires.
+CREATE TABLE username_redirects (
+    username TEXT PRIMARY KEY CHECK (username = lower(username)),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX username_redirects_user_id_idx ON username_redirects (user_id);
+
+-- +goose Down
+DROP TABLE username_redirects;
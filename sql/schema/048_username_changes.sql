@@ -0,0 +1,15 @@
+-- +goose Up
+-- Every change of a user's username. old_username is NULL when the user
+-- picked their first one.
+CREATE TABLE username_changes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_username TEXT,
+    new_username TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX username_changes_user_id_idx ON username_changes (user_id, changed_at);
+
+-- +goose Down
+DROP TABLE username_changes;
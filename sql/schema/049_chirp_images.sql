@@ -0,0 +1,13 @@
+-- +goose Up
+-- An image attached to a chirp. path is relative to the media root;
+-- followers_only images are stored under private/ and only served
+-- through signed links.
+CREATE TABLE chirp_images (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    path TEXT NOT NULL UNIQUE,
+    followers_only BOOLEAN NOT NULL DEFAULT false
+);
+
+-- +goose Down
+DROP TABLE chirp_images;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The audit log is kept forever, past the erasure of the people in it,
+-- so delete_chirp entries only keep IDs of the deleted chirp: drop the
+-- copies older entries hold.
+UPDATE admin_audit_log
+SET details = details - 'body' - 'created_at' - 'updated_at' - 'id'
+WHERE action = 'delete_chirp';
+
+-- +goose Down
+-- The chirps can't be put back.
+SELECT 1;
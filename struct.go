@@ -1,36 +1,202 @@
 package main
 
 import (
+	"database/sql"
+	"net/http"
+	"strconv"
 	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
+	"main.go/api"
+	"main.go/internal/anomaly"
+	"main.go/internal/auth"
+	"main.go/internal/broadcast"
+	"main.go/internal/chaos"
+	"main.go/internal/chirptext"
+	"main.go/internal/contentfilter"
 	"main.go/internal/database"
+	"main.go/internal/deadman"
+	"main.go/internal/events"
+	"main.go/internal/feed"
+	"main.go/internal/geoip"
+	"main.go/internal/health"
+	"main.go/internal/jobs"
+	"main.go/internal/mail"
+	"main.go/internal/media"
+	"main.go/internal/metrics"
+	"main.go/internal/notify"
+	"main.go/internal/oauth"
+	"main.go/internal/ratelimit"
+	"main.go/internal/reports"
+	"main.go/internal/response"
+	"main.go/internal/sanitize"
+	"main.go/internal/scheduler"
+	"main.go/internal/trust"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	DB             *database.Queries
-	PLATFORM       string
-	jwtSecret      string // Add this line
+	metrics             *metrics.Registry
+	prom                *serverMetrics
+	fileserverHits      *metrics.Counter
+	excludedHits        *metrics.Counter // requests left out of fileserverHits
+	hitExclusions       metrics.Exclusions
+	DB                  *database.Queries
+	sqlDB               *sql.DB // for transactions
+	PLATFORM            string
+	jwtKeys             auth.Keyring  // the first one signs access tokens
+	polkaKey            string        // API key of incoming webhooks
+	maxSessions         int           // outstanding refresh tokens per user
+	refreshTokenMaxAge  time.Duration // enables sliding expiration when set
+	deletionGracePeriod time.Duration
+	usernameGracePeriod time.Duration // old usernames redirect for it, 0 releases them at once
+	usernameCooldown    time.Duration // least time between username changes, 0 for no limit
+	health              *health.Checker
+	jobs                *jobs.Queue
+	scheduler           *scheduler.Scheduler
+	deadman             *deadman.Monitor
+	events              events.Publisher
+	webhooks            *events.WebhookPublisher // nil unless EVENT_BUS=webhook
+	ranker              feed.Ranker
+	mediaRoot           string
+	mediaStore          *media.Store
+	mediaSigningSecret  string
+	mediaAllowedHosts   []string
+	rateLimiter         *ratelimit.Limiter // authenticated requests, per user
+	trustedRateLimiter  *ratelimit.Limiter // authenticated requests, per trusted user
+	anonRateLimiter     *ratelimit.Limiter // anonymous requests, per IP
+	existsRateLimiter   *ratelimit.Limiter // account existence checks, per IP
+	resetRateLimiter    *ratelimit.Limiter // password reset emails, per IP
+	mailer              mail.Mailer
+	publicURL           string       // empty disables website verification
+	websiteClient       *http.Client // fetches users' websites
+	notifier            *notify.Dispatcher
+	oauthProviders      map[string]*oauth.Provider // by name, as in /api/auth/{provider}/
+	geoip               *geoip.Reader              // nil disables geo-velocity checks
+	loginMaxTravelSpeed float64                    // km/h
+	profanity           *profanityFilter           // also in contentFilter when enabled
+	contentFilter       contentfilter.ContentFilter
+	anomalies           *anomaly.Detector
+	chaos               *chaos.Injector              // nil outside dev and staging
+	readOnly            atomic.Bool                  // refuse writes, see middlewareReadOnly
+	maxBodySize         int64                        // bytes, see middlewareMaxBody
+	chirpStream         *broadcast.Hub[events.Event] // chirp.created events, for GET /api/chirps/stream
+	streamBuffer        int                          // chirps a stream client may fall behind
+	reportPolicies      map[string]reports.Policy    // by reason
+	trustRules          trust.Rules
 }
 
-type validateChirpRequest struct {
-	Body string `json:"body"`
+// newUser converts a database user to its API representation.
+func newUser(u database.User) api.User {
+	user := api.User{
+		ID:        u.ID,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+		Email:     u.Email,
+	}
+	if u.Username.Valid {
+		user.Username = &u.Username.String
+	}
+	user.DisplayName, user.Bio, user.Website = profileText(u)
+	user.WebsiteVerified = user.Website != nil && u.WebsiteVerifiedAt.Valid
+	if u.AvatarURL.Valid {
+		user.AvatarURL = &u.AvatarURL.String
+	}
+	return user
 }
 
-type validateChirpResponse struct {
-	CleanedBody string `json:"cleaned_body"`
+// profileText returns a user's display name, bio and website sanitized
+// again for showing, so that values stored before a sanitizer change
+// can't get out unchecked.
+func profileText(u database.User) (displayName, bio, website *string) {
+	if u.DisplayName.Valid {
+		s := sanitize.Text.HTML(u.DisplayName.String)
+		displayName = &s
+	}
+	if u.Bio.Valid {
+		s := sanitize.Inline.HTML(u.Bio.String)
+		bio = &s
+	}
+	if u.Website.Valid {
+		if s, err := sanitize.URL(u.Website.String); err == nil {
+			website = &s
+		}
+	}
+	return displayName, bio, website
 }
 
-type User struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Email     string    `json:"email"`
+// newChirp converts a database chirp to its API representation.
+func newChirp(c database.Chirp) api.Chirp {
+	chirp := api.Chirp{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		Body:      c.Body,
+		BodyHTML:  chirptext.HTML(c.Body),
+		UserID:    c.UserID.UUID,
+	}
+	if c.ParentChirpID.Valid {
+		chirp.ParentChirpID = &c.ParentChirpID.UUID
+	}
+	return chirp
 }
 
-type createUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+// chirpsETag is the ETag of a chirp, or a page of them with its paging
+// metadata in extra. Chirps are versioned by updated_at, but their
+// author's username and engagement counts change without it, so those
+// are included too.
+func chirpsETag(chirps []api.Chirp, extra ...string) string {
+	parts := extra
+	for _, c := range chirps {
+		parts = append(parts,
+			c.ID.String(),
+			c.UpdatedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(c.LikesCount, 10),
+			strconv.FormatInt(c.ReplyCount, 10),
+			strconv.FormatInt(c.RechirpCount, 10),
+			strconv.FormatInt(c.ShareCount, 10),
+		)
+		if c.Username != nil {
+			parts = append(parts, *c.Username)
+		}
+		if c.Rechirp != nil {
+			parts = append(parts, c.Rechirp.ID.String())
+		}
+	}
+	return response.ETag(parts...)
+}
+
+// newCollection converts a database collection to its API
+// representation, without its chirps.
+func newCollection(c database.Collection) api.Collection {
+	return api.Collection{
+		ID:          c.ID,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		UserID:      c.UserID,
+		Name:        c.Name,
+		Description: c.Description,
+	}
+}
+
+// newListedChirp converts a row of the chirp listings, which may be a
+// rechirp of the chirp.
+func newListedChirp(row database.GetChirpsRow) api.Chirp {
+	chirp := newChirp(database.Chirp{
+		ID:            row.ID,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+		Body:          row.Body,
+		UserID:        row.UserID,
+		SearchVector:  row.SearchVector,
+		ParentChirpID: row.ParentChirpID,
+	})
+	if row.RechirpID.Valid {
+		chirp.Rechirp = &api.Rechirp{
+			ID:        row.RechirpID.UUID,
+			ChirpID:   row.ID,
+			UserID:    row.PostedBy.UUID,
+			CreatedAt: row.PostedAt,
+		}
+	}
+	return chirp
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"main.go/internal/events"
+)
+
+// Revoked and expired refresh tokens are kept around for a while so
+// their use can still be investigated. The same goes for password reset
+// tokens after they expire.
+const staleRefreshTokenRetention = 7 * 24 * time.Hour
+
+// purgeDeletedUsersTask removes accounts whose deletion grace period
+// has expired, together with everything they posted.
+//
+// Chirps are deleted rather than kept under a tombstone author, so a
+// purged account leaves nothing behind. Likes, rechirps, follows and
+// sessions go with the user and the chirps, and avatars are removed from
+// media storage. Replies by other users stay up and keep their
+// parent_chirp_id, so the thread can still be listed.
+//
+// Accounts under legal hold, or with a chirp under one, are skipped and
+// stay soft-deleted until the hold is released.
+func (cfg *apiConfig) purgeDeletedUsersTask(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-cfg.deletionGracePeriod)
+
+	tx, err := cfg.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := cfg.txQueries(tx)
+
+	// Locking the rows stops a concurrent restore from saving a user
+	// whose chirps are already gone
+	userIDs, err := q.LockPurgeableUsers(ctx, sql.NullTime{Time: cutoff, Valid: true})
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	chirps, err := q.DeleteChirpsByUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	avatars, err := q.PurgeUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, avatar := range avatars {
+		if err := cfg.removeAvatar(avatar.String); err != nil {
+			slog.Warn("Couldn't remove avatar of purged user", "error", err)
+		}
+	}
+
+	for _, chirp := range chirps {
+		cfg.events.Publish(ctx, events.New(events.ChirpDeleted, events.ChirpData{
+			ID:        chirp.ID,
+			UserID:    chirp.UserID.UUID,
+			CreatedAt: chirp.CreatedAt,
+		}))
+	}
+	slog.Info("Purged deleted users", "users", len(avatars), "chirps", len(chirps))
+	return nil
+}
+
+// cleanupRefreshTokensTask removes refresh tokens that expired or were
+// revoked more than staleRefreshTokenRetention ago.
+func (cfg *apiConfig) cleanupRefreshTokensTask(ctx context.Context) error {
+	n, err := cfg.DB.DeleteStaleRefreshTokens(ctx, time.Now().UTC().Add(-staleRefreshTokenRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("Deleted stale refresh tokens", "count", n)
+	}
+	return nil
+}
+
+// cleanupPasswordResetTokensTask removes password reset tokens that
+// expired more than staleRefreshTokenRetention ago, used or not.
+func (cfg *apiConfig) cleanupPasswordResetTokensTask(ctx context.Context) error {
+	n, err := cfg.DB.DeleteStalePasswordResetTokens(ctx, time.Now().UTC().Add(-staleRefreshTokenRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("Deleted stale password reset tokens", "count", n)
+	}
+	return nil
+}
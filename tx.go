@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"main.go/internal/database"
+)
+
+// withTx runs fn in a database transaction, committing if it returns nil.
+func (cfg *apiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(cfg.txQueries(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// txQueries is cfg.DB.WithTx, keeping the query timing.
+func (cfg *apiConfig) txQueries(tx *sql.Tx) *database.Queries {
+	return database.New(timedDB{db: tx, durations: cfg.prom.queryDuration})
+}